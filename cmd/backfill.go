@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backfillChain      string
+	backfillFromHeight int64
+	backfillToHeight   int64
+	backfillCmd        = &cobra.Command{
+		Use:   "backfill",
+		Short: "Rebuild ibc txs of a chain in a height range, the live sync cursor is not touched.",
+		Run: func(cmd *cobra.Command, args []string) {
+			backfill()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(backfillCmd)
+	backfillCmd.Flags().StringVarP(&ConfigFilePath, "CONFIG", "c", "", "conf path: /opt/local.toml, default from env CONFIG_FILE_PATH")
+	backfillCmd.Flags().StringVar(&backfillChain, "chain", "", "chain name")
+	backfillCmd.Flags().Int64Var(&backfillFromHeight, "from", 0, "from height(inclusive)")
+	backfillCmd.Flags().Int64Var(&backfillToHeight, "to", 0, "to height(inclusive)")
+	_ = backfillCmd.MarkFlagRequired("chain")
+	_ = backfillCmd.MarkFlagRequired("from")
+	_ = backfillCmd.MarkFlagRequired("to")
+}
+
+func backfill() {
+	if ConfigFilePath == "" {
+		filepath, found := os.LookupEnv(constant.EnvNameConfigFilePath)
+		if !found {
+			panic("not found CONFIG_FILE_PATH")
+		}
+		ConfigFilePath = filepath
+	}
+	data, err := ioutil.ReadFile(ConfigFilePath)
+	if err != nil {
+		panic(err)
+	}
+	config, err := conf.ReadConfig(data)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := app.Backfill(config, backfillChain, backfillFromHeight, backfillToHeight)
+	if res != nil {
+		fmt.Printf("inserted: %d, updated: %d, unchanged: %d, related: %d\n", res.Inserted, res.Updated, res.Unchanged, res.Related)
+	}
+	if err != nil {
+		fmt.Printf("backfill %s [%d, %d] error: %v\n", backfillChain, backfillFromHeight, backfillToHeight, err)
+		os.Exit(1)
+	}
+}
@@ -34,3 +34,19 @@ func (ctl *ChannelController) List(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, response.Success(res))
 }
+
+// LatencyTrend send->recv and recv->ack latency of the channel, both directions
+func (ctl *ChannelController) LatencyTrend(c *gin.Context) {
+	var req vo.ChannelLatencyTrendReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+
+	res, err := latencyService.ChannelLatencyTrend(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(res))
+}
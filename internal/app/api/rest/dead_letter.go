@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+)
+
+type DeadLetterController struct {
+}
+
+func (ctl *DeadLetterController) List(c *gin.Context) {
+	var req vo.DeadLettersReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	if req.UseCount {
+		count, err := deadLetterService.DeadLettersCount(&req)
+		if err != nil {
+			c.JSON(http.StatusOK, response.FailError(err))
+			return
+		}
+		c.JSON(http.StatusOK, response.Success(count))
+		return
+	}
+	resp, err := deadLetterService.DeadLetters(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *DeadLetterController) Detail(c *gin.Context) {
+	resp, err := deadLetterService.DeadLetterDetail(c.Param("record_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *DeadLetterController) Replay(c *gin.Context) {
+	if err := deadLetterService.Replay(c.Param("record_id")); err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success("replayed"))
+}
+
+func (ctl *DeadLetterController) Discard(c *gin.Context) {
+	if err := deadLetterService.Discard(c.Param("record_id")); err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success("discarded"))
+}
@@ -37,11 +37,12 @@ func (ctl *RelayerController) List(c *gin.Context) {
 
 func (ctl *RelayerController) Collect(c *gin.Context) {
 	filepath := c.PostForm("filepath")
-	if err := relayerService.Collect(filepath); err != nil {
+	diff, err := relayerService.Collect(filepath, c.PostForm("dry_run") == "true")
+	if err != nil {
 		c.JSON(http.StatusOK, response.FailError(err))
 		return
 	}
-	c.JSON(http.StatusOK, response.Success(nil))
+	c.JSON(http.StatusOK, response.Success(diff))
 }
 
 func (ctl *RelayerController) TransferTypeTxs(c *gin.Context) {
@@ -89,6 +90,21 @@ func (ctl *RelayerController) TotalFeeCost(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Success(res))
 }
 
+func (ctl *RelayerController) TotalFeeIncome(c *gin.Context) {
+	relayerId := c.Param("relayer_id")
+	if relayerId == "" {
+		c.JSON(http.StatusOK, response.FailBadRequest(fmt.Errorf("invalid relayer id")))
+		return
+	}
+
+	res, err := relayerService.TotalFeeIncome(relayerId)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(res))
+}
+
 func (ctl *RelayerController) Detail(c *gin.Context) {
 	relayerId := c.Param("relayer_id")
 	var res interface{}
@@ -153,3 +169,19 @@ func (ctl *RelayerController) RelayerTrend(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, response.Success(res))
 }
+
+func (ctl *RelayerController) LatencyTrend(c *gin.Context) {
+	relayerId := c.Param("relayer_id")
+	var req vo.RelayerLatencyTrendReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+
+	res, err := latencyService.RelayerLatencyTrend(relayerId, &req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(res))
+}
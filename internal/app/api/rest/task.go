@@ -1,13 +1,17 @@
 package rest
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -21,69 +25,170 @@ func (ctl *TaskController) Run(c *gin.Context) {
 		c.JSON(http.StatusOK, response.FailBadRequest(fmt.Errorf("task name is empty")))
 		return
 	}
+	if !task.SchedulerStarted() {
+		c.JSON(http.StatusServiceUnavailable, response.FailMsg("tasks are not started on this instance"))
+		return
+	}
 	lockKey := fmt.Sprintf("%s:%s", "TaskController", taskName)
 	if err := cache.GetRedisClient().Lock(lockKey, time.Now().Unix(), time.Hour); err != nil {
 		c.JSON(http.StatusTooManyRequests, response.FailMsg("Please try again later"))
 		return
 	}
 
-	go func() {
+	// the run has the context of the scheduler, see task.RunWithLock
+	started := task.GoRun(func(context.Context) {
 		st := time.Now().Unix()
-		res := 0
 		logrus.Infof("TaskController task %s start", taskName)
+		res := task.RunWithRecord(taskName, entity.TaskRunTriggerAdmin, func() int {
+			return ctl.run(c, taskName)
+		})
+		logrus.Infof("TaskController task %s end, time use %d(s), exec status: %d", taskName, time.Now().Unix()-st, res)
+	})
+	if !started {
+		_, _ = cache.GetRedisClient().Del(lockKey)
+		c.JSON(http.StatusServiceUnavailable, response.FailMsg("server is shutting down"))
+		return
+	}
+	time.Sleep(1 * time.Second)
+	c.JSON(http.StatusOK, response.Success("task is running"))
 
-		switch taskName {
-		case addChainTask.Name():
+}
+
+// run the task with the pause flag and the lock of the task, the same as a scheduled run
+func (ctl *TaskController) run(c *gin.Context, taskName string) int {
+	res, err := task.RunWithLock(taskName, func(ctx context.Context) int {
+		return ctl.runTask(ctx, c, taskName)
+	})
+	if err != nil {
+		logrus.Errorf("TaskController run %s err, %v", taskName, err)
+		return -1
+	}
+	return res
+}
+
+func (ctl *TaskController) runTask(ctx context.Context, c *gin.Context, taskName string) int {
+	res := 0
+	switch taskName {
+	case addChainTask.Name():
+		if c.PostForm("dry_run") == "true" {
+			res = addChainTask.DryRunWithParam(c.PostForm("new_chains"))
+		} else {
 			res = addChainTask.RunWithParam(c.PostForm("new_chains"))
-		case tokenStatisticsTask.Name():
-			res = tokenStatisticsTask.Run()
-		case channelStatisticsTask.Name():
-			res = channelStatisticsTask.Run()
-		case relayerStatisticsTask.Name():
-			chain := c.PostForm("chain")
-			if chain == "" {
-				res = relayerStatisticsTask.Run()
-			} else {
-				startTime, err := strconv.ParseInt(c.PostForm("start_time"), 10, 64)
-				if err != nil {
-					logrus.Errorf("TaskController run %s err, %v", taskName, err)
-					return
-				}
-				endTime, err := strconv.ParseInt(c.PostForm("end_time"), 10, 64)
-				if err != nil {
-					logrus.Errorf("TaskController run %s err, %v", taskName, err)
-					return
-				}
-				res = relayerStatisticsTask.RunWithParam(chain, startTime, endTime)
+		}
+	case tokenStatisticsTask.Name():
+		res = tokenStatisticsTask.Run(ctx)
+	case channelStatisticsTask.Name():
+		res = channelStatisticsTask.Run(ctx)
+	case relayerStatisticsTask.Name():
+		chain := c.PostForm("chain")
+		if chain == "" {
+			res = relayerStatisticsTask.Run(ctx)
+		} else {
+			startTime, err := strconv.ParseInt(c.PostForm("start_time"), 10, 64)
+			if err != nil {
+				logrus.Errorf("TaskController run %s err, %v", taskName, err)
+				return -1
 			}
-		case addTransferDataTask.Name():
-			addTransferDataTask.RunWithParam(c.PostForm("new_chains"))
-		case ibcNodeLcdCronTask.Name():
-			value := c.PostForm("chains")
-			if len(value) > 0 {
-				ibcNodeLcdCronTask.RunWithParam(value)
-			} else {
-				ibcNodeLcdCronTask.Run()
+			endTime, err := strconv.ParseInt(c.PostForm("end_time"), 10, 64)
+			if err != nil {
+				logrus.Errorf("TaskController run %s err, %v", taskName, err)
+				return -1
 			}
-		case ibcStatisticCronTask.Name():
-			ibcStatisticCronTask.NewRun()
-		case fixRelayerStatisticsTask.Name():
-			fixRelayerStatisticsTask.Run()
-		case relayerAddressInitTask.Name():
-			relayerAddressInitTask.Run()
-		case chainInflowStatisticsTask.Name():
-			res = chainInflowStatisticsTask.RunFullStatistics()
-		case chainOutflowStatisticsTask.Name():
-			res = chainOutflowStatisticsTask.RunFullStatistics()
-		case ibcDenomHopsTask.Name():
-			res = ibcDenomHopsTask.Run()
-		default:
-			logrus.Errorf("TaskController run %s err, %s", taskName, "unknown task")
+			res = relayerStatisticsTask.RunWithParam(ctx, chain, startTime, endTime)
+		}
+	case addTransferDataTask.Name():
+		if c.PostForm("dry_run") == "true" {
+			res = addTransferDataTask.DryRunWithParam(c.PostForm("new_chains"))
+		} else {
+			res = addTransferDataTask.RunWithParam(c.PostForm("new_chains"))
+		}
+	case ibcNodeLcdCronTask.Name():
+		value := c.PostForm("chains")
+		if len(value) > 0 {
+			ibcNodeLcdCronTask.RunWithParam(ctx, value)
+		} else {
+			ibcNodeLcdCronTask.Run(ctx)
+		}
+	case ibcStatisticCronTask.Name():
+		ibcStatisticCronTask.NewRun()
+	case fixRelayerStatisticsTask.Name():
+		fixRelayerStatisticsTask.Run(ctx)
+	case relayerAddressInitTask.Name():
+		relayerAddressInitTask.Run(ctx)
+	case chainInflowStatisticsTask.Name():
+		res = chainInflowStatisticsTask.RunFullStatistics(ctx)
+	case chainOutflowStatisticsTask.Name():
+		res = chainOutflowStatisticsTask.RunFullStatistics(ctx)
+	case packetLatencyTask.Name():
+		res = packetLatencyTask.RunFullStatistics(ctx)
+	case ibcDenomHopsTask.Name():
+		res = ibcDenomHopsTask.Run(ctx)
+	case ibcTxBackfillTask.Name():
+		fromHeight, err := strconv.ParseInt(c.PostForm("from_height"), 10, 64)
+		if err != nil {
+			logrus.Errorf("TaskController run %s err, %v", taskName, err)
+			return -1
+		}
+		toHeight, err := strconv.ParseInt(c.PostForm("to_height"), 10, 64)
+		if err != nil {
+			logrus.Errorf("TaskController run %s err, %v", taskName, err)
+			return -1
+		}
+		backfillRes, err := ibcTxBackfillTask.RunWithParam(ctx, c.PostForm("chain"), fromHeight, toHeight)
+		if err != nil {
+			logrus.Errorf("TaskController run %s err, %v", taskName, err)
+			res = -1
+		} else {
+			logrus.Infof("TaskController task %s result, %+v", taskName, *backfillRes)
+			res = 1
+		}
+	default:
+		t, ok := task.GetTask(taskName)
+		if !ok {
+			logrus.Errorf("TaskController run %s err, unknown task", taskName)
+			return -1
 		}
+		res = t.Run(ctx)
+	}
+	return res
+}
 
-		logrus.Infof("TaskController task %s end, time use %d(s), exec status: %d", taskName, time.Now().Unix()-st, res)
-	}()
-	time.Sleep(1 * time.Second)
-	c.JSON(http.StatusOK, response.Success("task is running"))
+// Tasks the last run and the run counts of the tasks
+func (ctl *TaskController) Tasks(c *gin.Context) {
+	var req vo.TaskListReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
 
+	resp, err := taskRunService.Tasks(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *TaskController) Runs(c *gin.Context) {
+	taskName := c.Param("task_name")
+	var req vo.TaskRunsReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	if req.UseCount {
+		count, err := taskRunService.TaskRunsCount(taskName, &req)
+		if err != nil {
+			c.JSON(http.StatusOK, response.FailError(err))
+			return
+		}
+		c.JSON(http.StatusOK, response.Success(count))
+		return
+	}
+	resp, err := taskRunService.TaskRuns(taskName, &req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
 }
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+)
+
+type TaskAdminController struct {
+}
+
+// List registered tasks with the next run time, pause flag and lock
+func (ctl *TaskAdminController) List(c *gin.Context) {
+	resp, err := taskAdminService.List()
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *TaskAdminController) Pause(c *gin.Context) {
+	if err := taskAdminService.Pause(c.Param("task_name")); err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success("paused"))
+}
+
+func (ctl *TaskAdminController) Resume(c *gin.Context) {
+	if err := taskAdminService.Resume(c.Param("task_name")); err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success("resumed"))
+}
+
+func (ctl *TaskAdminController) Unlock(c *gin.Context) {
+	var req vo.TaskUnlockReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	if err := taskAdminService.Unlock(c.Param("task_name"), &req); err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success("unlocked"))
+}
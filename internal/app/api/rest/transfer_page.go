@@ -66,3 +66,17 @@ func (ctl *IbcTransferController) SearchCondition(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, response.Success(resp))
 }
+
+func (ctl *IbcTransferController) FailureStatistics(c *gin.Context) {
+	var req vo.TransferFailureStatisticsReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	resp, err := transferService.FailureStatistics(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
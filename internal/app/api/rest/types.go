@@ -6,15 +6,22 @@ import (
 )
 
 var (
-	tokenService    service.ITokenService    = new(service.TokenService)
-	channelService  service.IChannelService  = new(service.ChannelService)
-	chainService    service.IChainService    = new(service.ChainService)
-	addressService  service.IAddressService  = new(service.AddressService)
-	relayerService  service.IRelayerService  = new(service.RelayerService)
-	homeService     service.IHomeService     = new(service.HomeService)
-	transferService service.ITransferService = new(service.TransferService)
-	overviewService service.IOverviewService = new(service.OverviewService)
-	cacheService    service.CacheService
+	tokenService       service.ITokenService       = new(service.TokenService)
+	channelService     service.IChannelService     = new(service.ChannelService)
+	chainService       service.IChainService       = new(service.ChainService)
+	addressService     service.IAddressService     = new(service.AddressService)
+	relayerService     service.IRelayerService     = new(service.RelayerService)
+	homeService        service.IHomeService        = new(service.HomeService)
+	transferService    service.ITransferService    = new(service.TransferService)
+	nftTransferService service.INftTransferService = new(service.NftTransferService)
+	icaService         service.IIcaService         = new(service.IcaService)
+	overviewService    service.IOverviewService    = new(service.OverviewService)
+	deadLetterService  service.IDeadLetterService  = new(service.DeadLetterService)
+	latencyService     service.ILatencyService     = new(service.LatencyService)
+	taskRunService     service.ITaskRunService     = new(service.TaskRunService)
+	taskAdminService   service.ITaskAdminService   = new(service.TaskAdminService)
+	ibcClientService   service.IIbcClientService   = new(service.IbcClientService)
+	cacheService       service.CacheService
 
 	// task
 	addChainTask               task.AddChainTask
@@ -28,5 +35,7 @@ var (
 	relayerAddressInitTask     task.IbcRelayerAddressInitTask
 	chainInflowStatisticsTask  task.ChainInflowStatisticsTask
 	chainOutflowStatisticsTask task.ChainOutflowStatisticsTask
+	packetLatencyTask          task.PacketLatencyStatisticsTask
 	ibcDenomHopsTask           task.IBCDenomHopsTask
+	ibcTxBackfillTask          task.IbcTxBackfillTask
 )
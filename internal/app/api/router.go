@@ -33,12 +33,17 @@ func Routers(Router *gin.Engine) {
 	ibcRouter := Router.Group("ibc")
 	homePage(ibcRouter)
 	txsPage(ibcRouter)
+	nftTxsPage(ibcRouter)
+	icaPage(ibcRouter)
 	tokenPage(ibcRouter)
 	channelPage(ibcRouter)
 	chainPage(ibcRouter)
+	clientPage(ibcRouter)
 	relayerPage(ibcRouter)
 	cacheTools(ibcRouter)
 	taskTools(ibcRouter)
+	deadLetterTools(ibcRouter)
+	taskAdminTools(ibcRouter)
 	addressPage(ibcRouter)
 	overviewPage(ibcRouter)
 }
@@ -59,6 +64,22 @@ func txsPage(r *gin.RouterGroup) {
 	r.GET("/txs_detail/:hash", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TransferTxDetailNew))
 	r.GET("/trace_source/:hash", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TraceSource))
 	r.GET("/txs/searchCondition", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.SearchCondition))
+	r.GET("/txs/failureStatistics", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.FailureStatistics))
+}
+
+func nftTxsPage(r *gin.RouterGroup) {
+	ctl := rest.NftTransferController{}
+	r.GET("/nft/txs", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.NftTransferTxs))
+	r.GET("/nft/txs_detail/:hash", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.NftTransferTxDetail))
+	r.GET("/nft/classes", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.NftClasses))
+}
+
+func icaPage(r *gin.RouterGroup) {
+	ctl := rest.IcaController{}
+	r.GET("/ica/controller/:address/txs", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.ControllerIcaTxs))
+	r.GET("/ica/host/:chain/txs", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.HostIcaTxs))
+	r.GET("/ica/txs_detail/:hash", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.IcaTxDetail))
+	r.GET("/ica/accounts", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.IcaAccounts))
 }
 
 func tokenPage(r *gin.RouterGroup) {
@@ -70,6 +91,7 @@ func tokenPage(r *gin.RouterGroup) {
 func channelPage(r *gin.RouterGroup) {
 	ctl := rest.ChannelController{}
 	r.GET("/channelList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
+	r.GET("/channel/latencyTrend", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.LatencyTrend))
 }
 
 func chainPage(r *gin.RouterGroup) {
@@ -77,6 +99,12 @@ func chainPage(r *gin.RouterGroup) {
 	r.GET("/chainList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
 }
 
+func clientPage(r *gin.RouterGroup) {
+	ctl := rest.ClientController{}
+	r.GET("/clients", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
+	r.GET("/clients/expiring", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.Expiring))
+}
+
 func relayerPage(r *gin.RouterGroup) {
 	ctl := rest.RelayerController{}
 	r.GET("/relayerList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
@@ -84,10 +112,12 @@ func relayerPage(r *gin.RouterGroup) {
 	r.GET("/relayer/:relayer_id/txs", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.DetailRelayerTxs))
 	r.GET("/relayer/names", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.RelayerNameList))
 	r.GET("/relayer/:relayer_id/relayedTrend", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.RelayerTrend))
+	r.GET("/relayer/:relayer_id/latencyTrend", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.LatencyTrend))
 	r.POST("/relayerCollect", ctl.Collect)
 	r.GET("/relayer/:relayer_id/transferTypeTxs", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TransferTypeTxs))
 	r.GET("/relayer/:relayer_id/totalRelayedValue", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TotalRelayedValue))
 	r.GET("/relayer/:relayer_id/totalFeeCost", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TotalFeeCost))
+	r.GET("/relayer/:relayer_id/totalFeeIncome", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TotalFeeIncome))
 }
 
 func addressPage(r *gin.RouterGroup) {
@@ -115,4 +145,22 @@ func cacheTools(r *gin.RouterGroup) {
 func taskTools(r *gin.RouterGroup) {
 	ctl := rest.TaskController{}
 	r.POST("/task/:task_name", ctl.Run)
+	r.GET("/tasks", ctl.Tasks)
+	r.GET("/tasks/:task_name/runs", ctl.Runs)
+}
+
+func deadLetterTools(r *gin.RouterGroup) {
+	ctl := rest.DeadLetterController{}
+	r.GET("/deadLetters", ctl.List)
+	r.GET("/deadLetters/:record_id", ctl.Detail)
+	r.POST("/deadLetters/:record_id/replay", ctl.Replay)
+	r.DELETE("/deadLetters/:record_id", ctl.Discard)
+}
+
+func taskAdminTools(r *gin.RouterGroup) {
+	ctl := rest.TaskAdminController{}
+	r.GET("/taskAdmin/tasks", ctl.List)
+	r.POST("/taskAdmin/tasks/:task_name/pause", ctl.Pause)
+	r.POST("/taskAdmin/tasks/:task_name/resume", ctl.Resume)
+	r.DELETE("/taskAdmin/tasks/:task_name/lock", ctl.Unlock)
 }
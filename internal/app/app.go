@@ -2,9 +2,13 @@ package app
 
 import (
 	"context"
+	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api"
@@ -13,6 +17,7 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/distributiontask"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
@@ -21,6 +26,8 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const defaultShutdownTimeout = 30 * time.Second
+
 func Serve(cfg *conf.Config) {
 	time.Local = time.UTC
 	initCore(cfg)
@@ -35,13 +42,62 @@ func Serve(cfg *conf.Config) {
 	if cfg.App.StartMonitor {
 		go monitor.Start(cfg.App.Prometheus)
 	}
+	var distributionTask *distributiontask.DistributedTask
 	if cfg.App.StartTask {
-		go startTask(cfg.Redis)
+		distributionTask = startTask(cfg.Redis)
 	}
 	//if cfg.App.StartOneOffTask {
 	//	go startOneOffTask()
 	//}
-	logrus.Fatal(r.Run(cfg.App.Addr))
+
+	srv := &http.Server{
+		Addr:    cfg.App.Addr,
+		Handler: r,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Fatal(err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	logrus.Infof("receive signal %s, shutting down", sig)
+	shutdown(cfg, srv, distributionTask)
+}
+
+// shutdown drain the http server and stop the tasks at the same time, tasks finish their current batches and release
+// the locks they hold
+func shutdown(cfg *conf.Config, srv *http.Server, distributionTask *distributiontask.DistributedTask) {
+	timeout := defaultShutdownTimeout
+	if cfg.App.ShutdownTimeout > 0 {
+		timeout = time.Duration(cfg.App.ShutdownTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// admin runs are started by the http server, tasks are shut down even if they are not scheduled on this instance
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		task.Shutdown(ctx, distributionTask)
+	}()
+	if err := srv.Shutdown(ctx); err != nil {
+		logrus.Errorf("http server shutdown error, %v", err)
+	}
+	wg.Wait()
+	logrus.Info("server exited")
+}
+
+// Backfill rebuild ibc txs of chain in [fromHeight, toHeight], see task.IbcTxBackfillTask
+func Backfill(cfg *conf.Config, chain string, fromHeight, toHeight int64) (*task.IbcTxBackfillResult, error) {
+	time.Local = time.UTC
+	initCore(cfg)
+	defer repository.Close()
+
+	return new(task.IbcTxBackfillTask).RunWithParam(context.Background(), chain, fromHeight, toHeight)
 }
 
 func initCore(cfg *conf.Config) {
@@ -50,6 +106,7 @@ func initCore(cfg *conf.Config) {
 	repository.InitMgo(cfg.Mongo, context.Background())
 	repository.LoadIndexNameConf(cfg.HintIndexName)
 	cache.InitRedisClient(cfg.Redis)
+	lcd.Init(cfg.Lcd)
 	task.LoadTaskConf(cfg.Task)
 }
 
@@ -83,7 +140,7 @@ func initLogger(logCfg *conf.Log) {
 	}
 }
 
-func startTask(c conf.Redis) {
+func startTask(c conf.Redis) *distributiontask.DistributedTask {
 	distributionTask, err := distributiontask.NewDistributedTaskWithRedis(c.Addrs, c.User, c.Password, string(c.Mode), c.Db)
 	if err != nil {
 		logrus.Fatal(err)
@@ -103,14 +160,22 @@ func startTask(c conf.Redis) {
 		&task.IbcSyncTransferTxTask{},
 		&task.IbcTxRelateTask{},
 		&task.IbcTxRelateHistoryTask{},
+		&task.IbcSyncNftTransferTxTask{},
+		&task.IbcNftTxRelateTask{},
+		&task.IbcSyncIcaTxTask{},
+		&task.IbcIcaTxRelateTask{},
+		&task.IbcSyncPacketFeeTask{},
 		&task.IbcTxMigrateTask{},
 		&task.IbcNodeLcdCronTask{},
 		&task.ChainInflowStatisticsTask{},
 		&task.ChainOutflowStatisticsTask{},
+		&task.PacketLatencyStatisticsTask{},
+		&task.IbcClientTask{},
 	)
 
-	go distributionTask.Start()
-	go task.Start()
+	go task.Start(distributionTask)
+	task.StartIbcTxRelateWatcher(distributionTask.Context())
+	return distributionTask
 }
 
 func startOneOffTask() {
@@ -15,6 +15,8 @@ type Config struct {
 	Redis         Redis
 	Log           Log
 	Spi           Spi
+	Registry      RelayerRegistry `mapstructure:"relayer_registry"`
+	Lcd           Lcd
 	Task          Task
 	ChainConfig   ChainConfig `mapstructure:"chain_config"`
 }
@@ -50,6 +52,7 @@ type App struct {
 	StartOneOffTask      bool  `mapstructure:"start_one_off_task"`
 	ApiCacheAliveSeconds int   `mapstructure:"api_cache_alive_seconds"`
 	MaxPageSize          int64 `mapstructure:"max_page_size"`
+	ShutdownTimeout      int   `mapstructure:"shutdown_timeout"` // seconds
 	Version              string
 	Prometheus           string `mapstructure:"prometheus_port"`
 }
@@ -85,10 +88,20 @@ type Task struct {
 	CronTimeIbcTxMigrateTask              int    `mapstructure:"cron_time_ibc_tx_migrate_task"`
 	CronTimeIBCChainInflowStatisticsTask  int    `mapstructure:"cron_time_ibc_chain_inflow_statistics_task"`
 	CronTimeIBCChainOutflowStatisticsTask int    `mapstructure:"cron_time_ibc_chain_outflow_statistics_task"`
+	CronTimePacketLatencyStatisticsTask   int    `mapstructure:"cron_time_packet_latency_statistics_task"`
+	CronTimeIbcClientTask                 int    `mapstructure:"cron_time_ibc_client_task"`
 	RedisLockExpireTime                   int    `mapstructure:"redis_lock_expire_time"`
+	ChainLeaseExpireTime                  int    `mapstructure:"chain_lease_expire_time"`
 	SingleChainSyncTransferTxMax          int    `mapstructure:"single_chain_sync_transfer_tx_max"`
 	SingleChainIbcTxRelateMax             int    `mapstructure:"single_chain_ibc_tx_relate_max"`
+	IbcTxDeadLetterRetryTimes             int64  `mapstructure:"ibc_tx_dead_letter_retry_times"`
+	StatisticsCheckpointMaxAge            int64  `mapstructure:"statistics_checkpoint_max_age"`
 	CronTimeSyncAckTxTask                 int    `mapstructure:"cron_time_sync_ack_tx_task"`
+	CronTimeSyncNftTransferTxTask         int    `mapstructure:"cron_time_sync_nft_transfer_tx_task"`
+	CronTimeIbcNftTxRelateTask            int    `mapstructure:"cron_time_ibc_nft_tx_relate_task"`
+	CronTimeSyncIcaTxTask                 int    `mapstructure:"cron_time_sync_ica_tx_task"`
+	CronTimeIbcIcaTxRelateTask            int    `mapstructure:"cron_time_ibc_ica_tx_relate_task"`
+	CronTimeSyncPacketFeeTask             int    `mapstructure:"cron_time_sync_packet_fee_task"`
 	CronDenomHeatmapTask                  string `mapstructure:"cron_denom_heatmap_task"`
 
 	SwitchAddChainTask             bool `mapstructure:"switch_add_chain_task"`
@@ -98,11 +111,30 @@ type Task struct {
 	SwitchIbcChannelStatisticsTask bool `mapstructure:"switch_ibc_channel_statistics_task"`
 	SwitchIbcRelayerStatisticsTask bool `mapstructure:"switch_ibc_relayer_statistics_task"`
 	SwitchAddTransferDataTask      bool `mapstructure:"switch_add_transfer_data_task"`
+	SwitchIbcTxRelateWatch         bool `mapstructure:"switch_ibc_tx_relate_watch"`
+	SwitchIbcTxRelateRpcFill       bool `mapstructure:"switch_ibc_tx_relate_rpc_fill"`
 
 	SyncTransferTxWorkerNum int `mapstructure:"sync_transfer_tx_worker_num"`
 	IbcTxRelateWorkerNum    int `mapstructure:"ibc_tx_relate_worker_num"`
 
 	CreateAtUseTxTime bool `mapstructure:"create_at_use_tx_time"`
+
+	// CronExpressions cron expression(with seconds) by task name, it overrides the cron_time_xxx of the task
+	CronExpressions map[string]string `mapstructure:"cron_expressions"`
+}
+
+// Lcd the lcd client, see pkg/lcd
+//   - BreakerFailures: the circuit breaker of an lcd opens after the consecutive failures
+//   - BreakerOpenTime: seconds, an open circuit breaker lets a trial request through after it
+//   - RateLimit: requests per second to a host, 0 means no limit. ChainRateLimits overrides it by chain name
+type Lcd struct {
+	Timeout         int                `mapstructure:"timeout"` // seconds
+	RetryTimes      int                `mapstructure:"retry_times"`
+	BreakerFailures int                `mapstructure:"breaker_failures"`
+	BreakerOpenTime int                `mapstructure:"breaker_open_time"`
+	RateLimit       float64            `mapstructure:"rate_limit"`
+	RateBurst       int                `mapstructure:"rate_burst"`
+	ChainRateLimits map[string]float64 `mapstructure:"chain_rate_limits"`
 }
 
 type Spi struct {
@@ -114,6 +146,25 @@ type ChainConfig struct {
 	AddTransferChains string `mapstructure:"add_transfer_chains"`
 }
 
+// RelayerRegistry source of the relayer registry(iob-registry), each relayer is a directory with a relayer_info.json
+//   - Source: dir, git or http(default)
+//   - Dir: the relayers directory for dir, the checkout directory of the repository for git
+//   - GitUrl, GitRef, GitPath: the repository, the branch, tag or commit, and the relayers directory in the repository
+//   - IndexUrl: for http, a json list of the relayer names, or the github contents api of the relayers directory.
+//     InfoUrl: relayer_info.json of a relayer, %s is the relayer name
+//   - MaxRemoveRatio: share of the registered relayers which can be removed by a collect, 0.2 by default
+type RelayerRegistry struct {
+	Source   string `mapstructure:"source"`
+	Dir      string `mapstructure:"dir"`
+	GitUrl   string `mapstructure:"git_url"`
+	GitRef   string `mapstructure:"git_ref"`
+	GitPath  string `mapstructure:"git_path"`
+	IndexUrl string `mapstructure:"index_url"`
+	InfoUrl  string `mapstructure:"info_url"`
+
+	MaxRemoveRatio float64 `mapstructure:"max_remove_ratio"`
+}
+
 func ReadConfig(data []byte) (*Config, error) {
 	v := viper.New()
 	v.SetConfigType("toml")
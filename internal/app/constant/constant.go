@@ -24,6 +24,9 @@ const (
 	DenomAtom             = "uatom"
 	Iris                  = "iris"
 	PortTransfer          = "transfer"
+	PortNftTransfer       = "nft-transfer"
+	PortIcaHost           = "icahost"
+	PortIcaControllerPre  = "icacontroller-"
 	DefaultUnboundTime    = 1209600
 
 	IncreaseSymbol = "+"
@@ -35,11 +38,20 @@ const (
 	DisplayIbcRecordMax = 500000
 
 	MsgTypeTransfer           = "transfer"
+	MsgTypeNftTransfer        = "nft_transfer"
 	MsgTypeRecvPacket         = "recv_packet"
 	MsgTypeTimeoutPacket      = "timeout_packet"
 	MsgTypeAcknowledgement    = "acknowledge_packet"
 	MsgTypeUpdateClient       = "update_client"
 	MsgTypeChannelOpenConfirm = "channel_open_confirm"
+	MsgTypeChannelOpenAck     = "channel_open_ack"
+	MsgTypeIcaSendTx          = "send_tx"
+	MsgTypeIcaSubmitTx        = "submit_tx"
+	MsgTypeRegisterIca        = "register_interchain_account"
+	MsgTypePayPacketFee       = "pay_packet_fee"
+	MsgTypePayPacketFeeAsync  = "pay_packet_fee_async"
+	MsgTypeRegisterPayee      = "register_payee"
+	MsgTypeRegisterCpPayee    = "register_counterparty_payee"
 
 	ChannelOpenStatisticName  = "channel_opened"
 	ChannelCloseStatisticName = "channel_closed"
@@ -74,6 +86,12 @@ var HomeStatistics = []string{
 	BaseDenomAllStatisticName, DenomAllStatisticName,
 }
 
+var IcaControllerMsgTypes = []string{MsgTypeRegisterIca, MsgTypeIcaSendTx, MsgTypeIcaSubmitTx, MsgTypeChannelOpenAck}
+
+// PacketFeeMsgTypes msgs of ics29 fee middleware. fees are distributed in acknowledge and timeout txs
+var PacketFeeMsgTypes = []string{MsgTypePayPacketFee, MsgTypePayPacketFeeAsync, MsgTypeRegisterPayee, MsgTypeRegisterCpPayee,
+	MsgTypeAcknowledgement, MsgTypeTimeoutPacket}
+
 var RelayerDetailTxsType = []string{MsgTypeRecvPacket, MsgTypeAcknowledgement, MsgTypeTimeoutPacket}
 
 const (
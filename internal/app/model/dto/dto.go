@@ -205,6 +205,34 @@ type IbcTxQuery struct {
 	Denom          string
 }
 
+type IbcNftTxQuery struct {
+	StartTime      int64
+	EndTime        int64
+	Chain          []string
+	Status         []int
+	ClassId        string
+	BaseClassId    string
+	BaseClassChain string
+}
+
+type AggrNftClassDTO struct {
+	BaseClassId    string `bson:"base_class_id"`
+	BaseClassChain string `bson:"base_class_chain"`
+	TxsCount       int64  `bson:"txs_count"`
+	TokensCount    int64  `bson:"tokens_count"`
+	LatestTxTime   int64  `bson:"latest_tx_time"`
+}
+
+type IbcIcaTxQuery struct {
+	StartTime         int64
+	EndTime           int64
+	ControllerChain   string
+	ControllerAddress string
+	HostChain         string
+	HostAddress       string
+	Status            []int
+}
+
 type (
 	TxsAmtItem struct {
 		Txs        int64
@@ -249,3 +277,50 @@ type ChainAddressDTO struct {
 	Chain   string `bson:"chain"`
 	Address string `bson:"address"`
 }
+
+type AggrIbcTxErrorCategoryDTO struct {
+	ScChain       string `bson:"sc_chain"`
+	ScChannel     string `bson:"sc_channel"`
+	DcChain       string `bson:"dc_chain"`
+	DcChannel     string `bson:"dc_channel"`
+	ErrorCategory string `bson:"error_category"`
+	Count         int64  `bson:"count"`
+}
+
+type PacketLatencyTxDTO struct {
+	ScChain    string   `bson:"sc_chain"`
+	ScChannel  string   `bson:"sc_channel"`
+	DcChain    string   `bson:"dc_chain"`
+	DcChannel  string   `bson:"dc_channel"`
+	ScTime     int64    `bson:"sc_time"`
+	DcTime     int64    `bson:"dc_time"`
+	DcSigners  []string `bson:"dc_signers"`
+	AckTime    int64    `bson:"ack_time"`
+	AckSigners []string `bson:"ack_signers"`
+	AckMsgType string   `bson:"ack_msg_type"`
+}
+
+type AggrTaskRunDTO struct {
+	TaskName       string  `bson:"task_name"`
+	Runs           int64   `bson:"runs"`
+	FailedRuns     int64   `bson:"failed_runs"`
+	LastInstanceId string  `bson:"last_instance_id"`
+	LastStartTime  int64   `bson:"last_start_time"`
+	LastEndTime    int64   `bson:"last_end_time"`
+	LastDuration   int64   `bson:"last_duration"`
+	LastStatus     string  `bson:"last_status"`
+	LastExitStatus int     `bson:"last_exit_status"`
+	LastErrorMsg   string  `bson:"last_error_msg"`
+	LastProgress   float64 `bson:"last_progress"`
+}
+
+// TaskScheduleDTO reported by the scheduler before each run of a task, NextRunTime is computed by the instance reported
+type TaskScheduleDTO struct {
+	TaskName    string `json:"task_name"`
+	Cron        string `json:"cron"`
+	LockFree    bool   `json:"lock_free"`
+	LockKey     string `json:"lock_key"`
+	InstanceId  string `json:"instance_id"`
+	NextRunTime int64  `json:"next_run_time"`
+	UpdateAt    int64  `json:"update_at"`
+}
@@ -10,12 +10,21 @@ const (
 
 type ChainStatus int
 
+// transports of the chain queries, see ChainConfig.QueryTransport
+const (
+	QueryTransportRest = "rest"
+	QueryTransportGrpc = "grpc"
+)
+
 const (
 	ChainStatusOpen   ChainStatus = 1
 	ChainStatusClosed ChainStatus = 2
 )
 
 type (
+	// ChainConfig
+	//   - QueryTransport: rest(default) or grpc, the chain queries(channels, client state, balances, ...) go over it
+	//   - GrpcAddr: grpc endpoint of the chain, host:port. it is dialed with tls if it starts with https://
 	ChainConfig struct {
 		CurrentChainId  string      `bson:"current_chain_id"`
 		Icon            string      `bson:"icon"`
@@ -23,6 +32,8 @@ type (
 		PrettyName      string      `bson:"pretty_name"`
 		LcdApiPath      ApiPath     `bson:"lcd_api_path"`
 		GrpcRestGateway string      `bson:"grpc_rest_gateway"`
+		QueryTransport  string      `bson:"query_transport"`
+		GrpcAddr        string      `bson:"grpc_addr"`
 		AddrPrefix      string      `bson:"addr_prefix"`
 		IbcInfo         []*IbcInfo  `bson:"ibc_info"`
 		IbcInfoHashLcd  string      `bson:"ibc_info_hash_lcd"`
@@ -0,0 +1,23 @@
+package entity
+
+// DryRunReport what a one-off repair task would change, it is produced by a dry run instead of the changes
+type DryRunReport struct {
+	Items []*DryRunItem `bson:"items" json:"items"`
+}
+
+// DryRunItem changes of an operation by collection, chain and channel
+//   - Matched: the count of the documents the operation would change
+//   - Samples: some of the documents, only the fields to be changed are kept
+type DryRunItem struct {
+	Collection string         `bson:"collection" json:"collection"`
+	Operation  string         `bson:"operation" json:"operation"`
+	Chain      string         `bson:"chain" json:"chain"`
+	Channel    string         `bson:"channel" json:"channel"`
+	Matched    int64          `bson:"matched" json:"matched"`
+	Samples    []DryRunSample `bson:"samples" json:"samples"`
+}
+
+type DryRunSample struct {
+	Before map[string]interface{} `bson:"before" json:"before"`
+	After  map[string]interface{} `bson:"after" json:"after"`
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CollectionNameExIbcIcaTx = "ex_ibc_ica_tx"
+)
+
+// ExIbcIcaTx ics27 packet sent by an interchain account controller.
+//   - ScAddr: owner of the interchain account on controller chain
+//   - DcAddr: interchain account address on host chain
+type ExIbcIcaTx struct {
+	Id               primitive.ObjectID `bson:"_id"`
+	RecordId         string             `bson:"record_id"`
+	TxTime           int64              `bson:"tx_time"`
+	ScAddr           string             `bson:"sc_addr"`
+	DcAddr           string             `bson:"dc_addr"`
+	ScPort           string             `bson:"sc_port"`
+	ScChannel        string             `bson:"sc_channel"`
+	ScConnectionId   string             `bson:"sc_connection_id"`
+	ScClientId       string             `bson:"sc_client_id"`
+	ScChain          string             `bson:"sc_chain"`
+	DcPort           string             `bson:"dc_port"`
+	DcChannel        string             `bson:"dc_channel"`
+	DcConnectionId   string             `bson:"dc_connection_id"`
+	DcClientId       string             `bson:"dc_client_id"`
+	DcChain          string             `bson:"dc_chain"`
+	Sequence         string             `bson:"sequence"`
+	PacketId         string             `bson:"packet_id"`
+	PacketType       string             `bson:"packet_type"`
+	PacketMemo       string             `bson:"packet_memo"`
+	TimeoutHeight    int64              `bson:"timeout_height"`
+	TimeoutTimestamp int64              `bson:"timeout_timestamp"`
+	Status           IbcTxStatus        `bson:"status"`
+	ScTxInfo         *TxInfo            `bson:"sc_tx_info"`
+	DcTxInfo         *TxInfo            `bson:"dc_tx_info"`
+	AckTimeoutTxInfo *TxInfo            `bson:"ack_timeout_tx_info"`
+	ProcessInfo      string             `bson:"process_info"`
+	RetryTimes       int64              `bson:"retry_times"`
+	NextTryTime      int64              `bson:"next_try_time"`
+	CreateAt         int64              `bson:"create_at"`
+	UpdateAt         int64              `bson:"update_at"`
+}
+
+func (i ExIbcIcaTx) CollectionName() string {
+	return CollectionNameExIbcIcaTx
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CollectionNameExIbcNftTx = "ex_ibc_nft_tx"
+)
+
+type (
+	ExIbcNftTx struct {
+		Id               primitive.ObjectID `bson:"_id"`
+		RecordId         string             `bson:"record_id"`
+		TxTime           int64              `bson:"tx_time"`
+		ScAddr           string             `bson:"sc_addr"`
+		DcAddr           string             `bson:"dc_addr"`
+		ScPort           string             `bson:"sc_port"`
+		ScChannel        string             `bson:"sc_channel"`
+		ScConnectionId   string             `bson:"sc_connection_id"`
+		ScClientId       string             `bson:"sc_client_id"`
+		ScChain          string             `bson:"sc_chain"`
+		DcPort           string             `bson:"dc_port"`
+		DcChannel        string             `bson:"dc_channel"`
+		DcConnectionId   string             `bson:"dc_connection_id"`
+		DcClientId       string             `bson:"dc_client_id"`
+		DcChain          string             `bson:"dc_chain"`
+		Sequence         string             `bson:"sequence"`
+		Status           IbcTxStatus        `bson:"status"`
+		ScTxInfo         *TxInfo            `bson:"sc_tx_info"`
+		DcTxInfo         *TxInfo            `bson:"dc_tx_info"`
+		AckTimeoutTxInfo *TxInfo            `bson:"ack_timeout_tx_info"`
+		Class            *NftClass          `bson:"class"`
+		TokenIds         []string           `bson:"token_ids"`
+		TokenUris        []string           `bson:"token_uris"`
+		ProcessInfo      string             `bson:"process_info"`
+		RetryTimes       int64              `bson:"retry_times"`
+		NextTryTime      int64              `bson:"next_try_time"`
+		CreateAt         int64              `bson:"create_at"`
+		UpdateAt         int64              `bson:"update_at"`
+	}
+
+	// NftClass class info of an ics721 packet.
+	//   - ScClassId/DcClassId: class id on source/destination chain, eg: "kitty", "ibc/3C3D7B3B..."
+	//   - ClassPath: full trace path carried in packet data, eg: "nft-transfer/channel-1/kitty"
+	NftClass struct {
+		ScClassId      string `bson:"sc_class_id"`
+		DcClassId      string `bson:"dc_class_id"`
+		ClassPath      string `bson:"class_path"`
+		ClassUri       string `bson:"class_uri"`
+		BaseClassId    string `bson:"base_class_id"`
+		BaseClassChain string `bson:"base_class_chain"`
+	}
+)
+
+func (i ExIbcNftTx) CollectionName() string {
+	return CollectionNameExIbcNftTx
+}
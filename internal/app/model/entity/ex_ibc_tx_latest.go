@@ -13,9 +13,31 @@ const (
 	IbcTxStatusProcessing IbcTxStatus = 3
 	IbcTxStatusRefunded   IbcTxStatus = 4
 	IbcTxStatusSetting    IbcTxStatus = 5
+	// IbcTxStatusExpired the packet is timed out on dc chain and can be refunded, but the timeout packet has not been relayed yet
+	IbcTxStatusExpired IbcTxStatus = 6
 )
 
-var IbcTxUsefulStatus = []IbcTxStatus{IbcTxStatusSuccess, IbcTxStatusFailed, IbcTxStatusProcessing, IbcTxStatusRefunded}
+var IbcTxUsefulStatus = []IbcTxStatus{IbcTxStatusSuccess, IbcTxStatusFailed, IbcTxStatusProcessing, IbcTxStatusRefunded, IbcTxStatusExpired}
+
+// IbcTxToBeRelatedStatus ibc txs in these status are still waiting for recv, ack or timeout packet
+var IbcTxToBeRelatedStatus = []IbcTxStatus{IbcTxStatusProcessing, IbcTxStatusExpired}
+
+// IbcTxVolumeStatus ibc txs in these status are counted in the transfer volume, the tokens have left the sc chain
+var IbcTxVolumeStatus = []IbcTxStatus{IbcTxStatusSuccess, IbcTxStatusProcessing, IbcTxStatusExpired}
+
+// IbcTxErrorCategory category of the error acknowledgement(or the failed recv packet tx) of the ibc tx
+type IbcTxErrorCategory string
+
+const (
+	IbcTxErrorInvalidReceiver    IbcTxErrorCategory = "invalid_receiver"
+	IbcTxErrorInsufficientEscrow IbcTxErrorCategory = "insufficient_escrow_funds"
+	IbcTxErrorRateLimitExceeded  IbcTxErrorCategory = "rate_limit_exceeded"
+	IbcTxErrorDenomNotAllowed    IbcTxErrorCategory = "denom_not_allowed"
+	IbcTxErrorUnauthorizedMemo   IbcTxErrorCategory = "unauthorized_memo"
+	IbcTxErrorContract           IbcTxErrorCategory = "contract_error"
+	IbcTxErrorOutOfGas           IbcTxErrorCategory = "out_of_gas"
+	IbcTxErrorUnknown            IbcTxErrorCategory = "unknown"
+)
 
 const (
 	CollectionNameExIbcTx       = "ex_ibc_tx"
@@ -23,6 +45,12 @@ const (
 )
 
 type (
+	// ExIbcTx ics20 transfer packet.
+	//   - ParentRecordId: record_id of the previous hop when the packet is sent by packet-forward-middleware
+	//   - JourneyId: record_id of the first hop, shared by all hops of a multi-hop transfer
+	//   - ErrorCategory: set when the packet is acknowledged with error, or the recv packet tx fails for a known reason
+	//   - Tokens: all coins of an ics20 v2 multi-denom packet, the first one is also kept in Denoms, BaseDenom and
+	//     sc_tx_info.msg_amount. it is empty for single coin packet
 	ExIbcTx struct {
 		Id               primitive.ObjectID `bson:"_id"`
 		RecordId         string             `bson:"record_id"`
@@ -47,6 +75,10 @@ type (
 		Denoms           *Denoms            `bson:"denoms"`
 		BaseDenom        string             `bson:"base_denom"`
 		BaseDenomChain   string             `bson:"base_denom_chain"`
+		Tokens           []*TransferToken   `bson:"tokens,omitempty"`
+		ParentRecordId   string             `bson:"parent_record_id"`
+		JourneyId        string             `bson:"journey_id"`
+		ErrorCategory    IbcTxErrorCategory `bson:"error_category,omitempty"`
 		ProcessInfo      string             `bson:"process_info"`
 		RetryTimes       int64              `bson:"retry_times"`
 		NextTryTime      int64              `bson:"next_try_time"`
@@ -60,6 +92,13 @@ type (
 		ScDenom string `bson:"sc_denom"`
 		DcDenom string `bson:"dc_denom"`
 	}
+	TransferToken struct {
+		ScDenom        string `bson:"sc_denom"`
+		DcDenom        string `bson:"dc_denom"`
+		BaseDenom      string `bson:"base_denom"`
+		BaseDenomChain string `bson:"base_denom_chain"`
+		Amount         string `bson:"amount"`
+	}
 	TxInfo struct {
 		Hash      string       `bson:"hash"`
 		Status    TxStatus     `bson:"status"`
@@ -0,0 +1,39 @@
+package entity
+
+const IBCClientCollName = "ibc_client"
+
+type ClientStatus string
+
+const (
+	ClientStatusActive  ClientStatus = "active"
+	ClientStatusExpired ClientStatus = "expired"
+	ClientStatusFrozen  ClientStatus = "frozen"
+)
+
+// IBCClient light client of the counterparty chain hosted on Chain.
+//   - LastUpdateTime: timestamp of the consensus state at LatestHeight, the client expires TrustingPeriod seconds
+//     after it, which is ExpireTime
+//   - LatestUpdateTxTime, UpdateTxs24h: the latest update_client tx and the number of them in the last 24 hours
+type IBCClient struct {
+	Chain              string       `bson:"chain"`
+	ClientId           string       `bson:"client_id"`
+	ClientType         string       `bson:"client_type"`
+	CounterpartyChain  string       `bson:"counterparty_chain"`
+	ChainId            string       `bson:"chain_id"`
+	PortId             string       `bson:"port_id"`
+	ChannelId          string       `bson:"channel_id"`
+	TrustingPeriod     int64        `bson:"trusting_period"`
+	LatestHeight       string       `bson:"latest_height"`
+	FrozenHeight       string       `bson:"frozen_height"`
+	LastUpdateTime     int64        `bson:"last_update_time"`
+	ExpireTime         int64        `bson:"expire_time"`
+	Status             ClientStatus `bson:"status"`
+	LatestUpdateTxTime int64        `bson:"latest_update_tx_time"`
+	UpdateTxs24h       int64        `bson:"update_txs_24h"`
+	CreateAt           int64        `bson:"create_at"`
+	UpdateAt           int64        `bson:"update_at"`
+}
+
+func (i IBCClient) CollectionName() string {
+	return IBCClientCollName
+}
@@ -0,0 +1,28 @@
+package entity
+
+const (
+	CollectionNameIbcIcaAccount = "ibc_ica_account"
+)
+
+// IbcIcaAccount mapping of interchain account controller to host account.
+// HostAddress is empty until the channel handshake is acknowledged on controller chain.
+type IbcIcaAccount struct {
+	ControllerChain        string `bson:"controller_chain"`
+	ControllerAddress      string `bson:"controller_address"`
+	ControllerPort         string `bson:"controller_port"`
+	ControllerChannel      string `bson:"controller_channel"`
+	ControllerConnectionId string `bson:"controller_connection_id"`
+	HostChain              string `bson:"host_chain"`
+	HostAddress            string `bson:"host_address"`
+	HostPort               string `bson:"host_port"`
+	HostChannel            string `bson:"host_channel"`
+	HostConnectionId       string `bson:"host_connection_id"`
+	RegisterTxHash         string `bson:"register_tx_hash"`
+	RegisterTime           int64  `bson:"register_time"`
+	CreateAt               int64  `bson:"create_at"`
+	UpdateAt               int64  `bson:"update_at"`
+}
+
+func (i IbcIcaAccount) CollectionName() string {
+	return CollectionNameIbcIcaAccount
+}
@@ -0,0 +1,84 @@
+package entity
+
+import "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+
+const (
+	CollectionNameIbcPacketFee       = "ibc_packet_fee"
+	CollectionNameIbcFeePayee        = "ibc_fee_payee"
+	CollectionNameIbcFeeDistribution = "ibc_fee_distribution"
+)
+
+type FeePayeeType string
+
+const (
+	FeePayeeTypePayee             FeePayeeType = "payee"
+	FeePayeeTypeCounterpartyPayee FeePayeeType = "counterparty_payee"
+)
+
+// IbcPacketFee fee escrowed for a packet by MsgPayPacketFee or MsgPayPacketFeeAsync(ics29).
+// a packet may be incentivized by more than one payer
+type IbcPacketFee struct {
+	Chain         string        `bson:"chain"`
+	PortId        string        `bson:"port_id"`
+	ChannelId     string        `bson:"channel_id"`
+	Sequence      string        `bson:"sequence"`
+	MsgType       string        `bson:"msg_type"`
+	RefundAddress string        `bson:"refund_address"`
+	RecvFee       []*model.Coin `bson:"recv_fee"`
+	AckFee        []*model.Coin `bson:"ack_fee"`
+	TimeoutFee    []*model.Coin `bson:"timeout_fee"`
+	TxHash        string        `bson:"tx_hash"`
+	MsgIndex      int           `bson:"msg_index"`
+	Height        int64         `bson:"height"`
+	TxTime        int64         `bson:"tx_time"`
+	CreateAt      int64         `bson:"create_at"`
+	UpdateAt      int64         `bson:"update_at"`
+}
+
+func (i IbcPacketFee) CollectionName() string {
+	return CollectionNameIbcPacketFee
+}
+
+// IbcFeePayee payee registered by relayer. Counterparty payee receives the recv fee on the counterparty chain(PayeeChain)
+type IbcFeePayee struct {
+	Chain      string       `bson:"chain"`
+	PortId     string       `bson:"port_id"`
+	ChannelId  string       `bson:"channel_id"`
+	Relayer    string       `bson:"relayer"`
+	PayeeType  FeePayeeType `bson:"payee_type"`
+	Payee      string       `bson:"payee"`
+	PayeeChain string       `bson:"payee_chain"`
+	TxHash     string       `bson:"tx_hash"`
+	TxTime     int64        `bson:"tx_time"`
+	CreateAt   int64        `bson:"create_at"`
+	UpdateAt   int64        `bson:"update_at"`
+}
+
+func (i IbcFeePayee) CollectionName() string {
+	return CollectionNameIbcFeePayee
+}
+
+// IbcFeeDistribution fee paid out by distribute_fee event of acknowledge or timeout tx.
+// one record per receiver and denom, IsRefund is true when the fee goes back to the refund address
+type IbcFeeDistribution struct {
+	Chain            string  `bson:"chain"`
+	PortId           string  `bson:"port_id"`
+	ChannelId        string  `bson:"channel_id"`
+	Sequence         string  `bson:"sequence"`
+	TxType           TxType  `bson:"tx_type"`
+	TxHash           string  `bson:"tx_hash"`
+	MsgIndex         int     `bson:"msg_index"`
+	DistributeIndex  int     `bson:"distribute_index"`
+	Relayer          string  `bson:"relayer"`
+	Receiver         string  `bson:"receiver"`
+	ChainAddressComb string  `bson:"chain_address_comb"`
+	FeeDenom         string  `bson:"fee_denom"`
+	FeeAmount        float64 `bson:"fee_amount"`
+	IsRefund         bool    `bson:"is_refund"`
+	TxTime           int64   `bson:"tx_time"`
+	CreateAt         int64   `bson:"create_at"`
+}
+
+func (i IbcFeeDistribution) CollectionName() string {
+	return CollectionNameIbcFeeDistribution
+}
@@ -0,0 +1,50 @@
+package entity
+
+const IBCPacketLatencyStatisticsCollName = "ibc_packet_latency_statistics"
+
+type PacketLatencyStage string
+
+const (
+	// PacketLatencyStageSendRecv sc_tx_info.time -> dc_tx_info.time
+	PacketLatencyStageSendRecv PacketLatencyStage = "send_recv"
+	// PacketLatencyStageRecvAck dc_tx_info.time -> ack_timeout_tx_info.time
+	PacketLatencyStageRecvAck PacketLatencyStage = "recv_ack"
+)
+
+type PacketLatencyPeriod string
+
+const (
+	PacketLatencyPeriodHour PacketLatencyPeriod = "hour"
+	PacketLatencyPeriodDay  PacketLatencyPeriod = "day"
+)
+
+// PacketLatencyBuckets upper bounds(seconds) of the latency histogram, the last bucket of Histogram counts the
+// latencies greater than the last bound
+var PacketLatencyBuckets = []int64{6, 10, 15, 20, 30, 45, 60, 90, 120, 180, 300, 600, 900, 1800, 3600, 7200, 21600, 86400}
+
+// IBCPacketLatencyStatistics latency(seconds) of packets sent in the segment, by channel pair, stage and relayer.
+//   - Relayer: chain address comb of the relayer, the signer of recv packet tx for send_recv, the signer of ack packet
+//     tx for recv_ack
+//   - Histogram: counts of PacketLatencyBuckets, used to merge the percentiles of several rows
+type IBCPacketLatencyStatistics struct {
+	ScChain          string              `bson:"sc_chain"`
+	ScChannel        string              `bson:"sc_channel"`
+	DcChain          string              `bson:"dc_chain"`
+	DcChannel        string              `bson:"dc_channel"`
+	Stage            PacketLatencyStage  `bson:"stage"`
+	Relayer          string              `bson:"relayer"`
+	Period           PacketLatencyPeriod `bson:"period"`
+	TxsNumber        int64               `bson:"txs_number"`
+	P50              int64               `bson:"p50"`
+	P90              int64               `bson:"p90"`
+	P99              int64               `bson:"p99"`
+	Histogram        []int64             `bson:"histogram"`
+	SegmentStartTime int64               `bson:"segment_start_time"`
+	SegmentEndTime   int64               `bson:"segment_end_time"`
+	CreateAt         int64               `bson:"create_at"`
+	UpdateAt         int64               `bson:"update_at"`
+}
+
+func (i IBCPacketLatencyStatistics) CollectionName() string {
+	return IBCPacketLatencyStatisticsCollName
+}
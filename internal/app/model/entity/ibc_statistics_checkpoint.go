@@ -0,0 +1,26 @@
+package entity
+
+const CollectionNameIbcStatisticsCheckpoint = "ibc_statistics_checkpoint"
+
+// IbcStatisticsCheckpoint progress of a full statistics run by task and scope, a run resumes from the last completed
+// segment of each scope after a restart. the checkpoints of a task are deleted once its new collections are switched in.
+//   - Scope: the chain of the statistics by chain, or history/latest for the statistics of ex_ibc_tx/ex_ibc_tx_latest
+//   - SegmentStartTime, SegmentEndTime: the last completed segment of the scope
+//   - RunStartTime: the start time of the run, a run that started too long ago is not resumed
+//   - FirstSegmentStartTime, SegmentStep: the segments of the scope, the run is not resumed if they are split differently
+type IbcStatisticsCheckpoint struct {
+	TaskName              string `bson:"task_name"`
+	Scope                 string `bson:"scope"`
+	SegmentStartTime      int64  `bson:"segment_start_time"`
+	SegmentEndTime        int64  `bson:"segment_end_time"`
+	RunStartTime          int64  `bson:"run_start_time"`
+	FirstSegmentStartTime int64  `bson:"first_segment_start_time"`
+	SegmentStep           int64  `bson:"segment_step"`
+	Finished              bool   `bson:"finished"`
+	CreateAt              int64  `bson:"create_at"`
+	UpdateAt              int64  `bson:"update_at"`
+}
+
+func (i IbcStatisticsCheckpoint) CollectionName() string {
+	return CollectionNameIbcStatisticsCheckpoint
+}
@@ -1,6 +1,14 @@
 package entity
 
-const TaskNameFmt = "sync_%s_transfer"
+import "go.mongodb.org/mongo-driver/bson"
+
+const (
+	TaskNameFmt          = "sync_%s_transfer"
+	NftTaskNameFmt       = "sync_%s_nft_transfer"
+	IcaTaskNameFmt       = "sync_%s_ica"
+	PacketFeeTaskNameFmt = "sync_%s_packet_fee"
+	RelateWatchNameFmt   = "watch_%s_relate"
+)
 
 type TaskRecordStatus string
 
@@ -9,12 +17,14 @@ var (
 	TaskRecordStatusClose TaskRecordStatus = "close"
 )
 
+// IbcTaskRecord sync tasks record the handled height, watch tasks record the change stream resume token
 type IbcTaskRecord struct {
-	TaskName string           `bson:"task_name"`
-	Height   int64            `bson:"height"`
-	Status   TaskRecordStatus `bson:"status"`
-	CreateAt int64            `bson:"create_at"`
-	UpdateAt int64            `bson:"update_at"`
+	TaskName    string           `bson:"task_name"`
+	Height      int64            `bson:"height"`
+	Status      TaskRecordStatus `bson:"status"`
+	ResumeToken bson.Raw         `bson:"resume_token,omitempty"`
+	CreateAt    int64            `bson:"create_at"`
+	UpdateAt    int64            `bson:"update_at"`
 }
 
 func (t IbcTaskRecord) CollectionName() string {
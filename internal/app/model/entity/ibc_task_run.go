@@ -0,0 +1,48 @@
+package entity
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const CollectionNameIbcTaskRun = "ibc_task_run"
+
+type TaskRunTrigger string
+
+const (
+	TaskRunTriggerSchedule TaskRunTrigger = "schedule"
+	TaskRunTriggerOneOff   TaskRunTrigger = "one_off"
+	TaskRunTriggerAdmin    TaskRunTrigger = "admin"
+)
+
+type TaskRunStatus string
+
+const (
+	TaskRunStatusRunning TaskRunStatus = "running"
+	TaskRunStatusSuccess TaskRunStatus = "success"
+	TaskRunStatusFailed  TaskRunStatus = "failed"
+)
+
+// IbcTaskRun a run of a scheduled, one-off or admin-triggered task. a run stays running if the instance dies before
+// it ends.
+//   - Duration: milliseconds
+//   - ExitStatus: the value returned by the task, the run failed if it is less than 0
+//   - ChainCounters: counters by chain, such as the txs parsed of the chain
+//   - Progress: percent complete of a long run which reports it, such as a full statistics run
+//   - DryRunReport: what the run would change, only for a dry run of a one-off repair task
+type IbcTaskRun struct {
+	Id            primitive.ObjectID          `bson:"_id"`
+	TaskName      string                      `bson:"task_name"`
+	Trigger       TaskRunTrigger              `bson:"trigger"`
+	InstanceId    string                      `bson:"instance_id"`
+	StartTime     int64                       `bson:"start_time"`
+	EndTime       int64                       `bson:"end_time"`
+	Duration      int64                       `bson:"duration"`
+	Status        TaskRunStatus               `bson:"status"`
+	ExitStatus    int                         `bson:"exit_status"`
+	ErrorMsg      string                      `bson:"error_msg"`
+	ChainCounters map[string]map[string]int64 `bson:"chain_counters"`
+	Progress      float64                     `bson:"progress"`
+	DryRunReport  *DryRunReport               `bson:"dry_run_report,omitempty"`
+}
+
+func (t IbcTaskRun) CollectionName() string {
+	return CollectionNameIbcTaskRun
+}
@@ -0,0 +1,46 @@
+package entity
+
+const CollectionNameIbcTxDeadLetter = "ibc_tx_dead_letter"
+
+type DeadLetterReason string
+
+const (
+	DeadLetterReasonDcChainNotFound       DeadLetterReason = "dc_chain_not_found"
+	DeadLetterReasonInvalidScTxInfo       DeadLetterReason = "invalid_sc_tx_info"
+	DeadLetterReasonRecvPacketNotFound    DeadLetterReason = "recv_packet_not_found"
+	DeadLetterReasonAckPacketNotFound     DeadLetterReason = "ack_packet_not_found"
+	DeadLetterReasonTimeoutPacketNotFound DeadLetterReason = "timeout_packet_not_found"
+)
+
+const (
+	DeadLetterSourceLatest  = "latest"
+	DeadLetterSourceHistory = "history"
+)
+
+// IbcTxDeadLetter ibc tx which exhausts relate retries, it is moved out of ex_ibc_tx_latest(or ex_ibc_tx) and kept here until
+// it is replayed or discarded.
+//   - Source: the collection the ibc tx is moved from, latest or history
+//   - PacketIds: the packets the relate task was looking for, chain is the one where the packet tx should be found
+type IbcTxDeadLetter struct {
+	RecordId    string             `bson:"record_id"`
+	Source      string             `bson:"source"`
+	ScChain     string             `bson:"sc_chain"`
+	DcChain     string             `bson:"dc_chain"`
+	ReasonCode  DeadLetterReason   `bson:"reason_code"`
+	ProcessInfo string             `bson:"process_info"`
+	RetryTimes  int64              `bson:"retry_times"`
+	PacketIds   []DeadLetterPacket `bson:"packet_ids"`
+	IbcTx       *ExIbcTx           `bson:"ibc_tx"`
+	CreateAt    int64              `bson:"create_at"`
+	UpdateAt    int64              `bson:"update_at"`
+}
+
+type DeadLetterPacket struct {
+	Chain    string `bson:"chain"`
+	TxType   TxType `bson:"tx_type"`
+	PacketId string `bson:"packet_id"`
+}
+
+func (i IbcTxDeadLetter) CollectionName() string {
+	return CollectionNameIbcTxDeadLetter
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -32,14 +33,77 @@ type (
 		SourcePort       string        `bson:"source_port" json:"source_port"`
 		SourceChannel    string        `bson:"source_channel" json:"source_channel"`
 		Token            *Coin         `bson:"token" json:"token"`
+		Tokens           []*Coin       `bson:"tokens" json:"tokens"`
 		Sender           string        `bson:"sender" json:"sender"`
 		Receiver         string        `bson:"receiver" json:"receiver"`
 		TimeoutHeight    TimeoutHeight `bson:"timeout_height" json:"timeout_height"`
 		TimeoutTimestamp int64         `bson:"timeout_timestamp" json:"timeout_timestamp"`
 	}
 
+	NftTransferTxMsg struct {
+		PacketId         string        `bson:"packet_id" json:"packet_id"`
+		SourcePort       string        `bson:"source_port" json:"source_port"`
+		SourceChannel    string        `bson:"source_channel" json:"source_channel"`
+		ClassId          string        `bson:"class_id" json:"class_id"`
+		TokenIds         []string      `bson:"token_ids" json:"token_ids"`
+		Sender           string        `bson:"sender" json:"sender"`
+		Receiver         string        `bson:"receiver" json:"receiver"`
+		TimeoutHeight    TimeoutHeight `bson:"timeout_height" json:"timeout_height"`
+		TimeoutTimestamp int64         `bson:"timeout_timestamp" json:"timeout_timestamp"`
+		Memo             string        `bson:"memo" json:"memo"`
+	}
+
+	IcaSendTxMsg struct {
+		PacketId     string `bson:"packet_id" json:"packet_id"`
+		Owner        string `bson:"owner" json:"owner"`
+		ConnectionId string `bson:"connection_id" json:"connection_id"`
+	}
+
+	RegisterIcaMsg struct {
+		Owner        string `bson:"owner" json:"owner"`
+		ConnectionId string `bson:"connection_id" json:"connection_id"`
+		Version      string `bson:"version" json:"version"`
+	}
+
+	ChannelOpenAckMsg struct {
+		PortId                string `bson:"port_id" json:"port_id"`
+		ChannelId             string `bson:"channel_id" json:"channel_id"`
+		CounterpartyChannelId string `bson:"counterparty_channel_id" json:"counterparty_channel_id"`
+		CounterpartyVersion   string `bson:"counterparty_version" json:"counterparty_version"`
+		Signer                string `bson:"signer" json:"signer"`
+	}
+
+	PacketFee struct {
+		RecvFee    []*Coin `bson:"recv_fee" json:"recv_fee"`
+		AckFee     []*Coin `bson:"ack_fee" json:"ack_fee"`
+		TimeoutFee []*Coin `bson:"timeout_fee" json:"timeout_fee"`
+	}
+
+	PayPacketFeeMsg struct {
+		Fee             PacketFee `bson:"fee" json:"fee"`
+		SourcePortId    string    `bson:"source_port_id" json:"source_port_id"`
+		SourceChannelId string    `bson:"source_channel_id" json:"source_channel_id"`
+		Signer          string    `bson:"signer" json:"signer"`
+	}
+
+	PayPacketFeeAsyncMsg struct {
+		PacketFee struct {
+			Fee           PacketFee `bson:"fee" json:"fee"`
+			RefundAddress string    `bson:"refund_address" json:"refund_address"`
+		} `bson:"packet_fee" json:"packet_fee"`
+	}
+
+	// RegisterPayeeMsg MsgRegisterPayee and MsgRegisterCounterpartyPayee
+	RegisterPayeeMsg struct {
+		PortId            string `bson:"port_id" json:"port_id"`
+		ChannelId         string `bson:"channel_id" json:"channel_id"`
+		Relayer           string `bson:"relayer" json:"relayer"`
+		Payee             string `bson:"payee" json:"payee"`
+		CounterpartyPayee string `bson:"counterparty_payee" json:"counterparty_payee"`
+	}
+
 	TimeoutHeight struct {
-		RevisionNumber int64 `json:"revision_number" bson:"destination_channel"`
+		RevisionNumber int64 `json:"revision_number" bson:"revision_number"`
 		RevisionHeight int64 `json:"revision_height" bson:"revision_height"`
 	}
 
@@ -59,6 +123,10 @@ type (
 			Amount   interface{} `json:"amount" bson:"amount"`
 			Sender   string      `json:"sender" bson:"sender"`
 			Receiver string      `json:"receiver" bson:"receiver"`
+			Memo     string      `json:"memo" bson:"memo"`
+			// ics20 v2
+			Tokens     []PacketToken     `json:"tokens" bson:"tokens"`
+			Forwarding *PacketForwarding `json:"forwarding" bson:"forwarding"`
 		} `json:"data" bson:"data"`
 		TimeoutHeight    TimeoutHeight `json:"timeout_height" bson:"timeout_height"`
 		TimeoutTimestamp int64         `json:"timeout_timestamp" bson:"timeout_timestamp"`
@@ -118,6 +186,81 @@ func (m TxMsg) TransferMsg() TransferTxMsg {
 	return msg
 }
 
+// AllTokens MsgTransfer of ics20 v2 may carry several tokens in Tokens instead of Token
+func (m TransferTxMsg) AllTokens() []*Coin {
+	if len(m.Tokens) > 0 {
+		return m.Tokens
+	}
+	if m.Token != nil {
+		return []*Coin{m.Token}
+	}
+	return nil
+}
+
+// FullPathCoins see TransferTxPacketData.FullPathCoins
+func (p Packet) FullPathCoins() []*Coin {
+	if len(p.Data.Tokens) == 0 {
+		return []*Coin{{Denom: p.Data.Denom, Amount: fmt.Sprint(p.Data.Amount)}}
+	}
+	return packetTokenCoins(p.Data.Tokens)
+}
+
+func (m TxMsg) NftTransferMsg() NftTransferTxMsg {
+	var msg NftTransferTxMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+func (m TxMsg) IcaSendTxMsg() IcaSendTxMsg {
+	var msg IcaSendTxMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+func (m TxMsg) RegisterIcaMsg() RegisterIcaMsg {
+	var msg RegisterIcaMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+func (m TxMsg) ChannelOpenAckMsg() ChannelOpenAckMsg {
+	var msg ChannelOpenAckMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+func (m TxMsg) PayPacketFeeMsg() PayPacketFeeMsg {
+	var msg PayPacketFeeMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+func (m TxMsg) PayPacketFeeAsyncMsg() PayPacketFeeAsyncMsg {
+	var msg PayPacketFeeAsyncMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+func (m TxMsg) RegisterPayeeMsg() RegisterPayeeMsg {
+	var msg RegisterPayeeMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
 func (m TxMsg) RecvPacketMsg() RecvPacketMsg {
 	var msg RecvPacketMsg
 	bz, _ := json.Marshal(m.Msg)
@@ -143,8 +286,100 @@ func (m TxMsg) AckPacketMsg() AckPacketMsg {
 }
 
 type TransferTxPacketData struct {
-	Amount   string `json:"amount"`
-	Denom    string `json:"denom"`
+	Amount     string            `json:"amount"`
+	Denom      string            `json:"denom"`
+	Receiver   string            `json:"receiver"`
+	Sender     string            `json:"sender"`
+	Memo       string            `json:"memo"`
+	Tokens     []PacketToken     `json:"tokens"`
+	Forwarding *PacketForwarding `json:"forwarding"`
+}
+
+// FullPathCoins coins of the packet, denom of the coin is the full denom path. ics20 v1 packet carries only one coin
+func (d TransferTxPacketData) FullPathCoins() []*Coin {
+	if len(d.Tokens) == 0 {
+		return []*Coin{{Denom: d.Denom, Amount: d.Amount}}
+	}
+	return packetTokenCoins(d.Tokens)
+}
+
+// PacketToken token of ics20 v2 packet data, the denom is carried with its trace instead of the full path
+type PacketToken struct {
+	Denom  PacketDenom `json:"denom" bson:"denom"`
+	Amount string      `json:"amount" bson:"amount"`
+}
+
+type PacketDenom struct {
+	Base  string      `json:"base" bson:"base"`
+	Trace []PacketHop `json:"trace" bson:"trace"`
+}
+
+// FullPath eg: "transfer/channel-1/transfer/channel-0/uatom"
+func (d PacketDenom) FullPath() string {
+	var path string
+	for _, hop := range d.Trace {
+		path += fmt.Sprintf("%s/%s/", hop.PortId, hop.ChannelId)
+	}
+	return path + d.Base
+}
+
+type PacketHop struct {
+	PortId    string `json:"port_id" bson:"port_id"`
+	ChannelId string `json:"channel_id" bson:"channel_id"`
+}
+
+// PacketForwarding forwarding hops of ics20 v2 packet, the packet is sent to the next hop by the transfer module of dc chain
+type PacketForwarding struct {
+	Hops            []PacketHop `json:"hops" bson:"hops"`
+	DestinationMemo string      `json:"destination_memo" bson:"destination_memo"`
+}
+
+func packetTokenCoins(tokens []PacketToken) []*Coin {
+	coins := make([]*Coin, 0, len(tokens))
+	for _, v := range tokens {
+		coins = append(coins, &Coin{Denom: v.Denom.FullPath(), Amount: v.Amount})
+	}
+	return coins
+}
+
+// PacketForwardMemo memo of ics20 packet routed by packet-forward-middleware
+type PacketForwardMemo struct {
+	Forward *ForwardMetadata `json:"forward"`
+}
+
+// ForwardMetadata only the fields to locate the next hop are parsed, timeout, retries and next are left to the middleware
+type ForwardMetadata struct {
 	Receiver string `json:"receiver"`
-	Sender   string `json:"sender"`
+	Port     string `json:"port"`
+	Channel  string `json:"channel"`
+}
+
+// NftTransferTxPacketData ICS-721 packet data, field names follow the ics721 spec
+type NftTransferTxPacketData struct {
+	ClassId   string   `json:"classId"`
+	ClassUri  string   `json:"classUri"`
+	ClassData string   `json:"classData"`
+	TokenIds  []string `json:"tokenIds"`
+	TokenUris []string `json:"tokenUris"`
+	TokenData []string `json:"tokenData"`
+	Sender    string   `json:"sender"`
+	Receiver  string   `json:"receiver"`
+	Memo      string   `json:"memo"`
+}
+
+// IcaPacketData ICS-27 packet data
+type IcaPacketData struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+	Memo string `json:"memo"`
+}
+
+// IcaMetadata ICS-27 channel version metadata, the host account address is negotiated in channel_open_ack
+type IcaMetadata struct {
+	Version                string `json:"version"`
+	ControllerConnectionId string `json:"controller_connection_id"`
+	HostConnectionId       string `json:"host_connection_id"`
+	Address                string `json:"address"`
+	Encoding               string `json:"encoding"`
+	TxType                 string `json:"tx_type"`
 }
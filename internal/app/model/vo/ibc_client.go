@@ -0,0 +1,73 @@
+package vo
+
+import "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+
+type (
+	IbcClientsReq struct {
+		Page
+		UseCount bool   `json:"use_count" form:"use_count"`
+		Chain    string `json:"chain" form:"chain"`
+		Status   string `json:"status" form:"status"`
+	}
+	IbcClientsResp struct {
+		Items     []IbcClientDto `json:"items"`
+		PageInfo  PageInfo       `json:"page_info"`
+		TimeStamp int64          `json:"time_stamp"`
+	}
+
+	// ExpiringClientsReq Within: seconds, clients expiring within it are listed, expired ones included
+	ExpiringClientsReq struct {
+		Chain  string `json:"chain" form:"chain"`
+		Within int64  `json:"within" form:"within"`
+	}
+	ExpiringClientsResp struct {
+		Items     []IbcClientDto `json:"items"`
+		TimeStamp int64          `json:"time_stamp"`
+	}
+
+	// IbcClientDto ExpireIn: seconds left before the client expires, negative if it has expired
+	IbcClientDto struct {
+		Chain              string `json:"chain"`
+		ClientId           string `json:"client_id"`
+		ClientType         string `json:"client_type"`
+		CounterpartyChain  string `json:"counterparty_chain"`
+		ChainId            string `json:"chain_id"`
+		PortId             string `json:"port_id"`
+		ChannelId          string `json:"channel_id"`
+		TrustingPeriod     int64  `json:"trusting_period"`
+		LatestHeight       string `json:"latest_height"`
+		FrozenHeight       string `json:"frozen_height"`
+		LastUpdateTime     int64  `json:"last_update_time"`
+		ExpireTime         int64  `json:"expire_time"`
+		ExpireIn           int64  `json:"expire_in"`
+		Status             string `json:"status"`
+		LatestUpdateTxTime int64  `json:"latest_update_tx_time"`
+		UpdateTxs24h       int64  `json:"update_txs_24h"`
+		UpdateAt           int64  `json:"update_at"`
+	}
+)
+
+func LoadIbcClientDto(client *entity.IBCClient, now int64) IbcClientDto {
+	dto := IbcClientDto{
+		Chain:              client.Chain,
+		ClientId:           client.ClientId,
+		ClientType:         client.ClientType,
+		CounterpartyChain:  client.CounterpartyChain,
+		ChainId:            client.ChainId,
+		PortId:             client.PortId,
+		ChannelId:          client.ChannelId,
+		TrustingPeriod:     client.TrustingPeriod,
+		LatestHeight:       client.LatestHeight,
+		FrozenHeight:       client.FrozenHeight,
+		LastUpdateTime:     client.LastUpdateTime,
+		ExpireTime:         client.ExpireTime,
+		Status:             string(client.Status),
+		LatestUpdateTxTime: client.LatestUpdateTxTime,
+		UpdateTxs24h:       client.UpdateTxs24h,
+		UpdateAt:           client.UpdateAt,
+	}
+	if client.ExpireTime > 0 {
+		dto.ExpireIn = client.ExpireTime - now
+	}
+	return dto
+}
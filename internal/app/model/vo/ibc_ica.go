@@ -0,0 +1,181 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	IcaTxsReq struct {
+		Page
+		UseCount  bool   `json:"use_count" form:"use_count"`
+		DateRange string `json:"date_range" form:"date_range"`
+		Status    string `json:"status" form:"status"`
+		Chain     string `json:"chain" form:"chain"`
+	}
+	IcaTxsResp struct {
+		Items     []IbcIcaTxDto `json:"items"`
+		PageInfo  PageInfo      `json:"page_info"`
+		TimeStamp int64         `json:"time_stamp"`
+	}
+
+	IbcIcaTxDto struct {
+		RecordId   string    `json:"record_id"`
+		ScAddr     string    `json:"sc_addr"`
+		DcAddr     string    `json:"dc_addr"`
+		Status     int       `json:"status"`
+		ScChain    string    `json:"sc_chain"`
+		DcChain    string    `json:"dc_chain"`
+		ScPort     string    `json:"sc_port"`
+		ScChannel  string    `json:"sc_channel"`
+		DcChannel  string    `json:"dc_channel"`
+		Sequence   string    `json:"sequence"`
+		PacketType string    `json:"packet_type"`
+		ScTxInfo   TxInfoDto `json:"sc_tx_info"`
+		DcTxInfo   TxInfoDto `json:"dc_tx_info"`
+		TxTime     int64     `json:"tx_time"`
+		EndTime    int64     `json:"end_time"`
+	}
+
+	IcaTxDetailResp struct {
+		Items       []IbcIcaTxDto `json:"items,omitempty"`
+		IsList      bool          `json:"is_list"`
+		ScInfo      *ChainInfo    `json:"sc_info"`
+		DcInfo      *ChainInfo    `json:"dc_info"`
+		RelayerInfo *RelayerInfo  `json:"relayer_info"`
+		IbcTxInfo   *IbcTxInfo    `json:"ibc_tx_info"`
+		Status      int           `json:"status"`
+		Sequence    string        `json:"sequence"`
+		PacketType  string        `json:"packet_type"`
+		PacketMemo  string        `json:"packet_memo"`
+		ErrorLog    string        `json:"error_log"`
+		TimeStamp   int64         `json:"time_stamp"`
+	}
+
+	IcaAccountsReq struct {
+		Page
+		UseCount          bool   `json:"use_count" form:"use_count"`
+		ControllerChain   string `json:"controller_chain" form:"controller_chain"`
+		ControllerAddress string `json:"controller_address" form:"controller_address"`
+		HostChain         string `json:"host_chain" form:"host_chain"`
+	}
+	IcaAccountsResp struct {
+		Items     []IcaAccountDto `json:"items"`
+		PageInfo  PageInfo        `json:"page_info"`
+		TimeStamp int64           `json:"time_stamp"`
+	}
+	IcaAccountDto struct {
+		ControllerChain        string `json:"controller_chain"`
+		ControllerAddress      string `json:"controller_address"`
+		ControllerPort         string `json:"controller_port"`
+		ControllerChannel      string `json:"controller_channel"`
+		ControllerConnectionId string `json:"controller_connection_id"`
+		HostChain              string `json:"host_chain"`
+		HostAddress            string `json:"host_address"`
+		HostPort               string `json:"host_port"`
+		HostChannel            string `json:"host_channel"`
+		HostConnectionId       string `json:"host_connection_id"`
+		RegisterTxHash         string `json:"register_tx_hash"`
+		RegisterTime           int64  `json:"register_time"`
+	}
+)
+
+func (dto IbcIcaTxDto) LoadDto(ibcIcaTx *entity.ExIbcIcaTx) IbcIcaTxDto {
+	endTime := int64(0)
+	switch ibcIcaTx.Status {
+	case entity.IbcTxStatusSuccess:
+		if ibcIcaTx.DcTxInfo != nil {
+			endTime = ibcIcaTx.DcTxInfo.Time
+		}
+	case entity.IbcTxStatusFailed:
+		if ibcIcaTx.ScTxInfo != nil && ibcIcaTx.ScTxInfo.Status == entity.TxStatusFailed {
+			endTime = ibcIcaTx.ScTxInfo.Time
+		} else if ibcIcaTx.AckTimeoutTxInfo != nil {
+			endTime = ibcIcaTx.AckTimeoutTxInfo.Time
+		} else if ibcIcaTx.DcTxInfo != nil {
+			endTime = ibcIcaTx.DcTxInfo.Time
+		}
+	}
+	return IbcIcaTxDto{
+		RecordId:   ibcIcaTx.RecordId,
+		ScAddr:     ibcIcaTx.ScAddr,
+		DcAddr:     ibcIcaTx.DcAddr,
+		Status:     int(ibcIcaTx.Status),
+		ScChain:    ibcIcaTx.ScChain,
+		DcChain:    ibcIcaTx.DcChain,
+		ScPort:     ibcIcaTx.ScPort,
+		ScChannel:  ibcIcaTx.ScChannel,
+		DcChannel:  ibcIcaTx.DcChannel,
+		Sequence:   ibcIcaTx.Sequence,
+		PacketType: ibcIcaTx.PacketType,
+		ScTxInfo:   loadTxInfoDto(ibcIcaTx.ScTxInfo),
+		DcTxInfo:   loadTxInfoDto(ibcIcaTx.DcTxInfo),
+		TxTime:     ibcIcaTx.TxTime,
+		EndTime:    endTime,
+	}
+}
+
+func LoadIcaTxDetail(ibcIcaTx *entity.ExIbcIcaTx) IcaTxDetailResp {
+	var errLog string
+	if ibcIcaTx.Status == entity.IbcTxStatusFailed {
+		if ibcIcaTx.ScTxInfo != nil && ibcIcaTx.ScTxInfo.Status == entity.TxStatusFailed {
+			errLog = ibcIcaTx.ScTxInfo.Log
+		} else if ibcIcaTx.DcTxInfo != nil && ibcIcaTx.AckTimeoutTxInfo != nil && ibcIcaTx.AckTimeoutTxInfo.Msg != nil {
+			errLog = ibcIcaTx.AckTimeoutTxInfo.Msg.AckPacketMsg().Acknowledgement
+		}
+	}
+
+	ibcTxInfo := &IbcTxInfo{
+		ScTxInfo: loadTxDetailDto(ibcIcaTx.ScTxInfo),
+	}
+	if ibcIcaTx.DcTxInfo != nil {
+		ibcTxInfo.DcTxInfo = loadTxDetailDto(ibcIcaTx.DcTxInfo)
+		if ibcIcaTx.AckTimeoutTxInfo != nil && ibcIcaTx.AckTimeoutTxInfo.Msg != nil {
+			ibcTxInfo.DcTxInfo.Ack = ibcIcaTx.AckTimeoutTxInfo.Msg.AckPacketMsg().Acknowledgement
+		}
+	}
+	if ibcIcaTx.AckTimeoutTxInfo != nil {
+		ibcTxInfo.AckTimeoutTxInfo = loadTxDetailDto(ibcIcaTx.AckTimeoutTxInfo)
+	}
+
+	return IcaTxDetailResp{
+		ErrorLog:   errLog,
+		Status:     int(ibcIcaTx.Status),
+		Sequence:   ibcIcaTx.Sequence,
+		PacketType: ibcIcaTx.PacketType,
+		PacketMemo: ibcIcaTx.PacketMemo,
+		ScInfo: &ChainInfo{
+			Address:      ibcIcaTx.ScAddr,
+			Chain:        ibcIcaTx.ScChain,
+			ChannelId:    ibcIcaTx.ScChannel,
+			PortId:       ibcIcaTx.ScPort,
+			ConnectionId: ibcIcaTx.ScConnectionId,
+			ClientId:     ibcIcaTx.ScClientId,
+		},
+		DcInfo: &ChainInfo{
+			Address:      ibcIcaTx.DcAddr,
+			Chain:        ibcIcaTx.DcChain,
+			ChannelId:    ibcIcaTx.DcChannel,
+			PortId:       ibcIcaTx.DcPort,
+			ConnectionId: ibcIcaTx.DcConnectionId,
+			ClientId:     ibcIcaTx.DcClientId,
+		},
+		IbcTxInfo: ibcTxInfo,
+	}
+}
+
+func LoadIcaAccountDto(account *entity.IbcIcaAccount) IcaAccountDto {
+	return IcaAccountDto{
+		ControllerChain:        account.ControllerChain,
+		ControllerAddress:      account.ControllerAddress,
+		ControllerPort:         account.ControllerPort,
+		ControllerChannel:      account.ControllerChannel,
+		ControllerConnectionId: account.ControllerConnectionId,
+		HostChain:              account.HostChain,
+		HostAddress:            account.HostAddress,
+		HostPort:               account.HostPort,
+		HostChannel:            account.HostChannel,
+		HostConnectionId:       account.HostConnectionId,
+		RegisterTxHash:         account.RegisterTxHash,
+		RegisterTime:           account.RegisterTime,
+	}
+}
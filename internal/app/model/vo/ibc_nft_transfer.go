@@ -0,0 +1,193 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	NftTransferTxsReq struct {
+		Page
+		UseCount       bool   `json:"use_count" form:"use_count"`
+		DateRange      string `json:"date_range" form:"date_range"`
+		Status         string `json:"status" form:"status"`
+		Chain          string `json:"chain" form:"chain"`
+		ClassId        string `json:"class_id" form:"class_id"`
+		BaseClassId    string `json:"base_class_id" form:"base_class_id"`
+		BaseClassChain string `json:"base_class_chain" form:"base_class_chain"`
+	}
+	NftTransferTxsResp struct {
+		Items     []IbcNftTxDto `json:"items"`
+		PageInfo  PageInfo      `json:"page_info"`
+		TimeStamp int64         `json:"time_stamp"`
+	}
+
+	IbcNftTxDto struct {
+		RecordId  string    `json:"record_id"`
+		ScAddr    string    `json:"sc_addr"`
+		DcAddr    string    `json:"dc_addr"`
+		Status    int       `json:"status"`
+		ScChain   string    `json:"sc_chain"`
+		DcChain   string    `json:"dc_chain"`
+		ScChannel string    `json:"sc_channel"`
+		DcChannel string    `json:"dc_channel"`
+		Sequence  string    `json:"sequence"`
+		ScTxInfo  TxInfoDto `json:"sc_tx_info"`
+		DcTxInfo  TxInfoDto `json:"dc_tx_info"`
+		Class     NftClass  `json:"class"`
+		TokenIds  []string  `json:"token_ids"`
+		TxTime    int64     `json:"tx_time"`
+		EndTime   int64     `json:"end_time"`
+	}
+
+	NftClass struct {
+		ScClassId      string `json:"sc_class_id"`
+		DcClassId      string `json:"dc_class_id"`
+		ClassPath      string `json:"class_path"`
+		ClassUri       string `json:"class_uri"`
+		BaseClassId    string `json:"base_class_id"`
+		BaseClassChain string `json:"base_class_chain"`
+	}
+
+	NftTransferTxDetailResp struct {
+		Items       []IbcNftTxDto `json:"items,omitempty"`
+		IsList      bool          `json:"is_list"`
+		ScInfo      *ChainInfo    `json:"sc_info"`
+		DcInfo      *ChainInfo    `json:"dc_info"`
+		Class       *NftClass     `json:"class"`
+		TokenIds    []string      `json:"token_ids"`
+		TokenUris   []string      `json:"token_uris"`
+		RelayerInfo *RelayerInfo  `json:"relayer_info"`
+		IbcTxInfo   *IbcTxInfo    `json:"ibc_tx_info"`
+		Status      int           `json:"status"`
+		Sequence    string        `json:"sequence"`
+		ErrorLog    string        `json:"error_log"`
+		TimeStamp   int64         `json:"time_stamp"`
+	}
+
+	NftClassListReq struct {
+		Page
+		UseCount bool   `json:"use_count" form:"use_count"`
+		Chain    string `json:"chain" form:"chain"`
+	}
+	NftClassListResp struct {
+		Items     []NftClassDto `json:"items"`
+		PageInfo  PageInfo      `json:"page_info"`
+		TimeStamp int64         `json:"time_stamp"`
+	}
+	NftClassDto struct {
+		BaseClassId    string `json:"base_class_id"`
+		BaseClassChain string `json:"base_class_chain"`
+		TxsCount       int64  `json:"txs_count"`
+		TokensCount    int64  `json:"tokens_count"`
+		LatestTxTime   int64  `json:"latest_tx_time"`
+	}
+)
+
+func loadNftClass(class *entity.NftClass) NftClass {
+	if class == nil {
+		return NftClass{}
+	}
+	return NftClass{
+		ScClassId:      class.ScClassId,
+		DcClassId:      class.DcClassId,
+		ClassPath:      class.ClassPath,
+		ClassUri:       class.ClassUri,
+		BaseClassId:    class.BaseClassId,
+		BaseClassChain: class.BaseClassChain,
+	}
+}
+
+func (dto IbcNftTxDto) LoadDto(ibcNftTx *entity.ExIbcNftTx) IbcNftTxDto {
+	endTime := int64(0)
+	switch ibcNftTx.Status {
+	case entity.IbcTxStatusSuccess:
+		if ibcNftTx.DcTxInfo != nil {
+			endTime = ibcNftTx.DcTxInfo.Time
+		}
+	case entity.IbcTxStatusFailed:
+		if ibcNftTx.ScTxInfo != nil && ibcNftTx.ScTxInfo.Status == entity.TxStatusFailed {
+			endTime = ibcNftTx.ScTxInfo.Time
+		} else if ibcNftTx.AckTimeoutTxInfo != nil {
+			endTime = ibcNftTx.AckTimeoutTxInfo.Time
+		}
+	case entity.IbcTxStatusRefunded:
+		if ibcNftTx.AckTimeoutTxInfo != nil {
+			endTime = ibcNftTx.AckTimeoutTxInfo.Time
+		}
+	}
+	return IbcNftTxDto{
+		RecordId:  ibcNftTx.RecordId,
+		ScAddr:    ibcNftTx.ScAddr,
+		DcAddr:    ibcNftTx.DcAddr,
+		Status:    int(ibcNftTx.Status),
+		ScChain:   ibcNftTx.ScChain,
+		DcChain:   ibcNftTx.DcChain,
+		ScChannel: ibcNftTx.ScChannel,
+		DcChannel: ibcNftTx.DcChannel,
+		Sequence:  ibcNftTx.Sequence,
+		ScTxInfo:  loadTxInfoDto(ibcNftTx.ScTxInfo),
+		DcTxInfo:  loadTxInfoDto(ibcNftTx.DcTxInfo),
+		Class:     loadNftClass(ibcNftTx.Class),
+		TokenIds:  ibcNftTx.TokenIds,
+		TxTime:    ibcNftTx.TxTime,
+		EndTime:   endTime,
+	}
+}
+
+func LoadNftTransferTxDetail(ibcNftTx *entity.ExIbcNftTx) NftTransferTxDetailResp {
+	var errLog string
+	switch ibcNftTx.Status {
+	case entity.IbcTxStatusFailed:
+		errLog = ibcNftTx.ScTxInfo.Log
+	case entity.IbcTxStatusRefunded:
+		if ibcNftTx.DcTxInfo != nil {
+			if ibcNftTx.DcTxInfo.Status == entity.TxStatusSuccess {
+				if ibcNftTx.AckTimeoutTxInfo != nil && ibcNftTx.AckTimeoutTxInfo.Msg != nil {
+					errLog = ibcNftTx.AckTimeoutTxInfo.Msg.AckPacketMsg().Acknowledgement
+				}
+			} else {
+				errLog = ibcNftTx.DcTxInfo.Log
+			}
+		}
+	}
+
+	ibcTxInfo := &IbcTxInfo{
+		ScTxInfo: loadTxDetailDto(ibcNftTx.ScTxInfo),
+	}
+	if ibcNftTx.DcTxInfo != nil {
+		ibcTxInfo.DcTxInfo = loadTxDetailDto(ibcNftTx.DcTxInfo)
+		if ibcNftTx.AckTimeoutTxInfo != nil && ibcNftTx.AckTimeoutTxInfo.Msg != nil {
+			ibcTxInfo.DcTxInfo.Ack = ibcNftTx.AckTimeoutTxInfo.Msg.AckPacketMsg().Acknowledgement
+		}
+	}
+	if ibcNftTx.AckTimeoutTxInfo != nil {
+		ibcTxInfo.AckTimeoutTxInfo = loadTxDetailDto(ibcNftTx.AckTimeoutTxInfo)
+	}
+
+	class := loadNftClass(ibcNftTx.Class)
+	return NftTransferTxDetailResp{
+		ErrorLog: errLog,
+		Status:   int(ibcNftTx.Status),
+		Sequence: ibcNftTx.Sequence,
+		ScInfo: &ChainInfo{
+			Address:      ibcNftTx.ScAddr,
+			Chain:        ibcNftTx.ScChain,
+			ChannelId:    ibcNftTx.ScChannel,
+			PortId:       ibcNftTx.ScPort,
+			ConnectionId: ibcNftTx.ScConnectionId,
+			ClientId:     ibcNftTx.ScClientId,
+		},
+		DcInfo: &ChainInfo{
+			Address:      ibcNftTx.DcAddr,
+			Chain:        ibcNftTx.DcChain,
+			ChannelId:    ibcNftTx.DcChannel,
+			PortId:       ibcNftTx.DcPort,
+			ConnectionId: ibcNftTx.DcConnectionId,
+			ClientId:     ibcNftTx.DcClientId,
+		},
+		Class:     &class,
+		TokenIds:  ibcNftTx.TokenIds,
+		TokenUris: ibcNftTx.TokenUris,
+		IbcTxInfo: ibcTxInfo,
+	}
+}
@@ -0,0 +1,31 @@
+package vo
+
+type (
+	ChannelLatencyTrendReq struct {
+		Chain     string `json:"chain" form:"chain" binding:"required"`
+		Channel   string `json:"channel" form:"channel" binding:"required"`
+		Period    string `json:"period" form:"period"`
+		DateRange string `json:"date_range" form:"date_range"`
+	}
+	RelayerLatencyTrendReq struct {
+		Period    string `json:"period" form:"period"`
+		DateRange string `json:"date_range" form:"date_range"`
+	}
+	PacketLatencyTrendResp struct {
+		Items     []PacketLatencyTrendItem `json:"items"`
+		TimeStamp int64                    `json:"time_stamp"`
+	}
+	// PacketLatencyTrendItem latency(seconds) of a channel pair and stage in the segment, relayers are merged
+	PacketLatencyTrendItem struct {
+		SegmentStartTime int64  `json:"segment_start_time"`
+		ScChain          string `json:"sc_chain"`
+		ScChannel        string `json:"sc_channel"`
+		DcChain          string `json:"dc_chain"`
+		DcChannel        string `json:"dc_channel"`
+		Stage            string `json:"stage"`
+		TxsNumber        int64  `json:"txs_number"`
+		P50              int64  `json:"p50"`
+		P90              int64  `json:"p90"`
+		P99              int64  `json:"p99"`
+	}
+)
@@ -40,36 +40,39 @@ type (
 	}
 
 	IbcTxDto struct {
-		RecordId       string    `json:"record_id"`
-		ScAddr         string    `json:"sc_addr"`
-		DcAddr         string    `json:"dc_addr"`
-		Status         int       `json:"status"`
-		ScChain        string    `json:"sc_chain"`
-		DcChain        string    `json:"dc_chain"`
-		ScChannel      string    `json:"sc_channel"`
-		DcChannel      string    `json:"dc_channel"`
-		Sequence       string    `json:"sequence"`
-		ScTxInfo       TxInfoDto `json:"sc_tx_info"`
-		DcTxInfo       TxInfoDto `json:"dc_tx_info"`
-		BaseDenom      string    `json:"base_denom"`
-		BaseDenomChain string    `json:"base_denom_chain"`
-		Denoms         Denoms    `json:"denoms"`
-		TxTime         int64     `json:"tx_time"`
-		EndTime        int64     `json:"end_time"`
+		RecordId       string             `json:"record_id"`
+		ScAddr         string             `json:"sc_addr"`
+		DcAddr         string             `json:"dc_addr"`
+		Status         int                `json:"status"`
+		ScChain        string             `json:"sc_chain"`
+		DcChain        string             `json:"dc_chain"`
+		ScChannel      string             `json:"sc_channel"`
+		DcChannel      string             `json:"dc_channel"`
+		Sequence       string             `json:"sequence"`
+		ScTxInfo       TxInfoDto          `json:"sc_tx_info"`
+		DcTxInfo       TxInfoDto          `json:"dc_tx_info"`
+		BaseDenom      string             `json:"base_denom"`
+		BaseDenomChain string             `json:"base_denom_chain"`
+		Denoms         Denoms             `json:"denoms"`
+		Tokens         []TransferTokenDto `json:"tokens,omitempty"`
+		TxTime         int64              `json:"tx_time"`
+		EndTime        int64              `json:"end_time"`
 	}
 
 	TranaferTxDetailNewResp struct {
-		Items       []IbcTxDto   `json:"items,omitempty"`
-		IsList      bool         `json:"is_list"`
-		ScInfo      *ChainInfo   `json:"sc_info"`
-		DcInfo      *ChainInfo   `json:"dc_info"`
-		TokenInfo   *TokenInfo   `json:"token_info"`
-		RelayerInfo *RelayerInfo `json:"relayer_info"`
-		IbcTxInfo   *IbcTxInfo   `json:"ibc_tx_info"`
-		Status      int          `json:"status"`
-		Sequence    string       `json:"sequence"`
-		ErrorLog    string       `json:"error_log"`
-		TimeStamp   int64        `json:"time_stamp"`
+		Items         []IbcTxDto   `json:"items,omitempty"`
+		IsList        bool         `json:"is_list"`
+		ScInfo        *ChainInfo   `json:"sc_info"`
+		DcInfo        *ChainInfo   `json:"dc_info"`
+		TokenInfo     *TokenInfo   `json:"token_info"`
+		RelayerInfo   *RelayerInfo `json:"relayer_info"`
+		IbcTxInfo     *IbcTxInfo   `json:"ibc_tx_info"`
+		Status        int          `json:"status"`
+		Sequence      string       `json:"sequence"`
+		ErrorLog      string       `json:"error_log"`
+		ErrorCategory string       `json:"error_category,omitempty"`
+		Journey       *Journey     `json:"journey,omitempty"`
+		TimeStamp     int64        `json:"time_stamp"`
 	}
 
 	TraceSourceReq struct {
@@ -90,6 +93,13 @@ type (
 		ScDenom string `json:"sc_denom"`
 		DcDenom string `json:"dc_denom"`
 	}
+	// TransferTokenDto coin of ics20 v2 multi-denom transfer
+	TransferTokenDto struct {
+		BaseDenom      string `json:"base_denom"`
+		BaseDenomChain string `json:"base_denom_chain"`
+		Denoms         Denoms `json:"denoms"`
+		Amount         string `json:"amount"`
+	}
 	ChainInfo struct {
 		Address      string `json:"address"`
 		Chain        string `json:"chain"`
@@ -99,11 +109,12 @@ type (
 		ClientId     string `json:"client_id"`
 	}
 	TokenInfo struct {
-		BaseDenom      string      `json:"base_denom"`
-		BaseDenomChain string      `json:"base_denom_chain"`
-		SendToken      DetailToken `json:"send_token"`
-		RecvToken      DetailToken `json:"recv_token"`
-		Amount         string      `json:"amount"`
+		BaseDenom      string       `json:"base_denom"`
+		BaseDenomChain string       `json:"base_denom_chain"`
+		SendToken      DetailToken  `json:"send_token"`
+		RecvToken      DetailToken  `json:"recv_token"`
+		Amount         string       `json:"amount"`
+		Tokens         []*TokenInfo `json:"tokens,omitempty"`
 	}
 	DetailToken struct {
 		Denom     string `json:"denom"`
@@ -222,6 +233,9 @@ func loadTxDetailDto(info *entity.TxInfo) *TxDetailDto {
 		case constant.MsgTypeTransfer:
 			dto.TimeoutTimestamp = info.Msg.TransferMsg().TimeoutTimestamp
 			dto.TimeoutHeight = timeHeightString(info.Msg.TransferMsg().TimeoutHeight)
+		case constant.MsgTypeNftTransfer:
+			dto.TimeoutTimestamp = info.Msg.NftTransferMsg().TimeoutTimestamp
+			dto.TimeoutHeight = timeHeightString(info.Msg.NftTransferMsg().TimeoutHeight)
 		}
 	}
 
@@ -265,11 +279,28 @@ func (dto IbcTxDto) LoadDto(ibcTx *entity.ExIbcTx) IbcTxDto {
 		BaseDenom:      ibcTx.BaseDenom,
 		BaseDenomChain: ibcTx.BaseDenomChain,
 		Denoms:         Denoms{ScDenom: ibcTx.Denoms.ScDenom, DcDenom: ibcTx.Denoms.DcDenom},
+		Tokens:         loadTransferTokenDto(ibcTx.Tokens),
 		TxTime:         ibcTx.TxTime,
 		EndTime:        endTime,
 	}
 }
 
+func loadTransferTokenDto(tokens []*entity.TransferToken) []TransferTokenDto {
+	if len(tokens) == 0 {
+		return nil
+	}
+	res := make([]TransferTokenDto, 0, len(tokens))
+	for _, v := range tokens {
+		res = append(res, TransferTokenDto{
+			BaseDenom:      v.BaseDenom,
+			BaseDenomChain: v.BaseDenomChain,
+			Denoms:         Denoms{ScDenom: v.ScDenom, DcDenom: v.DcDenom},
+			Amount:         v.Amount,
+		})
+	}
+	return res
+}
+
 func LoadTranaferTxDetail(ibcTx *entity.ExIbcTx) TranaferTxDetailNewResp {
 	scChainInfo, dcChainInfo := loadChainInfo(ibcTx)
 	var errLog string
@@ -301,11 +332,12 @@ func LoadTranaferTxDetail(ibcTx *entity.ExIbcTx) TranaferTxDetailNewResp {
 		ibcTxInfo.AckTimeoutTxInfo = loadTxDetailDto(ibcTx.AckTimeoutTxInfo)
 	}
 	return TranaferTxDetailNewResp{
-		ErrorLog:  errLog,
-		Status:    int(ibcTx.Status),
-		Sequence:  ibcTx.Sequence,
-		ScInfo:    scChainInfo,
-		DcInfo:    dcChainInfo,
-		IbcTxInfo: ibcTxInfo,
+		ErrorLog:      errLog,
+		ErrorCategory: string(ibcTx.ErrorCategory),
+		Status:        int(ibcTx.Status),
+		Sequence:      ibcTx.Sequence,
+		ScInfo:        scChainInfo,
+		DcInfo:        dcChainInfo,
+		IbcTxInfo:     ibcTxInfo,
 	}
 }
@@ -0,0 +1,21 @@
+package vo
+
+type (
+	TransferFailureStatisticsReq struct {
+		DateRange string `json:"date_range" form:"date_range"`
+		Chain     string `json:"chain" form:"chain"`
+	}
+	TransferFailureStatisticsResp struct {
+		Items     []TransferFailureStatisticsItem `json:"items"`
+		TimeStamp int64                           `json:"time_stamp"`
+	}
+	// TransferFailureStatisticsItem count of failed transfers of a channel pair by error category
+	TransferFailureStatisticsItem struct {
+		ScChain       string `json:"sc_chain"`
+		ScChannel     string `json:"sc_channel"`
+		DcChain       string `json:"dc_chain"`
+		DcChannel     string `json:"dc_channel"`
+		ErrorCategory string `json:"error_category"`
+		Count         int64  `json:"count"`
+	}
+)
@@ -0,0 +1,74 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	// Journey hops of a transfer routed by packet-forward-middleware, ordered from the first hop
+	Journey struct {
+		JourneyId string       `json:"journey_id"`
+		Status    int          `json:"status"`
+		Hops      []JourneyHop `json:"hops"`
+	}
+	JourneyHop struct {
+		IbcTxDto
+		ParentRecordId string     `json:"parent_record_id"`
+		RefundTxInfo   *TxInfoDto `json:"refund_tx_info,omitempty"`
+	}
+)
+
+// LoadJourney order the hops by parent link, the status of journey is the status of the first unsuccessful hop
+func LoadJourney(journeyId string, ibcTxs []*entity.ExIbcTx) *Journey {
+	var root *entity.ExIbcTx
+	childMap := make(map[string][]*entity.ExIbcTx, len(ibcTxs))
+	for _, v := range ibcTxs {
+		if v.RecordId == journeyId {
+			root = v
+			continue
+		}
+		childMap[v.ParentRecordId] = append(childMap[v.ParentRecordId], v)
+	}
+
+	var orderedTxs []*entity.ExIbcTx
+	if root == nil { // the first hop is not found, ibcTxs are sorted by tx_time already
+		orderedTxs = ibcTxs
+	} else {
+		visited := make(map[string]struct{}, len(ibcTxs))
+		for hop := root; hop != nil; {
+			if _, ok := visited[hop.RecordId]; ok {
+				break
+			}
+			visited[hop.RecordId] = struct{}{}
+			orderedTxs = append(orderedTxs, hop)
+
+			children := childMap[hop.RecordId]
+			if len(children) == 0 {
+				break
+			}
+			hop = children[0]
+		}
+	}
+
+	journey := &Journey{
+		JourneyId: journeyId,
+		Status:    int(entity.IbcTxStatusSuccess),
+		Hops:      make([]JourneyHop, 0, len(orderedTxs)),
+	}
+	var dto IbcTxDto
+	for _, v := range orderedTxs {
+		hop := JourneyHop{
+			IbcTxDto:       dto.LoadDto(v),
+			ParentRecordId: v.ParentRecordId,
+		}
+		if v.Status == entity.IbcTxStatusRefunded && v.AckTimeoutTxInfo != nil {
+			refundTxInfo := loadTxInfoDto(v.AckTimeoutTxInfo)
+			hop.RefundTxInfo = &refundTxInfo
+		}
+		if journey.Status == int(entity.IbcTxStatusSuccess) && v.Status != entity.IbcTxStatusSuccess {
+			journey.Status = int(v.Status)
+		}
+		journey.Hops = append(journey.Hops, hop)
+	}
+	return journey
+}
@@ -0,0 +1,62 @@
+package vo
+
+import "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+
+type (
+	DeadLettersReq struct {
+		Page
+		UseCount   bool   `json:"use_count" form:"use_count"`
+		Chain      string `json:"chain" form:"chain"`
+		ReasonCode string `json:"reason_code" form:"reason_code"`
+	}
+	DeadLettersResp struct {
+		Items     []DeadLetterDto `json:"items"`
+		PageInfo  PageInfo        `json:"page_info"`
+		TimeStamp int64           `json:"time_stamp"`
+	}
+
+	DeadLetterDto struct {
+		RecordId    string                `json:"record_id"`
+		Source      string                `json:"source"`
+		ScChain     string                `json:"sc_chain"`
+		DcChain     string                `json:"dc_chain"`
+		ReasonCode  string                `json:"reason_code"`
+		ProcessInfo string                `json:"process_info"`
+		RetryTimes  int64                 `json:"retry_times"`
+		PacketIds   []DeadLetterPacketDto `json:"packet_ids"`
+		CreateAt    int64                 `json:"create_at"`
+	}
+	DeadLetterPacketDto struct {
+		Chain    string `json:"chain"`
+		TxType   string `json:"tx_type"`
+		PacketId string `json:"packet_id"`
+	}
+
+	DeadLetterDetailResp struct {
+		DeadLetterDto
+		IbcTx     *IbcTxDto `json:"ibc_tx"`
+		TimeStamp int64     `json:"time_stamp"`
+	}
+)
+
+func LoadDeadLetterDto(deadLetter *entity.IbcTxDeadLetter) DeadLetterDto {
+	packetIds := make([]DeadLetterPacketDto, 0, len(deadLetter.PacketIds))
+	for _, v := range deadLetter.PacketIds {
+		packetIds = append(packetIds, DeadLetterPacketDto{
+			Chain:    v.Chain,
+			TxType:   string(v.TxType),
+			PacketId: v.PacketId,
+		})
+	}
+	return DeadLetterDto{
+		RecordId:    deadLetter.RecordId,
+		Source:      deadLetter.Source,
+		ScChain:     deadLetter.ScChain,
+		DcChain:     deadLetter.DcChain,
+		ReasonCode:  string(deadLetter.ReasonCode),
+		ProcessInfo: deadLetter.ProcessInfo,
+		RetryTimes:  deadLetter.RetryTimes,
+		PacketIds:   packetIds,
+		CreateAt:    deadLetter.CreateAt,
+	}
+}
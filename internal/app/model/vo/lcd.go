@@ -1,5 +1,10 @@
 package vo
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type SupplyResp struct {
 	Supply []struct {
 		Denom  string `json:"denom"`
@@ -99,58 +104,75 @@ type IbcChannelsResp struct {
 	} `json:"height"`
 }
 
+type IdentifiedClientState struct {
+	ClientId    string `json:"client_id"`
+	ClientState struct {
+		Type       string `json:"@type"`
+		ChainId    string `json:"chain_id"`
+		TrustLevel struct {
+			Numerator   string `json:"numerator"`
+			Denominator string `json:"denominator"`
+		} `json:"trust_level"`
+		TrustingPeriod  string `json:"trusting_period"`
+		UnbondingPeriod string `json:"unbonding_period"`
+		MaxClockDrift   string `json:"max_clock_drift"`
+		FrozenHeight    struct {
+			RevisionNumber string `json:"revision_number"`
+			RevisionHeight string `json:"revision_height"`
+		} `json:"frozen_height"`
+		LatestHeight struct {
+			RevisionNumber string `json:"revision_number"`
+			RevisionHeight string `json:"revision_height"`
+		} `json:"latest_height"`
+		ProofSpecs []struct {
+			LeafSpec struct {
+				Hash         string `json:"hash"`
+				PrehashKey   string `json:"prehash_key"`
+				PrehashValue string `json:"prehash_value"`
+				Length       string `json:"length"`
+				Prefix       string `json:"prefix"`
+			} `json:"leaf_spec"`
+			InnerSpec struct {
+				ChildOrder      []int       `json:"child_order"`
+				ChildSize       int         `json:"child_size"`
+				MinPrefixLength int         `json:"min_prefix_length"`
+				MaxPrefixLength int         `json:"max_prefix_length"`
+				EmptyChild      interface{} `json:"empty_child"`
+				Hash            string      `json:"hash"`
+			} `json:"inner_spec"`
+			MaxDepth int `json:"max_depth"`
+			MinDepth int `json:"min_depth"`
+		} `json:"proof_specs"`
+		UpgradePath                  []string `json:"upgrade_path"`
+		AllowUpdateAfterExpiry       bool     `json:"allow_update_after_expiry"`
+		AllowUpdateAfterMisbehaviour bool     `json:"allow_update_after_misbehaviour"`
+	} `json:"client_state"`
+}
+
 type ClientStateResp struct {
-	IdentifiedClientState struct {
-		ClientId    string `json:"client_id"`
-		ClientState struct {
-			Type       string `json:"@type"`
-			ChainId    string `json:"chain_id"`
-			TrustLevel struct {
-				Numerator   string `json:"numerator"`
-				Denominator string `json:"denominator"`
-			} `json:"trust_level"`
-			TrustingPeriod  string `json:"trusting_period"`
-			UnbondingPeriod string `json:"unbonding_period"`
-			MaxClockDrift   string `json:"max_clock_drift"`
-			FrozenHeight    struct {
-				RevisionNumber string `json:"revision_number"`
-				RevisionHeight string `json:"revision_height"`
-			} `json:"frozen_height"`
-			LatestHeight struct {
-				RevisionNumber string `json:"revision_number"`
-				RevisionHeight string `json:"revision_height"`
-			} `json:"latest_height"`
-			ProofSpecs []struct {
-				LeafSpec struct {
-					Hash         string `json:"hash"`
-					PrehashKey   string `json:"prehash_key"`
-					PrehashValue string `json:"prehash_value"`
-					Length       string `json:"length"`
-					Prefix       string `json:"prefix"`
-				} `json:"leaf_spec"`
-				InnerSpec struct {
-					ChildOrder      []int       `json:"child_order"`
-					ChildSize       int         `json:"child_size"`
-					MinPrefixLength int         `json:"min_prefix_length"`
-					MaxPrefixLength int         `json:"max_prefix_length"`
-					EmptyChild      interface{} `json:"empty_child"`
-					Hash            string      `json:"hash"`
-				} `json:"inner_spec"`
-				MaxDepth int `json:"max_depth"`
-				MinDepth int `json:"min_depth"`
-			} `json:"proof_specs"`
-			UpgradePath                  []string `json:"upgrade_path"`
-			AllowUpdateAfterExpiry       bool     `json:"allow_update_after_expiry"`
-			AllowUpdateAfterMisbehaviour bool     `json:"allow_update_after_misbehaviour"`
-		} `json:"client_state"`
-	} `json:"identified_client_state"`
-	Proof       interface{} `json:"proof"`
-	ProofHeight struct {
+	IdentifiedClientState IdentifiedClientState `json:"identified_client_state"`
+	Proof                 interface{}           `json:"proof"`
+	ProofHeight           struct {
 		RevisionNumber string `json:"revision_number"`
 		RevisionHeight string `json:"revision_height"`
 	} `json:"proof_height"`
 }
 
+type ClientStatesResp struct {
+	ClientStates []IdentifiedClientState `json:"client_states"`
+	Pagination   struct {
+		NextKey *string `json:"next_key"`
+		Total   string  `json:"total"`
+	} `json:"pagination"`
+}
+
+type ConsensusStateResp struct {
+	ConsensusState struct {
+		Type      string    `json:"@type"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"consensus_state"`
+}
+
 type Connections struct {
 	ConnectionPaths []string `json:"connection_paths"`
 }
@@ -244,3 +266,50 @@ type IbcPacketCommitsResp struct {
 		Total   int     `json:"total,string"`
 	} `json:"pagination"`
 }
+
+// RpcResp response of the tendermint rpc, Result is the json of the method
+type RpcResp struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+type RpcTxResp struct {
+	Hash     string `json:"hash"`
+	Height   int64  `json:"height,string"`
+	Index    uint32 `json:"index"`
+	TxResult struct {
+		Code      uint32     `json:"code"`
+		Log       string     `json:"log"`
+		GasUsed   int64      `json:"gas_used,string"`
+		Codespace string     `json:"codespace"`
+		Events    []RpcEvent `json:"events"`
+	} `json:"tx_result"`
+	Tx []byte `json:"tx"`
+}
+
+type RpcEvent struct {
+	Type       string `json:"type"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+type RpcTxSearchResp struct {
+	Txs        []RpcTxResp `json:"txs"`
+	TotalCount int64       `json:"total_count,string"`
+}
+
+type RpcBlockResp struct {
+	Block struct {
+		Header struct {
+			Height int64     `json:"height,string"`
+			Time   time.Time `json:"time"`
+		} `json:"header"`
+	} `json:"block"`
+}
@@ -76,6 +76,17 @@ type IobRegistryRelayerInfoResp struct {
 	Addresses    []map[string]string `json:"addresses"`
 }
 
+// RelayerRegistryDiff relayers(team name) of the registry compared with the registered ones in ibc_relayer.
+// Invalid are the relayer directories whose relayer_info.json can not be loaded or fails the schema, they are left
+// untouched. RemoveBlocked: Removed are more than relayer_registry.max_remove_ratio allows, they are not removed
+type RelayerRegistryDiff struct {
+	Added         []string `json:"added"`
+	Changed       []string `json:"changed"`
+	Removed       []string `json:"removed"`
+	Invalid       []string `json:"invalid"`
+	RemoveBlocked bool     `json:"remove_blocked"`
+}
+
 type TransferTypeTxsResp struct {
 	RecvPacketTxs        int64 `json:"recv_packet_txs"`
 	AcknowledgePacketTxs int64 `json:"acknowledge_packet_txs"`
@@ -103,6 +114,16 @@ type TotalFeeCostResp struct {
 	DenomList       []DenomFeeItem `json:"denom_list"`
 }
 
+// TotalFeeIncomeResp ics29 fees paid to relayer, NetRevenueValue = TotalFeeIncomeValue - TotalFeeCostValue
+type TotalFeeIncomeResp struct {
+	TotalTxs            int64          `json:"total_txs"`
+	TotalFeeIncomeValue string         `json:"total_fee_income_value"`
+	TotalFeeCostValue   string         `json:"total_fee_cost_value"`
+	NetRevenueValue     string         `json:"net_revenue_value"`
+	TotalDenomCount     int64          `json:"total_denom_count"`
+	DenomList           []DenomFeeItem `json:"denom_list"`
+}
+
 type DenomFeeItem struct {
 	Denom      string `json:"denom"`
 	DenomChain string `json:"denom_chain"`
@@ -0,0 +1,26 @@
+package vo
+
+type (
+	TaskAdminListResp struct {
+		Items     []TaskAdminItem `json:"items"`
+		TimeStamp int64           `json:"time_stamp"`
+	}
+	// TaskAdminItem LockHolder and LockTTL(seconds) are those of the task lock, the lock of a lock free task is always
+	// empty, its chains are leased one by one
+	TaskAdminItem struct {
+		TaskName    string `json:"task_name"`
+		Cron        string `json:"cron"`
+		LockFree    bool   `json:"lock_free"`
+		NextRunTime int64  `json:"next_run_time"`
+		InstanceId  string `json:"instance_id"`
+		Paused      bool   `json:"paused"`
+		PausedAt    int64  `json:"paused_at"`
+		LockHolder  string `json:"lock_holder"`
+		LockTTL     int64  `json:"lock_ttl"`
+	}
+
+	TaskUnlockReq struct {
+		// Chain release the chain lease of a lock free task
+		Chain string `json:"chain" form:"chain"`
+	}
+)
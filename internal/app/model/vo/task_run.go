@@ -0,0 +1,60 @@
+package vo
+
+import "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+
+type (
+	TaskListReq struct {
+		// StartTime only the runs started since it are counted, it is 24 hours ago by default
+		StartTime int64 `json:"start_time" form:"start_time"`
+	}
+	TaskListResp struct {
+		Items     []TaskItem `json:"items"`
+		TimeStamp int64      `json:"time_stamp"`
+	}
+	TaskItem struct {
+		TaskName   string      `json:"task_name"`
+		Runs       int64       `json:"runs"`
+		FailedRuns int64       `json:"failed_runs"`
+		LastRun    TaskRunItem `json:"last_run"`
+	}
+
+	TaskRunsReq struct {
+		Page
+		UseCount bool   `json:"use_count" form:"use_count"`
+		Status   string `json:"status" form:"status"`
+	}
+	TaskRunsResp struct {
+		Items     []TaskRunItem `json:"items"`
+		PageInfo  PageInfo      `json:"page_info"`
+		TimeStamp int64         `json:"time_stamp"`
+	}
+	TaskRunItem struct {
+		Trigger       string                      `json:"trigger,omitempty"`
+		InstanceId    string                      `json:"instance_id"`
+		StartTime     int64                       `json:"start_time"`
+		EndTime       int64                       `json:"end_time"`
+		Duration      int64                       `json:"duration"`
+		Status        string                      `json:"status"`
+		ExitStatus    int                         `json:"exit_status"`
+		ErrorMsg      string                      `json:"error_msg"`
+		ChainCounters map[string]map[string]int64 `json:"chain_counters,omitempty"`
+		Progress      float64                     `json:"progress"`
+		DryRunReport  *entity.DryRunReport        `json:"dry_run_report,omitempty"`
+	}
+)
+
+func LoadTaskRunItem(run *entity.IbcTaskRun) TaskRunItem {
+	return TaskRunItem{
+		Trigger:       string(run.Trigger),
+		InstanceId:    run.InstanceId,
+		StartTime:     run.StartTime,
+		EndTime:       run.EndTime,
+		Duration:      run.Duration,
+		Status:        string(run.Status),
+		ExitStatus:    run.ExitStatus,
+		ErrorMsg:      run.ErrorMsg,
+		ChainCounters: run.ChainCounters,
+		Progress:      run.Progress,
+		DryRunReport:  run.DryRunReport,
+	}
+}
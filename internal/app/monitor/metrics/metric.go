@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/weichang-bianjie/metric-sdk/metrics"
 	"github.com/weichang-bianjie/metric-sdk/metrics/counter"
 	"github.com/weichang-bianjie/metric-sdk/metrics/gauge"
@@ -41,3 +42,15 @@ func CovertCounter(metric Metric) (Counter, bool) {
 	value, ok := metric.(Counter)
 	return value, ok
 }
+
+// NewGuageVec the series of the gauge can be deleted, the Guage of metric-sdk can only be set
+func NewGuageVec(nameSpace string, subSystem string, name string, help string, labels []string) *prometheus.GaugeVec {
+	guage := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: nameSpace,
+		Subsystem: subSystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	prometheus.MustRegister(guage)
+	return guage
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,8 +21,14 @@ var (
 	cronTaskStatusMetric  metrics.Guage
 	lcdConnectStatsMetric metrics.Guage
 	redisStatusMetric     metrics.Guage
+	lcdThrottledMetric    metrics.Counter
+	lcdThrottleWaitMetric metrics.Counter
+	lcdCoalescedMetric    metrics.Counter
+	clientExpireMetric    *prometheus.GaugeVec
 	TagName               = "taskname"
 	ChainTag              = "chain_id"
+	HostTag               = "host"
+	ClientTag             = "client_id"
 
 	chainConfigRepo   repository.IChainConfigRepo   = new(repository.ChainConfigRepo)
 	chainRegistryRepo repository.IChainRegistryRepo = new(repository.ChainRegistryRepo)
@@ -72,12 +79,90 @@ func NewMetricLcdStatus() metrics.Guage {
 	return connectionStatus
 }
 
+func NewMetricLcdThrottled() metrics.Counter {
+	lcdThrottledMetric := metrics.NewCounter(
+		"ibc_explorer_backend",
+		"lcd",
+		"throttled_requests_total",
+		"ibc_explorer_backend  requests to the chain which waited for the rate limiter of the host",
+		[]string{ChainTag, HostTag},
+	)
+	throttled, _ := metrics.CovertCounter(lcdThrottledMetric)
+	return throttled
+}
+
+func NewMetricLcdThrottleWait() metrics.Counter {
+	lcdThrottleWaitMetric := metrics.NewCounter(
+		"ibc_explorer_backend",
+		"lcd",
+		"throttle_wait_seconds_total",
+		"ibc_explorer_backend  seconds the requests to the chain waited for the rate limiter of the host",
+		[]string{ChainTag, HostTag},
+	)
+	throttleWait, _ := metrics.CovertCounter(lcdThrottleWaitMetric)
+	return throttleWait
+}
+
+func NewMetricLcdCoalesced() metrics.Counter {
+	lcdCoalescedMetric := metrics.NewCounter(
+		"ibc_explorer_backend",
+		"lcd",
+		"coalesced_requests_total",
+		"ibc_explorer_backend  requests to the chain which shared the response of an identical in-flight request",
+		[]string{ChainTag},
+	)
+	coalesced, _ := metrics.CovertCounter(lcdCoalescedMetric)
+	return coalesced
+}
+
+// NewMetricClientExpire the series of frozen and removed clients are deleted, see DeleteClientExpireMetric
+func NewMetricClientExpire() *prometheus.GaugeVec {
+	return metrics.NewGuageVec(
+		"ibc_explorer_backend",
+		"client",
+		"expire_seconds",
+		"ibc_explorer_backend  seconds left before the light client expires (negative:expired)",
+		[]string{ChainTag, ClientTag},
+	)
+}
+
 func SetCronTaskStatusMetricValue(taskName string, value float64) {
 	if cronTaskStatusMetric != nil {
 		cronTaskStatusMetric.With(TagName, taskName).Set(value)
 	}
 }
 
+// AddLcdThrottleMetricValue a request to the chain waited for the rate limiter of the host
+func AddLcdThrottleMetricValue(chain, host string, wait time.Duration) {
+	if lcdThrottledMetric != nil {
+		lcdThrottledMetric.With(ChainTag, chain, HostTag, host).Add(1)
+	}
+	if lcdThrottleWaitMetric != nil {
+		lcdThrottleWaitMetric.With(ChainTag, chain, HostTag, host).Add(wait.Seconds())
+	}
+}
+
+// AddLcdCoalescedMetricValue a request to the chain shared the response of an identical in-flight request
+func AddLcdCoalescedMetricValue(chain string) {
+	if lcdCoalescedMetric != nil {
+		lcdCoalescedMetric.With(ChainTag, chain).Add(1)
+	}
+}
+
+// SetClientExpireMetricValue seconds left before the client of the chain expires
+func SetClientExpireMetricValue(chain, clientId string, seconds float64) {
+	if clientExpireMetric != nil {
+		clientExpireMetric.WithLabelValues(chain, clientId).Set(seconds)
+	}
+}
+
+// DeleteClientExpireMetric the client is frozen, removed or never expires
+func DeleteClientExpireMetric(chain, clientId string) {
+	if clientExpireMetric != nil {
+		clientExpireMetric.DeleteLabelValues(chain, clientId)
+	}
+}
+
 func lcdConnectionStatus(quit chan bool) {
 	for {
 		t := time.NewTimer(time.Duration(120) * time.Second)
@@ -227,6 +312,10 @@ func Start(port string) {
 	cronTaskStatusMetric = NewMetricCronWorkStatus()
 	redisStatusMetric = NewMetricRedisStatus()
 	lcdConnectStatsMetric = NewMetricLcdStatus()
+	lcdThrottledMetric = NewMetricLcdThrottled()
+	lcdThrottleWaitMetric = NewMetricLcdThrottleWait()
+	lcdCoalescedMetric = NewMetricLcdCoalesced()
+	clientExpireMetric = NewMetricClientExpire()
 	server.Report(func() {
 		go redisClientStatus(quit)
 		go lcdConnectionStatus(quit)
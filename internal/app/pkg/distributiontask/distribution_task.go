@@ -1,24 +1,39 @@
 package distributiontask
 
 import (
+	"context"
 	"fmt"
 	v8 "github.com/go-redis/redis/v8"
 	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
 	"strings"
+	"sync"
 	"time"
 )
 
 type DistributedTask struct {
 	l          LockHasExpired
 	expiration time.Duration
+	runOnStart bool
+	beforeRun  func(task CronTask) bool
 	tasks      []CronTask
+
+	// ctx of the runs, it is canceled by Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mux     sync.Mutex
+	cron    *cron.Cron
+	stopped bool
+	running sync.WaitGroup
+	held    map[string]interface{} // lock key -> lock value of the running tasks
 }
 
 const defaultExpiration = 30 * time.Second
 
 func NewDistributedTask(l LockHasExpired) *DistributedTask {
-	return &DistributedTask{l: l}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DistributedTask{l: l, ctx: ctx, cancel: cancel}
 }
 
 func NewDistributedTaskWithRedis(addrs, user, password, mode string, db int) (*DistributedTask, error) {
@@ -40,11 +55,16 @@ func NewDistributedTaskWithRedis(addrs, user, password, mode string, db int) (*D
 		logrus.Errorf("unknown redis server mode, %s", mode)
 		return nil, fmt.Errorf("redis server mode must be cluster or single")
 	}
-	return &DistributedTask{l: &RedisLock{redisClient: redisClient}}, nil
+	return NewDistributedTask(&RedisLock{redisClient: redisClient}), nil
+}
+
+// Context the context of the runs, it is done once Stop is called
+func (d *DistributedTask) Context() context.Context {
+	return d.ctx
 }
 
 func (d *DistributedTask) Expiration() time.Duration {
-	if d.expiration <= 10 {
+	if d.expiration <= 10*time.Second {
 		return defaultExpiration
 	}
 	return d.expiration
@@ -54,6 +74,20 @@ func (d *DistributedTask) SetExpiration(expiration time.Duration) {
 	d.expiration = expiration
 }
 
+// SetRunOnStart run every task once as soon as Start, besides the cron schedule
+func (d *DistributedTask) SetRunOnStart(runOnStart bool) {
+	d.runOnStart = runOnStart
+}
+
+// SetBeforeRun fn is called before each run of a task, the run is skipped if it returns false
+func (d *DistributedTask) SetBeforeRun(fn func(task CronTask) bool) {
+	d.beforeRun = fn
+}
+
+func (d *DistributedTask) Tasks() []CronTask {
+	return d.tasks
+}
+
 func (d *DistributedTask) RegisterTasks(task ...CronTask) {
 	d.tasks = append(d.tasks, task...)
 }
@@ -68,29 +102,96 @@ func (d *DistributedTask) Start() {
 	}
 
 	c := cron.New(cron.WithSeconds())
+	d.mux.Lock()
+	if d.stopped {
+		d.mux.Unlock()
+		return
+	}
+	d.cron = c
+	d.mux.Unlock()
 	for _, v := range d.tasks {
 		t := v
-		_, err := c.AddFunc(t.Cron(), func() {
+		// a task is skipped if its last run in this process is not finished
+		job := cron.NewChain(cron.SkipIfStillRunning(cron.PrintfLogger(logrus.StandardLogger()))).Then(cron.FuncJob(func() {
 			d.RunOnceWithLock(t)
-		})
+		}))
+		_, err := c.AddJob(t.Cron(), job)
 		if err != nil {
 			logrus.WithField("task", t.Name()).
 				WithField("err", err.Error()).
 				Fatal("add cron job err", err)
 		}
+		if d.runOnStart {
+			go job.Run()
+		}
 	}
 	c.Run()
 }
 
-func (d *DistributedTask) RunOnceWithLock(task CronTask) {
+// Stop no more runs are started and the context of the running ones is canceled, it waits for them to return and
+// release their locks. when ctx is done before that, the locks still held are released anyway
+func (d *DistributedTask) Stop(ctx context.Context) error {
+	d.mux.Lock()
+	d.stopped = true
+	c := d.cron
+	d.mux.Unlock()
+	d.cancel()
+	if c != nil {
+		c.Stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.mux.Lock()
+		defer d.mux.Unlock()
+		for key, value := range d.held {
+			if _, err := d.l.UnLock(key, value); err != nil {
+				logrus.WithField("key", key).
+					WithField("err", err.Error()).
+					Error("unlock failed")
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// RunOnceWithLock false is returned if the task is not run, it is stopped, skipped by beforeRun or locked by others
+func (d *DistributedTask) RunOnceWithLock(task CronTask) bool {
+	d.mux.Lock()
+	if d.stopped {
+		d.mux.Unlock()
+		return false
+	}
+	d.running.Add(1)
+	d.mux.Unlock()
+	defer d.running.Done()
+
+	if d.beforeRun != nil && !d.beforeRun(task) {
+		return false
+	}
+
+	if lf, ok := task.(LockFreeTask); ok && lf.LockFree() {
+		RunOnce(d.ctx, task)
+		return true
+	}
+
+	key := LockKey(task)
 	value := GenTaskId(task.Name())
-	if err := d.l.Lock(task.Name(), value, d.Expiration()); err != nil {
-		logrus.WithField("key", task.Name()).
+	if err := d.l.Lock(key, value, d.Expiration()); err != nil {
+		logrus.WithField("key", key).
 			WithField("err", err.Error()).
 			Warnf("redis lock failed")
 
-		return
+		return false
 	}
+	d.setHeld(key, value)
 
 	stop := make(chan struct{})
 	t := time.NewTicker(10 * time.Second)
@@ -98,21 +199,45 @@ func (d *DistributedTask) RunOnceWithLock(task CronTask) {
 		for {
 			select {
 			case <-t.C:
-				d.ReNewExpiration(task.Name(), value)
+				d.ReNewExpiration(key, value)
 			case <-stop:
-				logrus.WithField("key", task.Name()).Debug("expired")
+				logrus.WithField("key", key).Debug("expired")
 				return
 			}
 		}
 	}()
 
-	RunOnce(task)
+	RunOnce(d.ctx, task)
 	stop <- struct{}{}
-	if _, err := d.l.UnLock(task.Name(), value); err != nil {
-		logrus.WithField("key", task.Name()).
+	t.Stop()
+	if _, err := d.l.UnLock(key, value); err != nil {
+		logrus.WithField("key", key).
 			WithField("err", err.Error()).
 			Error("unlock failed")
 	}
+	d.setHeld(key, nil)
+	return true
+}
+
+// LockKey the key of the task lock
+func LockKey(task CronTask) string {
+	if lk, ok := task.(LockKeyTask); ok {
+		return lk.LockKey()
+	}
+	return task.Name()
+}
+
+func (d *DistributedTask) setHeld(key string, value interface{}) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if value == nil {
+		delete(d.held, key)
+		return
+	}
+	if d.held == nil {
+		d.held = make(map[string]interface{})
+	}
+	d.held[key] = value
 }
 
 func (d *DistributedTask) ReNewExpiration(key string, value interface{}) {
@@ -1,6 +1,7 @@
 package distributiontask
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"os"
@@ -15,11 +16,23 @@ type (
 		Name() string
 		Cron() string
 		BeforeHook() error // init or status's judge in this
-		Run()
+		Run(ctx context.Context)
+	}
+
+	// LockFreeTask the task takes care of its concurrency by itself, it is run by all instances without the task lock
+	LockFreeTask interface {
+		CronTask
+		LockFree() bool
+	}
+
+	// LockKeyTask the task is locked by LockKey() instead of Name()
+	LockKeyTask interface {
+		CronTask
+		LockKey() string
 	}
 )
 
-func RunOnce(task CronTask) {
+func RunOnce(ctx context.Context, task CronTask) {
 	defer func() {
 		if r := recover(); r != nil {
 			const size = 64 << 10
@@ -40,7 +53,7 @@ func RunOnce(task CronTask) {
 		logrus.WithField("err", err.Error()).
 			Errorf("task[%s] %s error", task.Name(), "beforeHooks")
 	} else {
-		task.Run()
+		task.Run(ctx)
 	}
 	logrus.Infof("task[%s] end, use %d(second)", task.Name(), time.Now().Unix()-start)
 }
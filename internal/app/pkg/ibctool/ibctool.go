@@ -1,8 +1,10 @@
 package ibctool
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
 	"strings"
 	"time"
 
@@ -151,18 +153,31 @@ func CalculateIBCHash(fullPath string) string {
 	return fmt.Sprintf("%s/%s", constant.IBCTokenPrefix, strings.ToUpper(hash))
 }
 
-// CalculateNextDenomPath calculate full denom path of next hop.
+// CalculateNextDenomPath calculate full denom path of next hop, the first coin is taken for ics20 v2 packet.
 // return full denom path and cross back identification
 func CalculateNextDenomPath(packet model.Packet) (string, bool) {
-	prefixSc := fmt.Sprintf("%s/%s/", packet.SourcePort, packet.SourceChannel)
-	prefixDc := fmt.Sprintf("%s/%s/", packet.DestinationPort, packet.DestinationChannel)
-	denomPath := packet.Data.Denom
-	if strings.HasPrefix(denomPath, prefixSc) { // transfer to prev chain
-		denomPath = strings.Replace(denomPath, prefixSc, "", 1)
-		return denomPath, true
+	return CalculateNextCoinPath(packet, packet.FullPathCoins()[0].Denom)
+}
+
+// CalculateNextCoinPath calculate full denom path of next hop for a coin of the packet.
+// return full denom path and cross back identification
+func CalculateNextCoinPath(packet model.Packet, fullPath string) (string, bool) {
+	return calculateNextPath(fullPath, packet.SourcePort, packet.SourceChannel, packet.DestinationPort, packet.DestinationChannel)
+}
+
+// CalculateNextClassPath calculate full class path of next hop for ics721 packet, the trace rule is the same as ics20.
+// return full class path and cross back identification
+func CalculateNextClassPath(classPath, scPort, scChannel, dcPort, dcChannel string) (string, bool) {
+	return calculateNextPath(classPath, scPort, scChannel, dcPort, dcChannel)
+}
+
+func calculateNextPath(fullPath, scPort, scChannel, dcPort, dcChannel string) (string, bool) {
+	prefixSc := fmt.Sprintf("%s/%s/", scPort, scChannel)
+	prefixDc := fmt.Sprintf("%s/%s/", dcPort, dcChannel)
+	if strings.HasPrefix(fullPath, prefixSc) { // transfer to prev chain
+		return strings.Replace(fullPath, prefixSc, "", 1), true
 	} else {
-		denomPath = fmt.Sprintf("%s%s", prefixDc, denomPath)
-		return denomPath, false
+		return fmt.Sprintf("%s%s", prefixDc, fullPath), false
 	}
 }
 
@@ -178,3 +193,94 @@ func SplitFullPath(fullPath string) (denomPath, rootDenom string) {
 	rootDenom = pathSplits[len(pathSplits)-1]
 	return
 }
+
+// BuildPacketId build packet id the same way as the sync service does, used when the msg carries no packet id.
+func BuildPacketId(scPort, scChannel, dcPort, dcChannel, sequence string) string {
+	return fmt.Sprintf("%s%s%s%s%s", scPort, scChannel, dcPort, dcChannel, sequence)
+}
+
+// ParseForwardMemo parse packet-forward-middleware metadata from ics20 packet memo, return false if the packet is not forwarded
+func ParseForwardMemo(memo string) (*model.ForwardMetadata, bool) {
+	if !strings.Contains(memo, "forward") {
+		return nil, false
+	}
+
+	var forwardMemo model.PacketForwardMemo
+	if err := json.Unmarshal([]byte(memo), &forwardMemo); err != nil || forwardMemo.Forward == nil {
+		return nil, false
+	}
+	if forwardMemo.Forward.Port == "" || forwardMemo.Forward.Channel == "" {
+		return nil, false
+	}
+	return forwardMemo.Forward, true
+}
+
+// ParsePacketForward the next hop of the packet, forwarded by packet-forward-middleware(memo) or by ics20 v2 forwarding
+func ParsePacketForward(packet model.Packet) (*model.ForwardMetadata, bool) {
+	if forward, ok := ParseForwardMemo(packet.Data.Memo); ok {
+		return forward, true
+	}
+	if packet.Data.Forwarding == nil || len(packet.Data.Forwarding.Hops) == 0 {
+		return nil, false
+	}
+	hop := packet.Data.Forwarding.Hops[0]
+	return &model.ForwardMetadata{Port: hop.PortId, Channel: hop.ChannelId}, true
+}
+
+// IsIcaControllerPort check whether the port is bound by an interchain account controller, eg: "icacontroller-cosmos1..."
+func IsIcaControllerPort(port string) bool {
+	return strings.HasPrefix(port, constant.PortIcaControllerPre)
+}
+
+// IcaOwnerFromPort get owner address from interchain account controller port
+func IcaOwnerFromPort(port string) string {
+	return strings.TrimPrefix(port, constant.PortIcaControllerPre)
+}
+
+var coinRegexp = regexp.MustCompile(`^([0-9]+)([a-zA-Z][a-zA-Z0-9/:._-]{1,127})$`)
+
+// ParseCoins parse coins string in events, eg: "100uatom,20ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2".
+// invalid items are ignored
+func ParseCoins(coinsStr string) []*model.Coin {
+	var coins []*model.Coin
+	for _, v := range strings.Split(coinsStr, ",") {
+		matches := coinRegexp.FindStringSubmatch(strings.TrimSpace(v))
+		if len(matches) != 3 {
+			continue
+		}
+		coins = append(coins, &model.Coin{
+			Amount: matches[1],
+			Denom:  matches[2],
+		})
+	}
+	return coins
+}
+
+// ackErrorKeywords keywords of the error messages, in the order of matching. the message in the ack is replaced by
+// "ABCI code: x: error handling packet: see events for details" since ibc-go v3, the raw error is only kept in the
+// "error" attribute of fungible_token_packet event.
+var ackErrorKeywords = []struct {
+	category entity.IbcTxErrorCategory
+	keywords []string
+}{
+	{entity.IbcTxErrorOutOfGas, []string{"out of gas"}},
+	{entity.IbcTxErrorRateLimitExceeded, []string{"rate limit"}},
+	{entity.IbcTxErrorContract, []string{"wasm", "contract"}},
+	{entity.IbcTxErrorUnauthorizedMemo, []string{"memo"}},
+	{entity.IbcTxErrorInvalidReceiver, []string{"invalid address", "invalid receiver", "bech32", "not allowed to receive", "receiver"}},
+	{entity.IbcTxErrorDenomNotAllowed, []string{"denom", "not allowed", "disabled"}},
+	{entity.IbcTxErrorInsufficientEscrow, []string{"insufficient funds", "escrow"}},
+}
+
+// ClassifyAckError classify the error by the messages of ack, events or tx log
+func ClassifyAckError(errMsgs ...string) entity.IbcTxErrorCategory {
+	msg := strings.ToLower(strings.Join(errMsgs, " "))
+	for _, v := range ackErrorKeywords {
+		for _, keyword := range v.keywords {
+			if strings.Contains(msg, keyword) {
+				return v.category
+			}
+		}
+	}
+	return entity.IbcTxErrorUnknown
+}
@@ -0,0 +1,307 @@
+package lcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTimeout         = 10 * time.Second
+	defaultRetryTimes      = 3
+	defaultBreakerFailures = 5
+	defaultBreakerOpenTime = 30 * time.Second
+
+	endpointsRefreshTime = 10 * time.Minute
+	healthDecay          = 0.2 // weight of the latest request in the moving average of latency and error rate
+)
+
+// states of the circuit breaker of an endpoint
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+var (
+	httpClient      = &http.Client{Timeout: defaultTimeout}
+	retryTimes      = defaultRetryTimes
+	retryBackoff    = 200 * time.Millisecond
+	breakerFailures = defaultBreakerFailures
+	breakerOpenTime = defaultBreakerOpenTime
+
+	poolsMux sync.Mutex
+	pools    = make(map[string]*endpointPool)
+
+	// endpointSource the rest endpoints of the chain in chain-registry, which are cached by ibc_node_valid_lcd_task
+	endpointSource = func(chain string) []string {
+		var lcdAddrCache cache.LcdAddrCacheRepo
+		lcdAddrs, err := lcdAddrCache.Get(chain)
+		if err != nil {
+			return nil
+		}
+		addrs := make([]string, 0, len(lcdAddrs))
+		for _, v := range lcdAddrs {
+			addrs = append(addrs, v.LcdAddr)
+		}
+		return addrs
+	}
+)
+
+func Init(c conf.Lcd) {
+	if c.Timeout > 0 {
+		httpClient = &http.Client{Timeout: time.Duration(c.Timeout) * time.Second}
+	}
+	if c.RetryTimes > 0 {
+		retryTimes = c.RetryTimes
+	}
+	if c.BreakerFailures > 0 {
+		breakerFailures = c.BreakerFailures
+	}
+	if c.BreakerOpenTime > 0 {
+		breakerOpenTime = time.Duration(c.BreakerOpenTime) * time.Second
+	}
+	rateLimit = c.RateLimit
+	if c.RateBurst > 0 {
+		rateBurst = c.RateBurst
+	}
+	chainRateLimits = c.ChainRateLimits
+}
+
+// StatusError the lcd responds with a status other than 200
+type StatusError struct {
+	StatusCode int
+	msg        string
+}
+
+func (e *StatusError) Error() string {
+	return e.msg
+}
+
+// endpoint an lcd of the chain.
+//   - latency, errorRate: moving average of the requests, the lower the healthier
+//   - failures: consecutive failures, the circuit breaker opens when it reaches breakerFailures
+type endpoint struct {
+	addr      string
+	latency   float64 // ms
+	errorRate float64
+	requests  int64
+	failures  int
+	state     int
+	openUntil time.Time
+}
+
+// available an open circuit breaker lets a trial request through after breakerOpenTime, it is half-open until the
+// trial request finishes
+func (e *endpoint) available(now time.Time) bool {
+	switch e.state {
+	case breakerClosed:
+		return true
+	case breakerOpen:
+		return now.After(e.openUntil)
+	default:
+		return false
+	}
+}
+
+// score the lower the healthier, endpoints never requested score the lowest, so each of them is tried once
+func (e *endpoint) score() float64 {
+	return (e.latency + 1) * (1 + 10*e.errorRate)
+}
+
+// endpointPool endpoints of a chain, the configured lcd(ChainConfig.GrpcRestGateway) is the first one, the others are
+// loaded from endpointSource
+type endpointPool struct {
+	chain     string
+	mux       sync.Mutex
+	endpoints []*endpoint
+	refreshAt time.Time
+}
+
+func getPool(chain, lcd string) *endpointPool {
+	poolsMux.Lock()
+	pool, ok := pools[chain]
+	if !ok {
+		pool = &endpointPool{chain: chain}
+		pools[chain] = pool
+	}
+	poolsMux.Unlock()
+
+	pool.refresh(strings.TrimRight(lcd, "/"))
+	return pool
+}
+
+// refresh reload the endpoints when the configured lcd changes or endpointsRefreshTime passes, the health of the
+// endpoints already in the pool is kept
+func (p *endpointPool) refresh(lcd string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if len(p.endpoints) > 0 && p.endpoints[0].addr == lcd && time.Now().Before(p.refreshAt) {
+		return
+	}
+
+	existed := make(map[string]*endpoint, len(p.endpoints))
+	for _, v := range p.endpoints {
+		existed[v.addr] = v
+	}
+	addrs := append([]string{lcd}, endpointSource(p.chain)...)
+	endpoints := make([]*endpoint, 0, len(addrs))
+	seen := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		addr = strings.TrimRight(addr, "/")
+		if addr == "" {
+			continue
+		}
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+
+		if e, ok := existed[addr]; ok {
+			endpoints = append(endpoints, e)
+		} else {
+			endpoints = append(endpoints, &endpoint{addr: addr})
+		}
+	}
+	p.endpoints = endpoints
+	p.refreshAt = time.Now().Add(endpointsRefreshTime)
+}
+
+// pick the healthiest available endpoint which is not tried by the request yet
+func (p *endpointPool) pick(tried map[*endpoint]bool) *endpoint {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	now := time.Now()
+	var best *endpoint
+	for _, e := range p.endpoints {
+		if tried[e] || !e.available(now) {
+			continue
+		}
+		if best == nil || e.score() < best.score() {
+			best = e
+		}
+	}
+	if best != nil && best.state == breakerOpen {
+		best.state = breakerHalfOpen
+	}
+	return best
+}
+
+func (p *endpointPool) report(e *endpoint, latency time.Duration, err error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	e.requests++
+	ms := float64(latency.Milliseconds())
+	if e.requests == 1 {
+		e.latency = ms
+	} else {
+		e.latency = e.latency*(1-healthDecay) + ms*healthDecay
+	}
+
+	if err == nil {
+		e.errorRate = e.errorRate * (1 - healthDecay)
+		e.failures = 0
+		e.state = breakerClosed
+		return
+	}
+
+	e.errorRate = e.errorRate*(1-healthDecay) + healthDecay
+	e.failures++
+	if e.state == breakerHalfOpen || e.failures >= breakerFailures {
+		e.state = breakerOpen
+		e.openUntil = time.Now().Add(breakerOpenTime)
+		logrus.Warningf("lcd %s endpoint %s circuit breaker open, %v", p.chain, e.addr, err)
+	}
+}
+
+// Get request apiPath from the healthiest endpoint of the chain, lcd is the configured one(ChainConfig.GrpcRestGateway).
+// A failed request is retried with backoff, on another endpoint if there is one. Requests are rate limited by host,
+// and the identical in-flight ones are coalesced. The request and its retries stop as soon as ctx is done.
+func Get(ctx context.Context, chain, lcd, apiPath string) ([]byte, error) {
+	return coalesce(ctx, chain, chain+" "+apiPath, func() ([]byte, error) {
+		return get(ctx, chain, lcd, apiPath)
+	})
+}
+
+func get(ctx context.Context, chain, lcd, apiPath string) ([]byte, error) {
+	pool := getPool(chain, lcd)
+	tried := make(map[*endpoint]bool)
+	var lastErr error
+	for i := 0; i < retryTimes; i++ {
+		if i > 0 {
+			if err := sleep(ctx, retryBackoff<<(i-1)); err != nil {
+				return nil, err
+			}
+		}
+
+		e := pool.pick(tried)
+		if e == nil {
+			e = pool.pick(nil)
+		}
+		if e == nil {
+			break
+		}
+		tried[e] = true
+
+		if err := throttle(ctx, chain, e.addr); err != nil {
+			return nil, err
+		}
+		st := time.Now()
+		bz, err := httpGet(ctx, e.addr+apiPath)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil || !isEndpointErr(err) {
+			pool.report(e, time.Since(st), nil)
+			return bz, err
+		}
+		pool.report(e, time.Since(st), err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("chain %s has no available lcd", chain)
+	}
+	return nil, lastErr
+}
+
+func httpGet(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			msg:        fmt.Sprintf("StatusCode(%s) != 200, url: %s", resp.Status, url),
+		}
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// isEndpointErr errors caused by the endpoint itself. the other status errors, like 404 and 501(api not implemented),
+// are the answers of the lcd, they are neither retried nor counted as failures
+func isEndpointErr(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	return statusErr.StatusCode == http.StatusTooManyRequests ||
+		(statusErr.StatusCode >= http.StatusInternalServerError && statusErr.StatusCode != http.StatusNotImplemented)
+}
@@ -0,0 +1,90 @@
+package lcd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestServer(status int, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+}
+
+func TestGet(t *testing.T) {
+	var badHits, goodHits, notFoundHits int32
+	bad := newTestServer(http.StatusBadGateway, &badHits)
+	defer bad.Close()
+	good := newTestServer(http.StatusOK, &goodHits)
+	defer good.Close()
+	notFound := newTestServer(http.StatusNotFound, &notFoundHits)
+	defer notFound.Close()
+
+	retryBackoff = 0
+	endpointSource = func(chain string) []string {
+		if chain == "failover" {
+			return []string{bad.URL, good.URL}
+		}
+		return nil
+	}
+
+	if _, err := Get(context.Background(), "failover", "", "/node_info"); err != nil {
+		t.Fatal(err)
+	}
+	if goodHits != 1 {
+		t.Fatalf("request is not failed over, %d", goodHits)
+	}
+
+	for i := 0; i < breakerFailures; i++ {
+		_, _ = Get(context.Background(), "breaker", bad.URL, "/node_info")
+	}
+	if getPool("breaker", bad.URL).endpoints[0].state != breakerOpen {
+		t.Fatalf("circuit breaker of %s is not open", bad.URL)
+	}
+	hits := atomic.LoadInt32(&badHits)
+	if _, err := Get(context.Background(), "breaker", bad.URL, "/node_info"); err == nil {
+		t.Fatal("open endpoint responds")
+	}
+	if atomic.LoadInt32(&badHits) != hits {
+		t.Fatal("request is sent to the open endpoint")
+	}
+
+	if _, err := Get(context.Background(), "not_found", notFound.URL, "/node_info"); err == nil {
+		t.Fatal("404 is not returned")
+	}
+	if notFoundHits != 1 {
+		t.Fatalf("404 is retried, %d", notFoundHits)
+	}
+}
+
+func TestGetCanceled(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	st := time.Now()
+	if _, err := Get(ctx, "canceled", server.URL, "/node_info"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if time.Since(st) > time.Second {
+		t.Fatal("request is not canceled with ctx")
+	}
+	if getPool("canceled", server.URL).endpoints[0].requests != 0 {
+		t.Fatal("canceled request is counted")
+	}
+}
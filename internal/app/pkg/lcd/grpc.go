@@ -0,0 +1,385 @@
+package lcd
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const legacyDecPrecision = 18
+
+var (
+	grpcConnsMux sync.Mutex
+	grpcConns    = make(map[string]*grpc.ClientConn)
+
+	grpcJsonMarshaler = protojson.MarshalOptions{UseProtoNames: true}
+)
+
+// grpcConn connections are shared by the chains of the same grpc address
+func grpcConn(addr string) (*grpc.ClientConn, error) {
+	grpcConnsMux.Lock()
+	defer grpcConnsMux.Unlock()
+	if conn, ok := grpcConns[addr]; ok {
+		return conn, nil
+	}
+
+	var target string
+	var creds credentials.TransportCredentials
+	switch {
+	case strings.HasPrefix(addr, "https://"):
+		target = strings.TrimPrefix(addr, "https://")
+		creds = credentials.NewTLS(&tls.Config{})
+	default:
+		target = strings.TrimPrefix(addr, "http://")
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	grpcConns[addr] = conn
+	return conn, nil
+}
+
+var _ Querier = new(grpcQuerier)
+
+// grpcQuerier queries by the grpc address of the chain, the responses are converted to the format of lcd
+type grpcQuerier struct {
+	cfg *entity.ChainConfig
+}
+
+// invoke req is the request message in json, resp is the response in the format of lcd. the response is returned in
+// json too, for the fields which need to be decoded further
+func (q *grpcQuerier) invoke(ctx context.Context, method string, req map[string]interface{}, resp interface{}) ([]byte, error) {
+	if q.cfg.GrpcAddr == "" {
+		return nil, fmt.Errorf("chain %s grpc address is empty", q.cfg.ChainName)
+	}
+	conn, err := grpcConn(q.cfg.GrpcAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := grpcMethods[method]
+	reqMsg := dynamicpb.NewMessage(grpcMessageDescriptor(messages[0]))
+	reqBz, _ := json.Marshal(req)
+	if err = protojson.Unmarshal(reqBz, reqMsg); err != nil {
+		return nil, err
+	}
+
+	bz, err := coalesce(ctx, q.cfg.ChainName, q.cfg.ChainName+" "+method+" "+string(reqBz), func() ([]byte, error) {
+		if err := throttle(ctx, q.cfg.ChainName, q.cfg.GrpcAddr); err != nil {
+			return nil, err
+		}
+		respMsg := dynamicpb.NewMessage(grpcMessageDescriptor(messages[1]))
+		ctx, cancel := context.WithTimeout(ctx, httpClient.Timeout)
+		defer cancel()
+		if err := conn.Invoke(ctx, method, reqMsg, respMsg); err != nil {
+			return nil, fmt.Errorf("grpc %s %s error, %v", q.cfg.ChainName, method, err)
+		}
+		return grpcJsonMarshaler.Marshal(respMsg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bz, json.Unmarshal(bz, resp)
+}
+
+// pageRequest key is the base64 pagination.next_key of the previous page, same as the one in json
+func pageRequest(offset, limit int, key string) map[string]interface{} {
+	page := map[string]interface{}{"count_total": true}
+	if offset > 0 {
+		page["offset"] = offset
+	}
+	if limit > 0 {
+		page["limit"] = limit
+	}
+	if key != "" {
+		page["key"] = key
+	}
+	return page
+}
+
+// anyValue google.protobuf.Any in json, see AnyValue
+type anyValue struct {
+	TypeUrl string `json:"type_url"`
+	Value   []byte `json:"value"`
+}
+
+// decodeAny decode the value of the Any into resp in json
+func decodeAny(any anyValue, resp interface{}) error {
+	name, ok := anyMessages[any.TypeUrl]
+	if !ok {
+		return fmt.Errorf("unsupported type %s", any.TypeUrl)
+	}
+	msg := dynamicpb.NewMessage(grpcMessageDescriptor(name))
+	if err := proto.Unmarshal(any.Value, msg); err != nil {
+		return err
+	}
+	bz, err := grpcJsonMarshaler.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, resp)
+}
+
+// legacyDecString sdk.Dec is an integer of 18 decimals in protobuf, it is a decimal string in lcd
+func legacyDecString(s string) string {
+	if s == "" {
+		return s
+	}
+	if len(s) <= legacyDecPrecision {
+		s = strings.Repeat("0", legacyDecPrecision-len(s)+1) + s
+	}
+	return s[:len(s)-legacyDecPrecision] + "." + s[len(s)-legacyDecPrecision:]
+}
+
+// Channels without count_total, same as the rest one
+func (q *grpcQuerier) Channels(ctx context.Context, limit int, key string) (*vo.IbcChannelsResp, error) {
+	var resp vo.IbcChannelsResp
+	page := pageRequest(0, limit, key)
+	delete(page, "count_total")
+	if _, err := q.invoke(ctx, grpcMethodChannels, map[string]interface{}{
+		"pagination": page,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) ClientState(ctx context.Context, port, channel string) (*vo.ClientStateResp, error) {
+	var resp vo.ClientStateResp
+	bz, err := q.invoke(ctx, grpcMethodChannelClientState, map[string]interface{}{
+		"port_id":    port,
+		"channel_id": channel,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		IdentifiedClientState identifiedClientState `json:"identified_client_state"`
+	}
+	if err = json.Unmarshal(bz, &raw); err != nil {
+		return nil, err
+	}
+	if err = raw.IdentifiedClientState.decode(&resp.IdentifiedClientState); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// identifiedClientState IdentifiedClientState in json, the client state is decoded by its type url
+type identifiedClientState struct {
+	ClientState anyValue `json:"client_state"`
+}
+
+func (s identifiedClientState) decode(resp *vo.IdentifiedClientState) error {
+	resp.ClientState.Type = s.ClientState.TypeUrl
+	if _, ok := anyMessages[s.ClientState.TypeUrl]; ok {
+		return decodeAny(s.ClientState, &resp.ClientState)
+	}
+	return nil
+}
+
+// ClientStates without count_total, same as Channels
+func (q *grpcQuerier) ClientStates(ctx context.Context, limit int, key string) (*vo.ClientStatesResp, error) {
+	var resp vo.ClientStatesResp
+	page := pageRequest(0, limit, key)
+	delete(page, "count_total")
+	bz, err := q.invoke(ctx, grpcMethodClientStates, map[string]interface{}{
+		"pagination": page,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		ClientStates []identifiedClientState `json:"client_states"`
+	}
+	if err = json.Unmarshal(bz, &raw); err != nil {
+		return nil, err
+	}
+	for i := range raw.ClientStates {
+		if i >= len(resp.ClientStates) {
+			break
+		}
+		if err = raw.ClientStates[i].decode(&resp.ClientStates[i]); err != nil {
+			return nil, err
+		}
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) ClientConsensusState(ctx context.Context, clientId string, revisionNumber, revisionHeight int64) (*vo.ConsensusStateResp, error) {
+	var resp vo.ConsensusStateResp
+	bz, err := q.invoke(ctx, grpcMethodConsensusState, map[string]interface{}{
+		"client_id":       clientId,
+		"revision_number": revisionNumber,
+		"revision_height": revisionHeight,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		ConsensusState anyValue `json:"consensus_state"`
+	}
+	if err = json.Unmarshal(bz, &raw); err != nil {
+		return nil, err
+	}
+	resp.ConsensusState.Type = raw.ConsensusState.TypeUrl
+	if _, ok := anyMessages[raw.ConsensusState.TypeUrl]; ok {
+		if err = decodeAny(raw.ConsensusState, &resp.ConsensusState); err != nil {
+			return nil, err
+		}
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) PacketCommitments(ctx context.Context, port, channel string) (*vo.IbcPacketCommitsResp, error) {
+	var resp vo.IbcPacketCommitsResp
+	if _, err := q.invoke(ctx, grpcMethodPacketCommitments, map[string]interface{}{
+		"port_id":    port,
+		"channel_id": channel,
+		"pagination": pageRequest(0, 1, ""),
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) Supply(ctx context.Context, limit int, key string) (*vo.SupplyResp, error) {
+	var resp vo.SupplyResp
+	if _, err := q.invoke(ctx, grpcMethodTotalSupply, map[string]interface{}{
+		"pagination": pageRequest(0, limit, key),
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) Balances(ctx context.Context, address string, limit int, key string) (*vo.BalancesResp, error) {
+	var resp vo.BalancesResp
+	if _, err := q.invoke(ctx, grpcMethodAllBalances, map[string]interface{}{
+		"address":    address,
+		"pagination": pageRequest(0, limit, key),
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) Delegations(ctx context.Context, address string) (*vo.DelegationResp, error) {
+	var resp vo.DelegationResp
+	if _, err := q.invoke(ctx, grpcMethodDelegatorDelegations, map[string]interface{}{
+		"delegator_addr": address,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	for i := range resp.DelegationResponses {
+		shares := &resp.DelegationResponses[i].Delegation.Shares
+		*shares = legacyDecString(*shares)
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) Unbondings(ctx context.Context, address string) (*vo.UnbondingResp, error) {
+	var resp vo.UnbondingResp
+	if _, err := q.invoke(ctx, grpcMethodDelegatorUnbondings, map[string]interface{}{
+		"delegator_addr": address,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) Rewards(ctx context.Context, address string) (*vo.RewardsResp, error) {
+	var resp vo.RewardsResp
+	if _, err := q.invoke(ctx, grpcMethodDelegationTotalRewards, map[string]interface{}{
+		"delegator_address": address,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	for i := range resp.Rewards {
+		for j := range resp.Rewards[i].Reward {
+			amount := &resp.Rewards[i].Reward[j].Amount
+			*amount = legacyDecString(*amount)
+		}
+	}
+	for i := range resp.Total {
+		resp.Total[i].Amount = legacyDecString(resp.Total[i].Amount)
+	}
+	return &resp, nil
+}
+
+func (q *grpcQuerier) Account(ctx context.Context, address string) (*vo.AccountResp, error) {
+	var raw struct {
+		Account anyValue `json:"account"`
+	}
+	if _, err := q.invoke(ctx, grpcMethodAccount, map[string]interface{}{
+		"address": address,
+	}, &raw); err != nil {
+		return nil, err
+	}
+
+	depth, ok := accountWrappers[raw.Account.TypeUrl]
+	if !ok {
+		return nil, fmt.Errorf("unsupported account type %s", raw.Account.TypeUrl)
+	}
+	accountBz := raw.Account.Value
+	for i := 0; i < depth; i++ {
+		wrapper := dynamicpb.NewMessage(grpcMessageDescriptor("AccountWrapper"))
+		if err := proto.Unmarshal(accountBz, wrapper); err != nil {
+			return nil, err
+		}
+		accountBz = wrapper.Get(wrapper.Descriptor().Fields().ByNumber(1)).Bytes()
+	}
+
+	baseAccount := dynamicpb.NewMessage(grpcMessageDescriptor("BaseAccount"))
+	if err := proto.Unmarshal(accountBz, baseAccount); err != nil {
+		return nil, err
+	}
+	bz, err := grpcJsonMarshaler.Marshal(baseAccount)
+	if err != nil {
+		return nil, err
+	}
+	var account struct {
+		Address       string   `json:"address"`
+		PubKey        anyValue `json:"pub_key"`
+		AccountNumber string   `json:"account_number"`
+		Sequence      string   `json:"sequence"`
+	}
+	if err = json.Unmarshal(bz, &account); err != nil {
+		return nil, err
+	}
+
+	var resp vo.AccountResp
+	resp.Account.Type = raw.Account.TypeUrl
+	resp.Account.Address = account.Address
+	resp.Account.AccountNumber = account.AccountNumber
+	resp.Account.Sequence = account.Sequence
+	if account.PubKey.TypeUrl != "" {
+		resp.Account.PubKey.Type = account.PubKey.TypeUrl
+		var pubKey struct {
+			Key []byte `json:"key"`
+		}
+		if err = decodeAny(account.PubKey, &pubKey); err != nil {
+			return nil, err
+		}
+		resp.Account.PubKey.Key = base64.StdEncoding.EncodeToString(pubKey.Key)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,381 @@
+package lcd
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the messages of the grpc queries. only the fields used by the explorer are described, the others are skipped when
+// they are decoded. message names are local, only the method paths and the field numbers have to match the chain.
+// google.protobuf.Any is described as AnyValue, its value is decoded by the type url, see decodeAny
+const grpcProtoPackage = "iobscan.lcd"
+
+const (
+	grpcMethodChannels               = "/ibc.core.channel.v1.Query/Channels"
+	grpcMethodChannelClientState     = "/ibc.core.channel.v1.Query/ChannelClientState"
+	grpcMethodClientStates           = "/ibc.core.client.v1.Query/ClientStates"
+	grpcMethodConsensusState         = "/ibc.core.client.v1.Query/ConsensusState"
+	grpcMethodPacketCommitments      = "/ibc.core.channel.v1.Query/PacketCommitments"
+	grpcMethodTotalSupply            = "/cosmos.bank.v1beta1.Query/TotalSupply"
+	grpcMethodAllBalances            = "/cosmos.bank.v1beta1.Query/AllBalances"
+	grpcMethodDelegatorDelegations   = "/cosmos.staking.v1beta1.Query/DelegatorDelegations"
+	grpcMethodDelegatorUnbondings    = "/cosmos.staking.v1beta1.Query/DelegatorUnbondingDelegations"
+	grpcMethodDelegationTotalRewards = "/cosmos.distribution.v1beta1.Query/DelegationTotalRewards"
+	grpcMethodAccount                = "/cosmos.auth.v1beta1.Query/Account"
+)
+
+// grpcMethods request and response message of the methods
+var grpcMethods = map[string][2]string{
+	grpcMethodChannels:               {"QueryChannelsRequest", "QueryChannelsResponse"},
+	grpcMethodChannelClientState:     {"QueryChannelClientStateRequest", "QueryChannelClientStateResponse"},
+	grpcMethodClientStates:           {"QueryClientStatesRequest", "QueryClientStatesResponse"},
+	grpcMethodConsensusState:         {"QueryConsensusStateRequest", "QueryConsensusStateResponse"},
+	grpcMethodPacketCommitments:      {"QueryPacketCommitmentsRequest", "QueryPacketCommitmentsResponse"},
+	grpcMethodTotalSupply:            {"QueryTotalSupplyRequest", "QueryTotalSupplyResponse"},
+	grpcMethodAllBalances:            {"QueryAllBalancesRequest", "QueryAllBalancesResponse"},
+	grpcMethodDelegatorDelegations:   {"QueryDelegatorDelegationsRequest", "QueryDelegatorDelegationsResponse"},
+	grpcMethodDelegatorUnbondings:    {"QueryDelegatorUnbondingDelegationsRequest", "QueryDelegatorUnbondingDelegationsResponse"},
+	grpcMethodDelegationTotalRewards: {"QueryDelegationTotalRewardsRequest", "QueryDelegationTotalRewardsResponse"},
+	grpcMethodAccount:                {"QueryAccountRequest", "QueryAccountResponse"},
+}
+
+// anyMessages message of the type urls decoded from AnyValue
+var anyMessages = map[string]string{
+	"/ibc.lightclients.tendermint.v1.ClientState":    "TendermintClientState",
+	"/ibc.lightclients.tendermint.v1.ConsensusState": "TendermintConsensusState",
+	"/cosmos.crypto.secp256k1.PubKey":                "PubKey",
+	"/cosmos.crypto.ed25519.PubKey":                  "PubKey",
+	"/ethermint.crypto.v1.ethsecp256k1.PubKey":       "PubKey",
+	"/ibc.applications.transfer.v1.MsgTransfer":      "MsgTransfer",
+	"/ibc.core.channel.v1.MsgRecvPacket":             "MsgRecvPacket",
+	"/ibc.core.channel.v1.MsgTimeout":                "MsgTimeout",
+	"/ibc.core.channel.v1.MsgAcknowledgement":        "MsgAcknowledgement",
+}
+
+// accountWrappers how deep BaseAccount is embedded in the account types, each level is the field 1 of the wrapper.
+// see AccountWrapper
+var accountWrappers = map[string]int{
+	"/cosmos.auth.v1beta1.BaseAccount":                           0,
+	"/cosmos.auth.v1beta1.ModuleAccount":                         1,
+	"/ethermint.types.v1.EthAccount":                             1,
+	"/ibc.applications.interchain_accounts.v1.InterchainAccount": 1,
+	"/cosmos.vesting.v1beta1.BaseVestingAccount":                 1,
+	"/cosmos.vesting.v1beta1.ContinuousVestingAccount":           2,
+	"/cosmos.vesting.v1beta1.DelayedVestingAccount":              2,
+	"/cosmos.vesting.v1beta1.PeriodicVestingAccount":             2,
+	"/cosmos.vesting.v1beta1.PermanentLockedAccount":             2,
+}
+
+var grpcProtoFile protoreflect.FileDescriptor
+
+func init() {
+	file, err := protodesc.NewFile(grpcFileDescriptorProto(), protoregistry.GlobalFiles)
+	if err != nil {
+		panic(fmt.Sprintf("lcd grpc proto error, %v", err))
+	}
+	grpcProtoFile = file
+}
+
+func grpcMessageDescriptor(name string) protoreflect.MessageDescriptor {
+	return grpcProtoFile.Messages().ByName(protoreflect.Name(name))
+}
+
+const (
+	typeString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	typeBytes   = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+	typeUint64  = descriptorpb.FieldDescriptorProto_TYPE_UINT64
+	typeInt64   = descriptorpb.FieldDescriptorProto_TYPE_INT64
+	typeBool    = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	typeEnum    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	typeMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+)
+
+// protoField typeName is the message or enum name of typeMessage and typeEnum fields
+type protoField struct {
+	name     string
+	number   int32
+	typ      descriptorpb.FieldDescriptorProto_Type
+	typeName string
+	repeated bool
+}
+
+func protoMessage(name string, fields ...protoField) *descriptorpb.DescriptorProto {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	for _, f := range fields {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if f.repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(f.name),
+			JsonName: proto.String(f.name),
+			Number:   proto.Int32(f.number),
+			Label:    label.Enum(),
+			Type:     f.typ.Enum(),
+		}
+		if f.typeName != "" {
+			field.TypeName = proto.String(f.typeName)
+			if f.typeName[0] != '.' {
+				field.TypeName = proto.String(fmt.Sprintf(".%s.%s", grpcProtoPackage, f.typeName))
+			}
+		}
+		msg.Field = append(msg.Field, field)
+	}
+	return msg
+}
+
+func protoEnum(name string, values ...string) *descriptorpb.EnumDescriptorProto {
+	enum := &descriptorpb.EnumDescriptorProto{Name: proto.String(name)}
+	for i, v := range values {
+		enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{
+			Name:   proto.String(v),
+			Number: proto.Int32(int32(i)),
+		})
+	}
+	return enum
+}
+
+func grpcFileDescriptorProto() *descriptorpb.FileDescriptorProto {
+	const (
+		duration  = ".google.protobuf.Duration"
+		timestamp = ".google.protobuf.Timestamp"
+	)
+	pagination := func(number int32) protoField {
+		return protoField{"pagination", number, typeMessage, "PageRequest", false}
+	}
+	pageResponse := func(number int32) protoField {
+		return protoField{"pagination", number, typeMessage, "PageResponse", false}
+	}
+	height := func(name string, number int32) protoField {
+		return protoField{name, number, typeMessage, "Height", false}
+	}
+	str := func(name string, number int32) protoField {
+		return protoField{name, number, typeString, "", false}
+	}
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("iobscan/lcd/query.proto"),
+		Package:    proto.String(grpcProtoPackage),
+		Dependency: []string{"google/protobuf/duration.proto", "google/protobuf/timestamp.proto"},
+		Syntax:     proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			protoEnum("State", "STATE_UNINITIALIZED_UNSPECIFIED", "STATE_INIT", "STATE_TRYOPEN", "STATE_OPEN", "STATE_CLOSED"),
+			protoEnum("Order", "ORDER_NONE_UNSPECIFIED", "ORDER_UNORDERED", "ORDER_ORDERED"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			// common
+			protoMessage("AnyValue", str("type_url", 1), protoField{"value", 2, typeBytes, "", false}),
+			protoMessage("PageRequest",
+				protoField{"key", 1, typeBytes, "", false},
+				protoField{"offset", 2, typeUint64, "", false},
+				protoField{"limit", 3, typeUint64, "", false},
+				protoField{"count_total", 4, typeBool, "", false},
+			),
+			protoMessage("PageResponse",
+				protoField{"next_key", 1, typeBytes, "", false},
+				protoField{"total", 2, typeUint64, "", false},
+			),
+			protoMessage("Coin", str("denom", 1), str("amount", 2)),
+			protoMessage("Height",
+				protoField{"revision_number", 1, typeUint64, "", false},
+				protoField{"revision_height", 2, typeUint64, "", false},
+			),
+
+			// ibc.core.channel.v1
+			protoMessage("Counterparty", str("port_id", 1), str("channel_id", 2)),
+			protoMessage("IdentifiedChannel",
+				protoField{"state", 1, typeEnum, "State", false},
+				protoField{"ordering", 2, typeEnum, "Order", false},
+				protoField{"counterparty", 3, typeMessage, "Counterparty", false},
+				protoField{"connection_hops", 4, typeString, "", true},
+				str("version", 5),
+				str("port_id", 6),
+				str("channel_id", 7),
+			),
+			protoMessage("QueryChannelsRequest", pagination(1)),
+			protoMessage("QueryChannelsResponse",
+				protoField{"channels", 1, typeMessage, "IdentifiedChannel", true},
+				pageResponse(2),
+				height("height", 3),
+			),
+			protoMessage("IdentifiedClientState",
+				str("client_id", 1),
+				protoField{"client_state", 2, typeMessage, "AnyValue", false},
+			),
+			protoMessage("QueryChannelClientStateRequest", str("port_id", 1), str("channel_id", 2)),
+			protoMessage("QueryChannelClientStateResponse",
+				protoField{"identified_client_state", 1, typeMessage, "IdentifiedClientState", false},
+				height("proof_height", 3),
+			),
+			protoMessage("PacketState",
+				str("port_id", 1),
+				str("channel_id", 2),
+				protoField{"sequence", 3, typeUint64, "", false},
+				protoField{"data", 4, typeBytes, "", false},
+			),
+			protoMessage("QueryPacketCommitmentsRequest", str("port_id", 1), str("channel_id", 2), pagination(3)),
+			protoMessage("QueryPacketCommitmentsResponse",
+				protoField{"commitments", 1, typeMessage, "PacketState", true},
+				pageResponse(2),
+				height("height", 3),
+			),
+
+			// ibc.core.client.v1
+			protoMessage("QueryClientStatesRequest", pagination(1)),
+			protoMessage("QueryClientStatesResponse",
+				protoField{"client_states", 1, typeMessage, "IdentifiedClientState", true},
+				pageResponse(2),
+			),
+			protoMessage("QueryConsensusStateRequest",
+				str("client_id", 1),
+				protoField{"revision_number", 2, typeUint64, "", false},
+				protoField{"revision_height", 3, typeUint64, "", false},
+				protoField{"latest_height", 4, typeBool, "", false},
+			),
+			protoMessage("QueryConsensusStateResponse",
+				protoField{"consensus_state", 1, typeMessage, "AnyValue", false},
+				height("proof_height", 3),
+			),
+
+			// ibc.lightclients.tendermint.v1
+			protoMessage("Fraction",
+				protoField{"numerator", 1, typeUint64, "", false},
+				protoField{"denominator", 2, typeUint64, "", false},
+			),
+			protoMessage("TendermintClientState",
+				str("chain_id", 1),
+				protoField{"trust_level", 2, typeMessage, "Fraction", false},
+				protoField{"trusting_period", 3, typeMessage, duration, false},
+				protoField{"unbonding_period", 4, typeMessage, duration, false},
+				protoField{"max_clock_drift", 5, typeMessage, duration, false},
+				height("frozen_height", 6),
+				height("latest_height", 7),
+				protoField{"upgrade_path", 9, typeString, "", true},
+				protoField{"allow_update_after_expiry", 10, typeBool, "", false},
+				protoField{"allow_update_after_misbehaviour", 11, typeBool, "", false},
+			),
+			protoMessage("TendermintConsensusState", protoField{"timestamp", 1, typeMessage, timestamp, false}),
+
+			// cosmos.bank.v1beta1
+			protoMessage("QueryTotalSupplyRequest", pagination(1)),
+			protoMessage("QueryTotalSupplyResponse",
+				protoField{"supply", 1, typeMessage, "Coin", true},
+				pageResponse(2),
+			),
+			protoMessage("QueryAllBalancesRequest", str("address", 1), pagination(2)),
+			protoMessage("QueryAllBalancesResponse",
+				protoField{"balances", 1, typeMessage, "Coin", true},
+				pageResponse(2),
+			),
+
+			// cosmos.staking.v1beta1
+			protoMessage("Delegation", str("delegator_address", 1), str("validator_address", 2), str("shares", 3)),
+			protoMessage("DelegationResponse",
+				protoField{"delegation", 1, typeMessage, "Delegation", false},
+				protoField{"balance", 2, typeMessage, "Coin", false},
+			),
+			protoMessage("QueryDelegatorDelegationsRequest", str("delegator_addr", 1), pagination(2)),
+			protoMessage("QueryDelegatorDelegationsResponse",
+				protoField{"delegation_responses", 1, typeMessage, "DelegationResponse", true},
+				pageResponse(2),
+			),
+			protoMessage("UnbondingDelegationEntry",
+				protoField{"creation_height", 1, typeInt64, "", false},
+				str("initial_balance", 3),
+				str("balance", 4),
+			),
+			protoMessage("UnbondingDelegation",
+				str("delegator_address", 1),
+				str("validator_address", 2),
+				protoField{"entries", 3, typeMessage, "UnbondingDelegationEntry", true},
+			),
+			protoMessage("QueryDelegatorUnbondingDelegationsRequest", str("delegator_addr", 1), pagination(2)),
+			protoMessage("QueryDelegatorUnbondingDelegationsResponse",
+				protoField{"unbonding_responses", 1, typeMessage, "UnbondingDelegation", true},
+				pageResponse(2),
+			),
+
+			// cosmos.distribution.v1beta1
+			protoMessage("DelegationDelegatorReward",
+				str("validator_address", 1),
+				protoField{"reward", 2, typeMessage, "Coin", true},
+			),
+			protoMessage("QueryDelegationTotalRewardsRequest", str("delegator_address", 1)),
+			protoMessage("QueryDelegationTotalRewardsResponse",
+				protoField{"rewards", 1, typeMessage, "DelegationDelegatorReward", true},
+				protoField{"total", 2, typeMessage, "Coin", true},
+			),
+
+			// cosmos.auth.v1beta1
+			protoMessage("QueryAccountRequest", str("address", 1)),
+			protoMessage("QueryAccountResponse", protoField{"account", 1, typeMessage, "AnyValue", false}),
+			protoMessage("AccountWrapper", protoField{"base", 1, typeBytes, "", false}),
+			protoMessage("BaseAccount",
+				str("address", 1),
+				protoField{"pub_key", 2, typeMessage, "AnyValue", false},
+				protoField{"account_number", 3, typeUint64, "", false},
+				protoField{"sequence", 4, typeUint64, "", false},
+			),
+			protoMessage("PubKey", protoField{"key", 1, typeBytes, "", false}),
+
+			// cosmos.tx.v1beta1, txs of the tendermint rpc
+			protoMessage("TxRaw",
+				protoField{"body_bytes", 1, typeBytes, "", false},
+				protoField{"auth_info_bytes", 2, typeBytes, "", false},
+			),
+			protoMessage("TxBody",
+				protoField{"messages", 1, typeMessage, "AnyValue", true},
+				str("memo", 2),
+			),
+
+			// ibc msgs
+			protoMessage("Packet",
+				protoField{"sequence", 1, typeUint64, "", false},
+				str("source_port", 2),
+				str("source_channel", 3),
+				str("destination_port", 4),
+				str("destination_channel", 5),
+				protoField{"data", 6, typeBytes, "", false},
+				height("timeout_height", 7),
+				protoField{"timeout_timestamp", 8, typeUint64, "", false},
+			),
+			protoMessage("MsgTransfer",
+				str("source_port", 1),
+				str("source_channel", 2),
+				protoField{"token", 3, typeMessage, "Coin", false},
+				str("sender", 4),
+				str("receiver", 5),
+				height("timeout_height", 6),
+				protoField{"timeout_timestamp", 7, typeUint64, "", false},
+				str("memo", 8),
+			),
+			protoMessage("MsgRecvPacket",
+				protoField{"packet", 1, typeMessage, "Packet", false},
+				protoField{"proof_commitment", 2, typeBytes, "", false},
+				height("proof_height", 3),
+				str("signer", 4),
+			),
+			protoMessage("MsgTimeout",
+				protoField{"packet", 1, typeMessage, "Packet", false},
+				protoField{"proof_unreceived", 2, typeBytes, "", false},
+				height("proof_height", 3),
+				protoField{"next_sequence_recv", 4, typeUint64, "", false},
+				str("signer", 5),
+			),
+			protoMessage("MsgAcknowledgement",
+				protoField{"packet", 1, typeMessage, "Packet", false},
+				protoField{"acknowledgement", 2, typeBytes, "", false},
+				protoField{"proof_acked", 3, typeBytes, "", false},
+				height("proof_height", 4),
+				str("signer", 5),
+			),
+		},
+	}
+}
@@ -0,0 +1,138 @@
+package lcd
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func newTestMessage(t *testing.T, name, js string) *dynamicpb.Message {
+	msg := dynamicpb.NewMessage(grpcMessageDescriptor(name))
+	if err := protojson.Unmarshal([]byte(js), msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func newTestAny(t *testing.T, typeUrl, name, js string) string {
+	bz, err := proto.Marshal(newTestMessage(t, name, js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `{"type_url":"` + typeUrl + `","value":"` + base64.StdEncoding.EncodeToString(bz) + `"}`
+}
+
+// newTestGrpcServer responds the methods with the responses in json
+func newTestGrpcServer(t *testing.T, responses map[string]string) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		messages := grpcMethods[method]
+		req := dynamicpb.NewMessage(grpcMessageDescriptor(messages[0]))
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		return stream.SendMsg(newTestMessage(t, messages[1], responses[method]))
+	}))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestGrpcQuerier(t *testing.T) {
+	clientState := newTestAny(t, "/ibc.lightclients.tendermint.v1.ClientState", "TendermintClientState",
+		`{"chain_id":"cosmoshub-4","trusting_period":"1209600s","latest_height":{"revision_number":"4","revision_height":"100"}}`)
+	consensusState := newTestAny(t, "/ibc.lightclients.tendermint.v1.ConsensusState", "TendermintConsensusState",
+		`{"timestamp":"2023-01-02T03:04:05Z"}`)
+	pubKey := newTestAny(t, "/cosmos.crypto.secp256k1.PubKey", "PubKey", `{"key":"AQID"}`)
+	baseAccount := newTestMessage(t, "BaseAccount", `{"address":"cosmos1a","pub_key":`+pubKey+`,"account_number":"7","sequence":"9"}`)
+	baseAccountBz, _ := proto.Marshal(baseAccount)
+	moduleAccount := newTestAny(t, "/cosmos.auth.v1beta1.ModuleAccount", "AccountWrapper",
+		`{"base":"`+base64.StdEncoding.EncodeToString(baseAccountBz)+`"}`)
+
+	addr := newTestGrpcServer(t, map[string]string{
+		grpcMethodAllBalances: `{"balances":[{"denom":"uatom","amount":"100"}],"pagination":{"total":"1"}}`,
+		grpcMethodChannelClientState: `{"identified_client_state":{"client_id":"07-tendermint-0","client_state":` +
+			clientState + `}}`,
+		grpcMethodClientStates: `{"client_states":[{"client_id":"07-tendermint-0","client_state":` + clientState +
+			`}],"pagination":{"next_key":"AQ=="}}`,
+		grpcMethodConsensusState:       `{"consensus_state":` + consensusState + `}`,
+		grpcMethodDelegatorDelegations: `{"delegation_responses":[{"delegation":{"shares":"1500000000000000000"}}]}`,
+		grpcMethodAccount:              `{"account":` + moduleAccount + `}`,
+	})
+	querier := NewQuerier(&entity.ChainConfig{ChainName: "test", QueryTransport: entity.QueryTransportGrpc, GrpcAddr: addr})
+
+	balances, err := querier.Balances(context.Background(), "cosmos1a", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances.Balances) != 1 || balances.Balances[0].Amount != "100" || balances.Pagination.NextKey != nil {
+		t.Fatalf("unexpected balances %+v", balances)
+	}
+
+	state, err := querier.ClientState(context.Background(), "transfer", "channel-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := state.IdentifiedClientState.ClientState
+	if state.IdentifiedClientState.ClientId != "07-tendermint-0" || cs.ChainId != "cosmoshub-4" ||
+		cs.TrustingPeriod != "1209600s" || cs.LatestHeight.RevisionHeight != "100" {
+		t.Fatalf("unexpected client state %+v", state)
+	}
+
+	states, err := querier.ClientStates(context.Background(), 100, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states.ClientStates) != 1 || states.ClientStates[0].ClientId != "07-tendermint-0" ||
+		states.ClientStates[0].ClientState.TrustingPeriod != "1209600s" || states.Pagination.NextKey == nil {
+		t.Fatalf("unexpected client states %+v", states)
+	}
+
+	consensus, err := querier.ClientConsensusState(context.Background(), "07-tendermint-0", 4, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consensus.ConsensusState.Timestamp.Unix() != 1672628645 {
+		t.Fatalf("unexpected consensus state %+v", consensus)
+	}
+
+	delegations, err := querier.Delegations(context.Background(), "cosmos1a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares := delegations.DelegationResponses[0].Delegation.Shares; shares != "1.500000000000000000" {
+		t.Fatalf("unexpected shares %s", shares)
+	}
+
+	account, err := querier.Account(context.Background(), "cosmos1a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Account.AccountNumber != "7" || account.Account.PubKey.Key != "AQID" {
+		t.Fatalf("unexpected account %+v", account)
+	}
+}
+
+func TestLegacyDecString(t *testing.T) {
+	for s, want := range map[string]string{
+		"":                      "",
+		"1":                     "0.000000000000000001",
+		"1000000000000000000":   "1.000000000000000000",
+		"123456000000000000000": "123.456000000000000000",
+	} {
+		if got := legacyDecString(s); got != want {
+			t.Errorf("legacyDecString(%s) = %s, want %s", s, got, want)
+		}
+	}
+}
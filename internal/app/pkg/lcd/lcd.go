@@ -1,10 +1,10 @@
 package lcd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"strings"
 
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
@@ -18,144 +18,124 @@ const (
 	replaceHolderPort    = "PORT"
 )
 
-func GetAccount(chain, address, lcd, apiPath string, crossCache bool) (*vo.AccountResp, error) {
+func GetAccount(ctx context.Context, cfg *entity.ChainConfig, address string, crossCache bool) (*vo.AccountResp, error) {
 	lcdGet := func() (*vo.AccountResp, error) {
-		apiPath = strings.ReplaceAll(apiPath, replaceHolderAddress, address)
-		url := fmt.Sprintf("%s%s", lcd, apiPath)
-
-		bz, err := utils.HttpGet(url)
-		if err != nil {
-			return nil, err
-		}
-
-		var resp vo.AccountResp
-		err = json.Unmarshal(bz, &resp)
+		resp, err := NewQuerier(cfg).Account(ctx, address)
 		if err != nil {
 			return nil, err
 		}
 
-		_ = lcdTxDataCacheRepo.SetAccount(chain, address, &resp)
-		return &resp, err
+		_ = lcdTxDataCacheRepo.SetAccount(cfg.ChainName, address, resp)
+		return resp, err
 	}
 
 	if crossCache { // 绕过缓存，取链上的最新数据
 		return lcdGet()
 	}
 
-	if state, err := lcdTxDataCacheRepo.GetAccount(chain, address); err == nil {
+	if state, err := lcdTxDataCacheRepo.GetAccount(cfg.ChainName, address); err == nil {
 		return state, nil
 	}
 
 	return lcdGet()
 }
 
-func GetBalances(chain, address, lcd, apiPath string) (*vo.BalancesResp, error) {
-	if state, err := lcdTxDataCacheRepo.GetBalances(chain, address); err == nil {
+func GetBalances(ctx context.Context, cfg *entity.ChainConfig, address string) (*vo.BalancesResp, error) {
+	if state, err := lcdTxDataCacheRepo.GetBalances(cfg.ChainName, address); err == nil {
 		return state, nil
 	}
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderAddress, address)
-	url := fmt.Sprintf("%s%s", lcd, apiPath)
-
-	bz, err := utils.HttpGet(url)
-	if err != nil {
-		return nil, err
-	}
 
-	var resp vo.BalancesResp
-	err = json.Unmarshal(bz, &resp)
+	resp, err := NewQuerier(cfg).Balances(ctx, address, 0, "")
 	if err != nil {
 		return nil, err
 	}
 
-	_ = lcdTxDataCacheRepo.SetBalances(chain, address, &resp)
+	_ = lcdTxDataCacheRepo.SetBalances(cfg.ChainName, address, resp)
 
-	return &resp, nil
+	return resp, nil
 }
 
-func GetUnbonding(chain, address, lcd, apiPath string) (*vo.UnbondingResp, error) {
-	if state, err := lcdTxDataCacheRepo.GetUnbonding(chain, address); err == nil {
+func GetUnbonding(ctx context.Context, cfg *entity.ChainConfig, address string) (*vo.UnbondingResp, error) {
+	if state, err := lcdTxDataCacheRepo.GetUnbonding(cfg.ChainName, address); err == nil {
 		return state, nil
 	}
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderAddress, address)
-	url := fmt.Sprintf("%s%s", lcd, apiPath)
 
-	bz, err := utils.HttpGet(url)
+	resp, err := NewQuerier(cfg).Unbondings(ctx, address)
 	if err != nil {
 		return nil, err
 	}
-
-	var resp vo.UnbondingResp
-	err = json.Unmarshal(bz, &resp)
-	if err != nil {
-		return nil, err
-	}
-	_ = lcdTxDataCacheRepo.SetUnbonding(chain, address, &resp)
-	return &resp, nil
+	_ = lcdTxDataCacheRepo.SetUnbonding(cfg.ChainName, address, resp)
+	return resp, nil
 }
 
-func GetDelegation(chain, address, lcd, apiPath string) (*vo.DelegationResp, error) {
-	if state, err := lcdTxDataCacheRepo.GetDelegation(chain, address); err == nil {
+func GetDelegation(ctx context.Context, cfg *entity.ChainConfig, address string) (*vo.DelegationResp, error) {
+	if state, err := lcdTxDataCacheRepo.GetDelegation(cfg.ChainName, address); err == nil {
 		return state, nil
 	}
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderAddress, address)
-	url := fmt.Sprintf("%s%s", lcd, apiPath)
 
-	bz, err := utils.HttpGet(url)
+	resp, err := NewQuerier(cfg).Delegations(ctx, address)
 	if err != nil {
 		return nil, err
 	}
+	_ = lcdTxDataCacheRepo.SetDelegation(cfg.ChainName, address, resp)
+	return resp, nil
+}
+
+func GetRewards(ctx context.Context, cfg *entity.ChainConfig, address string) (*vo.RewardsResp, error) {
+	if state, err := lcdTxDataCacheRepo.GetRewards(cfg.ChainName, address); err == nil {
+		return state, nil
+	}
 
-	var resp vo.DelegationResp
-	err = json.Unmarshal(bz, &resp)
+	resp, err := NewQuerier(cfg).Rewards(ctx, address)
 	if err != nil {
 		return nil, err
 	}
-	_ = lcdTxDataCacheRepo.SetDelegation(chain, address, &resp)
-	return &resp, nil
+	_ = lcdTxDataCacheRepo.SetRewards(cfg.ChainName, address, resp)
+	return resp, nil
 }
 
-func GetRewards(chain, address, lcd, apiPath string) (*vo.RewardsResp, error) {
-	if state, err := lcdTxDataCacheRepo.GetRewards(chain, address); err == nil {
+// QueryClientState 查询channel的client_state
+func QueryClientState(ctx context.Context, cfg *entity.ChainConfig, port, channel string) (*vo.ClientStateResp, error) {
+	key := utils.Md5(fmt.Sprintf("%s/%s/%s", cfg.ChainName, port, channel))
+	if state, err := lcdTxDataCacheRepo.GetClientState(key); err == nil {
 		return state, nil
 	}
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderAddress, address)
-	url := fmt.Sprintf("%s%s", lcd, apiPath)
 
-	bz, err := utils.HttpGet(url)
+	resp, err := NewQuerier(cfg).ClientState(ctx, port, channel)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp vo.RewardsResp
-	err = json.Unmarshal(bz, &resp)
-	if err != nil {
-		return nil, err
-	}
-	_ = lcdTxDataCacheRepo.SetRewards(chain, address, &resp)
-	return &resp, nil
+	_ = lcdTxDataCacheRepo.SetClientState(key, resp)
+	return resp, nil
 }
 
-// QueryClientState 查询lcd client_state_path接口
-func QueryClientState(lcd, apiPath, port, channel string) (*vo.ClientStateResp, error) {
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderChannel, channel)
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderPort, port)
-	url := fmt.Sprintf("%s%s", lcd, apiPath)
+// QueryClientStates 分页查询链上的全部client_state, key为上一页返回的pagination.next_key
+func QueryClientStates(ctx context.Context, cfg *entity.ChainConfig, limit int, key string) (*vo.ClientStatesResp, error) {
+	return NewQuerier(cfg).ClientStates(ctx, limit, key)
+}
 
-	if state, err := lcdTxDataCacheRepo.GetClientState(utils.Md5(url)); err == nil {
-		return state, nil
-	}
+// QueryClientConsensusState 查询client在指定高度的consensus_state, 不走缓存
+func QueryClientConsensusState(ctx context.Context, cfg *entity.ChainConfig, clientId string, revisionNumber, revisionHeight int64) (*vo.ConsensusStateResp, error) {
+	return NewQuerier(cfg).ClientConsensusState(ctx, clientId, revisionNumber, revisionHeight)
+}
 
-	bz, err := utils.HttpGet(url)
-	if err != nil {
-		return nil, err
-	}
+// QueryBalances 分页查询balances, 不走缓存. key为上一页返回的pagination.next_key
+func QueryBalances(ctx context.Context, cfg *entity.ChainConfig, address string, limit int, key string) (*vo.BalancesResp, error) {
+	return NewQuerier(cfg).Balances(ctx, address, limit, key)
+}
 
-	var resp vo.ClientStateResp
-	err = json.Unmarshal(bz, &resp)
-	if err != nil {
-		return nil, err
-	}
+// QuerySupply 分页查询supply, key为上一页返回的pagination.next_key
+func QuerySupply(ctx context.Context, cfg *entity.ChainConfig, limit int, key string) (*vo.SupplyResp, error) {
+	return NewQuerier(cfg).Supply(ctx, limit, key)
+}
+
+// QueryChannels 分页查询channels, key为上一页返回的pagination.next_key
+func QueryChannels(ctx context.Context, cfg *entity.ChainConfig, limit int, key string) (*vo.IbcChannelsResp, error) {
+	return NewQuerier(cfg).Channels(ctx, limit, key)
+}
 
-	_ = lcdTxDataCacheRepo.SetClientState(utils.Md5(url), &resp)
-	return &resp, nil
+// QueryPacketCommitments 查询channel的packet_commitments
+func QueryPacketCommitments(ctx context.Context, cfg *entity.ChainConfig, port, channel string) (*vo.IbcPacketCommitsResp, error) {
+	return NewQuerier(cfg).PacketCommitments(ctx, port, channel)
 }
@@ -0,0 +1,177 @@
+package lcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+// Querier queries of a chain over the transport of ChainConfig.QueryTransport. whatever the transport is, the responses
+// are in the format of lcd. key is the pagination.next_key of the previous page
+type Querier interface {
+	Channels(ctx context.Context, limit int, key string) (*vo.IbcChannelsResp, error)
+	ClientState(ctx context.Context, port, channel string) (*vo.ClientStateResp, error)
+	ClientStates(ctx context.Context, limit int, key string) (*vo.ClientStatesResp, error)
+	ClientConsensusState(ctx context.Context, clientId string, revisionNumber, revisionHeight int64) (*vo.ConsensusStateResp, error)
+	PacketCommitments(ctx context.Context, port, channel string) (*vo.IbcPacketCommitsResp, error)
+	Supply(ctx context.Context, limit int, key string) (*vo.SupplyResp, error)
+	Balances(ctx context.Context, address string, limit int, key string) (*vo.BalancesResp, error)
+	Delegations(ctx context.Context, address string) (*vo.DelegationResp, error)
+	Unbondings(ctx context.Context, address string) (*vo.UnbondingResp, error)
+	Rewards(ctx context.Context, address string) (*vo.RewardsResp, error)
+	Account(ctx context.Context, address string) (*vo.AccountResp, error)
+}
+
+func NewQuerier(cfg *entity.ChainConfig) Querier {
+	if cfg.QueryTransport == entity.QueryTransportGrpc {
+		return &grpcQuerier{cfg: cfg}
+	}
+	return &restQuerier{cfg: cfg}
+}
+
+var _ Querier = new(restQuerier)
+
+// restQuerier queries by the lcd api paths of the chain, requests go through the endpoint pool, see Get
+type restQuerier struct {
+	cfg *entity.ChainConfig
+}
+
+func (q *restQuerier) get(ctx context.Context, apiPath string, resp interface{}) error {
+	bz, err := Get(ctx, q.cfg.ChainName, q.cfg.GrpcRestGateway, apiPath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, resp)
+}
+
+func paginationQuery(apiPath string, limit int, key string) string {
+	if limit <= 0 {
+		return apiPath
+	}
+	if key == "" {
+		return fmt.Sprintf("%s?pagination.limit=%d", apiPath, limit)
+	}
+	return fmt.Sprintf("%s?pagination.limit=%d&pagination.key=%s", apiPath, limit, url.QueryEscape(key))
+}
+
+// Channels pages by pagination.key, the query of channels_path(pagination.offset, pagination.count_total) is dropped.
+// offset paging times out on the hubs with thousands of channels
+func (q *restQuerier) Channels(ctx context.Context, limit int, key string) (*vo.IbcChannelsResp, error) {
+	apiPath := q.cfg.LcdApiPath.ChannelsPath
+	if i := strings.Index(apiPath, "?"); i >= 0 {
+		apiPath = apiPath[:i]
+	}
+	var resp vo.IbcChannelsResp
+	if err := q.get(ctx, paginationQuery(apiPath, limit, key), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) ClientState(ctx context.Context, port, channel string) (*vo.ClientStateResp, error) {
+	apiPath := strings.ReplaceAll(q.cfg.LcdApiPath.ClientStatePath, replaceHolderChannel, channel)
+	apiPath = strings.ReplaceAll(apiPath, replaceHolderPort, port)
+	var resp vo.ClientStateResp
+	if err := q.get(ctx, apiPath, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// clientApiPath the client queries are under the ibc client module of the same version as channels_path
+func (q *restQuerier) clientApiPath(path string) string {
+	version := "v1"
+	if s := strings.TrimPrefix(q.cfg.LcdApiPath.ChannelsPath, "/ibc/core/channel/"); s != q.cfg.LcdApiPath.ChannelsPath {
+		if i := strings.Index(s, "/"); i > 0 {
+			version = s[:i]
+		}
+	}
+	return fmt.Sprintf("/ibc/core/client/%s/%s", version, path)
+}
+
+func (q *restQuerier) ClientStates(ctx context.Context, limit int, key string) (*vo.ClientStatesResp, error) {
+	var resp vo.ClientStatesResp
+	if err := q.get(ctx, paginationQuery(q.clientApiPath("client_states"), limit, key), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) ClientConsensusState(ctx context.Context, clientId string, revisionNumber, revisionHeight int64) (*vo.ConsensusStateResp, error) {
+	apiPath := q.clientApiPath(fmt.Sprintf("consensus_states/%s/revision/%d/height/%d", clientId, revisionNumber, revisionHeight))
+	var resp vo.ConsensusStateResp
+	if err := q.get(ctx, apiPath, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PacketCommitments the path is derived from client_state_path, they are under the same channel path
+func (q *restQuerier) PacketCommitments(ctx context.Context, port, channel string) (*vo.IbcPacketCommitsResp, error) {
+	apiPath := strings.ReplaceAll(q.cfg.LcdApiPath.ClientStatePath, "client_state", "packet_commitments")
+	apiPath = strings.ReplaceAll(apiPath, replaceHolderChannel, channel)
+	apiPath = strings.ReplaceAll(apiPath, replaceHolderPort, port)
+	var resp vo.IbcPacketCommitsResp
+	if err := q.get(ctx, apiPath, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) Supply(ctx context.Context, limit int, key string) (*vo.SupplyResp, error) {
+	var resp vo.SupplyResp
+	if err := q.get(ctx, paginationQuery(q.cfg.LcdApiPath.SupplyPath, limit, key), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) Balances(ctx context.Context, address string, limit int, key string) (*vo.BalancesResp, error) {
+	apiPath := strings.ReplaceAll(q.cfg.LcdApiPath.BalancesPath, replaceHolderAddress, address)
+	var resp vo.BalancesResp
+	if err := q.get(ctx, paginationQuery(apiPath, limit, key), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) Delegations(ctx context.Context, address string) (*vo.DelegationResp, error) {
+	apiPath := strings.ReplaceAll(q.cfg.LcdApiPath.DelegationPath, replaceHolderAddress, address)
+	var resp vo.DelegationResp
+	if err := q.get(ctx, apiPath, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) Unbondings(ctx context.Context, address string) (*vo.UnbondingResp, error) {
+	apiPath := strings.ReplaceAll(q.cfg.LcdApiPath.UnbondingPath, replaceHolderAddress, address)
+	var resp vo.UnbondingResp
+	if err := q.get(ctx, apiPath, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) Rewards(ctx context.Context, address string) (*vo.RewardsResp, error) {
+	apiPath := strings.ReplaceAll(q.cfg.LcdApiPath.RewardsPath, replaceHolderAddress, address)
+	var resp vo.RewardsResp
+	if err := q.get(ctx, apiPath, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (q *restQuerier) Account(ctx context.Context, address string) (*vo.AccountResp, error) {
+	apiPath := strings.ReplaceAll(q.cfg.LcdApiPath.AccountsPath, replaceHolderAddress, address)
+	var resp vo.AccountResp
+	if err := q.get(ctx, apiPath, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,53 @@
+package lcd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+func TestRestChannels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/ibc/core/channel/v1/channels" || query.Get("pagination.offset") != "" ||
+			query.Get("pagination.count_total") != "" || query.Get("pagination.limit") != "2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch query.Get("pagination.key") {
+		case "":
+			_, _ = w.Write([]byte(`{"channels":[{"channel_id":"channel-0"},{"channel_id":"channel-1"}],"pagination":{"next_key":"Y2hhbm5lbC0y","total":"0"}}`))
+		case "Y2hhbm5lbC0y":
+			_, _ = w.Write([]byte(`{"channels":[{"channel_id":"channel-2"}],"pagination":{"next_key":null,"total":"0"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	endpointSource = func(chain string) []string { return nil }
+
+	cfg := &entity.ChainConfig{ChainName: "channels", GrpcRestGateway: server.URL}
+	cfg.LcdApiPath.ChannelsPath = "/ibc/core/channel/v1/channels?pagination.offset=OFFSET&pagination.limit=LIMIT&pagination.count_total=true"
+
+	var channels []string
+	key := ""
+	for {
+		resp, err := QueryChannels(context.Background(), cfg, 2, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range resp.Channels {
+			channels = append(channels, v.ChannelId)
+		}
+		if resp.Pagination.NextKey == nil {
+			break
+		}
+		key = *resp.Pagination.NextKey
+	}
+	if len(channels) != 3 || channels[2] != "channel-2" {
+		t.Fatalf("unexpected channels %v", channels)
+	}
+}
@@ -0,0 +1,148 @@
+package lcd
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultRateBurst = 1
+
+var (
+	rateLimit       float64
+	rateBurst       = defaultRateBurst
+	chainRateLimits map[string]float64
+
+	bucketsMux sync.Mutex
+	buckets    = make(map[string]*tokenBucket)
+
+	// requestGroup identical in-flight requests are collapsed, only one of them hits the network
+	requestGroup singleflight.Group
+)
+
+// tokenBucket tokens are refilled at rate per second, up to burst. a request takes a token, it waits when the bucket
+// is empty. the tokens may be negative, they are reserved by the waiting requests in order
+type tokenBucket struct {
+	mux    sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// take a token, returns how long the request has to wait for it
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func chainRateLimit(chain string) float64 {
+	if rate, ok := chainRateLimits[chain]; ok {
+		return rate
+	}
+	return rateLimit
+}
+
+// getBucket the bucket of the host, nil if the host is not limited. a host is shared by the chains on it, the rate of
+// the chain requesting is applied
+func getBucket(chain, host string) *tokenBucket {
+	rate := chainRateLimit(chain)
+	if rate <= 0 {
+		return nil
+	}
+
+	bucketsMux.Lock()
+	defer bucketsMux.Unlock()
+	bucket, ok := buckets[host]
+	if !ok {
+		bucket = newTokenBucket(rate, rateBurst)
+		buckets[host] = bucket
+		return bucket
+	}
+
+	bucket.mux.Lock()
+	bucket.rate = rate
+	bucket.mux.Unlock()
+	return bucket
+}
+
+// requestHost host of the lcd or grpc address, addr without scheme is a grpc address
+func requestHost(addr string) string {
+	if !strings.Contains(addr, "://") {
+		return addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return addr
+	}
+	return u.Host
+}
+
+// throttle wait for the rate limiter of the host of addr before a request to the chain
+func throttle(ctx context.Context, chain, addr string) error {
+	host := requestHost(addr)
+	bucket := getBucket(chain, host)
+	if bucket == nil {
+		return nil
+	}
+	if wait := bucket.take(time.Now()); wait > 0 {
+		monitor.AddLcdThrottleMetricValue(chain, host, wait)
+		return sleep(ctx, wait)
+	}
+	return nil
+}
+
+// sleep for d, ctx.Err() is returned if ctx is done before
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// coalesce identical in-flight requests of key share the response of the first one, which is requested with the ctx
+// of the first caller. a caller stops waiting as soon as its own ctx is done
+func coalesce(ctx context.Context, chain, key string, fn func() ([]byte, error)) ([]byte, error) {
+	ch := requestGroup.DoChan(key, func() (interface{}, error) {
+		return fn()
+	})
+	select {
+	case res := <-ch:
+		if res.Shared {
+			monitor.AddLcdCoalescedMetricValue(chain)
+		}
+		bz, _ := res.Val.([]byte)
+		return bz, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
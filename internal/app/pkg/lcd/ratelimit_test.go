@@ -0,0 +1,60 @@
+package lcd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(2, 2)
+	bucket.last = now
+	for i := 0; i < 2; i++ {
+		if wait := bucket.take(now); wait != 0 {
+			t.Fatalf("burst request %d waits %v", i, wait)
+		}
+	}
+	if wait := bucket.take(now); wait != 500*time.Millisecond {
+		t.Fatalf("unexpected wait %v", wait)
+	}
+	if wait := bucket.take(now); wait != time.Second {
+		t.Fatalf("unexpected wait %v", wait)
+	}
+	if wait := bucket.take(now.Add(2 * time.Second)); wait != 0 {
+		t.Fatalf("bucket is not refilled, %v", wait)
+	}
+}
+
+func TestGetCoalesce(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	endpointSource = func(chain string) []string { return nil }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Get(context.Background(), "coalesce", server.URL, "/cosmos/bank/v1beta1/supply"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if hits != 1 {
+		t.Fatalf("identical requests are not coalesced, %d", hits)
+	}
+}
@@ -0,0 +1,246 @@
+package lcd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// rpcSource the rpc addresses of the chain with tx index enabled, which are cached by ibc_node_valid_lcd_task
+var rpcSource = func(chain string) []string {
+	var lcdAddrCache cache.LcdAddrCacheRepo
+	lcdAddrs, err := lcdAddrCache.Get(chain)
+	if err != nil {
+		return nil
+	}
+	addrs := make([]string, 0, len(lcdAddrs))
+	for _, v := range lcdAddrs {
+		if v.RpcAddr != "" && v.TxIndexEnable {
+			addrs = append(addrs, v.RpcAddr)
+		}
+	}
+	return addrs
+}
+
+// RpcTx tx of the tendermint rpc, the messages are decoded in the format of lcd(with "@type"), messages not supported
+// by the explorer have "@type" only. Logs are the events of the messages by msg index
+type RpcTx struct {
+	Hash     string
+	Height   int64
+	Index    uint32
+	Code     uint32
+	Log      string
+	GasUsed  int64
+	Memo     string
+	Messages []json.RawMessage
+	Logs     []RpcTxLog
+}
+
+type RpcTxLog struct {
+	MsgIndex int            `json:"msg_index"`
+	Events   []entity.Event `json:"events"`
+}
+
+// rpcGet request the rpc nodes of the chain one by one until one of them answers, the result of the method is returned
+func rpcGet(ctx context.Context, chain, apiPath string) ([]byte, error) {
+	return coalesce(ctx, chain, chain+" rpc "+apiPath, func() ([]byte, error) {
+		addrs := rpcSource(chain)
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("chain %s has no available rpc", chain)
+		}
+
+		var lastErr error
+		for _, addr := range addrs {
+			addr = strings.TrimRight(addr, "/")
+			if err := throttle(ctx, chain, addr); err != nil {
+				return nil, err
+			}
+			bz, err := httpGet(ctx, addr+apiPath)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err != nil {
+				lastErr = err
+				continue
+			}
+
+			var resp vo.RpcResp
+			if err = json.Unmarshal(bz, &resp); err != nil {
+				lastErr = err
+				continue
+			}
+			if resp.Error != nil {
+				lastErr = fmt.Errorf("rpc %s error, %s %s", addr, resp.Error.Message, resp.Error.Data)
+				continue
+			}
+			return resp.Result, nil
+		}
+		return nil, lastErr
+	})
+}
+
+// GetTxByHash query the tx by /tx of the rpc nodes
+func GetTxByHash(ctx context.Context, chain, hash string) (*RpcTx, error) {
+	bz, err := rpcGet(ctx, chain, fmt.Sprintf("/tx?hash=0x%s", strings.ToUpper(hash)))
+	if err != nil {
+		return nil, err
+	}
+	var resp vo.RpcTxResp
+	if err = json.Unmarshal(bz, &resp); err != nil {
+		return nil, err
+	}
+	return decodeRpcTx(resp)
+}
+
+// SearchTxs query the latest txs matching query by /tx_search of the rpc nodes, eg:
+// recv_packet.packet_src_channel='channel-0' AND recv_packet.packet_sequence='1'
+func SearchTxs(ctx context.Context, chain, query string, limit int) ([]*RpcTx, error) {
+	apiPath := fmt.Sprintf("/tx_search?query=%s&page=1&per_page=%d&order_by=%s",
+		url.QueryEscape(fmt.Sprintf(`"%s"`, query)), limit, url.QueryEscape(`"desc"`))
+	bz, err := rpcGet(ctx, chain, apiPath)
+	if err != nil {
+		return nil, err
+	}
+	var resp vo.RpcTxSearchResp
+	if err = json.Unmarshal(bz, &resp); err != nil {
+		return nil, err
+	}
+
+	txs := make([]*RpcTx, 0, len(resp.Txs))
+	for _, v := range resp.Txs {
+		tx, err := decodeRpcTx(v)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// GetBlockTime time of the block by /block of the rpc nodes
+func GetBlockTime(ctx context.Context, chain string, height int64) (time.Time, error) {
+	bz, err := rpcGet(ctx, chain, fmt.Sprintf("/block?height=%d", height))
+	if err != nil {
+		return time.Time{}, err
+	}
+	var resp vo.RpcBlockResp
+	if err = json.Unmarshal(bz, &resp); err != nil {
+		return time.Time{}, err
+	}
+	return resp.Block.Header.Time, nil
+}
+
+func decodeRpcTx(resp vo.RpcTxResp) (*RpcTx, error) {
+	tx := &RpcTx{
+		Hash:    resp.Hash,
+		Height:  resp.Height,
+		Index:   resp.Index,
+		Code:    resp.TxResult.Code,
+		Log:     resp.TxResult.Log,
+		GasUsed: resp.TxResult.GasUsed,
+	}
+
+	txRaw := dynamicpb.NewMessage(grpcMessageDescriptor("TxRaw"))
+	if err := proto.Unmarshal(resp.Tx, txRaw); err != nil {
+		return nil, fmt.Errorf("decode tx %s error, %v", resp.Hash, err)
+	}
+	txBody := dynamicpb.NewMessage(grpcMessageDescriptor("TxBody"))
+	bodyBz := txRaw.Get(txRaw.Descriptor().Fields().ByName("body_bytes")).Bytes()
+	if err := proto.Unmarshal(bodyBz, txBody); err != nil {
+		return nil, fmt.Errorf("decode tx %s body error, %v", resp.Hash, err)
+	}
+	bz, err := grpcJsonMarshaler.Marshal(txBody)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Messages []anyValue `json:"messages"`
+		Memo     string     `json:"memo"`
+	}
+	if err = json.Unmarshal(bz, &body); err != nil {
+		return nil, err
+	}
+
+	tx.Memo = body.Memo
+	for _, v := range body.Messages {
+		msg := make(map[string]json.RawMessage)
+		if _, ok := anyMessages[v.TypeUrl]; ok {
+			if err = decodeAny(v, &msg); err != nil {
+				return nil, fmt.Errorf("decode tx %s msg %s error, %v", resp.Hash, v.TypeUrl, err)
+			}
+		}
+		msg["@type"], _ = json.Marshal(v.TypeUrl)
+		msgBz, _ := json.Marshal(msg)
+		tx.Messages = append(tx.Messages, msgBz)
+	}
+
+	tx.Logs = rpcTxLogs(resp, len(tx.Messages))
+	return tx, nil
+}
+
+// rpcTxLogs the events of the messages are in the log before cosmos-sdk v0.50, after that they are in the events of
+// the tx with a msg_index attribute. events of the tx itself(fee, signature) have no msg_index, they are skipped
+func rpcTxLogs(resp vo.RpcTxResp, msgNum int) []RpcTxLog {
+	var logs []RpcTxLog
+	if err := json.Unmarshal([]byte(resp.TxResult.Log), &logs); err == nil && len(logs) > 0 {
+		return logs
+	}
+	if resp.TxResult.Code != 0 {
+		return nil
+	}
+
+	logs = make([]RpcTxLog, msgNum)
+	for i := range logs {
+		logs[i].MsgIndex = i
+	}
+	for _, evt := range rpcEvents(resp.TxResult.Events) {
+		msgIndex := -1
+		for _, attr := range evt.Attributes {
+			if attr.Key == "msg_index" {
+				_, _ = fmt.Sscanf(attr.Value, "%d", &msgIndex)
+			}
+		}
+		if msgIndex >= 0 && msgIndex < msgNum {
+			logs[msgIndex].Events = append(logs[msgIndex].Events, evt)
+		}
+	}
+	return logs
+}
+
+// rpcEvents the attributes are base64 encoded by tendermint v0.34, they are decoded if all the keys are base64
+func rpcEvents(events []vo.RpcEvent) []entity.Event {
+	encoded := len(events) > 0
+	for _, evt := range events {
+		for _, attr := range evt.Attributes {
+			if _, err := base64.StdEncoding.DecodeString(attr.Key); err != nil {
+				encoded = false
+			}
+		}
+	}
+
+	res := make([]entity.Event, 0, len(events))
+	for _, evt := range events {
+		event := entity.Event{Type: evt.Type}
+		for _, attr := range evt.Attributes {
+			kv := entity.KvPair{Key: attr.Key, Value: attr.Value}
+			if encoded {
+				key, _ := base64.StdEncoding.DecodeString(attr.Key)
+				value, _ := base64.StdEncoding.DecodeString(attr.Value)
+				kv = entity.KvPair{Key: string(key), Value: string(value)}
+			}
+			event.Attributes = append(event.Attributes, kv)
+		}
+		res = append(res, event)
+	}
+	return res
+}
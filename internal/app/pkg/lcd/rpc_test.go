@@ -0,0 +1,87 @@
+package lcd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestTx(t *testing.T) []byte {
+	packetData := base64.StdEncoding.EncodeToString([]byte(`{"amount":"100","denom":"uatom","receiver":"osmo1b","sender":"cosmos1a"}`))
+	recvPacket := newTestAny(t, "/ibc.core.channel.v1.MsgRecvPacket", "MsgRecvPacket",
+		`{"packet":{"sequence":"5","source_port":"transfer","source_channel":"channel-0","destination_port":"transfer",
+		"destination_channel":"channel-141","data":"`+packetData+`","timeout_timestamp":"1700000000000000001"},"signer":"osmo1relayer"}`)
+	body, err := proto.Marshal(newTestMessage(t, "TxBody", `{"messages":[{"type_url":"/ibc.core.client.v1.MsgUpdateClient"},`+recvPacket+`],"memo":"relayed"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := proto.Marshal(newTestMessage(t, "TxRaw", `{"body_bytes":"`+base64.StdEncoding.EncodeToString(body)+`"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestGetTxByHash(t *testing.T) {
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	txResp, _ := json.Marshal(map[string]interface{}{
+		"hash":   "ABCD",
+		"height": "100",
+		"tx_result": map[string]interface{}{
+			"code": 0,
+			"log":  "",
+			"events": []map[string]interface{}{
+				{"type": "tx", "attributes": []map[string]string{{"key": b64("fee"), "value": b64("1uatom")}}},
+				{"type": "write_acknowledgement", "attributes": []map[string]string{
+					{"key": b64("packet_ack"), "value": b64(`{"result":"AQ=="}`)},
+					{"key": b64("msg_index"), "value": b64("1")},
+				}},
+			},
+		},
+		"tx": newTestTx(t),
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tx" || r.URL.Query().Get("hash") != "0xABCD" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"result":` + string(txResp) + `}`))
+	}))
+	defer server.Close()
+	rpcSource = func(chain string) []string { return []string{server.URL} }
+
+	tx, err := GetTxByHash(context.Background(), "rpc", "abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Height != 100 || tx.Memo != "relayed" || len(tx.Messages) != 2 {
+		t.Fatalf("unexpected tx %+v", tx)
+	}
+
+	var msg struct {
+		Type   string `json:"@type"`
+		Packet struct {
+			Sequence string `json:"sequence"`
+			Data     []byte `json:"data"`
+		} `json:"packet"`
+		Signer string `json:"signer"`
+	}
+	if err = json.Unmarshal(tx.Messages[1], &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "/ibc.core.channel.v1.MsgRecvPacket" || msg.Packet.Sequence != "5" || msg.Signer != "osmo1relayer" {
+		t.Fatalf("unexpected msg %s", tx.Messages[1])
+	}
+
+	if len(tx.Logs) != 2 || len(tx.Logs[0].Events) != 0 || len(tx.Logs[1].Events) != 1 {
+		t.Fatalf("unexpected logs %+v", tx.Logs)
+	}
+	if attr := tx.Logs[1].Events[0].Attributes[0]; attr.Key != "packet_ack" || attr.Value != `{"result":"AQ=="}` {
+		t.Fatalf("event attributes are not decoded, %+v", attr)
+	}
+}
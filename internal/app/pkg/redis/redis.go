@@ -123,6 +123,20 @@ func (r *Client) HGetAll(key string) (map[string]string, error) {
 	return result, err
 }
 
+// HDel RedisClient `HDEL` command
+func (r *Client) HDel(key string, fields ...string) (int64, error) {
+	result, err := r.redisClient.HDel(context.Background(), key, fields...).Result()
+	if err != nil {
+		logrus.Error("redis HDel fail, ", err.Error())
+	}
+	return result, err
+}
+
+// TTL RedisClient `TTL` command, it is -2 if the key does not exist and -1 if the key has no expiration
+func (r *Client) TTL(key string) (time.Duration, error) {
+	return r.redisClient.TTL(context.Background(), key).Result()
+}
+
 // UnmarshalGet RedisClient `GET` command with unmarshal. It returns redis.Nil error when key does not exist
 func (r *Client) UnmarshalGet(key string, value interface{}) error {
 	result, err := r.Get(key)
@@ -251,6 +265,38 @@ func (r *Client) Lock(key string, value interface{}, expiration time.Duration) e
 	return nil
 }
 
+const unlockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+else
+    return 0
+end`
+
+const expireScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+    return 0
+end`
+
+// UnLock release the lock only if it is still held by value
+func (r *Client) UnLock(key string, value interface{}) (bool, error) {
+	res, err := r.redisClient.Eval(context.Background(), unlockScript, []string{key}, value).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// ScriptExpire renew the lock only if it is still held by value
+func (r *Client) ScriptExpire(key string, value interface{}, expiration time.Duration) (bool, error) {
+	res, err := r.redisClient.Eval(context.Background(), expireScript, []string{key}, value, int64(expiration/time.Millisecond)).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
 func (r *Client) Incr(key string) (int64, error) {
 	incr, err := r.redisClient.Incr(context.Background(), key).Result()
 	if err != nil {
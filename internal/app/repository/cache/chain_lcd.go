@@ -13,6 +13,7 @@ type TraceSourceLcd struct {
 	LcdAddr       string `json:"lcd_addr"`
 	TxIndexEnable bool   `json:"tx_index_enable"`
 	FullNode      bool   `json:"full_node"`
+	RpcAddr       string `json:"rpc_addr"`
 }
 
 func (repo *LcdAddrCacheRepo) Set(chain string, value []TraceSourceLcd) error {
@@ -31,6 +31,7 @@ const (
 	ibcRelayerTransferTypeTxs   = "relayer_transfer_type_txs"
 	ibcRelayerTotalRelayedValue = "relayer_total_relayed_value"
 	ibcRelayerTotalFeeCost      = "relayer_total_fee_cost"
+	ibcRelayerTotalFeeIncome    = "relayer_total_fee_income"
 	relayerRelayedTrend         = "relayer_relayed_trend"
 	ibcRelayer                  = "ibc_relayer"
 	baseDenom                   = "base_denom"
@@ -53,4 +54,8 @@ const (
 	chainOutflowVolumeTrend     = "chain_outflow_volume_trend_%d"
 	chainOutflowVolume          = "chain_outflow_volume_%d"
 	overviewTokenDistribution   = "token_distribution:%s_%s"
+	taskSchedule                = "task_schedule"
+	taskPaused                  = "task_paused"
+	taskLock                    = "task:%s"
+	chainLease                  = "task:%s:%s"
 )
@@ -150,3 +150,27 @@ func (repo *RelayerDataCacheRepo) DelTotalFeeCost() error {
 	_, err := rc.Del(ibcRelayerTotalFeeCost)
 	return err
 }
+
+func (repo *RelayerDataCacheRepo) SetTotalFeeIncome(relayerId string, data *vo.TotalFeeIncomeResp) error {
+	bz, _ := json.Marshal(data)
+	_, err := rc.HSet(ibcRelayerTotalFeeIncome, relayerId, bz)
+	if err != nil {
+		return err
+	}
+	rc.Expire(ibcRelayerTotalFeeIncome, 7*oneDay)
+	return nil
+}
+
+func (repo *RelayerDataCacheRepo) GetTotalFeeIncome(relayerId string) (*vo.TotalFeeIncomeResp, error) {
+	value, err := rc.HGet(ibcRelayerTotalFeeIncome, relayerId)
+	if err != nil {
+		return nil, err
+	}
+
+	var res vo.TotalFeeIncomeResp
+	if err := json.Unmarshal([]byte(value), &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	v8 "github.com/go-redis/redis/v8"
+)
+
+// TaskAdminCacheRepo schedules, pause flags and locks of the tasks, they are shared by all the instances
+type TaskAdminCacheRepo struct {
+}
+
+// TaskLockKey the lock of a task taken by distributiontask.DistributedTask, it is the same key as the one taken before
+// the tasks run on the distributed scheduler, so the old and new instances exclude each other during a rolling deploy
+func TaskLockKey(taskName string) string {
+	return fmt.Sprintf(taskLock, taskName)
+}
+
+// ChainLeaseKey the lease of a chain taken by a worker of a chain leased task
+func ChainLeaseKey(taskName, chain string) string {
+	return fmt.Sprintf(chainLease, taskName, chain)
+}
+
+func (repo *TaskAdminCacheRepo) SetSchedule(schedule *dto.TaskScheduleDTO) error {
+	_, err := rc.HSet(taskSchedule, schedule.TaskName, string(utils.MarshalJsonIgnoreErr(schedule)))
+	return err
+}
+
+func (repo *TaskAdminCacheRepo) GetSchedules() ([]*dto.TaskScheduleDTO, error) {
+	value, err := rc.HGetAll(taskSchedule)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dto.TaskScheduleDTO, 0, len(value))
+	for _, v := range value {
+		var schedule dto.TaskScheduleDTO
+		utils.UnmarshalJsonIgnoreErr([]byte(v), &schedule)
+		res = append(res, &schedule)
+	}
+	return res, nil
+}
+
+func (repo *TaskAdminCacheRepo) Pause(taskName string) error {
+	_, err := rc.HSet(taskPaused, taskName, time.Now().Unix())
+	return err
+}
+
+func (repo *TaskAdminCacheRepo) Resume(taskName string) error {
+	_, err := rc.HDel(taskPaused, taskName)
+	return err
+}
+
+func (repo *TaskAdminCacheRepo) IsPaused(taskName string) (bool, error) {
+	_, err := rc.HGet(taskPaused, taskName)
+	if err == v8.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetPaused task name -> paused time
+func (repo *TaskAdminCacheRepo) GetPaused() (map[string]int64, error) {
+	value, err := rc.HGetAll(taskPaused)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]int64, len(value))
+	for k, v := range value {
+		res[k], _ = strconv.ParseInt(v, 10, 64)
+	}
+	return res, nil
+}
+
+// GetLock holder and ttl of the lock, holder is empty if the lock is free
+func (repo *TaskAdminCacheRepo) GetLock(key string) (string, time.Duration, error) {
+	holder, err := rc.Get(key)
+	if err == v8.Nil {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	ttl, err := rc.TTL(key)
+	if err != nil {
+		return "", 0, err
+	}
+	return holder, ttl, nil
+}
+
+func (repo *TaskAdminCacheRepo) ReleaseLock(key string) (bool, error) {
+	res, err := rc.Del(key)
+	return res > 0, err
+}
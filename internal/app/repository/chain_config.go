@@ -19,6 +19,8 @@ const (
 	ChainConfigFieldIbcInfo         = "ibc_info"
 	ChainConfigFieldIbcInfoHashLcd  = "ibc_info_hash_lcd"
 	ChainConfigFieldLcdApiPath      = "lcd_api_path"
+	ChainConfigFieldQueryTransport  = "query_transport"
+	ChainConfigFieldGrpcAddr        = "grpc_addr"
 )
 
 type IChainConfigRepo interface {
@@ -55,7 +57,7 @@ func (repo *ChainConfigRepo) FindAllChainInfos() ([]*entity.ChainConfig, error)
 	var res []*entity.ChainConfig
 	err := repo.coll().Find(context.Background(), bson.M{}).
 		Select(bson.M{ChainConfigFieldCurrentChainId: 1, ChainConfigFieldChainName: 1, ChainConfigFieldPrettyName: 1, ChainConfigFieldIcon: 1, ChainConfigFieldGrpcRestGateway: 1,
-			ChainConfigFieldLcdApiPath: 1, ChainConfigFieldStatus: 1, ChainConfigFieldAddrPrefix: 1}).All(&res)
+			ChainConfigFieldLcdApiPath: 1, ChainConfigFieldStatus: 1, ChainConfigFieldAddrPrefix: 1, ChainConfigFieldQueryTransport: 1, ChainConfigFieldGrpcAddr: 1}).All(&res)
 	return res, err
 }
 
@@ -63,7 +65,7 @@ func (repo *ChainConfigRepo) FindAllOpenChainInfos() ([]*entity.ChainConfig, err
 	var res []*entity.ChainConfig
 	err := repo.coll().Find(context.Background(), bson.M{ChainConfigFieldStatus: entity.ChainStatusOpen}).
 		Select(bson.M{ChainConfigFieldCurrentChainId: 1, ChainConfigFieldChainName: 1, ChainConfigFieldPrettyName: 1, ChainConfigFieldIcon: 1, ChainConfigFieldGrpcRestGateway: 1,
-			ChainConfigFieldLcdApiPath: 1}).All(&res)
+			ChainConfigFieldLcdApiPath: 1, ChainConfigFieldQueryTransport: 1, ChainConfigFieldGrpcAddr: 1}).All(&res)
 	return res, err
 }
 
@@ -77,7 +79,7 @@ func (repo *ChainConfigRepo) FindOneChainInfo(chain string) (*entity.ChainConfig
 	var res *entity.ChainConfig
 	err := repo.coll().Find(context.Background(), bson.M{ChainConfigFieldChainName: chain}).
 		Select(bson.M{ChainConfigFieldCurrentChainId: 1, ChainConfigFieldChainName: 1, ChainConfigFieldPrettyName: 1, ChainConfigFieldIcon: 1, ChainConfigFieldGrpcRestGateway: 1,
-			ChainConfigFieldLcdApiPath: 1}).
+			ChainConfigFieldLcdApiPath: 1, ChainConfigFieldQueryTransport: 1, ChainConfigFieldGrpcAddr: 1}).
 		One(&res)
 	return res, err
 }
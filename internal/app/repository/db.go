@@ -150,3 +150,21 @@ func CustomerUpdateAll(collName string, ctx context.Context, filter bson.M, upda
 	_, err := coll.UpdateAll(ctx, filter, update)
 	return err
 }
+
+// collectionExists whether the collection exists in ibc database
+func collectionExists(name string) (bool, error) {
+	var res struct {
+		Cursor struct {
+			FirstBatch []bson.M `bson:"firstBatch"`
+		} `bson:"cursor"`
+	}
+	command := bson.D{
+		{Key: "listCollections", Value: 1},
+		{Key: "filter", Value: bson.M{"name": name}},
+		{Key: "nameOnly", Value: true},
+	}
+	if err := mgo.Database(ibcDatabase).RunCommand(context.Background(), command).Decode(&res); err != nil {
+		return false, err
+	}
+	return len(res.Cursor.FirstBatch) > 0, nil
+}
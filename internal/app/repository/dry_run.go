@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// previewUpdateAll the dry run of UpdateAll(query, {"$set": set}). it counts the matched documents and samples limit of
+// them, a sample keeps _id and the fields in set, before and after set is applied.
+func previewUpdateAll(coll *qmgo.Collection, query, set bson.M, limit int64) (int64, []entity.DryRunSample, error) {
+	matched, err := coll.Find(context.Background(), query).Count()
+	if err != nil || matched == 0 || limit <= 0 {
+		return matched, nil, err
+	}
+
+	projection := bson.M{}
+	for k := range set {
+		projection[k] = 1
+	}
+	var docs []bson.M
+	if err = coll.Find(context.Background(), query).Select(projection).Limit(limit).All(&docs); err != nil {
+		return matched, nil, err
+	}
+
+	samples := make([]entity.DryRunSample, 0, len(docs))
+	for _, doc := range docs {
+		after := make(map[string]interface{}, len(doc))
+		for k, v := range doc {
+			after[k] = v
+		}
+		for k, v := range set {
+			after[k] = v
+		}
+		samples = append(samples, entity.DryRunSample{
+			Before: doc,
+			After:  after,
+		})
+	}
+	return matched, samples, nil
+}
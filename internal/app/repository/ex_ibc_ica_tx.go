@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IExIbcIcaTxRepo interface {
+	InsertBatch(txs []*entity.ExIbcIcaTx) error
+	FindProcessingTxs(chain string, limit int64) ([]*entity.ExIbcIcaTx, error)
+	UpdateIbcIcaTx(ibcIcaTx *entity.ExIbcIcaTx, repaired bool) error
+	CountIcaTxs(query dto.IbcIcaTxQuery) (int64, error)
+	FindIcaTxs(query dto.IbcIcaTxQuery, skip, limit int64) ([]*entity.ExIbcIcaTx, error)
+	TxDetail(hash string) ([]*entity.ExIbcIcaTx, error)
+}
+
+var _ IExIbcIcaTxRepo = new(ExIbcIcaTxRepo)
+
+type ExIbcIcaTxRepo struct {
+}
+
+func (repo *ExIbcIcaTxRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.ExIbcIcaTx{}.CollectionName())
+}
+
+func (repo *ExIbcIcaTxRepo) InsertBatch(txs []*entity.ExIbcIcaTx) error {
+	_, err := repo.coll().InsertMany(context.Background(), txs, insertIgnoreErrOpt)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (repo *ExIbcIcaTxRepo) FindProcessingTxs(chain string, limit int64) ([]*entity.ExIbcIcaTx, error) {
+	var res []*entity.ExIbcIcaTx
+	err := repo.coll().Find(context.Background(), bson.M{"sc_chain": chain, "status": entity.IbcTxStatusProcessing}).Sort("next_try_time").Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *ExIbcIcaTxRepo) UpdateIbcIcaTx(ibcIcaTx *entity.ExIbcIcaTx, repaired bool) error {
+	set := bson.M{
+		"status":              ibcIcaTx.Status,
+		"dc_addr":             ibcIcaTx.DcAddr,
+		"dc_connection_id":    ibcIcaTx.DcConnectionId,
+		"dc_tx_info":          ibcIcaTx.DcTxInfo,
+		"ack_timeout_tx_info": ibcIcaTx.AckTimeoutTxInfo,
+		"retry_times":         ibcIcaTx.RetryTimes,
+		"next_try_time":       ibcIcaTx.NextTryTime,
+		"process_info":        ibcIcaTx.ProcessInfo,
+		"update_at":           ibcIcaTx.UpdateAt,
+	}
+	if repaired {
+		set["sc_client_id"] = ibcIcaTx.ScClientId
+		set["dc_client_id"] = ibcIcaTx.DcClientId
+	}
+
+	return repo.coll().UpdateId(context.Background(), ibcIcaTx.Id, bson.M{
+		"$set": set,
+	})
+}
+
+func parseIcaQuery(queryCond dto.IbcIcaTxQuery) bson.M {
+	query := bson.M{}
+
+	//time
+	if queryCond.StartTime > 0 && queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+			"$lte": queryCond.EndTime,
+		}
+	} else if queryCond.StartTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+		}
+	} else if queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$lte": queryCond.EndTime,
+		}
+	}
+
+	if queryCond.ControllerChain != "" {
+		query["sc_chain"] = queryCond.ControllerChain
+	}
+	if queryCond.ControllerAddress != "" {
+		query["sc_addr"] = queryCond.ControllerAddress
+	}
+	if queryCond.HostChain != "" {
+		query["dc_chain"] = queryCond.HostChain
+	}
+	if queryCond.HostAddress != "" {
+		query["dc_addr"] = queryCond.HostAddress
+	}
+
+	//status
+	if len(queryCond.Status) == 0 {
+		query["status"] = bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		}
+	} else {
+		query["status"] = bson.M{
+			"$in": queryCond.Status,
+		}
+	}
+	return query
+}
+
+func (repo *ExIbcIcaTxRepo) CountIcaTxs(query dto.IbcIcaTxQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseIcaQuery(query)).Count()
+}
+
+func (repo *ExIbcIcaTxRepo) FindIcaTxs(query dto.IbcIcaTxQuery, skip, limit int64) ([]*entity.ExIbcIcaTx, error) {
+	var res []*entity.ExIbcIcaTx
+	err := repo.coll().Find(context.Background(), parseIcaQuery(query)).Skip(skip).Limit(limit).Sort("-tx_time").All(&res)
+	return res, err
+}
+
+func (repo *ExIbcIcaTxRepo) TxDetail(hash string) ([]*entity.ExIbcIcaTx, error) {
+	var res []*entity.ExIbcIcaTx
+	query := bson.M{
+		"status": bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		},
+		"$or": []bson.M{
+			{"sc_tx_info.hash": hash},
+			{"dc_tx_info.hash": hash},
+			{"ack_timeout_tx_info.hash": hash},
+		},
+	}
+	err := repo.coll().Find(context.Background(), query).All(&res)
+	return res, err
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IExIbcNftTxRepo interface {
+	InsertBatch(txs []*entity.ExIbcNftTx) error
+	FindProcessingTxs(chain string, limit int64) ([]*entity.ExIbcNftTx, error)
+	UpdateIbcNftTx(ibcNftTx *entity.ExIbcNftTx, repaired bool) error
+	CountTransferTxs(query dto.IbcNftTxQuery) (int64, error)
+	FindTransferTxs(query dto.IbcNftTxQuery, skip, limit int64) ([]*entity.ExIbcNftTx, error)
+	TxDetail(hash string) ([]*entity.ExIbcNftTx, error)
+	AggrClasses(chain string, skip, limit int64) ([]*dto.AggrNftClassDTO, error)
+	CountClasses(chain string) (int64, error)
+}
+
+var _ IExIbcNftTxRepo = new(ExIbcNftTxRepo)
+
+type ExIbcNftTxRepo struct {
+}
+
+func (repo *ExIbcNftTxRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.ExIbcNftTx{}.CollectionName())
+}
+
+func (repo *ExIbcNftTxRepo) InsertBatch(txs []*entity.ExIbcNftTx) error {
+	_, err := repo.coll().InsertMany(context.Background(), txs, insertIgnoreErrOpt)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (repo *ExIbcNftTxRepo) FindProcessingTxs(chain string, limit int64) ([]*entity.ExIbcNftTx, error) {
+	var res []*entity.ExIbcNftTx
+	err := repo.coll().Find(context.Background(), bson.M{"sc_chain": chain, "status": entity.IbcTxStatusProcessing}).Sort("next_try_time").Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *ExIbcNftTxRepo) UpdateIbcNftTx(ibcNftTx *entity.ExIbcNftTx, repaired bool) error {
+	set := bson.M{
+		"status":              ibcNftTx.Status,
+		"dc_connection_id":    ibcNftTx.DcConnectionId,
+		"class.dc_class_id":   ibcNftTx.Class.DcClassId,
+		"dc_tx_info":          ibcNftTx.DcTxInfo,
+		"ack_timeout_tx_info": ibcNftTx.AckTimeoutTxInfo,
+		"retry_times":         ibcNftTx.RetryTimes,
+		"next_try_time":       ibcNftTx.NextTryTime,
+		"process_info":        ibcNftTx.ProcessInfo,
+		"update_at":           ibcNftTx.UpdateAt,
+	}
+	if repaired {
+		set["sc_client_id"] = ibcNftTx.ScClientId
+		set["dc_client_id"] = ibcNftTx.DcClientId
+	}
+
+	return repo.coll().UpdateId(context.Background(), ibcNftTx.Id, bson.M{
+		"$set": set,
+	})
+}
+
+func parseNftQuery(queryCond dto.IbcNftTxQuery) bson.M {
+	query := bson.M{}
+
+	//time
+	if queryCond.StartTime > 0 && queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+			"$lte": queryCond.EndTime,
+		}
+	} else if queryCond.StartTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+		}
+	} else if queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$lte": queryCond.EndTime,
+		}
+	}
+	//chain
+	setChainQuery(query, queryCond.Chain)
+	//base class
+	if queryCond.BaseClassId != "" {
+		query["class.base_class_id"] = queryCond.BaseClassId
+	}
+	if queryCond.BaseClassChain != "" {
+		query["class.base_class_chain"] = queryCond.BaseClassChain
+	}
+	// class
+	if queryCond.ClassId != "" {
+		cond := []bson.M{
+			{"class.sc_class_id": queryCond.ClassId},
+			{"class.dc_class_id": queryCond.ClassId},
+		}
+		if _, exist := query["$or"]; exist {
+			query["$and"] = []bson.M{
+				{"$or": cond},
+			}
+		} else {
+			query["$or"] = cond
+		}
+	}
+
+	//status
+	if len(queryCond.Status) == 0 {
+		query["status"] = bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		}
+	} else {
+		query["status"] = bson.M{
+			"$in": queryCond.Status,
+		}
+	}
+	return query
+}
+
+func (repo *ExIbcNftTxRepo) CountTransferTxs(query dto.IbcNftTxQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseNftQuery(query)).Count()
+}
+
+func (repo *ExIbcNftTxRepo) FindTransferTxs(query dto.IbcNftTxQuery, skip, limit int64) ([]*entity.ExIbcNftTx, error) {
+	var res []*entity.ExIbcNftTx
+	err := repo.coll().Find(context.Background(), parseNftQuery(query)).Skip(skip).Limit(limit).Sort("-tx_time").All(&res)
+	return res, err
+}
+
+func (repo *ExIbcNftTxRepo) TxDetail(hash string) ([]*entity.ExIbcNftTx, error) {
+	var res []*entity.ExIbcNftTx
+	query := bson.M{
+		"status": bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		},
+		"$or": []bson.M{
+			{"sc_tx_info.hash": hash},
+			{"dc_tx_info.hash": hash},
+			{"ack_timeout_tx_info.hash": hash},
+		},
+	}
+	err := repo.coll().Find(context.Background(), query).All(&res)
+	return res, err
+}
+
+func (repo *ExIbcNftTxRepo) aggrClassesMatch(chain string) bson.M {
+	match := bson.M{
+		"status": bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		},
+	}
+	if chain != "" {
+		match["class.base_class_chain"] = chain
+	}
+	return bson.M{"$match": match}
+}
+
+func (repo *ExIbcNftTxRepo) AggrClasses(chain string, skip, limit int64) ([]*dto.AggrNftClassDTO, error) {
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"base_class_id":    "$class.base_class_id",
+				"base_class_chain": "$class.base_class_chain",
+			},
+			"txs_count": bson.M{
+				"$sum": 1,
+			},
+			"tokens_count": bson.M{
+				"$sum": bson.M{
+					"$size": bson.M{"$ifNull": bson.A{"$token_ids", bson.A{}}},
+				},
+			},
+			"latest_tx_time": bson.M{
+				"$max": "$tx_time",
+			},
+		},
+	}
+
+	project := bson.M{
+		"$project": bson.M{
+			"_id":              0,
+			"base_class_id":    "$_id.base_class_id",
+			"base_class_chain": "$_id.base_class_chain",
+			"txs_count":        "$txs_count",
+			"tokens_count":     "$tokens_count",
+			"latest_tx_time":   "$latest_tx_time",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, repo.aggrClassesMatch(chain), group, project,
+		bson.M{"$sort": bson.M{"txs_count": -1, "base_class_id": 1}},
+		bson.M{"$skip": skip},
+		bson.M{"$limit": limit})
+	var res []*dto.AggrNftClassDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
+
+func (repo *ExIbcNftTxRepo) CountClasses(chain string) (int64, error) {
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"base_class_id":    "$class.base_class_id",
+				"base_class_chain": "$class.base_class_chain",
+			},
+		},
+	}
+	count := bson.M{
+		"$count": "count",
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, repo.aggrClassesMatch(chain), group, count)
+	var res []struct {
+		Count int64 `bson:"count"`
+	}
+	if err := repo.coll().Aggregate(context.Background(), pipe).All(&res); err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+	return res[0].Count, nil
+}
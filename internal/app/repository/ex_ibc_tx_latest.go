@@ -8,15 +8,18 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type IExIbcTxRepo interface {
 	InsertBatch(txs []*entity.ExIbcTx) error
 	InsertBatchHistory(txs []*entity.ExIbcTx) error
 	DelBatch(ids []primitive.ObjectID) error
+	DelById(id primitive.ObjectID, history bool) error
 	FindByStatus(status []entity.IbcTxStatus, limit int64) ([]*entity.ExIbcTx, error)
 	FindByTxTime(startTime, endTime, skip, limit int64) ([]*entity.ExIbcTx, error)
 	FindHistoryByTxTime(startTime, endTime, skip, limit int64) ([]*entity.ExIbcTx, error)
@@ -28,8 +31,13 @@ type IExIbcTxRepo interface {
 	LatestHistory() (*entity.ExIbcTx, error)
 	FindProcessingTxs(chain string, limit int64) ([]*entity.ExIbcTx, error)
 	FindProcessingHistoryTxs(chain string, limit int64) ([]*entity.ExIbcTx, error)
+	FindProcessingTxsByPacketIds(scChain, dcChain string, packetIds []string) ([]*entity.ExIbcTx, error)
+	FindProcessingTxsByRecordIds(recordIds []string, history bool) ([]*entity.ExIbcTx, error)
+	BackfillUpsert(ibcTx *entity.ExIbcTx) (*qmgo.UpdateResult, error)
 	UpdateIbcTx(ibcTx *entity.ExIbcTx, repaired bool) error
 	UpdateIbcHistoryTx(ibcTx *entity.ExIbcTx, repaired bool) error
+	SaveForwardTx(ibcTx *entity.ExIbcTx, history bool) error
+	FindByJourneyId(journeyId string, history bool) ([]*entity.ExIbcTx, error)
 	CountBaseDenomTransferTxs(startTime, endTime int64) ([]*dto.CountBaseDenomTxsDTO, error)
 	CountBaseDenomHistoryTransferTxs(startTime, endTime int64) ([]*dto.CountBaseDenomTxsDTO, error)
 	CountIBCTokenRecvTxs(startTime, endTime int64) ([]*dto.CountIBCTokenRecvTxsDTO, error)
@@ -51,6 +59,9 @@ type IExIbcTxRepo interface {
 	AddNewChainUpdateHistoryFailedTx(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId string) error
 	UpdateBaseDenomInfo(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string) error
 	UpdateBaseDenomInfoHistory(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string) error
+	PreviewAddNewChainUpdate(scChain, scChannel, scClientId, dcChain, dcClientId string, history bool, limit int64) (int64, []entity.DryRunSample, error)
+	PreviewAddNewChainUpdateFailedTx(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId string, history bool, limit int64) (int64, []entity.DryRunSample, error)
+	PreviewUpdateBaseDenomInfo(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string, history bool, limit int64) (int64, []entity.DryRunSample, error)
 
 	HistoryLatestCreateAt() (int64, error)
 	HistoryCountAll(createAt int64, record bool) (int64, error)
@@ -61,6 +72,8 @@ type IExIbcTxRepo interface {
 	CountTransferTxs(query dto.IbcTxQuery) (int64, error)
 	FindTransferTxs(query dto.IbcTxQuery, skip, limit int64) ([]*entity.ExIbcTx, error)
 	AggrTxsValue(query dto.IbcTxQuery, isTargetHistory bool) ([]*dto.BaseDenomAmountDTO, error)
+	AggrErrorCategory(chain string, startTime, endTime int64, isTargetHistory bool) ([]*dto.AggrIbcTxErrorCategoryDTO, error)
+	FindLatencyTxs(startTime, endTime int64, isTargetHistory bool) ([]*dto.PacketLatencyTxDTO, error)
 	TxDetail(hash string, history bool) ([]*entity.ExIbcTx, error)
 	GetNeedAcknowledgeTxs(history bool, startTime int64) ([]*entity.ExIbcTx, error)
 	GetNeedRecvPacketTxs(history bool) ([]*entity.ExIbcTx, error)
@@ -101,6 +114,13 @@ func (repo *ExIbcTxRepo) DelBatch(ids []primitive.ObjectID) error {
 	return err
 }
 
+func (repo *ExIbcTxRepo) DelById(id primitive.ObjectID, history bool) error {
+	if history {
+		return repo.collHistory().RemoveId(context.Background(), id)
+	}
+	return repo.coll().RemoveId(context.Background(), id)
+}
+
 func (repo *ExIbcTxRepo) FindByStatus(status []entity.IbcTxStatus, limit int64) ([]*entity.ExIbcTx, error) {
 	var res []*entity.ExIbcTx
 	err := repo.coll().Find(context.Background(), bson.M{"status": bson.M{"$in": status}}).Sort("tx_time").Limit(limit).All(&res)
@@ -181,16 +201,120 @@ func (repo *ExIbcTxRepo) LatestHistory() (*entity.ExIbcTx, error) {
 
 func (repo *ExIbcTxRepo) FindProcessingTxs(chain string, limit int64) ([]*entity.ExIbcTx, error) {
 	var res []*entity.ExIbcTx
-	err := repo.coll().Find(context.Background(), bson.M{"sc_chain": chain, "status": entity.IbcTxStatusProcessing}).Sort("next_try_time").Limit(limit).All(&res)
+	err := repo.coll().Find(context.Background(), bson.M{"sc_chain": chain, "status": bson.M{"$in": entity.IbcTxToBeRelatedStatus}}).Sort("next_try_time").Limit(limit).All(&res)
 	return res, err
 }
 
 func (repo *ExIbcTxRepo) FindProcessingHistoryTxs(chain string, limit int64) ([]*entity.ExIbcTx, error) {
 	var res []*entity.ExIbcTx
-	err := repo.collHistory().Find(context.Background(), bson.M{"sc_chain": chain, "status": entity.IbcTxStatusProcessing}).Sort("next_try_time").Limit(limit).All(&res)
+	err := repo.collHistory().Find(context.Background(), bson.M{"sc_chain": chain, "status": bson.M{"$in": entity.IbcTxToBeRelatedStatus}}).Sort("next_try_time").Limit(limit).All(&res)
 	return res, err
 }
 
+// FindProcessingTxsByPacketIds scChain or dcChain can be empty
+func (repo *ExIbcTxRepo) FindProcessingTxsByPacketIds(scChain, dcChain string, packetIds []string) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	query := bson.M{
+		"sc_tx_info.msg.msg.packet_id": bson.M{"$in": packetIds},
+		"status":                       bson.M{"$in": entity.IbcTxToBeRelatedStatus},
+	}
+	if scChain != "" {
+		query["sc_chain"] = scChain
+	}
+	if dcChain != "" {
+		query["dc_chain"] = dcChain
+	}
+	err := repo.coll().Find(context.Background(), query).All(&res)
+	return res, err
+}
+
+func (repo *ExIbcTxRepo) FindProcessingTxsByRecordIds(recordIds []string, history bool) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	query := bson.M{
+		"record_id": bson.M{"$in": recordIds},
+		"status":    bson.M{"$in": entity.IbcTxToBeRelatedStatus},
+	}
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	err := coll.Find(context.Background(), query).All(&res)
+	return res, err
+}
+
+// BackfillUpsert upsert ibc tx rebuilt from source tx by record_id. The source side fields of an existing record are
+// overwritten and its relate result is kept. The record is updated in ex_ibc_tx if it has been migrated.
+func (repo *ExIbcTxRepo) BackfillUpsert(ibcTx *entity.ExIbcTx) (*qmgo.UpdateResult, error) {
+	set := bson.M{
+		"tx_time":          ibcTx.TxTime,
+		"sc_addr":          ibcTx.ScAddr,
+		"dc_addr":          ibcTx.DcAddr,
+		"sc_port":          ibcTx.ScPort,
+		"sc_channel":       ibcTx.ScChannel,
+		"sc_connection_id": ibcTx.ScConnectionId,
+		"sc_client_id":     ibcTx.ScClientId,
+		"sc_chain":         ibcTx.ScChain,
+		"dc_port":          ibcTx.DcPort,
+		"dc_channel":       ibcTx.DcChannel,
+		"dc_client_id":     ibcTx.DcClientId,
+		"dc_chain":         ibcTx.DcChain,
+		"sequence":         ibcTx.Sequence,
+		"sc_tx_info":       ibcTx.ScTxInfo,
+		"denoms.sc_denom":  ibcTx.Denoms.ScDenom,
+		"base_denom":       ibcTx.BaseDenom,
+		"base_denom_chain": ibcTx.BaseDenomChain,
+	}
+	if len(ibcTx.Tokens) > 0 {
+		set["tokens"] = ibcTx.Tokens
+	}
+	var setOnInsert bson.M
+	bz, err := bson.Marshal(ibcTx)
+	if err != nil {
+		return nil, err
+	}
+	if err = bson.Unmarshal(bz, &setOnInsert); err != nil {
+		return nil, err
+	}
+	for k := range set {
+		delete(setOnInsert, k)
+	}
+	delete(setOnInsert, "denoms")
+	setOnInsert["denoms.dc_denom"] = ibcTx.Denoms.DcDenom
+
+	filter := bson.M{"record_id": ibcTx.RecordId}
+	res, err := repo.collHistory().UpdateAll(context.Background(), filter, bson.M{"$set": set})
+	if err != nil {
+		return nil, err
+	}
+	coll := repo.collHistory()
+	if res.MatchedCount == 0 {
+		coll = repo.coll()
+		upsertOpt := opts.UpdateOptions{
+			UpdateOptions: officialOpts.Update().SetUpsert(true),
+		}
+		res, err = coll.UpdateAll(context.Background(), filter, bson.M{"$set": set, "$setOnInsert": setOnInsert}, upsertOpt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// dc chain is matched now
+	if res.UpsertedCount == 0 && ibcTx.Status == entity.IbcTxStatusProcessing {
+		settingRes, err := coll.UpdateAll(context.Background(), bson.M{"record_id": ibcTx.RecordId, "status": entity.IbcTxStatusSetting},
+			bson.M{"$set": bson.M{"status": ibcTx.Status, "next_try_time": ibcTx.NextTryTime}})
+		if err != nil {
+			return nil, err
+		}
+		res.ModifiedCount += settingRes.ModifiedCount
+	}
+	if res.ModifiedCount > 0 {
+		if _, err = coll.UpdateAll(context.Background(), filter, bson.M{"$set": bson.M{"update_at": ibcTx.UpdateAt}}); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
 func (repo *ExIbcTxRepo) parseUpdateIbcTxSql(ibcTx *entity.ExIbcTx, repaired bool) bson.M {
 	set := bson.M{
 		"status":              ibcTx.Status,
@@ -201,6 +325,7 @@ func (repo *ExIbcTxRepo) parseUpdateIbcTxSql(ibcTx *entity.ExIbcTx, repaired boo
 		"retry_times":         ibcTx.RetryTimes,
 		"next_try_time":       ibcTx.NextTryTime,
 		"process_info":        ibcTx.ProcessInfo,
+		"error_category":      ibcTx.ErrorCategory,
 		"update_at":           ibcTx.UpdateAt,
 	}
 	if repaired {
@@ -208,23 +333,92 @@ func (repo *ExIbcTxRepo) parseUpdateIbcTxSql(ibcTx *entity.ExIbcTx, repaired boo
 		set["dc_client_id"] = ibcTx.DcClientId
 		set["sc_connection_id"] = ibcTx.ScConnectionId
 	}
+	if ibcTx.JourneyId != "" {
+		set["journey_id"] = ibcTx.JourneyId
+	}
+	if len(ibcTx.Tokens) > 0 {
+		set["tokens"] = ibcTx.Tokens
+	}
 	return set
 }
 
+// UpdateIbcTx only the ibc txs still to be related are updated, so a relate worker working on an older read can not
+// overwrite the result of another one. qmgo.ErrNoSuchDocuments is returned if the tx has been related
 func (repo *ExIbcTxRepo) UpdateIbcTx(ibcTx *entity.ExIbcTx, repaired bool) error {
 	set := repo.parseUpdateIbcTxSql(ibcTx, repaired)
-	return repo.coll().UpdateId(context.Background(), ibcTx.Id, bson.M{
+	return repo.coll().UpdateOne(context.Background(), repo.toBeRelatedFilter(ibcTx), bson.M{
 		"$set": set,
 	})
 }
 
 func (repo *ExIbcTxRepo) UpdateIbcHistoryTx(ibcTx *entity.ExIbcTx, repaired bool) error {
 	set := repo.parseUpdateIbcTxSql(ibcTx, repaired)
-	return repo.collHistory().UpdateId(context.Background(), ibcTx.Id, bson.M{
+	return repo.collHistory().UpdateOne(context.Background(), repo.toBeRelatedFilter(ibcTx), bson.M{
 		"$set": set,
 	})
 }
 
+func (repo *ExIbcTxRepo) toBeRelatedFilter(ibcTx *entity.ExIbcTx) bson.M {
+	return bson.M{
+		"_id":    ibcTx.Id,
+		"status": bson.M{"$in": entity.IbcTxToBeRelatedStatus},
+	}
+}
+
+// SaveForwardTx save the hop sent by packet-forward-middleware. The hop is matched by sc chain and packet id,
+// if it has been saved before, only the link to its parent is updated.
+func (repo *ExIbcTxRepo) SaveForwardTx(ibcTx *entity.ExIbcTx, history bool) error {
+	bz, err := bson.Marshal(ibcTx)
+	if err != nil {
+		return err
+	}
+	var doc bson.M
+	if err = bson.Unmarshal(bz, &doc); err != nil {
+		return err
+	}
+	delete(doc, "parent_record_id")
+	delete(doc, "journey_id")
+
+	filter := bson.M{
+		"sc_chain":                     ibcTx.ScChain,
+		"sc_tx_info.msg.msg.packet_id": ibcTx.ScTxInfo.Msg.CommonMsg().PacketId,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"parent_record_id": ibcTx.ParentRecordId,
+			"journey_id":       ibcTx.JourneyId,
+		},
+		"$setOnInsert": doc,
+	}
+	updateOpt := opts.UpdateOptions{UpdateOptions: officialOpts.Update().SetUpsert(true)}
+
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	err = coll.UpdateOne(context.Background(), filter, update, updateOpt)
+	if err == qmgo.ErrNoSuchDocuments { // inserted by upsert
+		return nil
+	}
+	return err
+}
+
+func (repo *ExIbcTxRepo) FindByJourneyId(journeyId string, history bool) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	query := bson.M{
+		"$or": []bson.M{
+			{"record_id": journeyId},
+			{"journey_id": journeyId},
+		},
+	}
+	if history {
+		err := repo.collHistory().Find(context.Background(), query).Sort("tx_time").All(&res)
+		return res, err
+	}
+	err := repo.coll().Find(context.Background(), query).Sort("tx_time").All(&res)
+	return res, err
+}
+
 func (repo *ExIbcTxRepo) countBaseDenomTransferTxsPipe(startTime, endTime int64) []bson.M {
 	match := bson.M{
 		"$match": bson.M{
@@ -344,6 +538,30 @@ func (repo *ExIbcTxRepo) GetMinTxTime(isTargetHistory bool) (int64, error) {
 	return res.TxTime, nil
 }
 
+// unwindTokensPipe unwind the tokens of ics20 v2 multi-denom packet, so that every coin is summed into its own base
+// denom. single coin packet is unwound from base_denom and sc_tx_info.msg_amount. the tx number should only be counted
+// when token_index is 0, or a multi-denom packet would be counted more than once.
+func unwindTokensPipe() []bson.M {
+	addFields := bson.M{
+		"$addFields": bson.M{
+			"token": bson.M{
+				"$ifNull": bson.A{"$tokens", bson.A{bson.M{
+					"base_denom":       "$base_denom",
+					"base_denom_chain": "$base_denom_chain",
+					"amount":           "$sc_tx_info.msg_amount.amount",
+				}}},
+			},
+		},
+	}
+	unwind := bson.M{
+		"$unwind": bson.M{
+			"path":              "$token",
+			"includeArrayIndex": "token_index",
+		},
+	}
+	return []bson.M{addFields, unwind}
+}
+
 func (repo *ExIbcTxRepo) AggrIBCChannelTxsPipe(startTime, endTime int64) []bson.M {
 	match := bson.M{
 		"$match": bson.M{
@@ -359,8 +577,8 @@ func (repo *ExIbcTxRepo) AggrIBCChannelTxsPipe(startTime, endTime int64) []bson.
 	group := bson.M{
 		"$group": bson.M{
 			"_id": bson.M{
-				"base_denom":       "$base_denom",
-				"base_denom_chain": "$base_denom_chain",
+				"base_denom":       "$token.base_denom",
+				"base_denom_chain": "$token.base_denom_chain",
 				"sc_chain":         "$sc_chain",
 				"dc_chain":         "$dc_chain",
 				"sc_channel":       "$sc_channel",
@@ -368,11 +586,11 @@ func (repo *ExIbcTxRepo) AggrIBCChannelTxsPipe(startTime, endTime int64) []bson.
 				"status":           "$status",
 			},
 			"count": bson.M{
-				"$sum": 1,
+				"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$token_index", 0}}, 1, 0}},
 			},
 			"amount": bson.M{
 				"$sum": bson.M{
-					"$toDouble": "$sc_tx_info.msg_amount.amount",
+					"$toDouble": "$token.amount",
 				},
 			},
 		},
@@ -392,7 +610,9 @@ func (repo *ExIbcTxRepo) AggrIBCChannelTxsPipe(startTime, endTime int64) []bson.
 		},
 	}
 	var pipe []bson.M
-	pipe = append(pipe, match, group, project)
+	pipe = append(pipe, match)
+	pipe = append(pipe, unwindTokensPipe()...)
+	pipe = append(pipe, group, project)
 	return pipe
 }
 
@@ -428,17 +648,17 @@ func (repo *ExIbcTxRepo) AggrIBCChainInflow(startTime, endTime int64, targetHist
 	group := bson.M{
 		"$group": bson.M{
 			"_id": bson.M{
-				"base_denom":       "$base_denom",
-				"base_denom_chain": "$base_denom_chain",
+				"base_denom":       "$token.base_denom",
+				"base_denom_chain": "$token.base_denom_chain",
 				"dc_chain":         "$dc_chain",
 				"status":           "$status",
 			},
 			"txs_num": bson.M{
-				"$sum": 1,
+				"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$token_index", 0}}, 1, 0}},
 			},
 			"denom_amount": bson.M{
 				"$sum": bson.M{
-					"$toDouble": "$sc_tx_info.msg_amount.amount",
+					"$toDouble": "$token.amount",
 				},
 			},
 		},
@@ -455,7 +675,9 @@ func (repo *ExIbcTxRepo) AggrIBCChainInflow(startTime, endTime int64, targetHist
 		},
 	}
 	var pipe []bson.M
-	pipe = append(pipe, match, group, project)
+	pipe = append(pipe, match)
+	pipe = append(pipe, unwindTokensPipe()...)
+	pipe = append(pipe, group, project)
 
 	var res []*dto.AggrIBCChainInflowDTO
 	var err error
@@ -485,17 +707,17 @@ func (repo *ExIbcTxRepo) AggrIBCChainOutflow(startTime, endTime int64, targetHis
 	group := bson.M{
 		"$group": bson.M{
 			"_id": bson.M{
-				"base_denom":       "$base_denom",
-				"base_denom_chain": "$base_denom_chain",
+				"base_denom":       "$token.base_denom",
+				"base_denom_chain": "$token.base_denom_chain",
 				"sc_chain":         "$sc_chain",
 				"status":           "$status",
 			},
 			"txs_num": bson.M{
-				"$sum": 1,
+				"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$token_index", 0}}, 1, 0}},
 			},
 			"denom_amount": bson.M{
 				"$sum": bson.M{
-					"$toDouble": "$sc_tx_info.msg_amount.amount",
+					"$toDouble": "$token.amount",
 				},
 			},
 		},
@@ -512,7 +734,9 @@ func (repo *ExIbcTxRepo) AggrIBCChainOutflow(startTime, endTime int64, targetHis
 		},
 	}
 	var pipe []bson.M
-	pipe = append(pipe, match, group, project)
+	pipe = append(pipe, match)
+	pipe = append(pipe, unwindTokensPipe()...)
+	pipe = append(pipe, group, project)
 
 	var res []*dto.AggrIBCChainOutflowDTO
 	var err error
@@ -531,19 +755,19 @@ func (repo *ExIbcTxRepo) Aggr24hDenomVolume(startTime int64) ([]*dto.Aggr24hDeno
 				"$gte": startTime,
 			},
 			"status": bson.M{
-				"$in": []entity.IbcTxStatus{entity.IbcTxStatusProcessing, entity.IbcTxStatusSuccess},
+				"$in": entity.IbcTxVolumeStatus,
 			},
 		},
 	}
 	group := bson.M{
 		"$group": bson.M{
 			"_id": bson.M{
-				"base_denom":       "$base_denom",
-				"base_denom_chain": "$base_denom_chain",
+				"base_denom":       "$token.base_denom",
+				"base_denom_chain": "$token.base_denom_chain",
 			},
 			"denom_amount": bson.M{
 				"$sum": bson.M{
-					"$toDouble": "$sc_tx_info.msg_amount.amount",
+					"$toDouble": "$token.amount",
 				},
 			},
 		},
@@ -557,7 +781,9 @@ func (repo *ExIbcTxRepo) Aggr24hDenomVolume(startTime int64) ([]*dto.Aggr24hDeno
 		},
 	}
 	var pipe []bson.M
-	pipe = append(pipe, match, group, project)
+	pipe = append(pipe, match)
+	pipe = append(pipe, unwindTokensPipe()...)
+	pipe = append(pipe, group, project)
 
 	var res []*dto.Aggr24hDenomVolumeDTO
 	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
@@ -670,7 +896,8 @@ func (repo *ExIbcTxRepo) Migrate(txs []*entity.ExIbcTx) error {
 	return err
 }
 
-func (repo *ExIbcTxRepo) AddNewChainUpdate(scChain, scChannel, scClientId, dcChain, dcClientId string) error {
+// addNewChainUpdateFilter query and $set of AddNewChainUpdate, shared by the update and its dry run
+func (repo *ExIbcTxRepo) addNewChainUpdateFilter(scChain, scChannel, scClientId, dcChain, dcClientId string) (bson.M, bson.M) {
 	query := bson.M{
 		"sc_chain":   scChain,
 		"sc_channel": scChannel,
@@ -678,100 +905,135 @@ func (repo *ExIbcTxRepo) AddNewChainUpdate(scChain, scChannel, scClientId, dcCha
 			"$in": []entity.IbcTxStatus{entity.IbcTxStatusSetting, entity.IbcTxStatusProcessing},
 		},
 	}
+	set := bson.M{
+		"dc_chain":     dcChain,
+		"status":       entity.IbcTxStatusProcessing,
+		"sc_client_id": scClientId,
+		"dc_client_id": dcClientId,
+	}
+	return query, set
+}
 
-	_, err := repo.coll().UpdateAll(context.Background(), query, bson.M{
-		"$set": bson.M{
-			"dc_chain":     dcChain,
-			"status":       entity.IbcTxStatusProcessing,
-			"sc_client_id": scClientId,
-			"dc_client_id": dcClientId,
-		},
-	})
+func (repo *ExIbcTxRepo) AddNewChainUpdate(scChain, scChannel, scClientId, dcChain, dcClientId string) error {
+	query, set := repo.addNewChainUpdateFilter(scChain, scChannel, scClientId, dcChain, dcClientId)
+	_, err := repo.coll().UpdateAll(context.Background(), query, bson.M{"$set": set})
 	return err
 }
 
 func (repo *ExIbcTxRepo) AddNewChainUpdateHistory(scChain, scChannel, scClientId, dcChain, dcClientId string) error {
-	query := bson.M{
-		"sc_chain":   scChain,
-		"sc_channel": scChannel,
-		"status": bson.M{
-			"$in": []entity.IbcTxStatus{entity.IbcTxStatusSetting, entity.IbcTxStatusProcessing},
-		},
-	}
-
-	_, err := repo.collHistory().UpdateAll(context.Background(), query, bson.M{
-		"$set": bson.M{
-			"dc_chain":     dcChain,
-			"status":       entity.IbcTxStatusProcessing,
-			"sc_client_id": scClientId,
-			"dc_client_id": dcClientId,
-		},
-	})
+	query, set := repo.addNewChainUpdateFilter(scChain, scChannel, scClientId, dcChain, dcClientId)
+	_, err := repo.collHistory().UpdateAll(context.Background(), query, bson.M{"$set": set})
 	return err
 }
 
-func (repo *ExIbcTxRepo) AddNewChainUpdateFailedTx(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId string) error {
+// PreviewAddNewChainUpdate dry run of AddNewChainUpdate(or AddNewChainUpdateHistory), limit is the count of samples
+func (repo *ExIbcTxRepo) PreviewAddNewChainUpdate(scChain, scChannel, scClientId, dcChain, dcClientId string, history bool, limit int64) (int64, []entity.DryRunSample, error) {
+	query, set := repo.addNewChainUpdateFilter(scChain, scChannel, scClientId, dcChain, dcClientId)
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	return previewUpdateAll(coll, query, set, limit)
+}
+
+func (repo *ExIbcTxRepo) addNewChainUpdateFailedTxFilter(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId string) (bson.M, bson.M) {
 	query := bson.M{
 		"sc_chain":   scChain,
 		"sc_channel": scChannel,
 		"status":     entity.IbcTxStatusFailed,
 	}
+	set := bson.M{
+		"dc_chain":     dcChain,
+		"dc_channel":   dcChannel,
+		"sc_client_id": scClientId,
+		"dc_client_id": dcClientId,
+	}
+	return query, set
+}
 
-	_, err := repo.coll().UpdateAll(context.Background(), query, bson.M{
-		"$set": bson.M{
-			"dc_chain":     dcChain,
-			"dc_channel":   dcChannel,
-			"sc_client_id": scClientId,
-			"dc_client_id": dcClientId,
-		},
-	})
+func (repo *ExIbcTxRepo) AddNewChainUpdateFailedTx(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId string) error {
+	query, set := repo.addNewChainUpdateFailedTxFilter(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId)
+	_, err := repo.coll().UpdateAll(context.Background(), query, bson.M{"$set": set})
 	return err
 }
 
 func (repo *ExIbcTxRepo) AddNewChainUpdateHistoryFailedTx(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId string) error {
-	query := bson.M{
-		"sc_chain":   scChain,
-		"sc_channel": scChannel,
-		"status":     entity.IbcTxStatusFailed,
-	}
-
-	_, err := repo.collHistory().UpdateAll(context.Background(), query, bson.M{
-		"$set": bson.M{
-			"dc_chain":     dcChain,
-			"dc_channel":   dcChannel,
-			"sc_client_id": scClientId,
-			"dc_client_id": dcClientId,
-		},
-	})
+	query, set := repo.addNewChainUpdateFailedTxFilter(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId)
+	_, err := repo.collHistory().UpdateAll(context.Background(), query, bson.M{"$set": set})
 	return err
 }
 
-func (repo *ExIbcTxRepo) UpdateBaseDenomInfo(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string) error {
+// PreviewAddNewChainUpdateFailedTx dry run of AddNewChainUpdateFailedTx(or AddNewChainUpdateHistoryFailedTx)
+func (repo *ExIbcTxRepo) PreviewAddNewChainUpdateFailedTx(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId string, history bool, limit int64) (int64, []entity.DryRunSample, error) {
+	query, set := repo.addNewChainUpdateFailedTxFilter(scChain, scChannel, scClientId, dcChain, dcChannel, dcClientId)
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	return previewUpdateAll(coll, query, set, limit)
+}
+
+func (repo *ExIbcTxRepo) updateBaseDenomInfoFilter(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string) (bson.M, bson.M) {
 	query := bson.M{
 		"base_denom":       baseDenom,
 		"base_denom_chain": baseDenomChain,
 	}
-	_, err := repo.coll().UpdateAll(context.Background(), query, bson.M{
-		"$set": bson.M{
-			"base_denom":       baseDenomNew,
-			"base_denom_chain": baseDenomChainNew,
+	set := bson.M{
+		"base_denom":       baseDenomNew,
+		"base_denom_chain": baseDenomChainNew,
+	}
+	return query, set
+}
+
+// updateBaseDenomInfo the base denom of the tx and of its tokens, the tokens are updated by arrayFilters
+func (repo *ExIbcTxRepo) updateBaseDenomInfo(coll *qmgo.Collection, baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string) error {
+	query, set := repo.updateBaseDenomInfoFilter(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew)
+	if _, err := coll.UpdateAll(context.Background(), query, bson.M{"$set": set}); err != nil {
+		return err
+	}
+
+	tokensQuery := bson.M{
+		"tokens": bson.M{
+			"$elemMatch": query,
 		},
+	}
+	tokensSet := bson.M{
+		"tokens.$[t].base_denom":       baseDenomNew,
+		"tokens.$[t].base_denom_chain": baseDenomChainNew,
+	}
+	arrayFilters := officialOpts.ArrayFilters{Filters: []interface{}{bson.M{
+		"t.base_denom":       baseDenom,
+		"t.base_denom_chain": baseDenomChain,
+	}}}
+	_, err := coll.UpdateAll(context.Background(), tokensQuery, bson.M{"$set": tokensSet}, opts.UpdateOptions{
+		UpdateOptions: officialOpts.Update().SetArrayFilters(arrayFilters),
 	})
 	return err
 }
 
+func (repo *ExIbcTxRepo) UpdateBaseDenomInfo(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string) error {
+	return repo.updateBaseDenomInfo(repo.coll(), baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew)
+}
+
 func (repo *ExIbcTxRepo) UpdateBaseDenomInfoHistory(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string) error {
-	query := bson.M{
-		"base_denom":       baseDenom,
-		"base_denom_chain": baseDenomChain,
-	}
-	_, err := repo.collHistory().UpdateAll(context.Background(), query, bson.M{
-		"$set": bson.M{
-			"base_denom":       baseDenomNew,
-			"base_denom_chain": baseDenomChainNew,
+	return repo.updateBaseDenomInfo(repo.collHistory(), baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew)
+}
+
+// PreviewUpdateBaseDenomInfo dry run of UpdateBaseDenomInfo(or UpdateBaseDenomInfoHistory), the txs whose tokens have
+// the base denom are matched too
+func (repo *ExIbcTxRepo) PreviewUpdateBaseDenomInfo(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew string, history bool, limit int64) (int64, []entity.DryRunSample, error) {
+	query, set := repo.updateBaseDenomInfoFilter(baseDenom, baseDenomChain, baseDenomNew, baseDenomChainNew)
+	query = bson.M{
+		"$or": []bson.M{
+			query,
+			{"tokens": bson.M{"$elemMatch": query}},
 		},
-	})
-	return err
+	}
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	return previewUpdateAll(coll, query, set, limit)
 }
 
 func (repo *ExIbcTxRepo) HistoryLatestCreateAt() (int64, error) {
@@ -859,82 +1121,78 @@ func (repo *ExIbcTxRepo) CountAll(stats []entity.IbcTxStatus) (int64, error) {
 	return repo.coll().Find(context.Background(), query).Count()
 }
 
-func parseQuery(queryCond dto.IbcTxQuery) bson.M {
-	query := bson.M{}
-
-	//time
-	if queryCond.StartTime > 0 && queryCond.EndTime > 0 {
-		query["tx_time"] = bson.M{
-			"$gte": queryCond.StartTime,
-			"$lte": queryCond.EndTime,
-		}
-	} else if queryCond.StartTime > 0 {
-		query["tx_time"] = bson.M{
-			"$gte": queryCond.StartTime,
-		}
-	} else if queryCond.EndTime > 0 {
-		query["tx_time"] = bson.M{
-			"$lte": queryCond.EndTime,
-		}
-	}
-	//chain
-	if length := len(queryCond.Chain); length > 0 {
+// setChainQuery set sc_chain/dc_chain condition of query
+//   - chain: [transfer_chain or recv_chain] or [transfer_chain, recv_chain], allchain is supported
+func setChainQuery(query bson.M, chain []string) {
+	if length := len(chain); length > 0 {
 		switch length {
 		case 1:
 			// transfer_chain or recv_chain
-			if queryCond.Chain[0] != constant.AllChain {
+			if chain[0] != constant.AllChain {
 				query["$or"] = []bson.M{
-					{"sc_chain": queryCond.Chain[0]},
-					{"dc_chain": queryCond.Chain[0]},
+					{"sc_chain": chain[0]},
+					{"dc_chain": chain[0]},
 				}
 			}
 		case 2:
 			//transfer_chain and recv_chain
-			if queryCond.Chain[0] == queryCond.Chain[1] && queryCond.Chain[0] == constant.AllChain {
+			if chain[0] == chain[1] && chain[0] == constant.AllChain {
 				// nothing to do
 			} else {
-				value := strings.Join(queryCond.Chain, ",")
+				value := strings.Join(chain, ",")
 				if strings.Contains(value, constant.AllChain) {
 					index := strings.Index(value, constant.AllChain)
 					if index > 0 { //chain-id,allchain
-						query["sc_chain"] = queryCond.Chain[0]
+						query["sc_chain"] = chain[0]
 					} else { //allchain,chain-id
-						query["dc_chain"] = queryCond.Chain[1]
+						query["dc_chain"] = chain[1]
 					}
 
 				} else {
 					query["$and"] = []bson.M{
-						{"sc_chain": queryCond.Chain[0]},
-						{"dc_chain": queryCond.Chain[1]},
+						{"sc_chain": chain[0]},
+						{"dc_chain": chain[1]},
 					}
 				}
 			}
 
 		}
 	}
-	//base denom
-	if len(queryCond.BaseDenom) > 0 {
-		query["base_denom"] = bson.M{
-			"$in": queryCond.BaseDenom,
+}
+
+func parseQuery(queryCond dto.IbcTxQuery) bson.M {
+	query := bson.M{}
+
+	//time
+	if queryCond.StartTime > 0 && queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+			"$lte": queryCond.EndTime,
+		}
+	} else if queryCond.StartTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+		}
+	} else if queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$lte": queryCond.EndTime,
 		}
 	}
-	//origin_chain
-	if len(queryCond.BaseDenomChain) > 0 {
-		query["base_denom_chain"] = queryCond.BaseDenomChain
+	//chain
+	setChainQuery(query, queryCond.Chain)
+	//base denom, origin_chain. any token of the tx matches
+	if baseDenomQuery := tokenBaseDenomQuery(queryCond, ""); len(baseDenomQuery) > 0 {
+		addOrQuery(query, []bson.M{
+			baseDenomQuery,
+			{"tokens": bson.M{"$elemMatch": baseDenomQuery}},
+		})
 	}
 	// denom
 	if queryCond.Denom != "" {
-		cond := []bson.M{
+		addOrQuery(query, []bson.M{
 			{"denoms.sc_denom": queryCond.Denom},
 			{"denoms.dc_denom": queryCond.Denom},
-		}
-		if _, exist := query["$or"]; exist {
-			query["$and"] = []bson.M{
-				{"$or": cond},
-			}
-		} else {
-			query["$or"] = cond
-		}
+		})
 	}
 
 	//status
@@ -950,6 +1208,30 @@ func parseQuery(queryCond dto.IbcTxQuery) bson.M {
 	return query
 }
 
+// tokenBaseDenomQuery the base denom and origin chain conditions of the query, on the fields under prefix
+func tokenBaseDenomQuery(queryCond dto.IbcTxQuery, prefix string) bson.M {
+	query := bson.M{}
+	if len(queryCond.BaseDenom) > 0 {
+		query[prefix+"base_denom"] = bson.M{
+			"$in": queryCond.BaseDenom,
+		}
+	}
+	if len(queryCond.BaseDenomChain) > 0 {
+		query[prefix+"base_denom_chain"] = queryCond.BaseDenomChain
+	}
+	return query
+}
+
+// addOrQuery the $or conditions are joined by $and if the query has $or already
+func addOrQuery(query bson.M, cond []bson.M) {
+	if _, exist := query["$or"]; !exist {
+		query["$or"] = cond
+		return
+	}
+	and, _ := query["$and"].([]bson.M)
+	query["$and"] = append(and, bson.M{"$or": cond})
+}
+
 func (repo *ExIbcTxRepo) CountTransferTxs(query dto.IbcTxQuery) (int64, error) {
 	return repo.coll().Find(context.Background(), parseQuery(query)).Count()
 }
@@ -968,12 +1250,12 @@ func (repo *ExIbcTxRepo) AggrTxsValue(query dto.IbcTxQuery, isTargetHistory bool
 	group := bson.M{
 		"$group": bson.M{
 			"_id": bson.M{
-				"base_denom":       "$base_denom",
-				"base_denom_chain": "$base_denom_chain",
+				"base_denom":       "$token.base_denom",
+				"base_denom_chain": "$token.base_denom_chain",
 			},
 			"amount": bson.M{
 				"$sum": bson.M{
-					"$toDouble": "$sc_tx_info.msg_amount.amount",
+					"$toDouble": "$token.amount",
 				},
 			},
 		},
@@ -989,7 +1271,13 @@ func (repo *ExIbcTxRepo) AggrTxsValue(query dto.IbcTxQuery, isTargetHistory bool
 	}
 
 	var pipe []bson.M
-	pipe = append(pipe, match, group, project)
+	pipe = append(pipe, match)
+	pipe = append(pipe, unwindTokensPipe()...)
+	// the other tokens of a matched tx are not summed
+	if tokenQuery := tokenBaseDenomQuery(query, "token."); len(tokenQuery) > 0 {
+		pipe = append(pipe, bson.M{"$match": tokenQuery})
+	}
+	pipe = append(pipe, group, project)
 	var res []*dto.BaseDenomAmountDTO
 	var err error
 	if isTargetHistory {
@@ -1000,6 +1288,113 @@ func (repo *ExIbcTxRepo) AggrTxsValue(query dto.IbcTxQuery, isTargetHistory bool
 	return res, err
 }
 
+// AggrErrorCategory count the ibc txs with error category by channel pair and category
+func (repo *ExIbcTxRepo) AggrErrorCategory(chain string, startTime, endTime int64, isTargetHistory bool) ([]*dto.AggrIbcTxErrorCategoryDTO, error) {
+	query := bson.M{
+		"error_category": bson.M{
+			"$gt": "",
+		},
+	}
+	if startTime > 0 && endTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": startTime,
+			"$lte": endTime,
+		}
+	}
+	if chain != "" {
+		query["$or"] = []bson.M{
+			{"sc_chain": chain},
+			{"dc_chain": chain},
+		}
+	}
+	match := bson.M{
+		"$match": query,
+	}
+
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"sc_chain":       "$sc_chain",
+				"sc_channel":     "$sc_channel",
+				"dc_chain":       "$dc_chain",
+				"dc_channel":     "$dc_channel",
+				"error_category": "$error_category",
+			},
+			"count": bson.M{
+				"$sum": 1,
+			},
+		},
+	}
+
+	project := bson.M{
+		"$project": bson.M{
+			"_id":            0,
+			"sc_chain":       "$_id.sc_chain",
+			"sc_channel":     "$_id.sc_channel",
+			"dc_chain":       "$_id.dc_chain",
+			"dc_channel":     "$_id.dc_channel",
+			"error_category": "$_id.error_category",
+			"count":          "$count",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, match, group, project)
+	var res []*dto.AggrIbcTxErrorCategoryDTO
+	var err error
+	if isTargetHistory {
+		err = repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	} else {
+		err = repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	}
+	return res, err
+}
+
+// FindLatencyTxs the received txs sent in [startTime, endTime], only the fields for latency are returned
+func (repo *ExIbcTxRepo) FindLatencyTxs(startTime, endTime int64, isTargetHistory bool) ([]*dto.PacketLatencyTxDTO, error) {
+	match := bson.M{
+		"$match": bson.M{
+			"tx_time": bson.M{
+				"$gte": startTime,
+				"$lte": endTime,
+			},
+			"status": bson.M{
+				"$in": []entity.IbcTxStatus{entity.IbcTxStatusSuccess, entity.IbcTxStatusRefunded},
+			},
+			"dc_tx_info.time": bson.M{
+				"$gt": 0,
+			},
+		},
+	}
+
+	project := bson.M{
+		"$project": bson.M{
+			"_id":          0,
+			"sc_chain":     "$sc_chain",
+			"sc_channel":   "$sc_channel",
+			"dc_chain":     "$dc_chain",
+			"dc_channel":   "$dc_channel",
+			"sc_time":      "$sc_tx_info.time",
+			"dc_time":      "$dc_tx_info.time",
+			"dc_signers":   "$dc_tx_info.signers",
+			"ack_time":     "$ack_timeout_tx_info.time",
+			"ack_signers":  "$ack_timeout_tx_info.signers",
+			"ack_msg_type": "$ack_timeout_tx_info.msg.type",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, match, project)
+	var res []*dto.PacketLatencyTxDTO
+	var err error
+	if isTargetHistory {
+		err = repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	} else {
+		err = repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	}
+	return res, err
+}
+
 func (repo *ExIbcTxRepo) TxDetail(hash string, history bool) ([]*entity.ExIbcTx, error) {
 	var res []*entity.ExIbcTx
 	query := bson.M{
@@ -3,6 +3,7 @@ package repository
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"go.mongodb.org/mongo-driver/bson"
 	"testing"
 	"time"
@@ -36,3 +37,32 @@ func TestExIbcTxRepo_FindAndUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func Test_parseQueryBaseDenom(t *testing.T) {
+	query := parseQuery(dto.IbcTxQuery{
+		Chain:          []string{"osmosis"},
+		BaseDenom:      []string{"uatom"},
+		BaseDenomChain: "cosmoshub",
+		Denom:          "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+	})
+	if _, ok := query["base_denom"]; ok {
+		t.Fatalf("base_denom only matches the first token, %v", query)
+	}
+	and, ok := query["$and"].([]bson.M)
+	if !ok || len(and) != 2 {
+		t.Fatalf("unexpected $and %v", query["$and"])
+	}
+	baseDenomOr, _ := and[0]["$or"].([]bson.M)
+	if len(baseDenomOr) != 2 {
+		t.Fatalf("unexpected base denom $or %v", and[0])
+	}
+	tokens, _ := baseDenomOr[1]["tokens"].(bson.M)
+	elemMatch, _ := tokens["$elemMatch"].(bson.M)
+	if elemMatch["base_denom_chain"] != "cosmoshub" {
+		t.Fatalf("unexpected tokens query %v", baseDenomOr[1])
+	}
+
+	if tokenQuery := tokenBaseDenomQuery(dto.IbcTxQuery{BaseDenom: []string{"uatom"}}, "token."); len(tokenQuery) != 1 || tokenQuery["token.base_denom"] == nil {
+		t.Fatalf("unexpected token query %v", tokenQuery)
+	}
+}
@@ -14,6 +14,8 @@ import (
 
 type IChainInflowStatisticsRepo interface {
 	CreateNew() error
+	DropNew() error
+	ExistsNew() (bool, error)
 	SwitchColl() error
 	InsertMany(batch []*entity.IBCChainInflowStatistics) error
 	InsertManyToNew(batch []*entity.IBCChainInflowStatistics) error
@@ -51,6 +53,15 @@ func (repo *ChainInflowStatisticsRepo) CreateNew() error {
 	return nil
 }
 
+// DropNew drop the new collection left by an unfinished full statistics
+func (repo *ChainInflowStatisticsRepo) DropNew() error {
+	return repo.collNew().DropCollection(context.Background())
+}
+
+func (repo *ChainInflowStatisticsRepo) ExistsNew() (bool, error) {
+	return collectionExists(entity.IBCChainInflowStatisticsNewCollName)
+}
+
 func (repo *ChainInflowStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCChainInflowStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCChainInflowStatisticsCollName)},
@@ -131,7 +142,7 @@ func (repo *ChainInflowStatisticsRepo) AggrTrend(chain string, segmentStartTime,
 		"$match": bson.M{
 			"chain": chain,
 			"status": bson.M{
-				"$in": []entity.IbcTxStatus{entity.IbcTxStatusSuccess, entity.IbcTxStatusProcessing},
+				"$in": entity.IbcTxVolumeStatus,
 			},
 			"segment_start_time": bson.M{"$gte": segmentStartTime, "$lte": segmentEndTime},
 		},
@@ -17,6 +17,8 @@ type IChainOutflowStatisticsRepo interface {
 	InsertManyToNew(batch []*entity.IBCChainOutflowStatistics) error
 	AggrTrend(chain string, segmentStartTime, segmentEndTime int64) ([]*dto.AggrChainOutflowTrendDTO, error)
 	CreateNew() error
+	DropNew() error
+	ExistsNew() (bool, error)
 	SwitchColl() error
 	BatchSwapNew(segmentStartTime, segmentEndTime int64, batch []*entity.IBCChainOutflowStatistics) error
 	BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCChainOutflowStatistics) error
@@ -75,6 +77,15 @@ func (repo *ChainOutflowStatisticsRepo) CreateNew() error {
 	return nil
 }
 
+// DropNew drop the new collection left by an unfinished full statistics
+func (repo *ChainOutflowStatisticsRepo) DropNew() error {
+	return repo.collNew().DropCollection(context.Background())
+}
+
+func (repo *ChainOutflowStatisticsRepo) ExistsNew() (bool, error) {
+	return collectionExists(entity.IBCChainOutflowStatisticsNewCollName)
+}
+
 func (repo *ChainOutflowStatisticsRepo) BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCChainOutflowStatistics) error {
 	callback := func(sessCtx context.Context) (interface{}, error) {
 		query := bson.M{
@@ -128,7 +139,7 @@ func (repo *ChainOutflowStatisticsRepo) AggrTrend(chain string, segmentStartTime
 		"$match": bson.M{
 			"chain": chain,
 			"status": bson.M{
-				"$in": []entity.IbcTxStatus{entity.IbcTxStatusSuccess, entity.IbcTxStatusProcessing},
+				"$in": entity.IbcTxVolumeStatus,
 			},
 			"segment_start_time": bson.M{"$gte": segmentStartTime, "$lte": segmentEndTime},
 		},
@@ -165,7 +176,7 @@ func (repo *ChainOutflowStatisticsRepo) AggrDenomTxs() ([]*dto.AggrDenomTxsDTO,
 	match := bson.M{
 		"$match": bson.M{
 			"status": bson.M{
-				"$in": []entity.IbcTxStatus{entity.IbcTxStatusSuccess, entity.IbcTxStatusProcessing},
+				"$in": entity.IbcTxVolumeStatus,
 			},
 		},
 	}
@@ -125,7 +125,7 @@ func (repo *ChannelStatisticsRepo) BatchInsertToNew(batch []*entity.IBCChannelSt
 }
 
 func (repo *ChannelStatisticsRepo) Aggr() ([]*dto.ChannelStatisticsAggrDTO, error) {
-	ibcTxUseStatus := []entity.IbcTxStatus{entity.IbcTxStatusSuccess, entity.IbcTxStatusProcessing}
+	ibcTxUseStatus := entity.IbcTxVolumeStatus
 	match := bson.M{
 		"$match": bson.M{
 			"status": bson.M{
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IIBCClientRepo interface {
+	Save(client *entity.IBCClient) error
+	FindClients(chain string, status entity.ClientStatus, skip, limit int64) ([]*entity.IBCClient, error)
+	CountClients(chain string, status entity.ClientStatus) (int64, error)
+	FindExpiring(chain string, expireTime int64) ([]*entity.IBCClient, error)
+	FindByChain(chain string) ([]*entity.IBCClient, error)
+	DeleteClients(chain string, clientIds []string) error
+}
+
+var _ IIBCClientRepo = new(IBCClientRepo)
+
+type IBCClientRepo struct {
+}
+
+func (repo *IBCClientRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCClient{}.CollectionName())
+}
+
+// Save upsert the client by chain and client_id, qmgo reports ErrNoSuchDocuments when the document is inserted
+func (repo *IBCClientRepo) Save(client *entity.IBCClient) error {
+	now := time.Now().Unix()
+	update := bson.M{
+		"$set": bson.M{
+			"client_type":           client.ClientType,
+			"counterparty_chain":    client.CounterpartyChain,
+			"chain_id":              client.ChainId,
+			"port_id":               client.PortId,
+			"channel_id":            client.ChannelId,
+			"trusting_period":       client.TrustingPeriod,
+			"latest_height":         client.LatestHeight,
+			"frozen_height":         client.FrozenHeight,
+			"last_update_time":      client.LastUpdateTime,
+			"expire_time":           client.ExpireTime,
+			"status":                client.Status,
+			"latest_update_tx_time": client.LatestUpdateTxTime,
+			"update_txs_24h":        client.UpdateTxs24h,
+			"update_at":             now,
+		},
+		"$setOnInsert": bson.M{
+			"create_at": now,
+		},
+	}
+	query := bson.M{"chain": client.Chain, "client_id": client.ClientId}
+	err := repo.coll().UpdateOne(context.Background(), query, update, opts.UpdateOptions{
+		UpdateOptions: officialOpts.Update().SetUpsert(true),
+	})
+	if err == qmgo.ErrNoSuchDocuments {
+		return nil
+	}
+	return err
+}
+
+func (repo *IBCClientRepo) clientsQuery(chain string, status entity.ClientStatus) bson.M {
+	query := bson.M{}
+	if chain != "" {
+		query["chain"] = chain
+	}
+	if status != "" {
+		query["status"] = status
+	}
+	return query
+}
+
+func (repo *IBCClientRepo) FindClients(chain string, status entity.ClientStatus, skip, limit int64) ([]*entity.IBCClient, error) {
+	var res []*entity.IBCClient
+	err := repo.coll().Find(context.Background(), repo.clientsQuery(chain, status)).Sort("expire_time").Skip(skip).Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *IBCClientRepo) CountClients(chain string, status entity.ClientStatus) (int64, error) {
+	return repo.coll().Find(context.Background(), repo.clientsQuery(chain, status)).Count()
+}
+
+// FindExpiring clients expiring before expireTime, expired ones included. frozen clients are excluded, they can not
+// be updated anyway
+func (repo *IBCClientRepo) FindExpiring(chain string, expireTime int64) ([]*entity.IBCClient, error) {
+	query := bson.M{
+		"status":      bson.M{"$ne": entity.ClientStatusFrozen},
+		"expire_time": bson.M{"$lte": expireTime},
+	}
+	if chain != "" {
+		query["chain"] = chain
+	}
+	var res []*entity.IBCClient
+	err := repo.coll().Find(context.Background(), query).Sort("expire_time").All(&res)
+	return res, err
+}
+
+func (repo *IBCClientRepo) FindByChain(chain string) ([]*entity.IBCClient, error) {
+	var res []*entity.IBCClient
+	err := repo.coll().Find(context.Background(), bson.M{"chain": chain}).All(&res)
+	return res, err
+}
+
+func (repo *IBCClientRepo) DeleteClients(chain string, clientIds []string) error {
+	query := bson.M{
+		"chain":     chain,
+		"client_id": bson.M{"$in": clientIds},
+	}
+	_, err := repo.coll().RemoveAll(context.Background(), query)
+	return err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IFeeDistributionRepo interface {
+	InsertBatch(distributions []*entity.IbcFeeDistribution) error
+	AggrFeeIncomeDenomAmt(combs []string) ([]*dto.AggrRelayerTxsAmtDTo, error)
+}
+
+var _ IFeeDistributionRepo = new(FeeDistributionRepo)
+
+type FeeDistributionRepo struct {
+}
+
+func (repo *FeeDistributionRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcFeeDistribution{}.CollectionName())
+}
+
+func (repo *FeeDistributionRepo) InsertBatch(distributions []*entity.IbcFeeDistribution) error {
+	_, err := repo.coll().InsertMany(context.Background(), distributions, insertIgnoreErrOpt)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// AggrFeeIncomeDenomAmt aggregate fees received by the chain address combs, refunds are excluded
+func (repo *FeeDistributionRepo) AggrFeeIncomeDenomAmt(combs []string) ([]*dto.AggrRelayerTxsAmtDTo, error) {
+	match := bson.M{
+		"$match": bson.M{
+			"chain_address_comb": bson.M{"$in": combs},
+			"is_refund":          false,
+		},
+	}
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"fee_denom": "$fee_denom",
+				"chain":     "$chain",
+			},
+			"amount": bson.M{
+				"$sum": "$fee_amount",
+			},
+			"tx_hashes": bson.M{
+				"$addToSet": "$tx_hash",
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":       0,
+			"fee_denom": "$_id.fee_denom",
+			"chain":     "$_id.chain",
+			"amount":    "$amount",
+			"total_txs": bson.M{"$size": "$tx_hashes"},
+		},
+	}
+	var pipe []bson.M
+	pipe = append(pipe, match, group, project)
+	var res []*dto.AggrRelayerTxsAmtDTo
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IFeePayeeRepo interface {
+	Save(payee *entity.IbcFeePayee) error
+	FindByRelayers(relayers []string) ([]*entity.IbcFeePayee, error)
+}
+
+var _ IFeePayeeRepo = new(FeePayeeRepo)
+
+type FeePayeeRepo struct {
+}
+
+func (repo *FeePayeeRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcFeePayee{}.CollectionName())
+}
+
+// Save the latest registration of relayer on the channel overrides the previous one
+func (repo *FeePayeeRepo) Save(payee *entity.IbcFeePayee) error {
+	now := time.Now().Unix()
+	filter := bson.M{
+		"chain":      payee.Chain,
+		"port_id":    payee.PortId,
+		"channel_id": payee.ChannelId,
+		"relayer":    payee.Relayer,
+		"payee_type": payee.PayeeType,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"payee":       payee.Payee,
+			"payee_chain": payee.PayeeChain,
+			"tx_hash":     payee.TxHash,
+			"tx_time":     payee.TxTime,
+			"update_at":   now,
+		},
+		"$setOnInsert": bson.M{
+			"create_at": now,
+		},
+	}
+	upsertOpt := opts.UpdateOptions{
+		UpdateOptions: officialOpts.Update().SetUpsert(true),
+	}
+	err := repo.coll().UpdateOne(context.Background(), filter, update, upsertOpt)
+	if err == qmgo.ErrNoSuchDocuments { // inserted by upsert
+		return nil
+	}
+	return err
+}
+
+func (repo *FeePayeeRepo) FindByRelayers(relayers []string) ([]*entity.IbcFeePayee, error) {
+	var res []*entity.IbcFeePayee
+	err := repo.coll().Find(context.Background(), bson.M{"relayer": bson.M{"$in": relayers}}).All(&res)
+	return res, err
+}
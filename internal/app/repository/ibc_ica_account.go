@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IIcaAccountRepo interface {
+	SaveRegister(account *entity.IbcIcaAccount) error
+	SaveHost(account *entity.IbcIcaAccount) error
+	FindByControllerChain(chain string) ([]*entity.IbcIcaAccount, error)
+	FindAccounts(controllerChain, controllerAddress, hostChain string, skip, limit int64) ([]*entity.IbcIcaAccount, error)
+	CountAccounts(controllerChain, controllerAddress, hostChain string) (int64, error)
+}
+
+var _ IIcaAccountRepo = new(IcaAccountRepo)
+
+type IcaAccountRepo struct {
+}
+
+func (repo *IcaAccountRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcIcaAccount{}.CollectionName())
+}
+
+func (repo *IcaAccountRepo) upsertOpt() opts.UpdateOptions {
+	return opts.UpdateOptions{
+		UpdateOptions: officialOpts.Update().SetUpsert(true),
+	}
+}
+
+// upsert qmgo reports ErrNoSuchDocuments when the document is inserted by upsert
+func (repo *IcaAccountRepo) upsert(account *entity.IbcIcaAccount, update bson.M) error {
+	err := repo.coll().UpdateOne(context.Background(), repo.accountKey(account), update, repo.upsertOpt())
+	if err == qmgo.ErrNoSuchDocuments {
+		return nil
+	}
+	return err
+}
+
+func (repo *IcaAccountRepo) accountKey(account *entity.IbcIcaAccount) bson.M {
+	return bson.M{
+		"controller_chain":         account.ControllerChain,
+		"controller_port":          account.ControllerPort,
+		"controller_connection_id": account.ControllerConnectionId,
+	}
+}
+
+// SaveRegister save the controller side info from MsgRegisterInterchainAccount, host side info is left untouched
+func (repo *IcaAccountRepo) SaveRegister(account *entity.IbcIcaAccount) error {
+	now := time.Now().Unix()
+	update := bson.M{
+		"$set": bson.M{
+			"controller_address": account.ControllerAddress,
+			"controller_channel": account.ControllerChannel,
+			"register_tx_hash":   account.RegisterTxHash,
+			"register_time":      account.RegisterTime,
+			"update_at":          now,
+		},
+		"$setOnInsert": bson.M{
+			"create_at": now,
+		},
+	}
+	return repo.upsert(account, update)
+}
+
+// SaveHost save the host side info negotiated in channel_open_ack
+func (repo *IcaAccountRepo) SaveHost(account *entity.IbcIcaAccount) error {
+	now := time.Now().Unix()
+	update := bson.M{
+		"$set": bson.M{
+			"controller_address": account.ControllerAddress,
+			"controller_channel": account.ControllerChannel,
+			"host_chain":         account.HostChain,
+			"host_address":       account.HostAddress,
+			"host_port":          account.HostPort,
+			"host_channel":       account.HostChannel,
+			"host_connection_id": account.HostConnectionId,
+			"update_at":          now,
+		},
+		"$setOnInsert": bson.M{
+			"create_at": now,
+		},
+	}
+	return repo.upsert(account, update)
+}
+
+func (repo *IcaAccountRepo) FindByControllerChain(chain string) ([]*entity.IbcIcaAccount, error) {
+	var res []*entity.IbcIcaAccount
+	err := repo.coll().Find(context.Background(), bson.M{"controller_chain": chain}).All(&res)
+	return res, err
+}
+
+func (repo *IcaAccountRepo) accountsQuery(controllerChain, controllerAddress, hostChain string) bson.M {
+	query := bson.M{}
+	if controllerChain != "" {
+		query["controller_chain"] = controllerChain
+	}
+	if controllerAddress != "" {
+		query["controller_address"] = controllerAddress
+	}
+	if hostChain != "" {
+		query["host_chain"] = hostChain
+	}
+	return query
+}
+
+func (repo *IcaAccountRepo) FindAccounts(controllerChain, controllerAddress, hostChain string, skip, limit int64) ([]*entity.IbcIcaAccount, error) {
+	var res []*entity.IbcIcaAccount
+	query := repo.accountsQuery(controllerChain, controllerAddress, hostChain)
+	err := repo.coll().Find(context.Background(), query).Sort("-register_time").Skip(skip).Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *IcaAccountRepo) CountAccounts(controllerChain, controllerAddress, hostChain string) (int64, error) {
+	return repo.coll().Find(context.Background(), repo.accountsQuery(controllerChain, controllerAddress, hostChain)).Count()
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IPacketFeeRepo interface {
+	InsertBatch(fees []*entity.IbcPacketFee) error
+	FindByPacket(chain, port, channel, sequence string) ([]*entity.IbcPacketFee, error)
+}
+
+var _ IPacketFeeRepo = new(PacketFeeRepo)
+
+type PacketFeeRepo struct {
+}
+
+func (repo *PacketFeeRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcPacketFee{}.CollectionName())
+}
+
+func (repo *PacketFeeRepo) InsertBatch(fees []*entity.IbcPacketFee) error {
+	_, err := repo.coll().InsertMany(context.Background(), fees, insertIgnoreErrOpt)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (repo *PacketFeeRepo) FindByPacket(chain, port, channel, sequence string) ([]*entity.IbcPacketFee, error) {
+	var res []*entity.IbcPacketFee
+	query := bson.M{
+		"chain":      chain,
+		"port_id":    port,
+		"channel_id": channel,
+		"sequence":   sequence,
+	}
+	err := repo.coll().Find(context.Background(), query).All(&res)
+	return res, err
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IPacketLatencyRepo interface {
+	BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCPacketLatencyStatistics) error
+	FindByChannel(chain, channel string, period entity.PacketLatencyPeriod, segmentStartTime, segmentEndTime int64) ([]*entity.IBCPacketLatencyStatistics, error)
+	FindByRelayer(relayers []string, period entity.PacketLatencyPeriod, segmentStartTime, segmentEndTime int64) ([]*entity.IBCPacketLatencyStatistics, error)
+}
+
+var _ IPacketLatencyRepo = new(PacketLatencyRepo)
+
+type PacketLatencyRepo struct {
+}
+
+func (repo *PacketLatencyRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCPacketLatencyStatisticsCollName)
+}
+
+// BatchSwap replace the statistics(both hour and day) in [segmentStartTime, segmentEndTime]
+func (repo *PacketLatencyRepo) BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCPacketLatencyStatistics) error {
+	callback := func(sessCtx context.Context) (interface{}, error) {
+		query := bson.M{
+			"segment_start_time": bson.M{"$gte": segmentStartTime},
+			"segment_end_time":   bson.M{"$lte": segmentEndTime},
+		}
+		if _, err := repo.coll().RemoveAll(sessCtx, query); err != nil {
+			return nil, err
+		}
+
+		if len(batch) == 0 {
+			return nil, nil
+		}
+
+		if _, err := repo.coll().InsertMany(sessCtx, batch); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+	_, err := mgo.DoTransaction(context.Background(), callback)
+	return err
+}
+
+// FindByChannel statistics of both directions of the channel
+func (repo *PacketLatencyRepo) FindByChannel(chain, channel string, period entity.PacketLatencyPeriod, segmentStartTime, segmentEndTime int64) ([]*entity.IBCPacketLatencyStatistics, error) {
+	query := bson.M{
+		"$or": []bson.M{
+			{"sc_chain": chain, "sc_channel": channel},
+			{"dc_chain": chain, "dc_channel": channel},
+		},
+		"period":             period,
+		"segment_start_time": bson.M{"$gte": segmentStartTime, "$lte": segmentEndTime},
+	}
+	var res []*entity.IBCPacketLatencyStatistics
+	err := repo.coll().Find(context.Background(), query).Sort("segment_start_time").All(&res)
+	return res, err
+}
+
+func (repo *PacketLatencyRepo) FindByRelayer(relayers []string, period entity.PacketLatencyPeriod, segmentStartTime, segmentEndTime int64) ([]*entity.IBCPacketLatencyStatistics, error) {
+	query := bson.M{
+		"relayer":            bson.M{"$in": relayers},
+		"period":             period,
+		"segment_start_time": bson.M{"$gte": segmentStartTime, "$lte": segmentEndTime},
+	}
+	var res []*entity.IBCPacketLatencyStatistics
+	err := repo.coll().Find(context.Background(), query).Sort("segment_start_time").All(&res)
+	return res, err
+}
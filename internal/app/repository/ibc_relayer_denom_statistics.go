@@ -14,10 +14,13 @@ import (
 
 type IRelayerDenomStatisticsRepo interface {
 	CreateNew() error
+	DropNew() error
+	ExistsNew() (bool, error)
 	SwitchColl() error
 	InsertMany(batch []*entity.IBCRelayerDenomStatistics) error
 	InsertManyToNew(batch []*entity.IBCRelayerDenomStatistics) error
 	BatchSwap(chain string, segmentStartTime, segmentEndTime int64, batch []*entity.IBCRelayerDenomStatistics) error
+	BatchSwapNew(chain string, segmentStartTime, segmentEndTime int64, batch []*entity.IBCRelayerDenomStatistics) error
 	AggrRelayerBaseDenomAmtAndTxs(combs []string) ([]*dto.CountRelayerBaseDenomAmtDTO, error)
 	AggrRelayerAmtAndTxsBySegment(combs []string, segmentStartTime, segmentEndTime int64) ([]*dto.CountRelayerBaseDenomAmtBySegmentDTO, error)
 	AggrAmtByTxType(combs []string) ([]*dto.AggrRelayerTxTypeDTO, error)
@@ -59,6 +62,15 @@ func (repo *RelayerDenomStatisticsRepo) CreateNew() error {
 
 	return nil
 }
+
+// DropNew drop the new collection left by an unfinished full statistics
+func (repo *RelayerDenomStatisticsRepo) DropNew() error {
+	return repo.collNew().DropCollection(context.Background())
+}
+
+func (repo *RelayerDenomStatisticsRepo) ExistsNew() (bool, error) {
+	return collectionExists(entity.IBCRelayerDenomStatisticsNewCollName)
+}
 func (repo *RelayerDenomStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCRelayerDenomStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCRelayerDenomStatisticsCollName)},
@@ -104,6 +116,32 @@ func (repo *RelayerDenomStatisticsRepo) BatchSwap(chain string, segmentStartTime
 	return err
 }
 
+// BatchSwapNew same as BatchSwap, on the new collection
+func (repo *RelayerDenomStatisticsRepo) BatchSwapNew(chain string, segmentStartTime, segmentEndTime int64, batch []*entity.IBCRelayerDenomStatistics) error {
+	callback := func(sessCtx context.Context) (interface{}, error) {
+		query := bson.M{
+			"statistics_chain":   chain,
+			"segment_start_time": segmentStartTime,
+			"segment_end_time":   segmentEndTime,
+		}
+		if _, err := repo.collNew().RemoveAll(sessCtx, query); err != nil {
+			return nil, err
+		}
+
+		if len(batch) == 0 {
+			return nil, nil
+		}
+
+		if _, err := repo.collNew().InsertMany(sessCtx, batch); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+	_, err := mgo.DoTransaction(context.Background(), callback)
+	return err
+}
+
 func (repo *RelayerDenomStatisticsRepo) AggrRelayerBaseDenomAmtAndTxs(combs []string) ([]*dto.CountRelayerBaseDenomAmtDTO, error) {
 	match := bson.M{
 		"$match": bson.M{
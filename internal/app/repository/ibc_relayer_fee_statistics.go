@@ -14,10 +14,13 @@ import (
 
 type IRelayerFeeStatisticsRepo interface {
 	CreateNew() error
+	DropNew() error
+	ExistsNew() (bool, error)
 	SwitchColl() error
 	InsertMany(batch []*entity.IBCRelayerFeeStatistics) error
 	InsertManyToNew(batch []*entity.IBCRelayerFeeStatistics) error
 	BatchSwap(chain string, segmentStartTime, segmentEndTime int64, batch []*entity.IBCRelayerFeeStatistics) error
+	BatchSwapNew(chain string, segmentStartTime, segmentEndTime int64, batch []*entity.IBCRelayerFeeStatistics) error
 	AggrRelayerFeeDenomAmt(combs []string) ([]*dto.AggrRelayerTxsAmtDTo, error)
 	AggrChainAddressPair() ([]*dto.AggrChainAddrDTO, error)
 	UpdateChainAddressComb(chain, address, chainAddressComb string) error
@@ -52,6 +55,15 @@ func (repo *RelayerFeeStatisticsRepo) CreateNew() error {
 	return nil
 }
 
+// DropNew drop the new collection left by an unfinished full statistics
+func (repo *RelayerFeeStatisticsRepo) DropNew() error {
+	return repo.collNew().DropCollection(context.Background())
+}
+
+func (repo *RelayerFeeStatisticsRepo) ExistsNew() (bool, error) {
+	return collectionExists(entity.IBCRelayerFeeStatisticsNewCollName)
+}
+
 func (repo *RelayerFeeStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCRelayerFeeStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCRelayerFeeStatisticsCollName)},
@@ -98,6 +110,32 @@ func (repo *RelayerFeeStatisticsRepo) BatchSwap(chain string, segmentStartTime,
 	return err
 }
 
+// BatchSwapNew same as BatchSwap, on the new collection
+func (repo *RelayerFeeStatisticsRepo) BatchSwapNew(chain string, segmentStartTime, segmentEndTime int64, batch []*entity.IBCRelayerFeeStatistics) error {
+	callback := func(sessCtx context.Context) (interface{}, error) {
+		query := bson.M{
+			"statistics_chain":   chain,
+			"segment_start_time": segmentStartTime,
+			"segment_end_time":   segmentEndTime,
+		}
+		if _, err := repo.collNew().RemoveAll(sessCtx, query); err != nil {
+			return nil, err
+		}
+
+		if len(batch) == 0 {
+			return nil, nil
+		}
+
+		if _, err := repo.collNew().InsertMany(sessCtx, batch); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+	_, err := mgo.DoTransaction(context.Background(), callback)
+	return err
+}
+
 func (repo *RelayerFeeStatisticsRepo) AggrRelayerFeeDenomAmt(combs []string) ([]*dto.AggrRelayerTxsAmtDTo, error) {
 	match := bson.M{
 		"$match": bson.M{
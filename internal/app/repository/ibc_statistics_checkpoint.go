@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IStatisticsCheckpointRepo interface {
+	FindByTaskName(taskName string) ([]*entity.IbcStatisticsCheckpoint, error)
+	Save(checkpoint *entity.IbcStatisticsCheckpoint) error
+	DeleteByTaskName(taskName string) error
+}
+
+var _ IStatisticsCheckpointRepo = new(StatisticsCheckpointRepo)
+
+type StatisticsCheckpointRepo struct {
+}
+
+func (repo *StatisticsCheckpointRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcStatisticsCheckpoint{}.CollectionName())
+}
+
+func (repo *StatisticsCheckpointRepo) FindByTaskName(taskName string) ([]*entity.IbcStatisticsCheckpoint, error) {
+	var res []*entity.IbcStatisticsCheckpoint
+	err := repo.coll().Find(context.Background(), bson.M{"task_name": taskName}).All(&res)
+	return res, err
+}
+
+// Save upsert by task_name and scope, create_at is kept as it is set on insert
+func (repo *StatisticsCheckpointRepo) Save(checkpoint *entity.IbcStatisticsCheckpoint) error {
+	upsertOpt := opts.UpdateOptions{
+		UpdateOptions: officialOpts.Update().SetUpsert(true),
+	}
+	query := bson.M{
+		"task_name": checkpoint.TaskName,
+		"scope":     checkpoint.Scope,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"segment_start_time":       checkpoint.SegmentStartTime,
+			"segment_end_time":         checkpoint.SegmentEndTime,
+			"run_start_time":           checkpoint.RunStartTime,
+			"first_segment_start_time": checkpoint.FirstSegmentStartTime,
+			"segment_step":             checkpoint.SegmentStep,
+			"finished":                 checkpoint.Finished,
+			"update_at":                checkpoint.UpdateAt,
+		},
+		"$setOnInsert": bson.M{
+			"create_at": checkpoint.CreateAt,
+		},
+	}
+	err := repo.coll().UpdateOne(context.Background(), query, update, upsertOpt)
+	if err == qmgo.ErrNoSuchDocuments { // inserted by upsert
+		return nil
+	}
+	return err
+}
+
+func (repo *StatisticsCheckpointRepo) DeleteByTaskName(taskName string) error {
+	_, err := repo.coll().RemoveAll(context.Background(), bson.M{"task_name": taskName})
+	return err
+}
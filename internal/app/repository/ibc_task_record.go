@@ -13,6 +13,7 @@ type ITaskRecordRepo interface {
 	FindByTaskName(taskName string) (*entity.IbcTaskRecord, error)
 	Insert(record *entity.IbcTaskRecord) error
 	UpdateHeight(taskName string, height int64) error
+	UpdateResumeToken(taskName string, token bson.Raw) error
 }
 
 var _ ITaskRecordRepo = new(TaskRecordRepo)
@@ -47,3 +48,20 @@ func (repo *TaskRecordRepo) UpdateHeight(taskName string, height int64) error {
 		},
 	})
 }
+
+// UpdateResumeToken the token is removed if it is empty
+func (repo *TaskRecordRepo) UpdateResumeToken(taskName string, token bson.Raw) error {
+	update := bson.M{
+		"$set": bson.M{
+			"resume_token": token,
+			"update_at":    time.Now().Unix(),
+		},
+	}
+	if len(token) == 0 {
+		update = bson.M{
+			"$set":   bson.M{"update_at": time.Now().Unix()},
+			"$unset": bson.M{"resume_token": ""},
+		}
+	}
+	return repo.coll().UpdateOne(context.Background(), bson.M{"task_name": taskName}, update)
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ITaskRunRepo interface {
+	Insert(run *entity.IbcTaskRun) error
+	Finish(run *entity.IbcTaskRun) error
+	UpdateProgress(id primitive.ObjectID, progress float64) error
+	AggrTaskRuns(startTime int64) ([]*dto.AggrTaskRunDTO, error)
+	FindRuns(taskName string, status entity.TaskRunStatus, skip, limit int64) ([]*entity.IbcTaskRun, error)
+	CountRuns(taskName string, status entity.TaskRunStatus) (int64, error)
+}
+
+var _ ITaskRunRepo = new(TaskRunRepo)
+
+type TaskRunRepo struct {
+}
+
+func (repo *TaskRunRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcTaskRun{}.CollectionName())
+}
+
+func (repo *TaskRunRepo) Insert(run *entity.IbcTaskRun) error {
+	_, err := repo.coll().InsertOne(context.Background(), run)
+	return err
+}
+
+func (repo *TaskRunRepo) Finish(run *entity.IbcTaskRun) error {
+	set := bson.M{
+		"end_time":       run.EndTime,
+		"duration":       run.Duration,
+		"status":         run.Status,
+		"exit_status":    run.ExitStatus,
+		"error_msg":      run.ErrorMsg,
+		"chain_counters": run.ChainCounters,
+		"progress":       run.Progress,
+	}
+	if run.DryRunReport != nil {
+		set["dry_run_report"] = run.DryRunReport
+	}
+	return repo.coll().UpdateId(context.Background(), run.Id, bson.M{"$set": set})
+}
+
+func (repo *TaskRunRepo) UpdateProgress(id primitive.ObjectID, progress float64) error {
+	return repo.coll().UpdateId(context.Background(), id, bson.M{"$set": bson.M{"progress": progress}})
+}
+
+// AggrTaskRuns the last run and the run counts of each task, only the runs started since startTime are counted
+func (repo *TaskRunRepo) AggrTaskRuns(startTime int64) ([]*dto.AggrTaskRunDTO, error) {
+	match := bson.M{
+		"$match": bson.M{
+			"start_time": bson.M{
+				"$gte": startTime,
+			},
+		},
+	}
+	sort := bson.M{
+		"$sort": bson.M{
+			"start_time": -1,
+		},
+	}
+	group := bson.M{
+		"$group": bson.M{
+			"_id": "$task_name",
+			"last_run": bson.M{
+				"$first": "$$ROOT",
+			},
+			"runs": bson.M{
+				"$sum": 1,
+			},
+			"failed_runs": bson.M{
+				"$sum": bson.M{
+					"$cond": bson.A{bson.M{"$eq": bson.A{"$status", entity.TaskRunStatusFailed}}, 1, 0},
+				},
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":              0,
+			"task_name":        "$_id",
+			"runs":             "$runs",
+			"failed_runs":      "$failed_runs",
+			"last_instance_id": "$last_run.instance_id",
+			"last_start_time":  "$last_run.start_time",
+			"last_end_time":    "$last_run.end_time",
+			"last_duration":    "$last_run.duration",
+			"last_status":      "$last_run.status",
+			"last_exit_status": "$last_run.exit_status",
+			"last_error_msg":   "$last_run.error_msg",
+			"last_progress":    "$last_run.progress",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, match, sort, group, project)
+	var res []*dto.AggrTaskRunDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
+
+func (repo *TaskRunRepo) parseQuery(taskName string, status entity.TaskRunStatus) bson.M {
+	query := bson.M{"task_name": taskName}
+	if status != "" {
+		query["status"] = status
+	}
+	return query
+}
+
+func (repo *TaskRunRepo) FindRuns(taskName string, status entity.TaskRunStatus, skip, limit int64) ([]*entity.IbcTaskRun, error) {
+	var res []*entity.IbcTaskRun
+	err := repo.coll().Find(context.Background(), repo.parseQuery(taskName, status)).Sort("-start_time").Skip(skip).Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *TaskRunRepo) CountRuns(taskName string, status entity.TaskRunStatus) (int64, error) {
+	return repo.coll().Find(context.Background(), repo.parseQuery(taskName, status)).Count()
+}
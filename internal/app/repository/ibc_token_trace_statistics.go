@@ -14,6 +14,8 @@ import (
 
 type ITokenTraceStatisticsRepo interface {
 	CreateNew() error
+	DropNew() error
+	ExistsNew() (bool, error)
 	SwitchColl() error
 	BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCTokenTraceStatistics) error
 	BatchSwapNew(segmentStartTime, segmentEndTime int64, batch []*entity.IBCTokenTraceStatistics) error
@@ -45,6 +47,15 @@ func (repo *TokenTraceStatisticsRepo) CreateNew() error {
 	return nil
 }
 
+// DropNew drop the new collection left by an unfinished full statistics
+func (repo *TokenTraceStatisticsRepo) DropNew() error {
+	return repo.collNew().DropCollection(context.Background())
+}
+
+func (repo *TokenTraceStatisticsRepo) ExistsNew() (bool, error) {
+	return collectionExists(entity.IBCTokenTraceStatisticsNewCollName)
+}
+
 func (repo *TokenTraceStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCTokenTraceStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCTokenTraceStatisticsCollName)},
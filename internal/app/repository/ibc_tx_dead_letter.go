@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IIbcTxDeadLetterRepo interface {
+	Save(deadLetter *entity.IbcTxDeadLetter) error
+	FindByRecordId(recordId string) (*entity.IbcTxDeadLetter, error)
+	FindList(chain string, reasonCode entity.DeadLetterReason, skip, limit int64) ([]*entity.IbcTxDeadLetter, error)
+	Count(chain string, reasonCode entity.DeadLetterReason) (int64, error)
+	Delete(recordId string) error
+}
+
+var _ IIbcTxDeadLetterRepo = new(IbcTxDeadLetterRepo)
+
+type IbcTxDeadLetterRepo struct {
+}
+
+func (repo *IbcTxDeadLetterRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcTxDeadLetter{}.CollectionName())
+}
+
+// Save a record moved to dead letter again overrides the previous one
+func (repo *IbcTxDeadLetterRepo) Save(deadLetter *entity.IbcTxDeadLetter) error {
+	upsertOpt := opts.UpdateOptions{
+		UpdateOptions: officialOpts.Update().SetUpsert(true),
+	}
+	err := repo.coll().UpdateOne(context.Background(), bson.M{"record_id": deadLetter.RecordId}, bson.M{"$set": deadLetter}, upsertOpt)
+	if err == qmgo.ErrNoSuchDocuments { // inserted by upsert
+		return nil
+	}
+	return err
+}
+
+func (repo *IbcTxDeadLetterRepo) FindByRecordId(recordId string) (*entity.IbcTxDeadLetter, error) {
+	var res entity.IbcTxDeadLetter
+	err := repo.coll().Find(context.Background(), bson.M{"record_id": recordId}).One(&res)
+	return &res, err
+}
+
+func (repo *IbcTxDeadLetterRepo) parseQuery(chain string, reasonCode entity.DeadLetterReason) bson.M {
+	query := bson.M{}
+	if chain != "" {
+		query["$or"] = []bson.M{
+			{"sc_chain": chain},
+			{"dc_chain": chain},
+		}
+	}
+	if reasonCode != "" {
+		query["reason_code"] = reasonCode
+	}
+	return query
+}
+
+func (repo *IbcTxDeadLetterRepo) FindList(chain string, reasonCode entity.DeadLetterReason, skip, limit int64) ([]*entity.IbcTxDeadLetter, error) {
+	var res []*entity.IbcTxDeadLetter
+	err := repo.coll().Find(context.Background(), repo.parseQuery(chain, reasonCode)).Sort("-create_at").Skip(skip).Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *IbcTxDeadLetterRepo) Count(chain string, reasonCode entity.DeadLetterReason) (int64, error) {
+	return repo.coll().Find(context.Background(), repo.parseQuery(chain, reasonCode)).Count()
+}
+
+func (repo *IbcTxDeadLetterRepo) Delete(recordId string) error {
+	return repo.coll().Remove(context.Background(), bson.M{"record_id": recordId})
+}
@@ -6,15 +6,26 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ITxRepo interface {
 	GetFirstTx(chain string) (*entity.Tx, error)
 	GetUpdateTimeByUpdateClient(chain, address, clientId string, startTime int64) (int64, error)
+	GetLatestUpdateClientTime(chain, clientId string, startTime int64) (int64, error)
+	CountUpdateClientTxs(chain, clientId string, startTime int64) (int64, error)
 	GetLatestRecvPacketTime(chain, address, channelId string, startTime int64) (int64, error)
 	GetChannelOpenConfirmTime(chain, channelId string) (int64, error)
 	GetTransferTx(chain string, height, limit int64) ([]*entity.Tx, error)
+	GetTransferTxByHeightRange(chain string, height, toHeight, limit int64) ([]*entity.Tx, error)
+	GetNftTransferTx(chain string, height, limit int64) ([]*entity.Tx, error)
+	GetIcaTx(chain string, height, limit int64) ([]*entity.Tx, error)
+	GetPacketFeeTx(chain string, height, limit int64) ([]*entity.Tx, error)
+	WatchInsertTx(ctx context.Context, chain string, txTypes []string, resumeToken bson.Raw) (*mongo.ChangeStream, error)
+	FindByTypesAndHeight(chain string, txTypes []string, height int64) ([]*entity.Tx, error)
 	FindByTypeAndHeight(chain, txType string, height int64) ([]*entity.Tx, error)
 	GetTxByHash(chain string, hash string) (entity.Tx, error)
 	GetTxByHashes(chain string, hashs []string) ([]*entity.Tx, error)
@@ -72,6 +83,35 @@ func (repo *TxRepo) GetUpdateTimeByUpdateClient(chain, address, clientId string,
 	return 0, nil
 }
 
+// GetLatestUpdateClientTime time of the latest update_client tx of the client since startTime, by any relayer
+func (repo *TxRepo) GetLatestUpdateClientTime(chain, clientId string, startTime int64) (int64, error) {
+	var res entity.Tx
+	query := bson.M{
+		"msgs.type":          constant.MsgTypeUpdateClient,
+		"msgs.msg.client_id": clientId,
+		"time": bson.M{
+			"$gte": startTime,
+		},
+	}
+	err := repo.coll(chain).Find(context.Background(), query).
+		Select(bson.M{"time": 1}).Sort("-time").Limit(1).One(&res)
+	if err != nil {
+		return 0, err
+	}
+	return res.Time, nil
+}
+
+func (repo *TxRepo) CountUpdateClientTxs(chain, clientId string, startTime int64) (int64, error) {
+	query := bson.M{
+		"msgs.type":          constant.MsgTypeUpdateClient,
+		"msgs.msg.client_id": clientId,
+		"time": bson.M{
+			"$gte": startTime,
+		},
+	}
+	return repo.coll(chain).Find(context.Background(), query).Count()
+}
+
 func (repo *TxRepo) GetLatestRecvPacketTime(chain, address, channelId string, startTime int64) (int64, error) {
 	var res []*entity.Tx
 	query := bson.M{
@@ -122,6 +162,97 @@ func (repo *TxRepo) GetTransferTx(chain string, height, limit int64) ([]*entity.
 	return res, err
 }
 
+func (repo *TxRepo) GetTransferTxByHeightRange(chain string, height, toHeight, limit int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"types": constant.MsgTypeTransfer,
+		"height": bson.M{
+			"$gt":  height,
+			"$lte": toHeight,
+		},
+	}
+
+	err := repo.coll(chain).Find(context.Background(), query).Sort("height").Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *TxRepo) GetNftTransferTx(chain string, height, limit int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"types": constant.MsgTypeNftTransfer,
+		"height": bson.M{
+			"$gt": height,
+		},
+	}
+
+	err := repo.coll(chain).Find(context.Background(), query).Sort("height").Limit(limit).All(&res)
+	return res, err
+}
+
+// GetIcaTx get txs of interchain account controller: register account, send packet and channel open ack
+func (repo *TxRepo) GetIcaTx(chain string, height, limit int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"types": bson.M{
+			"$in": constant.IcaControllerMsgTypes,
+		},
+		"height": bson.M{
+			"$gt": height,
+		},
+	}
+
+	err := repo.coll(chain).Find(context.Background(), query).Sort("height").Limit(limit).All(&res)
+	return res, err
+}
+
+// GetPacketFeeTx get txs of ics29 fee middleware: pay packet fee, register payee and acknowledge/timeout packet
+func (repo *TxRepo) GetPacketFeeTx(chain string, height, limit int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"types": bson.M{
+			"$in": constant.PacketFeeMsgTypes,
+		},
+		"height": bson.M{
+			"$gt": height,
+		},
+		"status": entity.TxStatusSuccess,
+	}
+
+	err := repo.coll(chain).Find(context.Background(), query).Sort("height").Limit(limit).All(&res)
+	return res, err
+}
+
+// WatchInsertTx open change stream of txs inserted into sync_{chain}_tx, only available on replica set.
+// the stream starts after resumeToken if it is not empty
+func (repo *TxRepo) WatchInsertTx(ctx context.Context, chain string, txTypes []string, resumeToken bson.Raw) (*mongo.ChangeStream, error) {
+	pipe := []bson.M{
+		{
+			"$match": bson.M{
+				"operationType":      "insert",
+				"fullDocument.types": bson.M{"$in": txTypes},
+			},
+		},
+	}
+	streamOpts := officialOpts.ChangeStream()
+	if len(resumeToken) > 0 {
+		streamOpts.SetStartAfter(resumeToken)
+	}
+	return repo.coll(chain).Watch(ctx, pipe, &opts.ChangeStreamOptions{ChangeStreamOptions: streamOpts})
+}
+
+func (repo *TxRepo) FindByTypesAndHeight(chain string, txTypes []string, height int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"types": bson.M{
+			"$in": txTypes,
+		},
+		"height": height,
+	}
+
+	err := repo.coll(chain).Find(context.Background(), query).All(&res)
+	return res, err
+}
+
 func (repo *TxRepo) FindByTypeAndHeight(chain, txType string, height int64) ([]*entity.Tx, error) {
 	var res []*entity.Tx
 	query := bson.M{
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"strings"
@@ -45,7 +46,7 @@ func (svc *AddressService) BaseInfo(chain, address string) (*vo.BaseInfoResp, er
 		return nil, errors.Wrap(err)
 	}
 
-	account, err := lcd.GetAccount(chain, address, cfg.GrpcRestGateway, cfg.LcdApiPath.AccountsPath, true)
+	account, err := lcd.GetAccount(context.Background(), cfg, address, true)
 	if err != nil {
 		return nil, errors.WrapAddrNotFoundErr(err)
 	}
@@ -101,8 +102,10 @@ func (svc *AddressService) loadAddressTxItem(chain string, tx *entity.Tx, allCha
 			switch msgType {
 			case entity.TxTypeTransfer:
 				tm := msg.TransferMsg()
-				denom = tm.Token.Denom
-				amount = tm.Token.Amount
+				if tokens := tm.AllTokens(); len(tokens) > 0 { // the first coin of ics20 v2 multi-denom transfer
+					denom = tokens[0].Denom
+					amount = tokens[0].Amount
+				}
 				port = tm.SourcePort
 				sender = tm.Sender
 				receiver = tm.Receiver
@@ -116,7 +119,7 @@ func (svc *AddressService) loadAddressTxItem(chain string, tx *entity.Tx, allCha
 			case entity.TxTypeRecvPacket:
 				tm := msg.PacketDataMsg()
 				//denom = tm.Packet.Data.Denom
-				amount = fmt.Sprint(tm.Packet.Data.Amount)
+				amount = tm.Packet.FullPathCoins()[0].Amount
 				port = tm.Packet.DestinationPort
 				sender = tm.Packet.Data.Sender
 				receiver = tm.Packet.Data.Receiver
@@ -130,13 +133,14 @@ func (svc *AddressService) loadAddressTxItem(chain string, tx *entity.Tx, allCha
 			case entity.TxTypeAckPacket, entity.TxTypeTimeoutPacket:
 				tm := msg.PacketDataMsg()
 				//denom = tm.Packet.Data.Denom
-				amount = fmt.Sprint(tm.Packet.Data.Amount)
+				coin := tm.Packet.FullPathCoins()[0]
+				amount = coin.Amount
 				port = tm.Packet.SourcePort
 				sender = tm.Packet.Data.Sender
 				receiver = tm.Packet.Data.Receiver
 				scChain = chain
 				dcChain, _, _ = ibctool.MatchDcInfo(chain, tm.Packet.SourcePort, tm.Packet.SourceChannel, allChainMap)
-				ibcDenom := ibctool.TraceDenom(tm.Packet.Data.Denom, chain, allChainMap)
+				ibcDenom := ibctool.TraceDenom(coin.Denom, chain, allChainMap)
 				baseDenom = ibcDenom.BaseDenom
 				baseDenomChain = ibcDenom.BaseDenomChain
 				denom = ibcDenom.Denom
@@ -294,7 +298,7 @@ func (svc *AddressService) TokenList(chain, address string) (*vo.AddrTokenListRe
 	gw.Add(4)
 	go func() {
 		defer gw.Done()
-		balances, err := lcd.GetBalances(chain, address, cfg.GrpcRestGateway, cfg.LcdApiPath.BalancesPath)
+		balances, err := lcd.GetBalances(context.Background(), cfg, address)
 		if err != nil {
 			logrus.Errorf("AddressService.TokenList lcd.GetBalances %s-%s err, %v", chain, address, err.Error())
 			return
@@ -348,7 +352,7 @@ func (svc *AddressService) TokenList(chain, address string) (*vo.AddrTokenListRe
 	go func() {
 		defer gw.Done()
 		//delegation, err := lcd.GetDelegation(chain, address, cfg.GrpcRestGateway, "/cosmos/staking/v1beta1/delegations/{address}")
-		delegation, err := lcd.GetDelegation(chain, address, cfg.GrpcRestGateway, cfg.LcdApiPath.DelegationPath)
+		delegation, err := lcd.GetDelegation(context.Background(), cfg, address)
 		if err != nil {
 			logrus.Errorf("AddressService.TokenList lcd.GetDelegation %s-%s err, %v", chain, address, err.Error())
 			return
@@ -370,7 +374,7 @@ func (svc *AddressService) TokenList(chain, address string) (*vo.AddrTokenListRe
 	go func() {
 		defer gw.Done()
 		//rewards, err := lcd.GetRewards(chain, address, cfg.GrpcRestGateway, "/cosmos/distribution/v1beta1/delegators/{address}/rewards")
-		rewards, err := lcd.GetRewards(chain, address, cfg.GrpcRestGateway, cfg.LcdApiPath.RewardsPath)
+		rewards, err := lcd.GetRewards(context.Background(), cfg, address)
 		if err != nil {
 			logrus.Errorf("AddressService.TokenList lcd.GetRewards %s-%s err, %v", chain, address, err.Error())
 			return
@@ -392,7 +396,7 @@ func (svc *AddressService) TokenList(chain, address string) (*vo.AddrTokenListRe
 	go func() {
 		defer gw.Done()
 		//unbonding, err := lcd.GetUnbonding(chain, address, cfg.GrpcRestGateway, "/cosmos/staking/v1beta1/delegators/{address}/unbonding_delegations")
-		unbonding, err := lcd.GetUnbonding(chain, address, cfg.GrpcRestGateway, cfg.LcdApiPath.UnbondingPath)
+		unbonding, err := lcd.GetUnbonding(context.Background(), cfg, address)
 		if err != nil {
 			logrus.Errorf("AddressService.TokenList lcd.GetUnbonding %s-%s err, %v", chain, address, err.Error())
 			return
@@ -474,7 +478,7 @@ func (svc *AddressService) AccountList(chain, address string) (*vo.AccountListRe
 
 		return nil, errors.Wrap(err)
 	}
-	account, err := lcd.GetAccount(chain, address, cfg.GrpcRestGateway, cfg.LcdApiPath.AccountsPath, false)
+	account, err := lcd.GetAccount(context.Background(), cfg, address, false)
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
@@ -506,6 +510,7 @@ func (svc *AddressService) AccountList(chain, address string) (*vo.AccountListRe
 		}
 		chainsAddrInfo = append(chainsAddrInfo, AccountCfg{
 			Address:         addr,
+			ChainConfig:     val,
 			GrpcRestGateway: val.GrpcRestGateway,
 			BalancesPath:    val.LcdApiPath.BalancesPath,
 			AccountsPath:    val.LcdApiPath.AccountsPath,
@@ -522,10 +527,10 @@ func (svc *AddressService) AccountList(chain, address string) (*vo.AccountListRe
 }
 
 func (svc *AddressService) doHandleAddrTokenInfo(workNum int, addrCfgs []AccountCfg) (*vo.AccountListResp, errors.Error) {
-	checkValidAddrOk := func(chain, address, lcduri, accountsPath string) bool {
-		_, err := lcd.GetAccount(chain, address, lcduri, accountsPath, false)
+	checkValidAddrOk := func(cfg *entity.ChainConfig, address string) bool {
+		_, err := lcd.GetAccount(context.Background(), cfg, address, false)
 		if err != nil {
-			logrus.Errorf("AddressService.doHandleAddrTokenInfo lcd.GetAccount %s-%s err, %v", chain, address, err.Error())
+			logrus.Errorf("AddressService.doHandleAddrTokenInfo lcd.GetAccount %s-%s err, %v", cfg.ChainName, address, err.Error())
 			return false
 		}
 		return true
@@ -553,7 +558,7 @@ func (svc *AddressService) doHandleAddrTokenInfo(workNum int, addrCfgs []Account
 				if id%workNum != num {
 					continue
 				}
-				if !checkValidAddrOk(v.Chain, v.Address, v.GrpcRestGateway, v.AccountsPath) {
+				if !checkValidAddrOk(v.ChainConfig, v.Address) {
 					continue
 				}
 				logrus.Infof("task %d get token list chain(%s) address(%s)", num, v.Chain, v.Address)
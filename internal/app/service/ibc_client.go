@@ -0,0 +1,72 @@
+package service
+
+import (
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+const expiringClientsDefaultWithin = 7 * 86400
+
+type IIbcClientService interface {
+	ClientsCount(req *vo.IbcClientsReq) (int64, errors.Error)
+	Clients(req *vo.IbcClientsReq) (vo.IbcClientsResp, errors.Error)
+	ExpiringClients(req *vo.ExpiringClientsReq) (vo.ExpiringClientsResp, errors.Error)
+}
+
+var _ IIbcClientService = new(IbcClientService)
+
+type IbcClientService struct {
+}
+
+func (svc *IbcClientService) ClientsCount(req *vo.IbcClientsReq) (int64, errors.Error) {
+	count, err := ibcClientRepo.CountClients(req.Chain, entity.ClientStatus(req.Status))
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc *IbcClientService) Clients(req *vo.IbcClientsReq) (vo.IbcClientsResp, errors.Error) {
+	var resp vo.IbcClientsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	res, err := ibcClientRepo.FindClients(req.Chain, entity.ClientStatus(req.Status), skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+
+	now := time.Now().Unix()
+	items := make([]vo.IbcClientDto, 0, len(res))
+	for _, v := range res {
+		items = append(items, vo.LoadIbcClientDto(v, now))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = now
+	return resp, nil
+}
+
+// ExpiringClients clients expiring within req.Within seconds, 7 days by default
+func (svc *IbcClientService) ExpiringClients(req *vo.ExpiringClientsReq) (vo.ExpiringClientsResp, errors.Error) {
+	var resp vo.ExpiringClientsResp
+	within := req.Within
+	if within <= 0 {
+		within = expiringClientsDefaultWithin
+	}
+
+	now := time.Now().Unix()
+	res, err := ibcClientRepo.FindExpiring(req.Chain, now+within)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+
+	items := make([]vo.IbcClientDto, 0, len(res))
+	for _, v := range res {
+		items = append(items, vo.LoadIbcClientDto(v, now))
+	}
+	resp.Items = items
+	resp.TimeStamp = now
+	return resp, nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+type IIcaService interface {
+	ControllerIcaTxsCount(address string, req *vo.IcaTxsReq) (int64, errors.Error)
+	ControllerIcaTxs(address string, req *vo.IcaTxsReq) (vo.IcaTxsResp, errors.Error)
+	HostIcaTxsCount(chain string, req *vo.IcaTxsReq) (int64, errors.Error)
+	HostIcaTxs(chain string, req *vo.IcaTxsReq) (vo.IcaTxsResp, errors.Error)
+	IcaTxDetail(hash string) (*vo.IcaTxDetailResp, errors.Error)
+	IcaAccountsCount(req *vo.IcaAccountsReq) (int64, errors.Error)
+	IcaAccounts(req *vo.IcaAccountsReq) (vo.IcaAccountsResp, errors.Error)
+}
+
+var _ IIcaService = new(IcaService)
+
+type IcaService struct {
+	dto vo.IbcIcaTxDto
+}
+
+// createIbcIcaTxQuery chain in req is the counterparty chain of the listed side
+func createIbcIcaTxQuery(req *vo.IcaTxsReq) (dto.IbcIcaTxQuery, error) {
+	var (
+		query dto.IbcIcaTxQuery
+		err   error
+	)
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) == 2 {
+			query.StartTime, err = strconv.ParseInt(dateRange[0], 10, 64)
+			if err != nil {
+				return query, err
+			}
+			query.EndTime, err = strconv.ParseInt(dateRange[1], 10, 64)
+			if err != nil {
+				return query, err
+			}
+		}
+	}
+	if req.Status != "" {
+		stats := strings.Split(req.Status, ",")
+		for _, val := range stats {
+			stat, err := strconv.Atoi(val)
+			if err != nil {
+				return query, err
+			}
+			query.Status = append(query.Status, stat)
+		}
+	}
+	return query, nil
+}
+
+func (svc *IcaService) controllerQuery(address string, req *vo.IcaTxsReq) (dto.IbcIcaTxQuery, error) {
+	query, err := createIbcIcaTxQuery(req)
+	if err != nil {
+		return query, err
+	}
+	query.ControllerAddress = address
+	query.HostChain = req.Chain
+	return query, nil
+}
+
+func (svc *IcaService) hostQuery(chain string, req *vo.IcaTxsReq) (dto.IbcIcaTxQuery, error) {
+	query, err := createIbcIcaTxQuery(req)
+	if err != nil {
+		return query, err
+	}
+	query.HostChain = chain
+	query.ControllerChain = req.Chain
+	return query, nil
+}
+
+func (svc *IcaService) countIcaTxs(query dto.IbcIcaTxQuery) (int64, errors.Error) {
+	count, err := ibcIcaTxRepo.CountIcaTxs(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc *IcaService) findIcaTxs(query dto.IbcIcaTxQuery, page vo.Page) (vo.IcaTxsResp, errors.Error) {
+	var resp vo.IcaTxsResp
+	skip, limit := vo.ParseParamPage(page.PageNum, page.PageSize)
+	res, err := ibcIcaTxRepo.FindIcaTxs(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.IbcIcaTxDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.dto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), page.PageNum, page.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+func (svc *IcaService) ControllerIcaTxsCount(address string, req *vo.IcaTxsReq) (int64, errors.Error) {
+	query, err := svc.controllerQuery(address, req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	return svc.countIcaTxs(query)
+}
+
+func (svc *IcaService) ControllerIcaTxs(address string, req *vo.IcaTxsReq) (vo.IcaTxsResp, errors.Error) {
+	query, err := svc.controllerQuery(address, req)
+	if err != nil {
+		return vo.IcaTxsResp{}, errors.WrapBadRequest(err)
+	}
+	return svc.findIcaTxs(query, req.Page)
+}
+
+func (svc *IcaService) HostIcaTxsCount(chain string, req *vo.IcaTxsReq) (int64, errors.Error) {
+	query, err := svc.hostQuery(chain, req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	return svc.countIcaTxs(query)
+}
+
+func (svc *IcaService) HostIcaTxs(chain string, req *vo.IcaTxsReq) (vo.IcaTxsResp, errors.Error) {
+	query, err := svc.hostQuery(chain, req)
+	if err != nil {
+		return vo.IcaTxsResp{}, errors.WrapBadRequest(err)
+	}
+	return svc.findIcaTxs(query, req.Page)
+}
+
+func (svc *IcaService) IcaTxDetail(hash string) (*vo.IcaTxDetailResp, errors.Error) {
+	var resp vo.IcaTxDetailResp
+	ibcIcaTxs, err := ibcIcaTxRepo.TxDetail(hash)
+	if err != nil && err != qmgo.ErrNoSuchDocuments {
+		return nil, errors.Wrap(err)
+	}
+	if len(ibcIcaTxs) == 0 {
+		return nil, nil
+	}
+
+	if len(ibcIcaTxs) == 1 {
+		resp = vo.LoadIcaTxDetail(ibcIcaTxs[0])
+		resp.RelayerInfo, err = loadRelayerInfo(ibcIcaTxs[0].ScChain, ibcIcaTxs[0].DcChain, ibcIcaTxs[0].DcTxInfo, ibcIcaTxs[0].AckTimeoutTxInfo)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+	} else {
+		resp.IsList = true
+		for _, val := range ibcIcaTxs {
+			resp.Items = append(resp.Items, svc.dto.LoadDto(val))
+		}
+	}
+	resp.TimeStamp = time.Now().Unix()
+	return &resp, nil
+}
+
+func (svc *IcaService) IcaAccountsCount(req *vo.IcaAccountsReq) (int64, errors.Error) {
+	count, err := icaAccountRepo.CountAccounts(req.ControllerChain, req.ControllerAddress, req.HostChain)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc *IcaService) IcaAccounts(req *vo.IcaAccountsReq) (vo.IcaAccountsResp, errors.Error) {
+	var resp vo.IcaAccountsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	res, err := icaAccountRepo.FindAccounts(req.ControllerChain, req.ControllerAddress, req.HostChain, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+
+	items := make([]vo.IcaAccountDto, 0, len(res))
+	for _, v := range res {
+		items = append(items, vo.LoadIcaAccountDto(v))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+type INftTransferService interface {
+	NftTransferTxsCount(req *vo.NftTransferTxsReq) (int64, errors.Error)
+	NftTransferTxs(req *vo.NftTransferTxsReq) (vo.NftTransferTxsResp, errors.Error)
+	NftTransferTxDetail(hash string) (*vo.NftTransferTxDetailResp, errors.Error)
+	NftClassesCount(req *vo.NftClassListReq) (int64, errors.Error)
+	NftClasses(req *vo.NftClassListReq) (vo.NftClassListResp, errors.Error)
+}
+
+var _ INftTransferService = new(NftTransferService)
+
+type NftTransferService struct {
+	dto vo.IbcNftTxDto
+}
+
+func createIbcNftTxQuery(req *vo.NftTransferTxsReq) (dto.IbcNftTxQuery, error) {
+	var (
+		query dto.IbcNftTxQuery
+		err   error
+	)
+	if req.Chain != "" {
+		query.Chain = strings.Split(req.Chain, ",")
+	}
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) == 2 {
+			query.StartTime, err = strconv.ParseInt(dateRange[0], 10, 64)
+			if err != nil {
+				return query, err
+			}
+			query.EndTime, err = strconv.ParseInt(dateRange[1], 10, 64)
+			if err != nil {
+				return query, err
+			}
+		}
+	}
+	if req.Status != "" {
+		stats := strings.Split(req.Status, ",")
+		for _, val := range stats {
+			stat, err := strconv.Atoi(val)
+			if err != nil {
+				return query, err
+			}
+			query.Status = append(query.Status, stat)
+		}
+	}
+
+	query.ClassId = req.ClassId
+	query.BaseClassId = req.BaseClassId
+	query.BaseClassChain = req.BaseClassChain
+	return query, nil
+}
+
+func (svc *NftTransferService) NftTransferTxsCount(req *vo.NftTransferTxsReq) (int64, errors.Error) {
+	query, err := createIbcNftTxQuery(req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	if len(query.Chain) > 2 {
+		return 0, nil
+	}
+
+	count, err := ibcNftTxRepo.CountTransferTxs(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc *NftTransferService) NftTransferTxs(req *vo.NftTransferTxsReq) (vo.NftTransferTxsResp, errors.Error) {
+	var resp vo.NftTransferTxsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	query, err := createIbcNftTxQuery(req)
+	if err != nil {
+		return resp, errors.WrapBadRequest(err)
+	}
+	if len(query.Chain) > 2 {
+		return resp, nil
+	}
+
+	res, err := ibcNftTxRepo.FindTransferTxs(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.IbcNftTxDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.dto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+func (svc *NftTransferService) NftTransferTxDetail(hash string) (*vo.NftTransferTxDetailResp, errors.Error) {
+	var resp vo.NftTransferTxDetailResp
+	ibcNftTxs, err := ibcNftTxRepo.TxDetail(hash)
+	if err != nil && err != qmgo.ErrNoSuchDocuments {
+		return nil, errors.Wrap(err)
+	}
+	if len(ibcNftTxs) == 0 {
+		return nil, nil
+	}
+
+	if len(ibcNftTxs) == 1 {
+		resp = vo.LoadNftTransferTxDetail(ibcNftTxs[0])
+		resp.RelayerInfo, err = loadRelayerInfo(ibcNftTxs[0].ScChain, ibcNftTxs[0].DcChain, ibcNftTxs[0].DcTxInfo, ibcNftTxs[0].AckTimeoutTxInfo)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+	} else {
+		resp.IsList = true
+		for _, val := range ibcNftTxs {
+			resp.Items = append(resp.Items, svc.dto.LoadDto(val))
+		}
+	}
+	resp.TimeStamp = time.Now().Unix()
+	return &resp, nil
+}
+
+func (svc *NftTransferService) NftClassesCount(req *vo.NftClassListReq) (int64, errors.Error) {
+	count, err := ibcNftTxRepo.CountClasses(req.Chain)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc *NftTransferService) NftClasses(req *vo.NftClassListReq) (vo.NftClassListResp, errors.Error) {
+	var resp vo.NftClassListResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	res, err := ibcNftTxRepo.AggrClasses(req.Chain, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+
+	items := make([]vo.NftClassDto, 0, len(res))
+	for _, v := range res {
+		items = append(items, vo.NftClassDto{
+			BaseClassId:    v.BaseClassId,
+			BaseClassChain: v.BaseClassChain,
+			TxsCount:       v.TxsCount,
+			TokensCount:    v.TokensCount,
+			LatestTxTime:   v.LatestTxTime,
+		})
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
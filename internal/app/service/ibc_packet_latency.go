@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils/umath"
+)
+
+const (
+	packetLatencyHourDefaultDays = 7
+	packetLatencyDayDefaultDays  = 30
+)
+
+type ILatencyService interface {
+	ChannelLatencyTrend(req *vo.ChannelLatencyTrendReq) (*vo.PacketLatencyTrendResp, errors.Error)
+	RelayerLatencyTrend(relayerId string, req *vo.RelayerLatencyTrendReq) (*vo.PacketLatencyTrendResp, errors.Error)
+}
+
+var _ ILatencyService = new(LatencyService)
+
+type LatencyService struct {
+}
+
+func (svc *LatencyService) ChannelLatencyTrend(req *vo.ChannelLatencyTrendReq) (*vo.PacketLatencyTrendResp, errors.Error) {
+	period, startTime, endTime, e := svc.parseParam(req.Period, req.DateRange)
+	if e != nil {
+		return nil, e
+	}
+
+	list, err := packetLatencyRepo.FindByChannel(req.Chain, req.Channel, period, startTime, endTime)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return &vo.PacketLatencyTrendResp{
+		Items:     svc.mergeStatistics(list),
+		TimeStamp: time.Now().Unix(),
+	}, nil
+}
+
+func (svc *LatencyService) RelayerLatencyTrend(relayerId string, req *vo.RelayerLatencyTrendReq) (*vo.PacketLatencyTrendResp, errors.Error) {
+	period, startTime, endTime, e := svc.parseParam(req.Period, req.DateRange)
+	if e != nil {
+		return nil, e
+	}
+
+	relayer, err := relayerRepo.FindOneByRelayerId(relayerId)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	addrCombs := entity.ChannelPairInfoList(relayer.ChannelPairInfo).GetChainAddrCombs()
+	items := make([]vo.PacketLatencyTrendItem, 0)
+	if len(addrCombs) > 0 {
+		list, err := packetLatencyRepo.FindByRelayer(addrCombs, period, startTime, endTime)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		items = svc.mergeStatistics(list)
+	}
+	return &vo.PacketLatencyTrendResp{
+		Items:     items,
+		TimeStamp: time.Now().Unix(),
+	}, nil
+}
+
+// parseParam period is hour by default, date_range is the last 7 days for hour and the last 30 days for day by default
+func (svc *LatencyService) parseParam(periodParam, dateRangeParam string) (entity.PacketLatencyPeriod, int64, int64, errors.Error) {
+	period := entity.PacketLatencyPeriod(periodParam)
+	defaultDays := packetLatencyHourDefaultDays
+	switch period {
+	case "", entity.PacketLatencyPeriodHour:
+		period = entity.PacketLatencyPeriodHour
+	case entity.PacketLatencyPeriodDay:
+		defaultDays = packetLatencyDayDefaultDays
+	default:
+		return "", 0, 0, errors.WrapBadRequest(fmt.Errorf("invalid period: %s", periodParam))
+	}
+
+	if dateRangeParam == "" {
+		endTime := time.Now().Unix()
+		return period, endTime - int64(defaultDays)*24*3600, endTime, nil
+	}
+
+	dateRange := strings.Split(dateRangeParam, ",")
+	if len(dateRange) != 2 {
+		return "", 0, 0, errors.WrapBadRequest(fmt.Errorf("invalid date_range: %s", dateRangeParam))
+	}
+	startTime, err := strconv.ParseInt(dateRange[0], 10, 64)
+	if err != nil {
+		return "", 0, 0, errors.WrapBadRequest(err)
+	}
+	endTime, err := strconv.ParseInt(dateRange[1], 10, 64)
+	if err != nil {
+		return "", 0, 0, errors.WrapBadRequest(err)
+	}
+	return period, startTime, endTime, nil
+}
+
+// mergeStatistics statistics of different relayers are merged by segment, channel pair and stage. the percentiles of
+// a single row are exact, those of merged rows are estimated from the summed histogram.
+func (svc *LatencyService) mergeStatistics(list []*entity.IBCPacketLatencyStatistics) []vo.PacketLatencyTrendItem {
+	groupMap := make(map[string][]*entity.IBCPacketLatencyStatistics)
+	var keys []string
+	for _, v := range list {
+		key := fmt.Sprintf("%d%s%s%s%s%s", v.SegmentStartTime, v.ScChain, v.ScChannel, v.DcChain, v.DcChannel, v.Stage)
+		if _, ok := groupMap[key]; !ok {
+			keys = append(keys, key)
+		}
+		groupMap[key] = append(groupMap[key], v)
+	}
+
+	items := make([]vo.PacketLatencyTrendItem, 0, len(keys))
+	for _, key := range keys {
+		group := groupMap[key]
+		first := group[0]
+		item := vo.PacketLatencyTrendItem{
+			SegmentStartTime: first.SegmentStartTime,
+			ScChain:          first.ScChain,
+			ScChannel:        first.ScChannel,
+			DcChain:          first.DcChain,
+			DcChannel:        first.DcChannel,
+			Stage:            string(first.Stage),
+			TxsNumber:        first.TxsNumber,
+			P50:              first.P50,
+			P90:              first.P90,
+			P99:              first.P99,
+		}
+		if len(group) > 1 {
+			histogram := make([]int64, len(entity.PacketLatencyBuckets)+1)
+			item.TxsNumber = 0
+			for _, v := range group {
+				item.TxsNumber += v.TxsNumber
+				for i := range v.Histogram {
+					if i < len(histogram) {
+						histogram[i] += v.Histogram[i]
+					}
+				}
+			}
+			item.P50 = umath.HistogramPercentile(entity.PacketLatencyBuckets, histogram, 50)
+			item.P90 = umath.HistogramPercentile(entity.PacketLatencyBuckets, histogram, 90)
+			item.P99 = umath.HistogramPercentile(entity.PacketLatencyBuckets, histogram, 99)
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +18,7 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/qiniu/qmgo"
@@ -29,6 +32,7 @@ type ITransferService interface {
 	TransferTxDetailNew(hash string) (*vo.TranaferTxDetailNewResp, errors.Error)
 	TraceSource(hash string, req *vo.TraceSourceReq) (vo.TraceSourceResp, errors.Error)
 	SearchCondition() (*vo.SearchConditionResp, errors.Error)
+	FailureStatistics(req *vo.TransferFailureStatisticsReq) (*vo.TransferFailureStatisticsResp, errors.Error)
 }
 
 var _ ITransferService = new(TransferService)
@@ -105,7 +109,7 @@ func (t TransferService) TransferTxsCount(req *vo.TranaferTxsReq) (*vo.TransferT
 			return count
 		}
 		//default cond
-		if len(query.Chain) == 0 && len(query.Status) == 4 && query.StartTime == 0 && len(query.BaseDenom) == 0 && query.Denom == "" {
+		if len(query.Chain) == 0 && len(query.Status) == len(entity.IbcTxUsefulStatus) && query.StartTime == 0 && len(query.BaseDenom) == 0 && query.Denom == "" {
 			data, err2 := statisticRepo.FindOne(constant.TxLatestAllStatisticName)
 			if err2 != nil {
 				txsCountChan <- &vo.TxsCountChanDTO{Count: 0, Err: err2}
@@ -204,16 +208,25 @@ func (t TransferService) TransferTxDetailNew(hash string) (*vo.TranaferTxDetailN
 		return nil, nil
 	}
 
-	if len(ibcTxs) == 1 {
-		resp = vo.LoadTranaferTxDetail(ibcTxs[0])
-		resp.RelayerInfo, err = getRelayerInfo(ibcTxs[0])
+	// the recv tx on intermediate chain of a multi-hop transfer matches two hops, show the whole journey instead of list
+	journeyId := sameJourneyId(ibcTxs)
+	if len(ibcTxs) == 1 || journeyId != "" {
+		ibcTx := matchJourneyHop(hash, ibcTxs)
+		resp = vo.LoadTranaferTxDetail(ibcTx)
+		resp.RelayerInfo, err = getRelayerInfo(ibcTx)
 		if err != nil {
 			return nil, errors.Wrap(err)
 		}
-		resp.TokenInfo, err = getTokenInfo(ibcTxs[0])
+		resp.TokenInfo, err = getTokenInfo(ibcTx)
 		if err != nil {
 			return nil, errors.Wrap(err)
 		}
+		if ibcTx.JourneyId != "" {
+			resp.Journey, err = getJourney(ibcTx.JourneyId)
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
 	} else if len(ibcTxs) > 1 {
 		resp.IsList = true
 		for _, val := range ibcTxs {
@@ -225,26 +238,77 @@ func (t TransferService) TransferTxDetailNew(hash string) (*vo.TranaferTxDetailN
 	return &resp, nil
 }
 
+// sameJourneyId return the journey id if all the ibc txs are hops of the same journey
+func sameJourneyId(ibcTxs []*entity.ExIbcTx) string {
+	if len(ibcTxs) == 0 {
+		return ""
+	}
+	journeyId := ibcTxs[0].JourneyId
+	for _, v := range ibcTxs[1:] {
+		if v.JourneyId != journeyId {
+			return ""
+		}
+	}
+	return journeyId
+}
+
+// matchJourneyHop prefer the hop sent by the tx
+func matchJourneyHop(hash string, ibcTxs []*entity.ExIbcTx) *entity.ExIbcTx {
+	for _, v := range ibcTxs {
+		if v.ScTxInfo != nil && v.ScTxInfo.Hash == hash {
+			return v
+		}
+	}
+	return ibcTxs[0]
+}
+
+// getJourney hops of a journey may be split into latest and history collection by migration
+func getJourney(journeyId string) (*vo.Journey, error) {
+	latestTxs, err := ibcTxRepo.FindByJourneyId(journeyId, false)
+	if err != nil {
+		return nil, err
+	}
+	historyTxs, err := ibcTxRepo.FindByJourneyId(journeyId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]*entity.ExIbcTx, 0, len(latestTxs)+len(historyTxs))
+	recordIdMap := make(map[string]struct{}, len(latestTxs)+len(historyTxs))
+	for _, v := range append(historyTxs, latestTxs...) {
+		if _, ok := recordIdMap[v.RecordId]; ok {
+			continue
+		}
+		recordIdMap[v.RecordId] = struct{}{}
+		hops = append(hops, v)
+	}
+	return vo.LoadJourney(journeyId, hops), nil
+}
+
 func getRelayerInfo(val *entity.ExIbcTx) (*vo.RelayerInfo, error) {
+	return loadRelayerInfo(val.ScChain, val.DcChain, val.DcTxInfo, val.AckTimeoutTxInfo)
+}
+
+func loadRelayerInfo(scChain, dcChain string, dcTxInfo, ackTimeoutTxInfo *entity.TxInfo) (*vo.RelayerInfo, error) {
 	relayerMap, err := getRelayerMap()
 	if err != nil {
 		return nil, err
 	}
-	if val.DcTxInfo == nil && val.AckTimeoutTxInfo == nil {
+	if dcTxInfo == nil && ackTimeoutTxInfo == nil {
 		return nil, nil
 	}
 	var relayerInfo vo.RelayerInfo
-	if val.DcTxInfo != nil && val.DcTxInfo.Msg != nil {
-		dcRelayerAddr := val.DcTxInfo.Msg.CommonMsg().Signer
+	if dcTxInfo != nil && dcTxInfo.Msg != nil {
+		dcRelayerAddr := dcTxInfo.Msg.CommonMsg().Signer
 		relayerInfo.DcRelayer.RelayerAddr = dcRelayerAddr
 	}
-	if val.AckTimeoutTxInfo != nil && val.AckTimeoutTxInfo.Msg != nil {
-		scRelayerAddr := val.AckTimeoutTxInfo.Msg.CommonMsg().Signer
+	if ackTimeoutTxInfo != nil && ackTimeoutTxInfo.Msg != nil {
+		scRelayerAddr := ackTimeoutTxInfo.Msg.CommonMsg().Signer
 		relayerInfo.ScRelayer.RelayerAddr = scRelayerAddr
 	}
-	chainA, _ := entity.ConfirmRelayerPair(val.ScChain, val.DcChain)
+	chainA, _ := entity.ConfirmRelayerPair(scChain, dcChain)
 	matchInfo := strings.Join([]string{relayerInfo.ScRelayer.RelayerAddr, relayerInfo.DcRelayer.RelayerAddr}, ":")
-	if chainA != val.ScChain {
+	if chainA != scChain {
 		matchInfo = strings.Join([]string{relayerInfo.DcRelayer.RelayerAddr, relayerInfo.ScRelayer.RelayerAddr}, ":")
 	}
 	if value, ok := relayerMap[matchInfo]; ok {
@@ -276,16 +340,37 @@ func getRelayerMap() (map[string]entity.IBCRelayerNew, error) {
 }
 
 func getTokenInfo(ibcTx *entity.ExIbcTx) (*vo.TokenInfo, error) {
+	var amount string
+	if ibcTx.ScTxInfo != nil && ibcTx.ScTxInfo.MsgAmount != nil {
+		amount = ibcTx.ScTxInfo.MsgAmount.Amount
+	}
+	tokenInfo, err := loadTokenInfo(ibcTx, ibcTx.Denoms.ScDenom, ibcTx.Denoms.DcDenom, ibcTx.BaseDenom, ibcTx.BaseDenomChain, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// ics20 v2 multi-denom transfer
+	for _, v := range ibcTx.Tokens {
+		token, err := loadTokenInfo(ibcTx, v.ScDenom, v.DcDenom, v.BaseDenom, v.BaseDenomChain, v.Amount)
+		if err != nil {
+			return nil, err
+		}
+		tokenInfo.Tokens = append(tokenInfo.Tokens, token)
+	}
+	return tokenInfo, nil
+}
+
+func loadTokenInfo(ibcTx *entity.ExIbcTx, scDenom, dcDenom, baseDenom, baseDenomChain, amount string) (*vo.TokenInfo, error) {
 	var (
 		sendToken = vo.DetailToken{
-			Denom: ibcTx.Denoms.ScDenom,
+			Denom: scDenom,
 		}
 		recvToken = vo.DetailToken{
-			Denom: ibcTx.Denoms.DcDenom,
+			Denom: dcDenom,
 		}
 	)
-	if strings.HasPrefix(ibcTx.Denoms.ScDenom, "ibc/") {
-		denom, err := denomRepo.FindByDenomChain(ibcTx.Denoms.ScDenom, ibcTx.ScChain)
+	if strings.HasPrefix(scDenom, "ibc/") {
+		denom, err := denomRepo.FindByDenomChain(scDenom, ibcTx.ScChain)
 		if err != nil && err != qmgo.ErrNoSuchDocuments {
 			return nil, err
 		}
@@ -293,8 +378,8 @@ func getTokenInfo(ibcTx *entity.ExIbcTx) (*vo.TokenInfo, error) {
 			sendToken.DenomPath = strings.Join([]string{denom.DenomPath, denom.RootDenom}, "/")
 		}
 	}
-	if strings.HasPrefix(ibcTx.Denoms.DcDenom, "ibc/") {
-		denom, err := denomRepo.FindByDenomChain(ibcTx.Denoms.DcDenom, ibcTx.DcChain)
+	if strings.HasPrefix(dcDenom, "ibc/") {
+		denom, err := denomRepo.FindByDenomChain(dcDenom, ibcTx.DcChain)
 		if err != nil && err != qmgo.ErrNoSuchDocuments {
 			return nil, err
 		}
@@ -303,9 +388,9 @@ func getTokenInfo(ibcTx *entity.ExIbcTx) (*vo.TokenInfo, error) {
 		}
 	}
 	return &vo.TokenInfo{
-		BaseDenom:      ibcTx.BaseDenom,
-		BaseDenomChain: ibcTx.BaseDenomChain,
-		Amount:         ibcTx.ScTxInfo.MsgAmount.Amount,
+		BaseDenom:      baseDenom,
+		BaseDenomChain: baseDenomChain,
+		Amount:         amount,
 		SendToken:      sendToken,
 		RecvToken:      recvToken,
 	}, nil
@@ -397,7 +482,23 @@ func getMsgAndTxData(msgType, chain, hash string) (vo.TraceSourceResp, errors.Er
 	return resp, nil
 }
 
+// GetLcdTxData the tx is queried from the lcd nodes, the rpc nodes are tried if the lcd nodes fail(eg: the tx api is
+// pruned)
 func GetLcdTxData(chain, hash string) (LcdTxData, errors.Error) {
+	txData, err := getLcdTxData(chain, hash)
+	if err == nil {
+		return txData, nil
+	}
+
+	rpcTxData, rpcErr := GetTxDataFromRpc(chain, hash)
+	if rpcErr != nil {
+		logrus.Warningf("chain %s get tx %s from rpc error, %v", chain, hash, rpcErr)
+		return txData, err
+	}
+	return rpcTxData, nil
+}
+
+func getLcdTxData(chain, hash string) (LcdTxData, errors.Error) {
 	lcdAddrs, _ := lcdAddrCache.Get(chain)
 	if len(lcdAddrs) > 0 {
 		//全节点且支持交易查询
@@ -485,6 +586,30 @@ func GetTxDataFromChain(lcdUri string, hash string) (LcdTxData, errors.Error) {
 	return txData, nil
 }
 
+// GetTxDataFromRpc query the tx by /tx of the rpc nodes, it is converted to the format of lcd
+func GetTxDataFromRpc(chain, hash string) (LcdTxData, errors.Error) {
+	var txData LcdTxData
+	rpcTx, err := lcd.GetTxByHash(context.Background(), chain, hash)
+	if err != nil {
+		return txData, errors.WrapLcdNodeErr(err.Error())
+	}
+
+	for _, v := range rpcTx.Logs {
+		txData.TxResponse.Logs = append(txData.TxResponse.Logs, LogData{MsgIndex: v.MsgIndex, Events: v.Events})
+	}
+	for _, v := range rpcTx.Messages {
+		var msg LcdMessage
+		if err = json.Unmarshal(v, &msg); err != nil {
+			return LcdTxData{}, errors.Wrap(err)
+		}
+		txData.TxResponse.Tx.Body.Messages = append(txData.TxResponse.Tx.Body.Messages, msg)
+	}
+	if blockTime, err := lcd.GetBlockTime(context.Background(), chain, rpcTx.Height); err == nil {
+		txData.TxResponse.Timestamp = blockTime
+	}
+	return txData, nil
+}
+
 func (t TransferService) SearchCondition() (*vo.SearchConditionResp, errors.Error) {
 	txTime, err := ibcTxRepo.GetMinTxTime(false)
 	if err != nil {
@@ -493,3 +618,61 @@ func (t TransferService) SearchCondition() (*vo.SearchConditionResp, errors.Erro
 
 	return &vo.SearchConditionResp{TxTimeMin: txTime}, nil
 }
+
+// FailureStatistics failed transfers of latest and history data are counted together by channel pair and error category
+func (t TransferService) FailureStatistics(req *vo.TransferFailureStatisticsReq) (*vo.TransferFailureStatisticsResp, errors.Error) {
+	var startTime, endTime int64
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) != 2 {
+			return nil, errors.WrapBadRequest(fmt.Errorf("invalid date_range: %s", req.DateRange))
+		}
+		var err error
+		if startTime, err = strconv.ParseInt(dateRange[0], 10, 64); err != nil {
+			return nil, errors.WrapBadRequest(err)
+		}
+		if endTime, err = strconv.ParseInt(dateRange[1], 10, 64); err != nil {
+			return nil, errors.WrapBadRequest(err)
+		}
+	}
+
+	latestList, err := ibcTxRepo.AggrErrorCategory(req.Chain, startTime, endTime, false)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	historyList, err := ibcTxRepo.AggrErrorCategory(req.Chain, startTime, endTime, true)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	itemMap := make(map[string]*vo.TransferFailureStatisticsItem)
+	var keys []string
+	for _, v := range append(latestList, historyList...) {
+		key := fmt.Sprintf("%s%s%s%s%s", v.ScChain, v.ScChannel, v.DcChain, v.DcChannel, v.ErrorCategory)
+		if item, ok := itemMap[key]; ok {
+			item.Count += v.Count
+			continue
+		}
+		itemMap[key] = &vo.TransferFailureStatisticsItem{
+			ScChain:       v.ScChain,
+			ScChannel:     v.ScChannel,
+			DcChain:       v.DcChain,
+			DcChannel:     v.DcChannel,
+			ErrorCategory: v.ErrorCategory,
+			Count:         v.Count,
+		}
+		keys = append(keys, key)
+	}
+
+	items := make([]vo.TransferFailureStatisticsItem, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, *itemMap[key])
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Count > items[j].Count
+	})
+	return &vo.TransferFailureStatisticsResp{
+		Items:     items,
+		TimeStamp: time.Now().Unix(),
+	}, nil
+}
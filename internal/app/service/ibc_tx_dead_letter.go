@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+type IDeadLetterService interface {
+	DeadLettersCount(req *vo.DeadLettersReq) (int64, errors.Error)
+	DeadLetters(req *vo.DeadLettersReq) (vo.DeadLettersResp, errors.Error)
+	DeadLetterDetail(recordId string) (*vo.DeadLetterDetailResp, errors.Error)
+	Replay(recordId string) errors.Error
+	Discard(recordId string) errors.Error
+}
+
+var _ IDeadLetterService = new(DeadLetterService)
+
+type DeadLetterService struct {
+	dto vo.IbcTxDto
+}
+
+func (svc *DeadLetterService) DeadLettersCount(req *vo.DeadLettersReq) (int64, errors.Error) {
+	count, err := ibcTxDeadLetterRepo.Count(req.Chain, entity.DeadLetterReason(req.ReasonCode))
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc *DeadLetterService) DeadLetters(req *vo.DeadLettersReq) (vo.DeadLettersResp, errors.Error) {
+	var resp vo.DeadLettersResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	res, err := ibcTxDeadLetterRepo.FindList(req.Chain, entity.DeadLetterReason(req.ReasonCode), skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+
+	items := make([]vo.DeadLetterDto, 0, len(res))
+	for _, v := range res {
+		items = append(items, vo.LoadDeadLetterDto(v))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+func (svc *DeadLetterService) findDeadLetter(recordId string) (*entity.IbcTxDeadLetter, errors.Error) {
+	deadLetter, err := ibcTxDeadLetterRepo.FindByRecordId(recordId)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, errors.WrapBadRequest(fmt.Errorf("dead letter %s is not found", recordId))
+		}
+		return nil, errors.Wrap(err)
+	}
+	return deadLetter, nil
+}
+
+func (svc *DeadLetterService) DeadLetterDetail(recordId string) (*vo.DeadLetterDetailResp, errors.Error) {
+	deadLetter, e := svc.findDeadLetter(recordId)
+	if e != nil {
+		return nil, e
+	}
+
+	resp := &vo.DeadLetterDetailResp{
+		DeadLetterDto: vo.LoadDeadLetterDto(deadLetter),
+		TimeStamp:     time.Now().Unix(),
+	}
+	if deadLetter.IbcTx != nil {
+		ibcTxDto := svc.dto.LoadDto(deadLetter.IbcTx)
+		resp.IbcTx = &ibcTxDto
+	}
+	return resp, nil
+}
+
+// Replay put the ibc tx back to the collection it is moved from, it is related again from the first retry
+func (svc *DeadLetterService) Replay(recordId string) errors.Error {
+	deadLetter, e := svc.findDeadLetter(recordId)
+	if e != nil {
+		return e
+	}
+	if deadLetter.IbcTx == nil {
+		return errors.WrapBadRequest(fmt.Errorf("dead letter %s has no ibc tx", recordId))
+	}
+
+	ibcTx := deadLetter.IbcTx
+	ibcTx.RetryTimes = 0
+	ibcTx.NextTryTime = time.Now().Unix()
+	ibcTx.ProcessInfo = ""
+	ibcTx.UpdateAt = time.Now().Unix()
+	var err error
+	if deadLetter.Source == entity.DeadLetterSourceHistory {
+		err = ibcTxRepo.InsertBatchHistory([]*entity.ExIbcTx{ibcTx})
+	} else {
+		err = ibcTxRepo.InsertBatch([]*entity.ExIbcTx{ibcTx})
+	}
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if err = ibcTxDeadLetterRepo.Delete(recordId); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+func (svc *DeadLetterService) Discard(recordId string) errors.Error {
+	if _, e := svc.findDeadLetter(recordId); e != nil {
+		return e
+	}
+	if err := ibcTxDeadLetterRepo.Delete(recordId); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
@@ -3,10 +3,11 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/antchfx/htmlquery"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
@@ -17,74 +18,175 @@ import (
 )
 
 const (
-	mainPageUrl           = "https://github.com/irisnet/iob-registry/tree/main/relayers"
-	xpathRowHeader        = "//div[@role=\"rowheader\"]"
-	rawInformationJsonUrl = "https://raw.githubusercontent.com/irisnet/iob-registry/main/relayers/%s/relayer_info.json"
-	iconUrl               = "https://iobscan.io/resources/ibc-relayer/%s.png"
-	parentPath            = ". ."
+	iconUrl = "https://iobscan.io/resources/ibc-relayer/%s.png"
 )
 
 type RelayerHandler struct {
 	chainIdNameMap map[string]string
 }
 
+// registryChanges relayers of the registry(team name -> dist relayer ids) and the diff with ibc_relayer
+type registryChanges struct {
+	diff              *vo.RelayerRegistryDiff
+	relayers          map[string][]string
+	removedRelayerIds []string
+}
+
+// Collect filepath is a relayer(directory) of the registry, all the relayers are collected if it is empty.
+// registered relayers removed from the registry are removed from ibc_relayer only when all the relayers are collected
 func (h *RelayerHandler) Collect(filepath string) {
 	logrus.Infof("RelayerHandler collect %s", filepath)
 	st := time.Now().Unix()
 
-	chainIdNameMapData, err := repository.GetChainIdNameMap()
+	changes, err := h.loadChanges(filepath, true)
 	if err != nil {
-		logrus.Errorf("getChainIdNameMap err, %v", err)
+		logrus.Errorf("RelayerHandler loadChanges %s err, %v", filepath, err)
 		return
 	}
-	h.chainIdNameMap = chainIdNameMapData
-
-	if filepath == "" {
-		h.xPathMainPage()
-	} else {
-		h.fetchSave(filepath)
-	}
+	diff := changes.diff
+	logrus.WithField("added", diff.Added).WithField("changed", diff.Changed).WithField("removed", diff.Removed).
+		WithField("invalid", diff.Invalid).Infof("RelayerHandler registry diff")
+	h.apply(changes)
 
 	logrus.Infof("RelayerHandler collect %s end, time use: %d(s)", filepath, time.Now().Unix()-st)
 }
 
-func (h *RelayerHandler) xPathMainPage() {
-	doc, err := htmlquery.LoadURL(mainPageUrl)
+// Diff the diff of the registry and ibc_relayer, nothing is applied. the registry is not fetched if it is checked out
+func (h *RelayerHandler) Diff(filepath string) (*vo.RelayerRegistryDiff, error) {
+	changes, err := h.loadChanges(filepath, false)
 	if err != nil {
-		logrus.Errorf("RelayerHandler xPathMainPage err, %v", err)
-		return
+		return nil, err
 	}
+	return changes.diff, nil
+}
 
-	nodes, err := htmlquery.QueryAll(doc, xpathRowHeader)
+func (h *RelayerHandler) loadChanges(filepath string, fetch bool) (*registryChanges, error) {
+	chainIdNameMapData, err := repository.GetChainIdNameMap()
 	if err != nil {
-		logrus.Errorf("RelayerHandler xPathMainPage xpath err, %v", err)
+		return nil, err
+	}
+	h.chainIdNameMap = chainIdNameMapData
+
+	source, err := newRelayerRegistrySource(global.Config.Registry, fetch)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{filepath}
+	if filepath == "" {
+		if names, err = source.Relayers(); err != nil {
+			return nil, err
+		}
+		// 空列表多半是registry源出错, 按其处理会删除全部已注册的relayer
+		if len(names) == 0 {
+			return nil, fmt.Errorf("the registry lists no relayers")
+		}
 	}
 
-	for _, v := range nodes {
-		filepath := strings.TrimSpace(htmlquery.InnerText(v))
-		if strings.HasSuffix(filepath, ".json") || strings.HasSuffix(filepath, ".md") || filepath == parentPath {
+	changes := &registryChanges{
+		diff: &vo.RelayerRegistryDiff{
+			Added:   []string{},
+			Changed: []string{},
+			Removed: []string{},
+			Invalid: []string{},
+		},
+		relayers: make(map[string][]string),
+	}
+	// 无法确定team name的无效relayer可能是任一已注册的relayer, 此时不删除relayer
+	removable := filepath == ""
+	invalidTeams := make(map[string]struct{})
+	for _, name := range names {
+		bz, err := source.RelayerInfo(name)
+		if err != nil {
+			logrus.Warningf("RelayerHandler relayer %s info err, %v", name, err)
+			changes.diff.Invalid = append(changes.diff.Invalid, name)
+			removable = false
 			continue
 		}
 
-		h.fetchSave(filepath)
+		info, err := validateRelayerInfo(bz)
+		if err != nil {
+			logrus.Warningf("RelayerHandler relayer %s is invalid, %v", name, err)
+			changes.diff.Invalid = append(changes.diff.Invalid, name)
+			var team struct {
+				TeamName string `json:"team_name"`
+			}
+			if json.Unmarshal(bz, &team) == nil && team.TeamName != "" {
+				invalidTeams[team.TeamName] = struct{}{}
+			} else {
+				removable = false
+			}
+			continue
+		}
+		changes.relayers[info.TeamName] = append(changes.relayers[info.TeamName], h.distRelayerIds(info)...)
 	}
-}
 
-func (h *RelayerHandler) fetchSave(filepath string) {
-	logrus.Infof("RelayerHandler fetchSave %s", filepath)
-	relayerInfoResp, err := h.queryInfoJson(filepath)
+	registered, err := relayerRepo.FindAuthed()
 	if err != nil {
-		logrus.Infof("RelayerHandler queryInfoJson %s err, %v", filepath, err)
-		return
+		return nil, err
+	}
+	registeredMap := make(map[string]*entity.IBCRelayerNew, len(registered))
+	for _, v := range registered {
+		registeredMap[v.RelayerName] = v
+		if _, ok := changes.relayers[v.RelayerName]; ok || !removable {
+			continue
+		}
+		if _, ok := invalidTeams[v.RelayerName]; ok {
+			continue
+		}
+		changes.diff.Removed = append(changes.diff.Removed, v.RelayerName)
+		changes.removedRelayerIds = append(changes.removedRelayerIds, v.RelayerId)
 	}
 
-	if relayerInfoResp.TeamName == "" {
-		logrus.Warningf("RelayerHandler relayer %s team name is blank", filepath)
-		return
+	if !removeAllowed(len(changes.removedRelayerIds), len(registered), global.Config.Registry.MaxRemoveRatio) {
+		logrus.Errorf("RelayerHandler %d of %d registered relayers are removed from the registry, they are not removed %v",
+			len(changes.removedRelayerIds), len(registered), changes.diff.Removed)
+		changes.diff.RemoveBlocked = true
+		changes.removedRelayerIds = nil
+	}
+
+	for name, distRelayerIds := range changes.relayers {
+		relayer, ok := registeredMap[name]
+		if !ok {
+			changes.diff.Added = append(changes.diff.Added, name)
+		} else if h.pairsChanged(relayer, distRelayerIds) {
+			changes.diff.Changed = append(changes.diff.Changed, name)
+		}
+	}
+	sort.Strings(changes.diff.Added)
+	sort.Strings(changes.diff.Changed)
+	sort.Strings(changes.diff.Removed)
+	return changes, nil
+}
+
+// removeAllowed at most maxRatio of the registered relayers are removed by a collect, one relayer is always allowed
+func removeAllowed(removed, registered int, maxRatio float64) bool {
+	if maxRatio <= 0 {
+		maxRatio = defaultMaxRemoveRatio
 	}
+	return removed <= 1 || float64(removed) <= maxRatio*float64(registered)
+}
 
+func (h *RelayerHandler) apply(changes *registryChanges) {
+	for _, names := range [][]string{changes.diff.Added, changes.diff.Changed} {
+		for _, name := range names {
+			if err := h.saveRegistryRelayer(name, changes.relayers[name]); err != nil {
+				logrus.Errorf("RelayerHandler saveRegistryRelayer %s err, %v", name, err)
+			}
+		}
+	}
+
+	if len(changes.removedRelayerIds) > 0 {
+		if err := relayerRepo.RemoveDumpData(changes.removedRelayerIds); err != nil {
+			logrus.Errorf("RelayerHandler remove relayers %v err, %v", changes.diff.Removed, err)
+			return
+		}
+		logrus.WithField("relayer_ids", changes.removedRelayerIds).Infof("RelayerHandler remove relayers %v succeed", changes.diff.Removed)
+	}
+}
+
+func (h *RelayerHandler) distRelayerIds(relayerInfo *vo.IobRegistryRelayerInfoResp) []string {
 	var distRelayerIds []string
-	for _, addrMap := range relayerInfoResp.Addresses {
+	for _, addrMap := range relayerInfo.Addresses {
 		index := 0
 		var chainA, chainAAddress, chainB, chainBAddress string
 		for k, v := range addrMap {
@@ -106,11 +208,25 @@ func (h *RelayerHandler) fetchSave(filepath string) {
 		}
 		distRelayerIds = append(distRelayerIds, entity.GenerateDistRelayerId(chainA, chainAAddress, chainB, chainBAddress))
 	}
+	return distRelayerIds
+}
 
-	if err = h.saveRegistryRelayer(relayerInfoResp.TeamName, distRelayerIds); err != nil {
-		logrus.Errorf("RelayerHandler saveRegistryRelayer %s err, %v", relayerInfoResp.TeamName, err)
-		return
+// pairsChanged same as updateRelayer, the relayer is changed if an address pair is added or removed
+func (h *RelayerHandler) pairsChanged(relayer *entity.IBCRelayerNew, nowDistRelayerIds []string) bool {
+	existedDistRelayerIds := make(map[string]struct{}, len(relayer.ChannelPairInfo))
+	for _, v := range relayer.ChannelPairInfo {
+		distRelayerId := entity.GenerateDistRelayerId(v.ChainA, v.ChainAAddress, v.ChainB, v.ChainBAddress)
+		if !utils.InArray(nowDistRelayerIds, distRelayerId) {
+			return true
+		}
+		existedDistRelayerIds[distRelayerId] = struct{}{}
+	}
+	for _, v := range nowDistRelayerIds {
+		if _, ok := existedDistRelayerIds[v]; !ok {
+			return true
+		}
 	}
+	return false
 }
 
 func (h *RelayerHandler) saveRegistryRelayer(relayerName string, nowDistRelayerIds []string) error {
@@ -261,17 +377,3 @@ func (h *RelayerHandler) updateRelayer(relayer *entity.IBCRelayerNew, nowDistRel
 		return nil
 	}
 }
-
-func (h *RelayerHandler) queryInfoJson(filepath string) (*vo.IobRegistryRelayerInfoResp, error) {
-	url := fmt.Sprintf(rawInformationJsonUrl, filepath)
-	bz, err := utils.HttpGet(url)
-	if err != nil {
-		return nil, err
-	}
-
-	var res vo.IobRegistryRelayerInfoResp
-	if err = json.Unmarshal(bz, &res); err != nil {
-		return nil, err
-	}
-	return &res, nil
-}
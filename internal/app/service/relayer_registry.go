@@ -0,0 +1,219 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+)
+
+const (
+	relayerRegistrySourceDir  = "dir"
+	relayerRegistrySourceGit  = "git"
+	relayerRegistrySourceHttp = "http"
+
+	relayerInfoFile          = "relayer_info.json"
+	defaultRegistryIndexUrl  = "https://api.github.com/repos/irisnet/iob-registry/contents/relayers?ref=main"
+	defaultRegistryInfoUrl   = "https://raw.githubusercontent.com/irisnet/iob-registry/main/relayers/%s/relayer_info.json"
+	defaultRegistryGitRef    = "main"
+	defaultRegistryGitPath   = "relayers"
+	defaultRegistryGitRemote = "origin"
+	defaultMaxRemoveRatio    = 0.2
+)
+
+// registryMux one collect or diff of the registry at a time, they share the checkout of the git source
+var registryMux sync.Mutex
+
+// relayerRegistrySource the relayers of the registry, a relayer is named by its directory
+type relayerRegistrySource interface {
+	Relayers() ([]string, error)
+	RelayerInfo(name string) ([]byte, error)
+}
+
+// newRelayerRegistrySource the git repository is checked out(fetched) at GitRef when the source is created, the
+// existing checkout is used as it is if fetch is false
+func newRelayerRegistrySource(cfg conf.RelayerRegistry, fetch bool) (relayerRegistrySource, error) {
+	switch cfg.Source {
+	case relayerRegistrySourceDir:
+		if cfg.Dir == "" {
+			return nil, fmt.Errorf("relayer registry dir is empty")
+		}
+		return &dirRegistrySource{dir: cfg.Dir}, nil
+	case relayerRegistrySourceGit:
+		if cfg.Dir == "" || cfg.GitUrl == "" {
+			return nil, fmt.Errorf("relayer registry dir or git_url is empty")
+		}
+		ref, path := cfg.GitRef, cfg.GitPath
+		if ref == "" {
+			ref = defaultRegistryGitRef
+		}
+		if path == "" {
+			path = defaultRegistryGitPath
+		}
+		dir := filepath.Join(cfg.Dir, path)
+		if _, err := os.Stat(dir); fetch || err != nil {
+			if err = gitCheckout(cfg.Dir, cfg.GitUrl, ref); err != nil {
+				return nil, err
+			}
+		}
+		return &dirRegistrySource{dir: dir}, nil
+	case relayerRegistrySourceHttp, "":
+		source := &httpRegistrySource{indexUrl: cfg.IndexUrl, infoUrl: cfg.InfoUrl}
+		if source.indexUrl == "" {
+			source.indexUrl = defaultRegistryIndexUrl
+		}
+		if source.infoUrl == "" {
+			source.infoUrl = defaultRegistryInfoUrl
+		}
+		return source, nil
+	default:
+		return nil, fmt.Errorf("unsupported relayer registry source %s", cfg.Source)
+	}
+}
+
+// validRelayerName the name is a directory of the registry, it comes from the api too
+func validRelayerName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+type dirRegistrySource struct {
+	dir string
+}
+
+func (s *dirRegistrySource) Relayers() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, v := range entries {
+		if v.IsDir() && !strings.HasPrefix(v.Name(), ".") {
+			names = append(names, v.Name())
+		}
+	}
+	return names, nil
+}
+
+func (s *dirRegistrySource) RelayerInfo(name string) ([]byte, error) {
+	if !validRelayerName(name) {
+		return nil, fmt.Errorf("invalid relayer name %s", name)
+	}
+	return os.ReadFile(filepath.Join(s.dir, name, relayerInfoFile))
+}
+
+// gitCheckout fetch ref(branch, tag or commit) of the repository into dir and check it out, the local changes are
+// discarded
+func gitCheckout(dir, gitUrl, ref string) error {
+	git := func(args ...string) error {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s error, %v, %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err = git("init", "-q"); err != nil {
+			return err
+		}
+		if err = git("remote", "add", defaultRegistryGitRemote, gitUrl); err != nil {
+			return err
+		}
+	} else if err = git("remote", "set-url", defaultRegistryGitRemote, gitUrl); err != nil {
+		return err
+	}
+
+	if err := git("fetch", "-q", "--depth", "1", defaultRegistryGitRemote, ref); err != nil {
+		return err
+	}
+	return git("checkout", "-q", "-f", "FETCH_HEAD")
+}
+
+type httpRegistrySource struct {
+	indexUrl string
+	infoUrl  string
+}
+
+// Relayers the index is a list of names, or a list of objects with name and type(the github contents api), only
+// the dirs are relayers
+func (s *httpRegistrySource) Relayers() ([]string, error) {
+	bz, err := utils.HttpGet(s.indexUrl)
+	if err != nil {
+		return nil, err
+	}
+	var items []json.RawMessage
+	if err = json.Unmarshal(bz, &items); err != nil {
+		return nil, fmt.Errorf("invalid relayer registry index, %v", err)
+	}
+
+	var names []string
+	for _, v := range items {
+		var name string
+		if err = json.Unmarshal(v, &name); err == nil {
+			names = append(names, name)
+			continue
+		}
+
+		var content struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		}
+		if err = json.Unmarshal(v, &content); err != nil {
+			return nil, fmt.Errorf("invalid relayer registry index item %s", v)
+		}
+		if content.Type == "" || content.Type == "dir" {
+			names = append(names, content.Name)
+		}
+	}
+	return names, nil
+}
+
+func (s *httpRegistrySource) RelayerInfo(name string) ([]byte, error) {
+	if !validRelayerName(name) {
+		return nil, fmt.Errorf("invalid relayer name %s", name)
+	}
+	return utils.HttpGet(fmt.Sprintf(s.infoUrl, url.PathEscape(name)))
+}
+
+// validateRelayerInfo the schema of relayer_info.json, unknown fields are allowed:
+//   - team_name: string, required
+//   - team_logo: string
+//   - contact: object of string website, github, twitter and discord
+//   - introduction: array of string
+//   - addresses: array, required. each item is an object of two chain_id -> address, both are required
+func validateRelayerInfo(bz []byte) (*vo.IobRegistryRelayerInfoResp, error) {
+	var info vo.IobRegistryRelayerInfoResp
+	if err := json.Unmarshal(bz, &info); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(info.TeamName) == "" {
+		return nil, fmt.Errorf("team_name is required")
+	}
+	if len(info.Addresses) == 0 {
+		return nil, fmt.Errorf("addresses is required")
+	}
+	for i, addrMap := range info.Addresses {
+		if len(addrMap) != 2 {
+			return nil, fmt.Errorf("addresses[%d] should have 2 chains, got %d", i, len(addrMap))
+		}
+		for chainId, address := range addrMap {
+			if strings.TrimSpace(chainId) == "" || strings.TrimSpace(address) == "" {
+				return nil, fmt.Errorf("addresses[%d] has blank chain_id or address", i)
+			}
+		}
+	}
+	return &info, nil
+}
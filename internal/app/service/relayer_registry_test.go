@@ -0,0 +1,147 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+)
+
+func TestDirRegistrySource(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"relayer_a", "relayer_b", ".github"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "relayer_a", relayerInfoFile), []byte(`{"team_name":"A"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := newRelayerRegistrySource(conf.RelayerRegistry{Source: relayerRegistrySourceDir, Dir: dir}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err := source.Relayers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"relayer_a", "relayer_b"}) {
+		t.Fatalf("unexpected relayers %v", names)
+	}
+	if bz, err := source.RelayerInfo("relayer_a"); err != nil || string(bz) != `{"team_name":"A"}` {
+		t.Fatalf("unexpected relayer info %s, %v", bz, err)
+	}
+	if _, err = source.RelayerInfo("../relayer_a"); err == nil {
+		t.Fatal("relayer name out of the registry is accepted")
+	}
+}
+
+func TestHttpRegistrySource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/contents":
+			_, _ = w.Write([]byte(`[{"name":"relayer_a","type":"dir"},{"name":"README.md","type":"file"},{"name":"relayer b","type":"dir"}]`))
+		case "/names":
+			_, _ = w.Write([]byte(`["relayer_a"]`))
+		case "/relayers/relayer b/relayer_info.json":
+			_, _ = w.Write([]byte(`{"team_name":"B"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	for indexPath, want := range map[string][]string{
+		"/contents": {"relayer_a", "relayer b"},
+		"/names":    {"relayer_a"},
+	} {
+		source, err := newRelayerRegistrySource(conf.RelayerRegistry{IndexUrl: server.URL + indexPath, InfoUrl: server.URL + "/relayers/%s/relayer_info.json"}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := source.Relayers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Fatalf("unexpected relayers %v of index %s", names, indexPath)
+		}
+	}
+
+	source, _ := newRelayerRegistrySource(conf.RelayerRegistry{Source: relayerRegistrySourceHttp, IndexUrl: server.URL, InfoUrl: server.URL + "/relayers/%s/relayer_info.json"}, true)
+	if bz, err := source.RelayerInfo("relayer b"); err != nil || string(bz) != `{"team_name":"B"}` {
+		t.Fatalf("unexpected relayer info %s, %v", bz, err)
+	}
+}
+
+func Test_validateRelayerInfo(t *testing.T) {
+	valid := `{"team_name":"A","contact":{"website":"a.io"},"introduction":["a"],"extra":1,
+		"addresses":[{"cosmoshub-4":"cosmos1a","osmosis-1":"osmo1a"}]}`
+	if info, err := validateRelayerInfo([]byte(valid)); err != nil || info.TeamName != "A" || len(info.Addresses) != 1 {
+		t.Fatalf("valid relayer info is rejected, %+v, %v", info, err)
+	}
+
+	for _, invalid := range []string{
+		`{"team_name":" ","addresses":[{"cosmoshub-4":"cosmos1a","osmosis-1":"osmo1a"}]}`,
+		`{"team_name":"A"}`,
+		`{"team_name":"A","addresses":[{"cosmoshub-4":"cosmos1a"}]}`,
+		`{"team_name":"A","addresses":[{"cosmoshub-4":"cosmos1a","osmosis-1":""}]}`,
+		`{"team_name":"A","introduction":"a","addresses":[{"cosmoshub-4":"cosmos1a","osmosis-1":"osmo1a"}]}`,
+		`{"team_name":"A",`,
+	} {
+		if _, err := validateRelayerInfo([]byte(invalid)); err == nil {
+			t.Fatalf("invalid relayer info is accepted, %s", invalid)
+		}
+	}
+}
+
+func Test_removeAllowed(t *testing.T) {
+	cases := []struct {
+		removed, registered int
+		maxRatio            float64
+		allowed             bool
+	}{
+		{0, 0, 0, true},
+		{1, 1, 0, true},
+		{2, 10, 0, true},
+		{3, 10, 0, false},
+		{10, 10, 0, false},
+		{5, 10, 0.5, true},
+		{6, 10, 0.5, false},
+	}
+	for _, v := range cases {
+		if allowed := removeAllowed(v.removed, v.registered, v.maxRatio); allowed != v.allowed {
+			t.Fatalf("removeAllowed(%d, %d, %v) = %v", v.removed, v.registered, v.maxRatio, allowed)
+		}
+	}
+}
+
+func TestGitRegistrySourceDryRun(t *testing.T) {
+	dir := t.TempDir()
+	cfg := conf.RelayerRegistry{Source: relayerRegistrySourceGit, Dir: dir, GitUrl: filepath.Join(dir, "no_remote")}
+	if _, err := newRelayerRegistrySource(cfg, false); err == nil {
+		t.Fatal("the registry is not fetched without a checkout")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, defaultRegistryGitPath, "relayer_a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	source, err := newRelayerRegistrySource(cfg, false)
+	if err != nil {
+		t.Fatalf("the checkout is fetched on a dry run, %v", err)
+	}
+	if names, err := source.Relayers(); err != nil || !reflect.DeepEqual(names, []string{"relayer_a"}) {
+		t.Fatalf("unexpected relayers %v, %v", names, err)
+	}
+	if _, err = newRelayerRegistrySource(cfg, true); err == nil {
+		t.Fatal("the registry is not fetched on a collect")
+	}
+}
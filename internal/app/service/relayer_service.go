@@ -23,10 +23,11 @@ import (
 type IRelayerService interface {
 	List(req *vo.RelayerListReq) (vo.RelayerListResp, errors.Error)
 	ListCount(req *vo.RelayerListReq) (int64, errors.Error)
-	Collect(operatorFile string) errors.Error
+	Collect(operatorFile string, dryRun bool) (*vo.RelayerRegistryDiff, errors.Error)
 	TransferTypeTxs(relayerId string) (*vo.TransferTypeTxsResp, errors.Error)
 	TotalRelayedValue(relayerId string) (*vo.TotalRelayedValueResp, errors.Error)
 	TotalFeeCost(relayerId string) (*vo.TotalFeeCostResp, errors.Error)
+	TotalFeeIncome(relayerId string) (*vo.TotalFeeIncomeResp, errors.Error)
 	Detail(relayerId string) (vo.RelayerDetailResp, errors.Error)
 	DetailRelayerTxsCount(relayerId string, req *vo.DetailRelayerTxsReq) (int64, errors.Error)
 	DetailRelayerTxs(relayerId string, req *vo.DetailRelayerTxsReq) (vo.DetailRelayerTxsResp, errors.Error)
@@ -67,9 +68,25 @@ func (svc *RelayerService) ListCount(req *vo.RelayerListReq) (int64, errors.Erro
 	return total, nil
 }
 
-func (svc *RelayerService) Collect(operatorFile string) errors.Error {
-	go svc.relayerHandler.Collect(operatorFile)
-	return nil
+// Collect dryRun 只返回registry与ibc_relayer的差异, 不更新. 同一时间只运行一个collect
+func (svc *RelayerService) Collect(operatorFile string, dryRun bool) (*vo.RelayerRegistryDiff, errors.Error) {
+	if !registryMux.TryLock() {
+		return nil, errors.Wrap(fmt.Errorf("a collect of the relayer registry is running"))
+	}
+	if dryRun {
+		defer registryMux.Unlock()
+		diff, err := svc.relayerHandler.Diff(operatorFile)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		return diff, nil
+	}
+
+	go func() {
+		defer registryMux.Unlock()
+		svc.relayerHandler.Collect(operatorFile)
+	}()
+	return nil, nil
 }
 
 func (svc *RelayerService) TransferTypeTxs(relayerId string) (*vo.TransferTypeTxsResp, errors.Error) {
@@ -126,6 +143,18 @@ func (svc *RelayerService) TotalFeeCost(relayerId string) (*vo.TotalFeeCostResp,
 	return res, nil
 }
 
+func (svc *RelayerService) TotalFeeIncome(relayerId string) (*vo.TotalFeeIncomeResp, errors.Error) {
+	_, err := getRelayerChainsInfo(relayerId)
+	if err != nil {
+		return nil, err
+	}
+	res, err1 := relayerDataCache.GetTotalFeeIncome(relayerId)
+	if err1 != nil {
+		return nil, errors.Wrap(err1)
+	}
+	return res, nil
+}
+
 func (svc *RelayerService) Detail(relayerId string) (vo.RelayerDetailResp, errors.Error) {
 	var resp vo.RelayerDetailResp
 	one, err := relayerRepo.FindOneByRelayerId(relayerId)
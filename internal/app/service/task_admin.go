@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+)
+
+type ITaskAdminService interface {
+	List() (*vo.TaskAdminListResp, errors.Error)
+	Pause(taskName string) errors.Error
+	Resume(taskName string) errors.Error
+	Unlock(taskName string, req *vo.TaskUnlockReq) errors.Error
+}
+
+var _ ITaskAdminService = new(TaskAdminService)
+
+// TaskAdminService tasks are known by the schedules reported by the instances running tasks, see task.Start
+type TaskAdminService struct {
+}
+
+func (svc *TaskAdminService) List() (*vo.TaskAdminListResp, errors.Error) {
+	schedules, err := taskAdminCache.GetSchedules()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	pausedMap, err := taskAdminCache.GetPaused()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	items := make([]vo.TaskAdminItem, 0, len(schedules))
+	for _, v := range schedules {
+		item := vo.TaskAdminItem{
+			TaskName:    v.TaskName,
+			Cron:        v.Cron,
+			LockFree:    v.LockFree,
+			NextRunTime: v.NextRunTime,
+			InstanceId:  v.InstanceId,
+		}
+		item.PausedAt, item.Paused = pausedMap[v.TaskName]
+		if !v.LockFree {
+			holder, ttl, err := taskAdminCache.GetLock(taskLockKey(v))
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			item.LockHolder = holder
+			item.LockTTL = int64(ttl / time.Second)
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].TaskName < items[j].TaskName
+	})
+
+	return &vo.TaskAdminListResp{
+		Items:     items,
+		TimeStamp: time.Now().Unix(),
+	}, nil
+}
+
+func (svc *TaskAdminService) findSchedule(taskName string) (*dto.TaskScheduleDTO, errors.Error) {
+	schedules, err := taskAdminCache.GetSchedules()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	for _, v := range schedules {
+		if v.TaskName == taskName {
+			return v, nil
+		}
+	}
+	return nil, errors.WrapBadRequest(fmt.Errorf("task %s is not found", taskName))
+}
+
+// Pause the running one is not stopped, the next runs on all the instances are skipped until it is resumed
+func (svc *TaskAdminService) Pause(taskName string) errors.Error {
+	if _, e := svc.findSchedule(taskName); e != nil {
+		return e
+	}
+	if err := taskAdminCache.Pause(taskName); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+func (svc *TaskAdminService) Resume(taskName string) errors.Error {
+	if err := taskAdminCache.Resume(taskName); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+// Unlock force release a stale lock, the task lock or a chain lease of the task
+func (svc *TaskAdminService) Unlock(taskName string, req *vo.TaskUnlockReq) errors.Error {
+	schedule, e := svc.findSchedule(taskName)
+	if e != nil {
+		return e
+	}
+
+	key := taskLockKey(schedule)
+	if schedule.LockFree {
+		if req.Chain == "" {
+			return errors.WrapBadRequest(fmt.Errorf("task %s leases chains, chain is required", taskName))
+		}
+		key = cache.ChainLeaseKey(taskName, req.Chain)
+	}
+
+	released, err := taskAdminCache.ReleaseLock(key)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if !released {
+		return errors.WrapBadRequest(fmt.Errorf("lock %s is not held", key))
+	}
+	return nil
+}
+
+// taskLockKey the lock key is reported with the schedule, the schedules reported by the old instances have no lock key
+func taskLockKey(schedule *dto.TaskScheduleDTO) string {
+	if schedule.LockKey != "" {
+		return schedule.LockKey
+	}
+	return cache.TaskLockKey(schedule.TaskName)
+}
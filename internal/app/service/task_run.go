@@ -0,0 +1,90 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+type ITaskRunService interface {
+	Tasks(req *vo.TaskListReq) (*vo.TaskListResp, errors.Error)
+	TaskRunsCount(taskName string, req *vo.TaskRunsReq) (int64, errors.Error)
+	TaskRuns(taskName string, req *vo.TaskRunsReq) (*vo.TaskRunsResp, errors.Error)
+}
+
+var _ ITaskRunService = new(TaskRunService)
+
+type TaskRunService struct {
+}
+
+// Tasks the tasks run since req.StartTime, the failed ones come first
+func (svc *TaskRunService) Tasks(req *vo.TaskListReq) (*vo.TaskListResp, errors.Error) {
+	startTime := req.StartTime
+	if startTime <= 0 {
+		startTime = time.Now().Unix() - 24*3600
+	}
+
+	list, err := taskRunRepo.AggrTaskRuns(startTime)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	items := make([]vo.TaskItem, 0, len(list))
+	for _, v := range list {
+		items = append(items, vo.TaskItem{
+			TaskName:   v.TaskName,
+			Runs:       v.Runs,
+			FailedRuns: v.FailedRuns,
+			LastRun: vo.TaskRunItem{
+				InstanceId: v.LastInstanceId,
+				StartTime:  v.LastStartTime,
+				EndTime:    v.LastEndTime,
+				Duration:   v.LastDuration,
+				Status:     v.LastStatus,
+				ExitStatus: v.LastExitStatus,
+				ErrorMsg:   v.LastErrorMsg,
+				Progress:   v.LastProgress,
+			},
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].FailedRuns != items[j].FailedRuns {
+			return items[i].FailedRuns > items[j].FailedRuns
+		}
+		return items[i].TaskName < items[j].TaskName
+	})
+
+	return &vo.TaskListResp{
+		Items:     items,
+		TimeStamp: time.Now().Unix(),
+	}, nil
+}
+
+func (svc *TaskRunService) TaskRunsCount(taskName string, req *vo.TaskRunsReq) (int64, errors.Error) {
+	count, err := taskRunRepo.CountRuns(taskName, entity.TaskRunStatus(req.Status))
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc *TaskRunService) TaskRuns(taskName string, req *vo.TaskRunsReq) (*vo.TaskRunsResp, errors.Error) {
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	list, err := taskRunRepo.FindRuns(taskName, entity.TaskRunStatus(req.Status), skip, limit)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	items := make([]vo.TaskRunItem, 0, len(list))
+	for _, v := range list {
+		items = append(items, vo.LoadTaskRunItem(v))
+	}
+	return &vo.TaskRunsResp{
+		Items:     items,
+		PageInfo:  vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize),
+		TimeStamp: time.Now().Unix(),
+	}, nil
+}
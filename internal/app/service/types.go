@@ -17,10 +17,17 @@ var (
 	statisticRepo              repository.IStatisticRepo              = new(repository.IbcStatisticRepo)
 	chainCfgRepo               repository.IChainConfigRepo            = new(repository.ChainConfigRepo)
 	ibcTxRepo                  repository.IExIbcTxRepo                = new(repository.ExIbcTxRepo)
+	ibcNftTxRepo               repository.IExIbcNftTxRepo             = new(repository.ExIbcNftTxRepo)
+	ibcIcaTxRepo               repository.IExIbcIcaTxRepo             = new(repository.ExIbcIcaTxRepo)
+	ibcTxDeadLetterRepo        repository.IIbcTxDeadLetterRepo        = new(repository.IbcTxDeadLetterRepo)
+	taskRunRepo                repository.ITaskRunRepo                = new(repository.TaskRunRepo)
+	icaAccountRepo             repository.IIcaAccountRepo             = new(repository.IcaAccountRepo)
 	txRepo                     repository.ITxRepo                     = new(repository.TxRepo)
 	exSearchRecordRepo         repository.IUbaSearchRecordRepo        = new(repository.UbaSearchRecordRepo)
 	relayerDenomStatisticsRepo repository.IRelayerDenomStatisticsRepo = new(repository.RelayerDenomStatisticsRepo)
 	denomHeatmapRepo           repository.IDenomHeatmap               = new(repository.DenomHeatmap)
+	packetLatencyRepo          repository.IPacketLatencyRepo          = new(repository.PacketLatencyRepo)
+	ibcClientRepo              repository.IIBCClientRepo              = new(repository.IBCClientRepo)
 	chainFlowCacheRepo         cache.ChainFlowCacheRepo
 	relayerDataCache           cache.RelayerDataCacheRepo
 	lcdTxDataCache             cache.LcdTxDataCacheRepo
@@ -31,6 +38,7 @@ var (
 	chainCache                 cache.ChainCacheRepo
 	supportCache               cache.DenomDataCacheRepo
 	overviewCache              cache.OverviewCacheRepo
+	taskAdminCache             cache.TaskAdminCacheRepo
 )
 
 type (
@@ -78,6 +86,7 @@ type (
 
 	AccountCfg struct {
 		Chain           string
+		ChainConfig     *entity.ChainConfig
 		GrpcRestGateway string
 		BalancesPath    string
 		AccountsPath    string
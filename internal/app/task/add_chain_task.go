@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -25,7 +26,7 @@ func (t *AddChainTask) Switch() bool {
 	return global.Config.Task.SwitchAddChainTask
 }
 
-func (t *AddChainTask) Run() int {
+func (t *AddChainTask) Run(ctx context.Context) int {
 	chainsStr := global.Config.ChainConfig.NewChains
 	newChains := strings.Split(chainsStr, ",")
 	if len(newChains) == 0 {
@@ -33,7 +34,7 @@ func (t *AddChainTask) Run() int {
 		return 1
 	}
 
-	return t.handle(newChains)
+	return t.handle(newChains, nil)
 }
 
 func (t *AddChainTask) RunWithParam(chainsStr string) int {
@@ -43,10 +44,30 @@ func (t *AddChainTask) RunWithParam(chainsStr string) int {
 		return 1
 	}
 
-	return t.handle(newChains)
+	return t.handle(newChains, nil)
 }
 
-func (t *AddChainTask) handle(newChains []string) int {
+// DryRunWithParam same matching as RunWithParam, but nothing is updated. the documents which would be updated are
+// reported to the task run instead, see entity.DryRunReport
+func (t *AddChainTask) DryRunWithParam(chainsStr string) int {
+	newChains := strings.Split(chainsStr, ",")
+	if len(newChains) == 0 {
+		logrus.Errorf("task %s don't have new chains", t.Name())
+		return 1
+	}
+
+	report := newDryRunReport()
+	res := t.handle(newChains, report)
+	dryRunReport := report.report()
+	setTaskRunReport(t.Name(), dryRunReport)
+	for _, v := range dryRunReport.Items {
+		logrus.Infof("task %s dry run, %s %s chain: %s, channel: %s, matched: %d", t.Name(), v.Collection, v.Operation, v.Chain, v.Channel, v.Matched)
+	}
+	return res
+}
+
+// handle the changes are reported to report instead if it is not nil
+func (t *AddChainTask) handle(newChains []string, report *dryRunReport) int {
 	chainMap, err := getAllChainMap()
 	if err != nil {
 		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
@@ -71,21 +92,21 @@ func (t *AddChainTask) handle(newChains []string) int {
 				continue
 			}
 
-			t.updateIbcTx(chain, chainConfig, chainMap)
+			t.updateIbcTx(chain, chainConfig, chainMap, report)
 		}
 	}()
 
 	// update denom
 	go func() {
 		defer waitGroup.Done()
-		t.updateDenom(denomList, chainMap)
+		t.updateDenom(denomList, chainMap, report)
 	}()
 
 	waitGroup.Wait()
 	return 1
 }
 
-func (t *AddChainTask) updateIbcTx(chain string, chainConfig *entity.ChainConfig, chainMap map[string]*entity.ChainConfig) {
+func (t *AddChainTask) updateIbcTx(chain string, chainConfig *entity.ChainConfig, chainMap map[string]*entity.ChainConfig, report *dryRunReport) {
 	logrus.Infof("task %s start updating %s ibc tx", t.Name(), chain)
 	if len(chainConfig.IbcInfo) == 0 {
 		logrus.Warningf("task %s %s dont't have ibc info", t.Name(), chain)
@@ -109,6 +130,11 @@ func (t *AddChainTask) updateIbcTx(chain string, chainConfig *entity.ChainConfig
 			}
 
 			channelId := path.ChannelId
+			if report != nil {
+				t.previewIbcTx(report, chain, channelId, clientId, counterpartyChain, counterpartyChannelId, counterpartyClientId)
+				continue
+			}
+
 			var waitGroup sync.WaitGroup
 			waitGroup.Add(4)
 			go func() {
@@ -146,8 +172,34 @@ func (t *AddChainTask) updateIbcTx(chain string, chainConfig *entity.ChainConfig
 	logrus.Infof("task %s update %s ibc tx end", t.Name(), chain)
 }
 
-func (t *AddChainTask) updateDenom(denomList entity.IBCDenomList, chainMap map[string]*entity.ChainConfig) {
+// previewIbcTx dry run of the updates in updateIbcTx, the ibc txs from the counterparty channel are matched
+func (t *AddChainTask) previewIbcTx(report *dryRunReport, chain, channelId, clientId, counterpartyChain, counterpartyChannelId, counterpartyClientId string) {
+	for _, history := range []bool{false, true} {
+		collection := entity.CollectionNameExIbcTxLatest
+		if history {
+			collection = entity.CollectionNameExIbcTx
+		}
+
+		matched, samples, err := ibcTxRepo.PreviewAddNewChainUpdate(counterpartyChain, counterpartyChannelId, counterpartyClientId, chain, clientId, history, dryRunSampleLimit)
+		if err != nil {
+			logrus.Errorf("task %s %s PreviewAddNewChainUpdate error, counterpartyChain: %s, counterpartyChannelId: %s, %v", t.Name(), chain, counterpartyChain, counterpartyChannelId, err)
+		} else {
+			report.add(collection, "add_new_chain_update", counterpartyChain, counterpartyChannelId, matched, samples)
+		}
+
+		matched, samples, err = ibcTxRepo.PreviewAddNewChainUpdateFailedTx(counterpartyChain, counterpartyChannelId, counterpartyClientId, chain, channelId, clientId, history, dryRunSampleLimit)
+		if err != nil {
+			logrus.Errorf("task %s %s PreviewAddNewChainUpdateFailedTx error, counterpartyChain: %s, counterpartyChannelId: %s, %v", t.Name(), chain, counterpartyChain, counterpartyChannelId, err)
+		} else {
+			report.add(collection, "add_new_chain_update_failed_tx", counterpartyChain, counterpartyChannelId, matched, samples)
+		}
+	}
+}
+
+func (t *AddChainTask) updateDenom(denomList entity.IBCDenomList, chainMap map[string]*entity.ChainConfig, report *dryRunReport) {
 	logrus.Infof("task %s update denom start", t.Name())
+	// the ibc txs of a base denom are updated by the first denom of it, the others match nothing
+	previewedBaseDenoms := make(map[string]struct{})
 
 	for _, v := range denomList {
 		if v.DenomPath == "" || v.RootDenom == "" {
@@ -159,12 +211,26 @@ func (t *AddChainTask) updateDenom(denomList entity.IBCDenomList, chainMap map[s
 		if v.BaseDenom != denomNew.BaseDenom || v.BaseDenomChain != denomNew.BaseDenomChain || v.PrevDenom != denomNew.PrevDenom ||
 			v.PrevChain != denomNew.PrevChain || v.IsBaseDenom != denomNew.IsBaseDenom {
 			logrus.WithField("denom", v).WithField("denom_new", denomNew).Infof("task %s denom trace path is changed", t.Name())
-			if err := denomRepo.UpdateDenom(denomNew); err != nil {
+			if report != nil {
+				report.add(v.CollectionName(false), "update_denom", v.Chain, "", 1, []entity.DryRunSample{{
+					Before: denomTraceFields(v),
+					After:  denomTraceFields(denomNew),
+				}})
+			} else if err := denomRepo.UpdateDenom(denomNew); err != nil {
 				logrus.Errorf("task %s update denom %s-%s error, %v", t.Name(), denomNew.Chain, denomNew.Denom, err)
 			}
 		}
 
 		if v.BaseDenom != denomNew.BaseDenom || v.BaseDenomChain != denomNew.BaseDenomChain {
+			if report != nil {
+				key := fmt.Sprintf("%s/%s", v.BaseDenomChain, v.BaseDenom)
+				if _, ok := previewedBaseDenoms[key]; !ok {
+					previewedBaseDenoms[key] = struct{}{}
+					t.previewBaseDenomInfo(report, v, denomNew)
+				}
+				continue
+			}
+
 			if err := ibcTxRepo.UpdateBaseDenomInfo(v.BaseDenom, v.BaseDenomChain, denomNew.BaseDenom, denomNew.BaseDenomChain); err != nil {
 				logrus.Errorf("task %s UpdateBaseDenomInfo error, %s-%s => %s-%s", t.Name(), v.BaseDenomChain, v.BaseDenom, denomNew.BaseDenomChain, denomNew.BaseDenom)
 			}
@@ -176,3 +242,33 @@ func (t *AddChainTask) updateDenom(denomList entity.IBCDenomList, chainMap map[s
 
 	logrus.Infof("task %s update denom end", t.Name())
 }
+
+// previewBaseDenomInfo dry run of UpdateBaseDenomInfo, the ibc txs are reported by the base denom chain
+func (t *AddChainTask) previewBaseDenomInfo(report *dryRunReport, denom, denomNew *entity.IBCDenom) {
+	for _, history := range []bool{false, true} {
+		collection := entity.CollectionNameExIbcTxLatest
+		if history {
+			collection = entity.CollectionNameExIbcTx
+		}
+
+		matched, samples, err := ibcTxRepo.PreviewUpdateBaseDenomInfo(denom.BaseDenom, denom.BaseDenomChain, denomNew.BaseDenom, denomNew.BaseDenomChain, history, dryRunSampleLimit)
+		if err != nil {
+			logrus.Errorf("task %s PreviewUpdateBaseDenomInfo error, %s-%s => %s-%s, %v", t.Name(), denom.BaseDenomChain, denom.BaseDenom, denomNew.BaseDenomChain, denomNew.BaseDenom, err)
+			continue
+		}
+		report.add(collection, "update_base_denom_info", denom.BaseDenomChain, "", matched, samples)
+	}
+}
+
+// denomTraceFields the fields of the denom updated by denomRepo.UpdateDenom
+func denomTraceFields(denom *entity.IBCDenom) map[string]interface{} {
+	return map[string]interface{}{
+		"chain":            denom.Chain,
+		"denom":            denom.Denom,
+		"base_denom":       denom.BaseDenom,
+		"base_denom_chain": denom.BaseDenomChain,
+		"prev_denom":       denom.PrevDenom,
+		"prev_chain":       denom.PrevChain,
+		"is_base_denom":    denom.IsBaseDenom,
+	}
+}
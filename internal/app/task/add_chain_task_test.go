@@ -1,15 +1,20 @@
 package task
 
 import (
+	"context"
 	"testing"
 )
 
 func Test_AddChainTask(t *testing.T) {
-	new(AddChainTask).Run()
+	new(AddChainTask).Run(context.Background())
 }
 
 func Test_UpdateIbcTx(t *testing.T) {
 	chainMap, _ := getAllChainMap()
 	chain := "bigbangname"
-	new(AddChainTask).updateIbcTx(chain, chainMap[chain], chainMap)
+	new(AddChainTask).updateIbcTx(chain, chainMap[chain], chainMap, nil)
+}
+
+func Test_AddChainTaskDryRun(t *testing.T) {
+	new(AddChainTask).DryRunWithParam("bigbangname")
 }
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/sirupsen/logrus"
 	"strings"
@@ -19,16 +20,28 @@ func (t *AddTransferDataTask) Switch() bool {
 	return false
 }
 
-func (t *AddTransferDataTask) Run() int {
+func (t *AddTransferDataTask) Run(ctx context.Context) int {
 	return 1
 	//return t.handle(global.Config.ChainConfig.AddTransferChains)
 }
 
 func (t *AddTransferDataTask) RunWithParam(chainsStr string) int {
-	return t.handle(chainsStr)
+	return t.handle(chainsStr, nil)
 }
 
-func (t *AddTransferDataTask) handle(chainsStr string) int {
+// DryRunWithParam the ibc txs and denoms are parsed as RunWithParam, but reported to the task run instead of inserted
+func (t *AddTransferDataTask) DryRunWithParam(chainsStr string) int {
+	report := newDryRunReport()
+	res := t.handle(chainsStr, report)
+	dryRunReport := report.report()
+	setTaskRunReport(t.Name(), dryRunReport)
+	for _, v := range dryRunReport.Items {
+		logrus.Infof("task %s dry run, %s %s chain: %s, channel: %s, matched: %d", t.Name(), v.Collection, v.Operation, v.Chain, v.Channel, v.Matched)
+	}
+	return res
+}
+
+func (t *AddTransferDataTask) handle(chainsStr string, report *dryRunReport) int {
 	newChains := strings.Split(chainsStr, ",")
 	if len(newChains) == 0 {
 		logrus.Errorf("task %s don't have new chains", t.Name())
@@ -46,7 +59,7 @@ func (t *AddTransferDataTask) handle(chainsStr string) int {
 	for _, val := range newChains {
 		logrus.Info("start handle chain:", val)
 		for {
-			curH, size, err := t.DoChain(w, val, chainCureight[val], defaultMaxHandlerTx)
+			curH, size, err := t.DoChain(w, val, chainCureight[val], defaultMaxHandlerTx, report)
 			if err != nil {
 				logrus.Error(err.Error())
 				return -1
@@ -61,7 +74,7 @@ func (t *AddTransferDataTask) handle(chainsStr string) int {
 	return 1
 }
 
-func (t *AddTransferDataTask) DoChain(w *syncTransferTxWorker, chain string, height, limit int64) (int64, int, error) {
+func (t *AddTransferDataTask) DoChain(w *syncTransferTxWorker, chain string, height, limit int64, report *dryRunReport) (int64, int, error) {
 	maxHeight := int64(-1)
 	denomMap, err := w.getChainDenomMap(chain)
 	if err != nil {
@@ -80,7 +93,7 @@ func (t *AddTransferDataTask) DoChain(w *syncTransferTxWorker, chain string, hei
 		return maxHeight, 0, err
 	}
 	total := len(txList)
-	if err := t.handleChain(chain, w, txList, denomMap); err != nil {
+	if err := t.handleChain(chain, w, txList, denomMap, report); err != nil {
 		return maxHeight, 0, err
 	}
 	if len(txList) > 0 {
@@ -89,12 +102,16 @@ func (t *AddTransferDataTask) DoChain(w *syncTransferTxWorker, chain string, hei
 	return maxHeight, total, nil
 }
 
-func (t *AddTransferDataTask) handleChain(chain string, w *syncTransferTxWorker, txList []*entity.Tx, denomMap map[string]*entity.IBCDenom) error {
+func (t *AddTransferDataTask) handleChain(chain string, w *syncTransferTxWorker, txList []*entity.Tx, denomMap map[string]*entity.IBCDenom, report *dryRunReport) error {
 	if len(txList) == 0 {
 		return nil
 	}
 
 	ibcTxList, ibcDenomList := w.handleSourceTx(chain, txList, denomMap)
+	if report != nil {
+		t.reportInsert(report, ibcTxList, ibcDenomList)
+		return nil
+	}
 	if len(ibcDenomList) > 0 {
 		if err := denomRepo.InsertBatch(ibcDenomList); err != nil {
 			logrus.Errorf("task %s worker %s denomRepo.InsertBatch %s error, %v", w.taskName, w.workerName, chain, err)
@@ -109,3 +126,26 @@ func (t *AddTransferDataTask) handleChain(chain string, w *syncTransferTxWorker,
 	}
 	return nil
 }
+
+// reportInsert the ibc txs are reported by source chain and channel, the denoms by chain
+func (t *AddTransferDataTask) reportInsert(report *dryRunReport, ibcTxList []*entity.ExIbcTx, ibcDenomList entity.IBCDenomList) {
+	for _, v := range ibcDenomList {
+		report.add(v.CollectionName(false), "insert", v.Chain, "", 1, []entity.DryRunSample{{
+			After: denomTraceFields(v),
+		}})
+	}
+	for _, v := range ibcTxList {
+		report.add(v.CollectionName(false), "insert", v.ScChain, v.ScChannel, 1, []entity.DryRunSample{{
+			After: map[string]interface{}{
+				"record_id":        v.RecordId,
+				"sc_chain":         v.ScChain,
+				"sc_channel":       v.ScChannel,
+				"dc_chain":         v.DcChain,
+				"dc_channel":       v.DcChannel,
+				"status":           v.Status,
+				"base_denom":       v.BaseDenom,
+				"base_denom_chain": v.BaseDenomChain,
+			},
+		}})
+	}
+}
@@ -0,0 +1,121 @@
+package task
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/sirupsen/logrus"
+)
+
+// chainLeasedTask the task is not locked as a whole, its workers take a chainLease for each chain instead. so the
+// instances run the task at the same time and share the chains.
+type chainLeasedTask interface {
+	Task
+	chainLeased()
+}
+
+func chainLeaseExpiration() time.Duration {
+	if taskConf.ChainLeaseExpireTime > 0 {
+		return time.Duration(taskConf.ChainLeaseExpireTime) * time.Second
+	}
+	return ChainLeaseExpireTime * time.Second
+}
+
+// chainLease the chain of a task is handled by the holder only. it is renewed in the background while the holder is
+// working, when the holder dies the lease expires and the chain is taken by another instance on its next run.
+type chainLease struct {
+	key        string
+	value      string
+	expiration time.Duration
+	stop       chan struct{}
+	isLost     int32
+	once       sync.Once
+}
+
+var (
+	heldLeasesMux sync.Mutex
+	heldLeases    = make(map[*chainLease]struct{})
+)
+
+// acquireChainLease an error is returned if the chain is held by others
+func acquireChainLease(taskName, chain string) (*chainLease, error) {
+	return acquireLease(cache.ChainLeaseKey(taskName, chain), chainLeaseExpiration())
+}
+
+// acquireLease the lock value is unique to the holder, so it is renewed and released by the holder only
+func acquireLease(key string, expiration time.Duration) (*chainLease, error) {
+	lease := &chainLease{
+		key:        key,
+		value:      fmt.Sprintf("%s-%d", instanceId, time.Now().UnixNano()),
+		expiration: expiration,
+		stop:       make(chan struct{}),
+	}
+	if err := cache.GetRedisClient().Lock(lease.key, lease.value, lease.expiration); err != nil {
+		return nil, err
+	}
+
+	heldLeasesMux.Lock()
+	heldLeases[lease] = struct{}{}
+	heldLeasesMux.Unlock()
+
+	go lease.renew()
+	return lease, nil
+}
+
+func (l *chainLease) renew() {
+	ticker := time.NewTicker(l.expiration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ok, err := cache.GetRedisClient().ScriptExpire(l.key, l.value, l.expiration)
+			if err != nil {
+				logrus.Errorf("chain lease %s renew error, %v", l.key, err)
+				continue
+			}
+			if !ok {
+				logrus.Warningf("chain lease %s is lost", l.key)
+				atomic.StoreInt32(&l.isLost, 1)
+				return
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// lost the lease expired before renewal and may be held by others now, the holder should stop working on the chain
+func (l *chainLease) lost() bool {
+	return l != nil && atomic.LoadInt32(&l.isLost) == 1
+}
+
+// release it is safe to release a lease more than once
+func (l *chainLease) release() {
+	l.once.Do(func() {
+		close(l.stop)
+		if _, err := cache.GetRedisClient().UnLock(l.key, l.value); err != nil {
+			logrus.Errorf("chain lease %s release error, %v", l.key, err)
+		}
+
+		heldLeasesMux.Lock()
+		delete(heldLeases, l)
+		heldLeasesMux.Unlock()
+	})
+}
+
+// releaseHeldLeases release the leases whose holders have not returned yet, it is called on shutdown only
+func releaseHeldLeases() {
+	heldLeasesMux.Lock()
+	leases := make([]*chainLease, 0, len(heldLeases))
+	for l := range heldLeases {
+		leases = append(leases, l)
+	}
+	heldLeasesMux.Unlock()
+
+	for _, l := range leases {
+		l.release()
+	}
+}
@@ -0,0 +1,36 @@
+package task
+
+import (
+	"testing"
+)
+
+func Test_ChainLease(t *testing.T) {
+	lease, err := acquireChainLease("chain_lease_test", "irishub_qa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = acquireChainLease("chain_lease_test", "irishub_qa"); err == nil {
+		t.Fatal("chain is leased twice")
+	}
+
+	lease.release()
+	lease, err = acquireChainLease("chain_lease_test", "irishub_qa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lease.release()
+}
+
+func Test_ReleaseHeldLeases(t *testing.T) {
+	if _, err := acquireChainLease("chain_lease_test", "irishub_qa"); err != nil {
+		t.Fatal(err)
+	}
+
+	releaseHeldLeases()
+	lease, err := acquireChainLease("chain_lease_test", "irishub_qa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lease.release()
+	lease.release()
+}
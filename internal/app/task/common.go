@@ -2,12 +2,17 @@ package task
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
 	"github.com/sirupsen/logrus"
 )
 
@@ -194,8 +199,8 @@ func getAllChainInfosMap() (map[string]*entity.ChainConfig, error) {
 	return allChainMap, err
 }
 
-// parseTransferTxEvents parse ibc info from events of transfer tx
-func parseTransferTxEvents(msgIndex int, tx *entity.Tx) (dcPort, dcChannel, denomFullPath, sequence, scConnection string) {
+// parseTransferTxEvents parse ibc info from events of transfer tx. denomFullPaths are in the same order as the tokens of the msg
+func parseTransferTxEvents(msgIndex int, tx *entity.Tx) (dcPort, dcChannel string, denomFullPaths []string, sequence, scConnection string) {
 	if len(tx.EventsNew) > msgIndex {
 		for _, evt := range tx.EventsNew[msgIndex].Events {
 			if evt.Type == "send_packet" {
@@ -210,7 +215,9 @@ func parseTransferTxEvents(msgIndex int, tx *entity.Tx) (dcPort, dcChannel, deno
 					case "packet_data":
 						var data model.TransferTxPacketData
 						_ = json.Unmarshal([]byte(attr.Value), &data)
-						denomFullPath = data.Denom
+						for _, coin := range data.FullPathCoins() {
+							denomFullPaths = append(denomFullPaths, coin.Denom)
+						}
 					case "packet_connection":
 						scConnection = attr.Value
 					default:
@@ -223,6 +230,215 @@ func parseTransferTxEvents(msgIndex int, tx *entity.Tx) (dcPort, dcChannel, deno
 	return
 }
 
+// parseNftTransferTxEvents parse ibc info from events of ics721 transfer tx
+func parseNftTransferTxEvents(msgIndex int, tx *entity.Tx) (dcPort, dcChannel, sequence, scConnection string, packetData model.NftTransferTxPacketData) {
+	if len(tx.EventsNew) > msgIndex {
+		for _, evt := range tx.EventsNew[msgIndex].Events {
+			if evt.Type == "send_packet" {
+				for _, attr := range evt.Attributes {
+					switch attr.Key {
+					case "packet_dst_port":
+						dcPort = attr.Value
+					case "packet_dst_channel":
+						dcChannel = attr.Value
+					case "packet_sequence":
+						sequence = attr.Value
+					case "packet_data":
+						_ = json.Unmarshal([]byte(attr.Value), &packetData)
+					case "packet_connection":
+						scConnection = attr.Value
+					default:
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// parseIcaSendTxEvents parse ibc info from send_packet event of interchain account controller tx
+func parseIcaSendTxEvents(msgIndex int, tx *entity.Tx) (packet model.Packet, scConnection string, packetData model.IcaPacketData) {
+	if len(tx.EventsNew) > msgIndex {
+		for _, evt := range tx.EventsNew[msgIndex].Events {
+			if evt.Type == "send_packet" {
+				for _, attr := range evt.Attributes {
+					switch attr.Key {
+					case "packet_src_port":
+						packet.SourcePort = attr.Value
+					case "packet_src_channel":
+						packet.SourceChannel = attr.Value
+					case "packet_dst_port":
+						packet.DestinationPort = attr.Value
+					case "packet_dst_channel":
+						packet.DestinationChannel = attr.Value
+					case "packet_sequence":
+						packet.Sequence, _ = strconv.ParseInt(attr.Value, 10, 64)
+					case "packet_timeout_height":
+						packet.TimeoutHeight = parseTimeoutHeight(attr.Value)
+					case "packet_timeout_timestamp":
+						packet.TimeoutTimestamp, _ = strconv.ParseInt(attr.Value, 10, 64)
+					case "packet_data":
+						_ = json.Unmarshal([]byte(attr.Value), &packetData)
+					case "packet_connection":
+						scConnection = attr.Value
+					default:
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// parseForwardTxEvents parse the packet sent by packet-forward-middleware from events of recv packet tx.
+// the send_packet event is matched by the forward port and channel in packet memo
+func parseForwardTxEvents(msgIndex int, tx *entity.Tx, forward *model.ForwardMetadata) (packet model.Packet, scConnection string, packetData model.TransferTxPacketData, exist bool) {
+	if len(tx.EventsNew) <= msgIndex {
+		return
+	}
+
+	for _, evt := range tx.EventsNew[msgIndex].Events {
+		if evt.Type != "send_packet" {
+			continue
+		}
+
+		var evtPacket model.Packet
+		var evtConnection string
+		var evtPacketData model.TransferTxPacketData
+		for _, attr := range evt.Attributes {
+			switch attr.Key {
+			case "packet_src_port":
+				evtPacket.SourcePort = attr.Value
+			case "packet_src_channel":
+				evtPacket.SourceChannel = attr.Value
+			case "packet_dst_port":
+				evtPacket.DestinationPort = attr.Value
+			case "packet_dst_channel":
+				evtPacket.DestinationChannel = attr.Value
+			case "packet_sequence":
+				evtPacket.Sequence, _ = strconv.ParseInt(attr.Value, 10, 64)
+			case "packet_timeout_height":
+				evtPacket.TimeoutHeight = parseTimeoutHeight(attr.Value)
+			case "packet_timeout_timestamp":
+				evtPacket.TimeoutTimestamp, _ = strconv.ParseInt(attr.Value, 10, 64)
+			case "packet_data":
+				_ = json.Unmarshal([]byte(attr.Value), &evtPacketData)
+			case "packet_connection":
+				evtConnection = attr.Value
+			default:
+			}
+		}
+
+		if evtPacket.SourcePort == forward.Port && evtPacket.SourceChannel == forward.Channel {
+			return evtPacket, evtConnection, evtPacketData, true
+		}
+	}
+
+	return
+}
+
+// parseTimeoutHeight parse timeout height from event value, eg: "1-1024"
+func parseTimeoutHeight(v string) model.TimeoutHeight {
+	var res model.TimeoutHeight
+	split := strings.Split(v, "-")
+	if len(split) != 2 {
+		return res
+	}
+	res.RevisionNumber, _ = strconv.ParseInt(split[0], 10, 64)
+	res.RevisionHeight, _ = strconv.ParseInt(split[1], 10, 64)
+	return res
+}
+
+// parseChannelOpenEvents parse port, channel and connection from channel_open_init or channel_open_ack event
+func parseChannelOpenEvents(msgIndex int, tx *entity.Tx, evtType string) (port, channel, connection, counterpartyPort, counterpartyChannel string) {
+	if len(tx.EventsNew) > msgIndex {
+		for _, evt := range tx.EventsNew[msgIndex].Events {
+			if evt.Type == evtType {
+				for _, attr := range evt.Attributes {
+					switch attr.Key {
+					case "port_id":
+						port = attr.Value
+					case "channel_id":
+						channel = attr.Value
+					case "connection_id":
+						connection = attr.Value
+					case "counterparty_port_id":
+						counterpartyPort = attr.Value
+					case "counterparty_channel_id":
+						counterpartyChannel = attr.Value
+					default:
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// parseIncentivizedPacketEvents parse fee escrowed for packet from events of pay packet fee tx
+func parseIncentivizedPacketEvents(msgIndex int, tx *entity.Tx) (port, channel, sequence string, fee model.PacketFee, exist bool) {
+	if len(tx.EventsNew) > msgIndex {
+		for _, evt := range tx.EventsNew[msgIndex].Events {
+			if evt.Type == "incentivized_ibc_packet" {
+				exist = true
+				for _, attr := range evt.Attributes {
+					switch attr.Key {
+					case "port_id":
+						port = attr.Value
+					case "channel_id":
+						channel = attr.Value
+					case "packet_sequence":
+						sequence = attr.Value
+					case "recv_fee":
+						fee.RecvFee = ibctool.ParseCoins(attr.Value)
+					case "ack_fee":
+						fee.AckFee = ibctool.ParseCoins(attr.Value)
+					case "timeout_fee":
+						fee.TimeoutFee = ibctool.ParseCoins(attr.Value)
+					default:
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+type distributeFeeEvent struct {
+	receiver string
+	fee      []*model.Coin
+}
+
+// parseDistributeFeeEvents parse fees paid out from events of acknowledge or timeout packet tx
+func parseDistributeFeeEvents(msgIndex int, tx *entity.Tx) []distributeFeeEvent {
+	var res []distributeFeeEvent
+	if len(tx.EventsNew) > msgIndex {
+		for _, evt := range tx.EventsNew[msgIndex].Events {
+			if evt.Type == "distribute_fee" {
+				var item distributeFeeEvent
+				for _, attr := range evt.Attributes {
+					switch attr.Key {
+					case "receiver":
+						item.receiver = attr.Value
+					case "fee":
+						item.fee = ibctool.ParseCoins(attr.Value)
+					default:
+					}
+				}
+				if item.receiver != "" && len(item.fee) > 0 {
+					res = append(res, item)
+				}
+			}
+		}
+	}
+
+	return res
+}
+
 // parseRecvPacketTxEvents parse ibc info from events of recv packet tx
 func parseRecvPacketTxEvents(msgIndex int, tx *entity.Tx) (dcConnection, packetAck string, existPacketAck bool) {
 	if len(tx.EventsNew) > msgIndex {
@@ -253,6 +469,24 @@ func parseRecvPacketTxEvents(msgIndex int, tx *entity.Tx) (dcConnection, packetA
 	return
 }
 
+// parseFungibleTokenPacketError the raw error of the ics20 packet, which is kept in the "error" attribute of
+// fungible_token_packet event of recv packet(or ack packet) tx
+func parseFungibleTokenPacketError(msgIndex int, tx *entity.Tx) string {
+	if len(tx.EventsNew) > msgIndex {
+		for _, evt := range tx.EventsNew[msgIndex].Events {
+			if evt.Type != "fungible_token_packet" {
+				continue
+			}
+			for _, attr := range evt.Attributes {
+				if attr.Key == "error" {
+					return attr.Value
+				}
+			}
+		}
+	}
+	return ""
+}
+
 // parseAckPacketTxEvents parse ibc info from events of ack packet tx
 func parseAckPacketTxEvents(msgIndex int, tx *entity.Tx) (existTransferEvent bool) {
 	if len(tx.EventsNew) > msgIndex {
@@ -266,6 +500,122 @@ func parseAckPacketTxEvents(msgIndex int, tx *entity.Tx) (existTransferEvent boo
 	return
 }
 
+func genPacketTxMapKey(chain, packetId string) string {
+	return fmt.Sprintf("%s_%s", chain, packetId)
+}
+
+// packetExpired 按 dc chain 最新块判断 packet 是否已超时, timeout_timestamp 为纳秒, 0 表示不限制
+func packetExpired(timeoutHeight, timeoutTimestamp int64, latestBlock *dto.HeightTimeDTO) bool {
+	if timeoutHeight > 0 && latestBlock.Height >= timeoutHeight {
+		return true
+	}
+	if timeoutTimestamp > 0 {
+		timeoutTime := timeoutTimestamp
+		if len(strconv.FormatInt(timeoutTimestamp, 10)) == 19 { // Nano
+			timeoutTime = timeoutTimestamp / int64(time.Second)
+		}
+		if latestBlock.Time >= timeoutTime {
+			return true
+		}
+	}
+	return false
+}
+
+// findPacketTxs find recv_packet txs on dc chain, acknowledge_packet and timeout_packet txs on sc chain by packet ids.
+// it is shared by the relate tasks of non-ics20 packets(ics721, ics27).
+func findPacketTxs(taskName, workerName, scChain string, packetIdsMap map[string][]*dto.PacketIdDTO) (recvPacketTxMap, ackTxMap map[string][]*entity.Tx, timeoutTxMap map[string]*entity.Tx, timeoutIbcTxMap, noFoundAckMap map[string]struct{}) {
+	chainLatestBlockMap := make(map[string]*dto.HeightTimeDTO)
+	var timeoutTxPacketIds, ackPacketIds []string
+	recvPacketTxMap = make(map[string][]*entity.Tx)
+	ackTxMap = make(map[string][]*entity.Tx)
+	timeoutTxMap = make(map[string]*entity.Tx)
+	timeoutIbcTxMap = make(map[string]struct{})
+	noFoundAckMap = make(map[string]struct{})
+	packetIdRecordMap := make(map[string]string)
+	status := entity.TxStatusSuccess
+
+	for dcChain, packetIds := range packetIdsMap {
+		if _, ok := chainLatestBlockMap[dcChain]; !ok {
+			if block, err := syncBlockRepo.FindLatestBlock(dcChain); err == nil {
+				chainLatestBlockMap[dcChain] = &dto.HeightTimeDTO{Height: block.Height, Time: block.Time}
+			} else {
+				logrus.Errorf("task %s worker %s chain %s findLatestBlock error, %v", taskName, workerName, dcChain, err)
+			}
+		}
+		latestBlock := chainLatestBlockMap[dcChain]
+
+		var recvPacketIds []string
+		for _, packet := range packetIds {
+			packetIdRecordMap[dcChain+packet.PacketId] = packet.ObjectId
+			recvPacketIds = append(recvPacketIds, packet.PacketId)
+			if latestBlock == nil { // dc chain 最新块未知时, 仍查询 timeout_packet tx
+				timeoutTxPacketIds = append(timeoutTxPacketIds, packet.PacketId)
+			} else if packetExpired(packet.TimeoutHeight, packet.TimeOutTime, latestBlock) {
+				timeoutTxPacketIds = append(timeoutTxPacketIds, packet.PacketId)
+				timeoutIbcTxMap[packet.ObjectId] = struct{}{}
+			}
+		}
+
+		// 处理 recv_packet tx
+		recvTxList, err := txRepo.FindByPacketIds(dcChain, constant.MsgTypeRecvPacket, recvPacketIds, nil)
+		if err != nil {
+			logrus.Errorf("task %s worker %s dc chain %s find recv txs error, %v", taskName, workerName, dcChain, err)
+			continue
+		}
+
+		for _, tx := range recvTxList {
+			for _, msg := range tx.DocTxMsgs {
+				if msg.Type != constant.MsgTypeRecvPacket {
+					continue
+				}
+				if packetId := msg.CommonMsg().PacketId; packetId != "" {
+					mk := genPacketTxMapKey(dcChain, packetId)
+					recvPacketTxMap[mk] = append(recvPacketTxMap[mk], tx)
+					// recv_packet成功时查询ack
+					if tx.Status == entity.TxStatusSuccess {
+						ackPacketIds = append(ackPacketIds, packetId)
+						if oid, ok := packetIdRecordMap[dcChain+packetId]; ok {
+							noFoundAckMap[oid] = struct{}{}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if len(timeoutTxPacketIds) > 0 {
+		timeoutTxList, err := txRepo.FindByPacketIds(scChain, constant.MsgTypeTimeoutPacket, timeoutTxPacketIds, &status)
+		if err == nil {
+			for _, tx := range timeoutTxList {
+				for _, msg := range tx.DocTxMsgs {
+					if packetId := msg.CommonMsg().PacketId; msg.Type == constant.MsgTypeTimeoutPacket && packetId != "" {
+						timeoutTxMap[genPacketTxMapKey(scChain, packetId)] = tx
+					}
+				}
+			}
+		} else {
+			logrus.Errorf("task %s worker %s sc chain %s find timeout txs error, %v", taskName, workerName, scChain, err)
+		}
+	}
+
+	if len(ackPacketIds) > 0 {
+		ackTxList, err := txRepo.FindByPacketIds(scChain, constant.MsgTypeAcknowledgement, ackPacketIds, &status)
+		if err == nil {
+			for _, tx := range ackTxList {
+				for _, msg := range tx.DocTxMsgs {
+					if packetId := msg.CommonMsg().PacketId; msg.Type == constant.MsgTypeAcknowledgement && packetId != "" {
+						mk := genPacketTxMapKey(scChain, packetId)
+						ackTxMap[mk] = append(ackTxMap[mk], tx)
+					}
+				}
+			}
+		} else {
+			logrus.Errorf("task %s worker %s sc chain %s find ack txs error, %v", taskName, workerName, scChain, err)
+		}
+	}
+	return
+}
+
 //并发处理全量数据
 func doHandleSegments(taskName string, workNum int, segments []*segment, isTargetHistory bool, dowork WorkerExecHandler) {
 	if workNum <= 0 {
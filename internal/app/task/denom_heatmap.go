@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -12,9 +13,9 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/distributiontask"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
@@ -42,9 +43,15 @@ func (t *DenomHeatmapTask) BeforeHook() error {
 	return nil
 }
 
-func (t *DenomHeatmapTask) Run() {
+func (t *DenomHeatmapTask) Run(ctx context.Context) {
+	RunWithRecord(t.Name(), entity.TaskRunTriggerSchedule, func() int {
+		return t.run(ctx)
+	})
+}
+
+func (t *DenomHeatmapTask) run(ctx context.Context) int {
 	if err := t.init(); err != nil {
-		return
+		return -1
 	}
 
 	nowTime := time.Now()
@@ -57,7 +64,7 @@ func (t *DenomHeatmapTask) Run() {
 
 	go func() {
 		defer wg.Done()
-		t.supplyHandler()
+		t.supplyHandler(ctx)
 	}()
 
 	go func() {
@@ -77,6 +84,7 @@ func (t *DenomHeatmapTask) Run() {
 	wg.Wait()
 
 	t.aggrData(statisticsTime, coinPriceMap, txVolumeMap)
+	return 1
 }
 
 func (t *DenomHeatmapTask) init() error {
@@ -149,43 +157,26 @@ func (t *DenomHeatmapTask) coinPriceHandler() (map[string]float64, error) {
 }
 
 // supplyHandler Get supply of denoms, then save supply info to cache
-func (t *DenomHeatmapTask) supplyHandler() {
+func (t *DenomHeatmapTask) supplyHandler(ctx context.Context) {
 	wg := sync.WaitGroup{}
 	wg.Add(len(t.chainConfigMap))
 	for _, v := range t.chainConfigMap {
 		cf := v
 		go func() {
 			defer wg.Done()
-			t.getSupplyFromLcd(cf)
+			t.getSupplyFromLcd(ctx, cf)
 		}()
 	}
 	wg.Wait()
 }
 
-func (t *DenomHeatmapTask) getSupplyFromLcd(chainCfg *entity.ChainConfig) {
+func (t *DenomHeatmapTask) getSupplyFromLcd(ctx context.Context, chainCfg *entity.ChainConfig) {
 	chain := chainCfg.ChainName
-	lcd := chainCfg.GrpcRestGateway
-	apiPath := chainCfg.LcdApiPath.SupplyPath
-	baseUrl := fmt.Sprintf("%s%s", lcd, apiPath)
 	limit := 1000
 	key := ""
 
 	for {
-		var url string
-		if key == "" {
-			url = fmt.Sprintf("%s?pagination.limit=%d", baseUrl, limit)
-		} else {
-			url = fmt.Sprintf("%s?pagination.limit=%d&pagination.key=%s", baseUrl, limit, key)
-		}
-
-		bz, err := utils.HttpGet(url)
-		if err != nil {
-			logrus.Errorf("task %s chain: %s setSupply error, %v", t.Name(), chain, err)
-			return
-		}
-
-		var supplyResp vo.SupplyResp
-		err = json.Unmarshal(bz, &supplyResp)
+		supplyResp, err := lcd.QuerySupply(ctx, chainCfg, limit, key)
 		if err != nil {
 			logrus.Errorf("task %s chain: %s setSupply error, %v", t.Name(), chain, err)
 			return
@@ -336,7 +327,7 @@ func (t *IBCDenomHopsTask) Switch() bool {
 	return true
 }
 
-func (t *IBCDenomHopsTask) Run() int {
+func (t *IBCDenomHopsTask) Run(ctx context.Context) int {
 	denomList, err := denomRepo.FindAll()
 	if err != nil {
 		logrus.Errorf("task %s denomRepo.FindAll err, %v", t.Name(), err)
@@ -1,7 +1,10 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func Test_DenomHeatmapTask(t *testing.T) {
-	denomHeatmapTask.Run()
+	denomHeatmapTask.Run(context.Background())
 }
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+const dryRunSampleLimit = 3
+
+// dryRunReport collects what a one-off repair task would change, the changes are merged by collection, operation,
+// chain and channel. the report of a dry run is saved to its task run, see setTaskRunReport
+type dryRunReport struct {
+	mux   sync.Mutex
+	items map[[4]string]*entity.DryRunItem
+}
+
+func newDryRunReport() *dryRunReport {
+	return &dryRunReport{
+		items: make(map[[4]string]*entity.DryRunItem),
+	}
+}
+
+func (r *dryRunReport) add(collection, operation, chain, channel string, matched int64, samples []entity.DryRunSample) {
+	if matched == 0 {
+		return
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	key := [4]string{collection, operation, chain, channel}
+	item, ok := r.items[key]
+	if !ok {
+		item = &entity.DryRunItem{
+			Collection: collection,
+			Operation:  operation,
+			Chain:      chain,
+			Channel:    channel,
+		}
+		r.items[key] = item
+	}
+	item.Matched += matched
+	for _, v := range samples {
+		if len(item.Samples) >= dryRunSampleLimit {
+			break
+		}
+		item.Samples = append(item.Samples, v)
+	}
+}
+
+// report the items are sorted by collection, operation, chain and channel
+func (r *dryRunReport) report() *entity.DryRunReport {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	items := make([]*entity.DryRunItem, 0, len(r.items))
+	for _, v := range r.items {
+		items = append(items, v)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Collection != b.Collection {
+			return a.Collection < b.Collection
+		}
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		if a.Chain != b.Chain {
+			return a.Chain < b.Chain
+		}
+		return a.Channel < b.Channel
+	})
+	return &entity.DryRunReport{Items: items}
+}
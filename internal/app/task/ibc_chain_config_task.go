@@ -1,12 +1,12 @@
 package task
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
@@ -36,7 +36,7 @@ func (t *IbcChainConfigTask) Cron() int {
 	return EveryMinute
 }
 
-func (t *IbcChainConfigTask) Run() int {
+func (t *IbcChainConfigTask) Run(ctx context.Context) int {
 	t.init()
 	chainConfList, err := t.getChainConf()
 	if err != nil {
@@ -51,17 +51,19 @@ func (t *IbcChainConfigTask) Run() int {
 		chain := v
 		go func() {
 			defer wg.Done()
-			channelPathList, err := t.getIbcChannels(chain.ChainName, chain.GrpcRestGateway, chain.LcdApiPath.ChannelsPath)
+			channelPathList, err := t.getIbcChannels(ctx, chain)
 			if err != nil {
 				t.chainUpdateMap.Store(chain.ChainName, false) // 出错时，此链的信息将不会被更新
 			} else {
-				t.setChainAndCounterpartyState(chain, channelPathList)
 				t.chainUpdateMap.Store(chain.ChainName, true)
 			}
 			t.chainChannelMap.Store(chain.ChainName, channelPathList)
 		}()
 	}
 	wg.Wait()
+	if ctx.Err() != nil { // 停止时channel信息不完整, 不更新
+		return -1
+	}
 
 	// 为channel设置counterparty state
 	for _, chain := range chainConfList {
@@ -106,36 +108,34 @@ func (t *IbcChainConfigTask) getChainConf() ([]*entity.ChainConfig, error) {
 	return chainConfList, nil
 }
 
-// getIbcChannels 通过lcd channels_path 接口获取链上存在的所有channel信息
-func (t *IbcChainConfigTask) getIbcChannels(chain, lcd, apiPath string) ([]*entity.ChannelPath, error) {
-	if lcd == "" {
+// getIbcChannels 通过lcd channels_path 接口按pagination.key逐页获取链上存在的所有channel信息
+// 1. 单页失败时只重试该页
+// 2. 部分节点不支持count_total, 不依赖total判断结束; 节点返回了total时校验channel数量, 避免用不完整的数据更新ibc_info
+// 3. 每页的channel在取回时即设置目标链, 只保留目标链在chain_config中的channel, 内存占用不随链上channel总数增长
+func (t *IbcChainConfigTask) getIbcChannels(ctx context.Context, chainCfg *entity.ChainConfig) ([]*entity.ChannelPath, error) {
+	chain := chainCfg.ChainName
+	if chainCfg.GrpcRestGateway == "" && chainCfg.GrpcAddr == "" {
 		logrus.Errorf("task %s %s getIbcChannels error, lcd error", t.Name(), chain)
 		return nil, fmt.Errorf("lcd error")
 	}
 
-	limit := 1000
-	offset := 0
+	existChannelStateMap := t.existChannelStateMap(chainCfg)
+	lcdConnectionErr := false
 	var channelPathList []*entity.ChannelPath
+	var key string
+	var total, count int
+	nextKeys := make(map[string]struct{})
 
 	for {
-		apiPath = strings.ReplaceAll(apiPath, replaceHolderOffset, strconv.Itoa(offset))
-		apiPath = strings.ReplaceAll(apiPath, replaceHolderLimit, strconv.Itoa(limit))
-		url := fmt.Sprintf("%s%s", lcd, apiPath)
-		bz, err := utils.HttpGet(url)
-		if err != nil {
-			logrus.Errorf("task %s %s getIbcChannels error, %v", t.Name(), chain, err)
-			return nil, err
-		}
-
-		var resp vo.IbcChannelsResp
-		err = json.Unmarshal(bz, &resp)
+		resp, err := t.queryChannelsPage(ctx, chainCfg, key)
 		if err != nil {
 			logrus.Errorf("task %s %s getIbcChannels error, %v", t.Name(), chain, err)
 			return nil, err
 		}
 
+		page := make([]*entity.ChannelPath, 0, len(resp.Channels))
 		for _, v := range resp.Channels {
-			channelPathList = append(channelPathList, &entity.ChannelPath{
+			page = append(page, &entity.ChannelPath{
 				State:     v.State,
 				PortId:    v.PortId,
 				ChannelId: v.ChannelId,
@@ -147,23 +147,62 @@ func (t *IbcChainConfigTask) getIbcChannels(chain, lcd, apiPath string) ([]*enti
 					ChannelId: v.Counterparty.ChannelId,
 				},
 			})
+		}
+		count += len(page)
+
+		lcdConnectionErr = t.setChainAndCounterpartyState(ctx, chainCfg, existChannelStateMap, page, lcdConnectionErr)
+		for _, v := range page {
+			if !utils.InArray(t.allChainList, v.Chain) {
+				continue
+			}
 			k := fmt.Sprintf("%s%s%s%s%s", chain, v.PortId, v.ChannelId, v.Counterparty.PortId, v.Counterparty.ChannelId)
 			t.channelStateMap.Store(k, v.State)
+			channelPathList = append(channelPathList, v)
 		}
 
-		if len(resp.Channels) < limit {
+		if pageTotal, _ := strconv.Atoi(resp.Pagination.Total); pageTotal > total {
+			total = pageTotal
+		}
+		if resp.Pagination.NextKey == nil || *resp.Pagination.NextKey == "" || len(resp.Channels) == 0 {
 			break
 		}
-		offset += limit
+
+		key = *resp.Pagination.NextKey
+		if _, ok := nextKeys[key]; ok { // 节点忽略了pagination.key, 会一直返回同一页
+			logrus.Errorf("task %s %s getIbcChannels error, next_key %s repeated", t.Name(), chain, key)
+			return nil, fmt.Errorf("pagination.key is ignored")
+		}
+		nextKeys[key] = struct{}{}
 	}
 
+	if total > count {
+		logrus.Errorf("task %s %s getIbcChannels error, got %d channels, total %d", t.Name(), chain, count, total)
+		return nil, fmt.Errorf("channels are incomplete")
+	}
 	return channelPathList, nil
 }
 
-// setChainAndCounterpartyState 设置channel path的目标链chain 和 目标链channel state
-// 1. 对于之前已经存在的channel，取之前的值即可;对于新增的channel，需要查询lcd 接口获取
-// 2. 对于之前已经存在的channel，目标链channel state，暂取之前的值，后面 setCounterpartyState 方法会进一步处理
-func (t *IbcChainConfigTask) setChainAndCounterpartyState(chain *entity.ChainConfig, channelPathList []*entity.ChannelPath) {
+func (t *IbcChainConfigTask) queryChannelsPage(ctx context.Context, chainCfg *entity.ChainConfig, key string) (*vo.IbcChannelsResp, error) {
+	var err error
+	for i := 0; i < channelsPageRetryTimes; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(time.Duration(i) * time.Second):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		var resp *vo.IbcChannelsResp
+		if resp, err = lcd.QueryChannels(ctx, chainCfg, channelsPageLimit, key); err == nil {
+			return resp, nil
+		}
+		logrus.Warningf("task %s %s query channels page(key: %s) error, retry times: %d, %v", t.Name(), chainCfg.ChainName, key, i, err)
+	}
+	return nil, err
+}
+
+func (t *IbcChainConfigTask) existChannelStateMap(chain *entity.ChainConfig) map[string]*entity.ChannelPath {
 	existChannelStateMap := make(map[string]*entity.ChannelPath)
 	for _, ibcInfo := range chain.IbcInfo {
 		for _, path := range ibcInfo.Paths {
@@ -171,8 +210,14 @@ func (t *IbcChainConfigTask) setChainAndCounterpartyState(chain *entity.ChainCon
 			existChannelStateMap[key] = path
 		}
 	}
+	return existChannelStateMap
+}
 
-	lcdConnectionErr := false
+// setChainAndCounterpartyState 设置channel path的目标链chain 和 目标链channel state, 返回是否遇到了lcd连接问题
+// 1. 对于之前已经存在的channel，取之前的值即可;对于新增的channel，需要查询lcd 接口获取
+// 2. 对于之前已经存在的channel，目标链channel state，暂取之前的值，后面 setCounterpartyState 方法会进一步处理
+func (t *IbcChainConfigTask) setChainAndCounterpartyState(ctx context.Context, chain *entity.ChainConfig, existChannelStateMap map[string]*entity.ChannelPath,
+	channelPathList []*entity.ChannelPath, lcdConnectionErr bool) bool {
 	for _, v := range channelPathList {
 		key := fmt.Sprintf("%s%s%s%s", v.PortId, v.ChannelId, v.Counterparty.PortId, v.Counterparty.ChannelId)
 		existChannelState, ok := existChannelStateMap[key]
@@ -185,7 +230,7 @@ func (t *IbcChainConfigTask) setChainAndCounterpartyState(chain *entity.ChainCon
 			v.ClientId = existChannelState.ClientId
 		} else {
 			if !lcdConnectionErr { // 如果遇到lcd连接问题，则不再请求lcd.
-				stateResp, err := lcd.QueryClientState(chain.GrpcRestGateway, chain.LcdApiPath.ClientStatePath, v.PortId, v.ChannelId)
+				stateResp, err := lcd.QueryClientState(ctx, chain, v.PortId, v.ChannelId)
 				if err != nil {
 					lcdConnectionErr = isConnectionErr(err)
 					logrus.Errorf("task %s %s queryClientState error, %v", t.Name(), chain.ChainName, err)
@@ -196,6 +241,7 @@ func (t *IbcChainConfigTask) setChainAndCounterpartyState(chain *entity.ChainCon
 			}
 		}
 	}
+	return lcdConnectionErr
 }
 
 func (t *IbcChainConfigTask) setCounterpartyState(chain string) {
@@ -240,7 +286,7 @@ func (t *IbcChainConfigTask) updateChain(chainConf *entity.ChainConfig) {
 	}
 
 	sort.Slice(ibcInfoList, func(i, j int) bool {
-		return ibcInfoList[i].Chain < ibcInfoList[i].Chain
+		return ibcInfoList[i].Chain < ibcInfoList[j].Chain
 	})
 
 	hashCode := utils.Md5(utils.MustMarshalJsonToStr(ibcInfoList))
@@ -1,7 +1,10 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func Test_ibcChainConfigTask(t *testing.T) {
-	_ibcChainConfigTask.Run()
+	_ibcChainConfigTask.Run(context.Background())
 }
@@ -1,24 +1,27 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 var chainOutflowStatisticsTask ChainOutflowStatisticsTask
 var chainInflowStatisticsTask ChainInflowStatisticsTask
 
 func Test_chainOutflowStatisticsTaskRunFullStatistics(t *testing.T) {
-	chainOutflowStatisticsTask.RunFullStatistics()
+	chainOutflowStatisticsTask.RunFullStatistics(context.Background())
 }
 
 func Test_chainOutflowStatisticsTaskRun(t *testing.T) {
-	chainOutflowStatisticsTask.Run()
+	chainOutflowStatisticsTask.Run(context.Background())
 }
 
 func Test_chainInflowStatisticsTaskRunFullStatistics(t *testing.T) {
-	chainInflowStatisticsTask.RunFullStatistics()
+	chainInflowStatisticsTask.RunFullStatistics(context.Background())
 }
 
 func Test_chainInflowStatisticsTaskRun(t *testing.T) {
-	chainInflowStatisticsTask.Run()
+	chainInflowStatisticsTask.Run(context.Background())
 }
 
 func Test_chainInflowSetStatisticsDataCache(t *testing.T) {
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
@@ -32,15 +33,15 @@ func (t *ChainInflowStatisticsTask) Cron() int {
 }
 
 // Run 增量更新
-func (t *ChainInflowStatisticsTask) Run() int {
+func (t *ChainInflowStatisticsTask) Run(ctx context.Context) int {
 	t.todayStatistics()
 	t.yesterdayStatistics()
 	t.setStatisticsDataCache()
 	return 1
 }
 
-// RunFullStatistics 全量更新
-func (t *ChainInflowStatisticsTask) RunFullStatistics() int {
+// RunFullStatistics 全量更新，每个分段完成后保存checkpoint，中断后从最后完成的分段继续
+func (t *ChainInflowStatisticsTask) RunFullStatistics(ctx context.Context) int {
 	t.segmentMinTime = math.MaxInt64
 	t.segmentStatisticsMap = make(map[string][]*dto.AggrIBCChainInflowDTO)
 	segments, err := getTxTimeSegment(false, segmentStepLatest)
@@ -57,22 +58,53 @@ func (t *ChainInflowStatisticsTask) RunFullStatistics() int {
 
 	t.segmentMinTime = segments[0].StartTime
 
+	checkpoint, err := loadStatisticsCheckpoint(t.Name())
+	if err != nil {
+		logrus.Errorf("task %s loadStatisticsCheckpoint err, %v", t.Name(), err)
+		return -1
+	}
+	scopeSegments := map[string][]*segment{checkpointScopeHistory: historySegments, checkpointScopeLatest: segments}
+	resumed, err := checkpoint.resume(scopeSegments, chainInflowStatisticsRepo)
+	if err != nil {
+		logrus.Errorf("task %s resume checkpoint err, %v", t.Name(), err)
+		return -1
+	}
+	if !resumed {
+		if err = chainInflowStatisticsRepo.DropNew(); err != nil {
+			logrus.Errorf("task %s DropNew err, %v", t.Name(), err)
+			return -1
+		}
+	}
+
 	if err := chainInflowStatisticsRepo.CreateNew(); err != nil {
 		logrus.Errorf("task %s CreateNew err, %v", t.Name(), err)
 		return -1
 	}
 
+	pendingHistorySegments := checkpoint.pending(checkpointScopeHistory, historySegments)
+	pendingSegments := checkpoint.pending(checkpointScopeLatest, segments)
+
 	// 先处理历史表
-	logrus.Infof("task %s deal history segment total: %d", t.Name(), len(historySegments))
-	t.deal(historySegments, true, true)
+	logrus.Infof("task %s deal history segment total: %d, pending: %d", t.Name(), len(historySegments), len(pendingHistorySegments))
+	if err = t.dealFull(ctx, pendingHistorySegments, true, checkpoint); err != nil {
+		logrus.Errorf("task %s deal history segment err, %v", t.Name(), err)
+		return -1
+	}
+	t.loadOverlapSegments(historySegments)
 
-	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
-	t.deal(segments, false, true)
+	logrus.Infof("task %s deal segment total: %d, pending: %d", t.Name(), len(segments), len(pendingSegments))
+	if err = t.dealFull(ctx, pendingSegments, false, checkpoint); err != nil {
+		logrus.Errorf("task %s deal segment err, %v", t.Name(), err)
+		return -1
+	}
 
 	if err = chainInflowStatisticsRepo.SwitchColl(); err != nil {
 		logrus.Errorf("task %s SwitchColl err, %v", t.Name(), err)
 		return -1
 	}
+	if err = checkpoint.clear(); err != nil {
+		logrus.Errorf("task %s clear checkpoint err, %v", t.Name(), err)
+	}
 
 	t.setStatisticsDataCache()
 	return 1
@@ -81,13 +113,18 @@ func (t *ChainInflowStatisticsTask) RunFullStatistics() int {
 // deal 对ibc tx表的数据进行统计
 //	- targetHistory true: 统计ex_ibc_tx表; false: 统计ex_ibc_tx_latest表
 //  - fullStatistics true: 统计数据写入新表(xxx_new); 当全量统计时，此值为true
-func (t *ChainInflowStatisticsTask) deal(segments []*segment, targetHistory bool, fullStatistics bool) {
+//  - 出错的分段不影响其他分段, 返回第一个出错分段的错误
+func (t *ChainInflowStatisticsTask) deal(segments []*segment, targetHistory bool, fullStatistics bool) error {
+	var dealErr error
 	for _, v := range segments {
 		logrus.Infof("task %s deal segment [%d, %d], targetHistory: %t", t.Name(), v.StartTime, v.EndTime, targetHistory)
 
 		aggrRes, err := ibcTxRepo.AggrIBCChainInflow(v.StartTime, v.EndTime, targetHistory)
 		if err != nil {
 			logrus.Errorf("task %s AggrIBCChainInflow segment [%d, %d], targetHistory: %t err, %v", t.Name(), v.StartTime, v.EndTime, targetHistory, err)
+			if dealErr == nil {
+				dealErr = err
+			}
 			continue
 		}
 
@@ -101,6 +138,52 @@ func (t *ChainInflowStatisticsTask) deal(segments []*segment, targetHistory bool
 
 		if err = t.saveData(aggrRes, v, targetHistory, fullStatistics); err != nil {
 			logrus.Errorf("task %s dealHistory saveData err, %v", t.Name(), err)
+			if dealErr == nil {
+				dealErr = err
+			}
+		}
+	}
+	return dealErr
+}
+
+// dealFull 全量统计，按分段保存checkpoint
+func (t *ChainInflowStatisticsTask) dealFull(ctx context.Context, segments []*segment, targetHistory bool, checkpoint *statisticsCheckpoint) error {
+	scope := checkpointScopeLatest
+	if targetHistory {
+		scope = checkpointScopeHistory
+	}
+
+	for _, v := range segments {
+		if ctx.Err() != nil {
+			return errStatisticsInterrupted
+		}
+
+		// 出错的分段不保存checkpoint, 恢复时重新统计
+		if err := t.deal([]*segment{v}, targetHistory, true); err != nil {
+			return err
+		}
+		if err := checkpoint.complete(scope, v); err != nil {
+			return err
+		}
+	}
+	return checkpoint.finish(scope)
+}
+
+// loadOverlapSegments 中断前已完成的历史表分段不在segmentStatisticsMap中，重新统计与新表重叠的分段
+func (t *ChainInflowStatisticsTask) loadOverlapSegments(historySegments []*segment) {
+	for _, v := range historySegments {
+		key := fmt.Sprintf("%d-%d", v.StartTime, v.EndTime)
+		if _, ok := t.segmentStatisticsMap[key]; ok || v.StartTime < t.segmentMinTime {
+			continue
+		}
+
+		aggrRes, err := ibcTxRepo.AggrIBCChainInflow(v.StartTime, v.EndTime, true)
+		if err != nil {
+			logrus.Errorf("task %s AggrIBCChainInflow segment [%d, %d], targetHistory: true err, %v", t.Name(), v.StartTime, v.EndTime, err)
+			continue
+		}
+		if len(aggrRes) > 0 {
+			t.segmentStatisticsMap[key] = aggrRes
 		}
 	}
 }
@@ -169,11 +252,8 @@ func (t *ChainInflowStatisticsTask) saveData(aggrRes []*dto.AggrIBCChainInflowDT
 
 	var err error
 	if fullStatistics {
-		if targetHistory {
-			err = chainInflowStatisticsRepo.InsertManyToNew(entityList)
-		} else {
-			err = chainInflowStatisticsRepo.BatchSwapNew(seg.StartTime, seg.EndTime, entityList)
-		}
+		// 按分段替换，中断后重新处理的分段不会重复写入
+		err = chainInflowStatisticsRepo.BatchSwapNew(seg.StartTime, seg.EndTime, entityList)
 	} else {
 		err = chainInflowStatisticsRepo.BatchSwap(seg.StartTime, seg.EndTime, entityList)
 	}
@@ -191,7 +271,7 @@ func (t *ChainInflowStatisticsTask) todayStatistics() {
 		},
 	}
 
-	t.deal(segments, false, false)
+	_ = t.deal(segments, false, false)
 }
 
 func (t *ChainInflowStatisticsTask) yesterdayStatistics() {
@@ -201,7 +281,7 @@ func (t *ChainInflowStatisticsTask) yesterdayStatistics() {
 	}
 
 	logrus.Infof("task %s check yeaterday statistics", t.Name())
-	t.deal([]*segment{seg}, false, false)
+	_ = t.deal([]*segment{seg}, false, false)
 }
 
 func (t *ChainInflowStatisticsTask) setStatisticsDataCache() {
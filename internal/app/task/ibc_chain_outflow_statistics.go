@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
@@ -31,15 +32,15 @@ func (t *ChainOutflowStatisticsTask) Cron() int {
 }
 
 // Run 增量更新
-func (t *ChainOutflowStatisticsTask) Run() int {
+func (t *ChainOutflowStatisticsTask) Run(ctx context.Context) int {
 	t.todayStatistics()
 	t.yesterdayStatistics()
 	t.setStatisticsDataCache()
 	return 1
 }
 
-// RunFullStatistics 全量更新
-func (t *ChainOutflowStatisticsTask) RunFullStatistics() int {
+// RunFullStatistics 全量更新，每个分段完成后保存checkpoint，中断后从最后完成的分段继续
+func (t *ChainOutflowStatisticsTask) RunFullStatistics(ctx context.Context) int {
 	t.segmentMinTime = math.MaxInt64
 	t.segmentStatisticsMap = make(map[string][]*dto.AggrIBCChainOutflowDTO)
 	segments, err := getTxTimeSegment(false, segmentStepLatest)
@@ -56,22 +57,53 @@ func (t *ChainOutflowStatisticsTask) RunFullStatistics() int {
 
 	t.segmentMinTime = segments[0].StartTime
 
+	checkpoint, err := loadStatisticsCheckpoint(t.Name())
+	if err != nil {
+		logrus.Errorf("task %s loadStatisticsCheckpoint err, %v", t.Name(), err)
+		return -1
+	}
+	scopeSegments := map[string][]*segment{checkpointScopeHistory: historySegments, checkpointScopeLatest: segments}
+	resumed, err := checkpoint.resume(scopeSegments, chainOutflowStatisticsRepo)
+	if err != nil {
+		logrus.Errorf("task %s resume checkpoint err, %v", t.Name(), err)
+		return -1
+	}
+	if !resumed {
+		if err = chainOutflowStatisticsRepo.DropNew(); err != nil {
+			logrus.Errorf("task %s DropNew err, %v", t.Name(), err)
+			return -1
+		}
+	}
+
 	if err := chainOutflowStatisticsRepo.CreateNew(); err != nil {
 		logrus.Errorf("task %s CreateNew err, %v", t.Name(), err)
 		return -1
 	}
 
+	pendingHistorySegments := checkpoint.pending(checkpointScopeHistory, historySegments)
+	pendingSegments := checkpoint.pending(checkpointScopeLatest, segments)
+
 	// 先处理历史表
-	logrus.Infof("task %s deal history segment total: %d", t.Name(), len(historySegments))
-	t.deal(historySegments, true, true)
+	logrus.Infof("task %s deal history segment total: %d, pending: %d", t.Name(), len(historySegments), len(pendingHistorySegments))
+	if err = t.dealFull(ctx, pendingHistorySegments, true, checkpoint); err != nil {
+		logrus.Errorf("task %s deal history segment err, %v", t.Name(), err)
+		return -1
+	}
+	t.loadOverlapSegments(historySegments)
 
-	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
-	t.deal(segments, false, true)
+	logrus.Infof("task %s deal segment total: %d, pending: %d", t.Name(), len(segments), len(pendingSegments))
+	if err = t.dealFull(ctx, pendingSegments, false, checkpoint); err != nil {
+		logrus.Errorf("task %s deal segment err, %v", t.Name(), err)
+		return -1
+	}
 
 	if err = chainOutflowStatisticsRepo.SwitchColl(); err != nil {
 		logrus.Errorf("task %s SwitchColl err, %v", t.Name(), err)
 		return -1
 	}
+	if err = checkpoint.clear(); err != nil {
+		logrus.Errorf("task %s clear checkpoint err, %v", t.Name(), err)
+	}
 
 	t.setStatisticsDataCache()
 	return 1
@@ -80,13 +112,18 @@ func (t *ChainOutflowStatisticsTask) RunFullStatistics() int {
 // deal 对ibc tx表的数据进行统计
 //	- targetHistory true: 统计ex_ibc_tx表; false: 统计ex_ibc_tx_latest表
 //  - fullStatistics true: 统计数据写入新表(xxx_new); 当全量统计时，此值为true
-func (t *ChainOutflowStatisticsTask) deal(segments []*segment, targetHistory bool, fullStatistics bool) {
+//  - 出错的分段不影响其他分段, 返回第一个出错分段的错误
+func (t *ChainOutflowStatisticsTask) deal(segments []*segment, targetHistory bool, fullStatistics bool) error {
+	var dealErr error
 	for _, v := range segments {
 		logrus.Infof("task %s deal segment [%d, %d], targetHistory: %t", t.Name(), v.StartTime, v.EndTime, targetHistory)
 
 		aggrRes, err := ibcTxRepo.AggrIBCChainOutflow(v.StartTime, v.EndTime, targetHistory)
 		if err != nil {
 			logrus.Errorf("task %s AggrIBCChainOutflow segment [%d, %d], targetHistory: %t err, %v", t.Name(), v.StartTime, v.EndTime, targetHistory, err)
+			if dealErr == nil {
+				dealErr = err
+			}
 			continue
 		}
 
@@ -100,6 +137,52 @@ func (t *ChainOutflowStatisticsTask) deal(segments []*segment, targetHistory boo
 
 		if err = t.saveData(aggrRes, v, targetHistory, fullStatistics); err != nil {
 			logrus.Errorf("task %s dealHistory saveData err, %v", t.Name(), err)
+			if dealErr == nil {
+				dealErr = err
+			}
+		}
+	}
+	return dealErr
+}
+
+// dealFull 全量统计，按分段保存checkpoint
+func (t *ChainOutflowStatisticsTask) dealFull(ctx context.Context, segments []*segment, targetHistory bool, checkpoint *statisticsCheckpoint) error {
+	scope := checkpointScopeLatest
+	if targetHistory {
+		scope = checkpointScopeHistory
+	}
+
+	for _, v := range segments {
+		if ctx.Err() != nil {
+			return errStatisticsInterrupted
+		}
+
+		// 出错的分段不保存checkpoint, 恢复时重新统计
+		if err := t.deal([]*segment{v}, targetHistory, true); err != nil {
+			return err
+		}
+		if err := checkpoint.complete(scope, v); err != nil {
+			return err
+		}
+	}
+	return checkpoint.finish(scope)
+}
+
+// loadOverlapSegments 中断前已完成的历史表分段不在segmentStatisticsMap中，重新统计与新表重叠的分段
+func (t *ChainOutflowStatisticsTask) loadOverlapSegments(historySegments []*segment) {
+	for _, v := range historySegments {
+		key := fmt.Sprintf("%d-%d", v.StartTime, v.EndTime)
+		if _, ok := t.segmentStatisticsMap[key]; ok || v.StartTime < t.segmentMinTime {
+			continue
+		}
+
+		aggrRes, err := ibcTxRepo.AggrIBCChainOutflow(v.StartTime, v.EndTime, true)
+		if err != nil {
+			logrus.Errorf("task %s AggrIBCChainOutflow segment [%d, %d], targetHistory: true err, %v", t.Name(), v.StartTime, v.EndTime, err)
+			continue
+		}
+		if len(aggrRes) > 0 {
+			t.segmentStatisticsMap[key] = aggrRes
 		}
 	}
 }
@@ -168,11 +251,8 @@ func (t *ChainOutflowStatisticsTask) saveData(aggrRes []*dto.AggrIBCChainOutflow
 
 	var err error
 	if fullStatistics {
-		if targetHistory {
-			err = chainOutflowStatisticsRepo.InsertManyToNew(entityList)
-		} else {
-			err = chainOutflowStatisticsRepo.BatchSwapNew(seg.StartTime, seg.EndTime, entityList)
-		}
+		// 按分段替换，中断后重新处理的分段不会重复写入
+		err = chainOutflowStatisticsRepo.BatchSwapNew(seg.StartTime, seg.EndTime, entityList)
 	} else {
 		err = chainOutflowStatisticsRepo.BatchSwap(seg.StartTime, seg.EndTime, entityList)
 	}
@@ -190,7 +270,7 @@ func (t *ChainOutflowStatisticsTask) todayStatistics() {
 		},
 	}
 
-	t.deal(segments, false, false)
+	_ = t.deal(segments, false, false)
 }
 
 func (t *ChainOutflowStatisticsTask) yesterdayStatistics() {
@@ -200,7 +280,7 @@ func (t *ChainOutflowStatisticsTask) yesterdayStatistics() {
 	}
 
 	logrus.Infof("task %s check yeaterday statistics", t.Name())
-	t.deal([]*segment{seg}, false, false)
+	_ = t.deal([]*segment{seg}, false, false)
 }
 
 func (t *ChainOutflowStatisticsTask) setStatisticsDataCache() {
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/sirupsen/logrus"
 	"time"
@@ -18,7 +19,7 @@ func (t *IbcChainCronTask) Cron() int {
 	}
 	return EveryMinute
 }
-func (t *IbcChainCronTask) Run() int {
+func (t *IbcChainCronTask) Run(ctx context.Context) int {
 	chainCfgs, err := chainConfigRepo.FindAll()
 	if err != nil {
 		logrus.Errorf("task %s run error, %s", t.Name(), err.Error())
@@ -47,7 +47,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestRunOnce(t *testing.T) {
-	new(IbcChainCronTask).Run()
+	new(IbcChainCronTask).Run(context.Background())
 }
 
 func Test_CheckFollowingStatus(t *testing.T) {
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
@@ -31,7 +32,7 @@ func ChannelIncrementStatistics(segments []*segment) error {
 	return channelStatisticsTask.deal(segments, false)
 }
 
-func (t *ChannelStatisticsTask) Run() int {
+func (t *ChannelStatisticsTask) Run(ctx context.Context) int {
 	t.segmentMinTime = math.MaxInt64
 	t.segmentChannelStatisticsMap = make(map[string][]*dto.ChannelStatisticsDTO)
 
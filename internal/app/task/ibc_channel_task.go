@@ -1,9 +1,8 @@
 package task
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"math"
 	"strings"
 	"sync"
@@ -12,6 +11,7 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
@@ -37,7 +37,7 @@ func (t *ChannelTask) Cron() int {
 	return ThreeMinute
 }
 
-func (t *ChannelTask) Run() int {
+func (t *ChannelTask) Run(ctx context.Context) int {
 	t.clear()
 	if err := t.analyzeChainConfig(); err != nil {
 		return -1
@@ -83,7 +83,7 @@ func (t *ChannelTask) Run() int {
 		chainCfgMap[v.ChainName] = v
 	}
 
-	if err = t.setPendingTxs(chainCfgMap, existedChannelList, newChannelList); err != nil { // 统计pendingTx数量
+	if err = t.setPendingTxs(ctx, chainCfgMap, existedChannelList, newChannelList); err != nil { // 统计pendingTx数量
 		logrus.Errorf("task %s setPendingTxs error, %v", t.Name(), err)
 		return -1
 	}
@@ -342,7 +342,7 @@ func (t *ChannelTask) setTransferTxs(existedChannelList entity.IBCChannelList, n
 	return nil
 }
 
-func (t *ChannelTask) setPendingTxs(chainCfgMap map[string]*entity.ChainConfig, existedChannelList entity.IBCChannelList, newChannelList entity.IBCChannelList) error {
+func (t *ChannelTask) setPendingTxs(ctx context.Context, chainCfgMap map[string]*entity.ChainConfig, existedChannelList entity.IBCChannelList, newChannelList entity.IBCChannelList) error {
 	chainPendingTxs := func(channel *entity.IBCChannel) {
 		wg := sync.WaitGroup{}
 		var chainAPendingTxs, chainBPendingTxs int
@@ -353,7 +353,7 @@ func (t *ChannelTask) setPendingTxs(chainCfgMap map[string]*entity.ChainConfig,
 			if chainACfg != nil && channel.ChannelA != "" {
 				//todo not only support 'transfer' port
 				//pendingTxCnt, err := t.getPengingTxsFromLcd(channel.ChannelA, "transfer", chainACfg.GrpcRestGateway, chainACfg.LcdApiPath.PacketCommitsPath)
-				pendingTxCnt, err := t.getPengingTxsFromLcd(channel.ChannelA, "transfer", chainACfg.GrpcRestGateway, strings.ReplaceAll(chainACfg.LcdApiPath.ClientStatePath, "client_state", "packet_commitments"))
+				pendingTxCnt, err := t.getPengingTxsFromLcd(ctx, chainACfg, channel.ChannelA, "transfer")
 				if err != nil {
 					logrus.Error(err.Error())
 					return
@@ -369,7 +369,7 @@ func (t *ChannelTask) setPendingTxs(chainCfgMap map[string]*entity.ChainConfig,
 			if chainBCfg != nil && channel.ChannelB != "" {
 				//todo not only support 'transfer' port
 				//pendingTxCnt, err := t.getPengingTxsFromLcd(channel.ChannelB, "transfer", chainBCfg.GrpcRestGateway, chainBCfg.LcdApiPath.PacketCommitsPath)
-				pendingTxCnt, err := t.getPengingTxsFromLcd(channel.ChannelB, "transfer", chainBCfg.GrpcRestGateway, strings.ReplaceAll(chainBCfg.LcdApiPath.ClientStatePath, "client_state", "packet_commitments"))
+				pendingTxCnt, err := t.getPengingTxsFromLcd(ctx, chainBCfg, channel.ChannelB, "transfer")
 				if err != nil {
 					logrus.Error(err.Error())
 					return
@@ -407,18 +407,8 @@ func (t *ChannelTask) setPendingTxs(chainCfgMap map[string]*entity.ChainConfig,
 	return nil
 }
 
-func (t *ChannelTask) getPengingTxsFromLcd(channel, port, lcd, apiPath string) (*int, error) {
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderChannel, channel)
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderPort, port)
-	url := fmt.Sprintf("%s%s", lcd, apiPath)
-
-	bz, err := utils.HttpGet(url)
-	if err != nil {
-		return nil, err
-	}
-
-	var resp vo.IbcPacketCommitsResp
-	err = json.Unmarshal(bz, &resp)
+func (t *ChannelTask) getPengingTxsFromLcd(ctx context.Context, chainCfg *entity.ChainConfig, channel, port string) (*int, error) {
+	resp, err := lcd.QueryPacketCommitments(ctx, chainCfg, port, channel)
 	if err != nil {
 		return nil, err
 	}
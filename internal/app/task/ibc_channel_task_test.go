@@ -1,15 +1,16 @@
 package task
 
 import (
+	"context"
 	"testing"
 )
 
 var channelTask ChannelTask
 
 func TestChannelTaskRun(t *testing.T) {
-	channelTask.Run()
+	channelTask.Run(context.Background())
 }
 
 func Test_ChannelStatistics(t *testing.T) {
-	channelStatisticsTask.Run()
+	channelStatisticsTask.Run(context.Background())
 }
@@ -0,0 +1,260 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+	"github.com/qiniu/qmgo"
+	"github.com/sirupsen/logrus"
+)
+
+// IbcClientTask record all the light clients of the chains, the expire time of a client is the timestamp of its
+// latest consensus state plus the trusting period. an expired client can not be updated any more, the channels on it
+// are stuck until the client is recovered by governance
+type IbcClientTask struct {
+}
+
+var _ Task = new(IbcClientTask)
+
+func (t *IbcClientTask) Name() string {
+	return "ibc_client_task"
+}
+
+func (t *IbcClientTask) Cron() int {
+	if taskConf.CronTimeIbcClientTask > 0 {
+		return taskConf.CronTimeIbcClientTask
+	}
+	return TenMinute
+}
+
+func (t *IbcClientTask) Run(ctx context.Context) int {
+	chainList, err := chainConfigRepo.FindAll()
+	if err != nil {
+		logrus.Errorf("task %s FindAll chain config err, %v", t.Name(), err)
+		return -1
+	}
+
+	chainIdNameMap := make(map[string]string, len(chainList))
+	for _, cfg := range chainList {
+		chainIdNameMap[cfg.CurrentChainId] = cfg.ChainName
+	}
+
+	res := 1
+	for _, cfg := range chainList {
+		if ctx.Err() != nil {
+			return -1
+		}
+		if cfg.Status == entity.ChainStatusClosed {
+			err = t.deleteChainMetrics(cfg.ChainName)
+		} else {
+			err = t.dealChain(ctx, cfg, chainIdNameMap)
+		}
+		if err != nil {
+			logrus.Errorf("task %s deal chain %s err, %v", t.Name(), cfg.ChainName, err)
+			res = -1
+		}
+	}
+	return res
+}
+
+// clientPath the channel and the counterparty chain of a client in chain_config.ibc_info
+type clientPath struct {
+	counterpartyChain string
+	path              *entity.ChannelPath
+}
+
+// dealChain the clients are listed by client_states. lcd errors are skipped, the client is retried next round. the
+// clients gone from a complete listing are removed
+func (t *IbcClientTask) dealChain(ctx context.Context, cfg *entity.ChainConfig, chainIdNameMap map[string]string) error {
+	existClients, err := ibcClientRepo.FindByChain(cfg.ChainName)
+	if err != nil {
+		return err
+	}
+	existMap := make(map[string]*entity.IBCClient, len(existClients))
+	for _, v := range existClients {
+		existMap[v.ClientId] = v
+	}
+
+	pathMap := make(map[string]clientPath)
+	for _, info := range cfg.IbcInfo {
+		for _, path := range info.Paths {
+			if _, ok := pathMap[path.ClientId]; !ok && path.ClientId != "" {
+				pathMap[path.ClientId] = clientPath{counterpartyChain: info.Chain, path: path}
+			}
+		}
+	}
+
+	now := time.Now().Unix()
+	listed := make(map[string]struct{}, len(existClients))
+	var key string
+	for {
+		resp, err := lcd.QueryClientStates(ctx, cfg, clientStatesPageLimit, key)
+		if err != nil {
+			logrus.Warningf("task %s chain %s query client states(key: %s) err, %v", t.Name(), cfg.ChainName, key, err)
+			return nil
+		}
+
+		for i := range resp.ClientStates {
+			state := &resp.ClientStates[i]
+			if state.ClientId == "" {
+				continue
+			}
+			listed[state.ClientId] = struct{}{}
+
+			cp, ok := pathMap[state.ClientId]
+			if !ok {
+				cp.counterpartyChain = chainIdNameMap[state.ClientState.ChainId]
+			}
+			if err = t.dealClient(ctx, cfg, state, cp, existMap[state.ClientId], now); err != nil {
+				return err
+			}
+		}
+
+		if resp.Pagination.NextKey == nil || *resp.Pagination.NextKey == "" {
+			break
+		}
+		key = *resp.Pagination.NextKey
+	}
+
+	var removed []string
+	for clientId := range existMap {
+		if _, ok := listed[clientId]; !ok {
+			removed = append(removed, clientId)
+			monitor.DeleteClientExpireMetric(cfg.ChainName, clientId)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	logrus.Infof("task %s chain %s remove clients %v", t.Name(), cfg.ChainName, removed)
+	return ibcClientRepo.DeleteClients(cfg.ChainName, removed)
+}
+
+func (t *IbcClientTask) dealClient(ctx context.Context, cfg *entity.ChainConfig, state *vo.IdentifiedClientState, cp clientPath, exist *entity.IBCClient, now int64) error {
+	consensusTime, err := t.consensusTime(ctx, cfg, state, exist)
+	if err != nil {
+		logrus.Warningf("task %s chain %s query consensus state of client %s err, %v", t.Name(), cfg.ChainName, state.ClientId, err)
+		return nil
+	}
+	client := newIBCClient(cfg.ChainName, cp.counterpartyChain, cp.path, state, consensusTime, now)
+
+	// the update_client txs before the latest known one are not scanned again
+	startTime := now - clientUpdateTxWindow
+	if exist != nil {
+		client.LatestUpdateTxTime = exist.LatestUpdateTxTime
+		if exist.LatestUpdateTxTime > startTime {
+			startTime = exist.LatestUpdateTxTime
+		}
+	}
+	latestUpdateTxTime, err := txRepo.GetLatestUpdateClientTime(cfg.ChainName, client.ClientId, startTime)
+	switch {
+	case err == nil:
+		client.LatestUpdateTxTime = latestUpdateTxTime
+	case err != qmgo.ErrNoSuchDocuments:
+		logrus.Errorf("task %s chain %s GetLatestUpdateClientTime %s err, %v", t.Name(), cfg.ChainName, client.ClientId, err)
+		return err
+	}
+	if client.UpdateTxs24h, err = txRepo.CountUpdateClientTxs(cfg.ChainName, client.ClientId, now-OneDay); err != nil {
+		logrus.Errorf("task %s chain %s CountUpdateClientTxs %s err, %v", t.Name(), cfg.ChainName, client.ClientId, err)
+		return err
+	}
+
+	if err = ibcClientRepo.Save(client); err != nil {
+		logrus.Errorf("task %s chain %s save client %s err, %v", t.Name(), cfg.ChainName, client.ClientId, err)
+		return err
+	}
+	if client.ExpireTime > 0 && client.Status != entity.ClientStatusFrozen {
+		monitor.SetClientExpireMetricValue(cfg.ChainName, client.ClientId, float64(client.ExpireTime-now))
+	} else {
+		monitor.DeleteClientExpireMetric(cfg.ChainName, client.ClientId)
+	}
+	return nil
+}
+
+// consensusTime timestamp of the consensus state at the latest height, only clients with a trusting period need it.
+// it is not queried again if the latest height is not changed
+func (t *IbcClientTask) consensusTime(ctx context.Context, cfg *entity.ChainConfig, state *vo.IdentifiedClientState, exist *entity.IBCClient) (int64, error) {
+	clientState := state.ClientState
+	if clientState.TrustingPeriod == "" {
+		return 0, nil
+	}
+	if exist != nil && exist.LastUpdateTime > 0 &&
+		exist.LatestHeight == clientHeight(clientState.LatestHeight.RevisionNumber, clientState.LatestHeight.RevisionHeight) {
+		return exist.LastUpdateTime, nil
+	}
+
+	revisionNumber, _ := strconv.ParseInt(clientState.LatestHeight.RevisionNumber, 10, 64)
+	revisionHeight, _ := strconv.ParseInt(clientState.LatestHeight.RevisionHeight, 10, 64)
+	if revisionHeight <= 0 {
+		return 0, nil
+	}
+	consensus, err := lcd.QueryClientConsensusState(ctx, cfg, state.ClientId, revisionNumber, revisionHeight)
+	if err != nil {
+		return 0, err
+	}
+	if consensus.ConsensusState.Timestamp.IsZero() {
+		return 0, nil
+	}
+	return consensus.ConsensusState.Timestamp.Unix(), nil
+}
+
+// deleteChainMetrics the chain is closed, its clients are not tracked any more
+func (t *IbcClientTask) deleteChainMetrics(chain string) error {
+	clients, err := ibcClientRepo.FindByChain(chain)
+	if err != nil {
+		return err
+	}
+	for _, v := range clients {
+		monitor.DeleteClientExpireMetric(chain, v.ClientId)
+	}
+	return nil
+}
+
+// newIBCClient clients without a trusting period(solo machine, localhost) never expire, ExpireTime is 0. path is nil
+// if the client is not on any channel of chain_config.ibc_info
+func newIBCClient(chain, counterpartyChain string, path *entity.ChannelPath, state *vo.IdentifiedClientState, consensusTime, now int64) *entity.IBCClient {
+	clientState := state.ClientState
+	client := &entity.IBCClient{
+		Chain:             chain,
+		ClientId:          state.ClientId,
+		ClientType:        clientState.Type,
+		CounterpartyChain: counterpartyChain,
+		ChainId:           clientState.ChainId,
+		LatestHeight:      clientHeight(clientState.LatestHeight.RevisionNumber, clientState.LatestHeight.RevisionHeight),
+		LastUpdateTime:    consensusTime,
+		Status:            entity.ClientStatusActive,
+	}
+	if path != nil {
+		client.PortId = path.PortId
+		client.ChannelId = path.ChannelId
+	}
+	if trustingPeriod, err := time.ParseDuration(clientState.TrustingPeriod); err == nil {
+		client.TrustingPeriod = int64(trustingPeriod.Seconds())
+	}
+	if consensusTime > 0 && client.TrustingPeriod > 0 {
+		client.ExpireTime = consensusTime + client.TrustingPeriod
+	}
+
+	switch {
+	case clientState.FrozenHeight.RevisionHeight != "" && clientState.FrozenHeight.RevisionHeight != "0":
+		client.FrozenHeight = clientHeight(clientState.FrozenHeight.RevisionNumber, clientState.FrozenHeight.RevisionHeight)
+		client.Status = entity.ClientStatusFrozen
+	case client.ExpireTime > 0 && client.ExpireTime <= now:
+		client.Status = entity.ClientStatusExpired
+	}
+	return client
+}
+
+// clientHeight height in the format of ibc-go, {revision_number}-{revision_height}
+func clientHeight(revisionNumber, revisionHeight string) string {
+	if revisionNumber == "" {
+		revisionNumber = "0"
+	}
+	return fmt.Sprintf("%s-%s", revisionNumber, revisionHeight)
+}
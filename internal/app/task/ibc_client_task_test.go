@@ -0,0 +1,45 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+func Test_newIBCClient(t *testing.T) {
+	path := &entity.ChannelPath{PortId: "transfer", ChannelId: "channel-0", ClientId: "07-tendermint-0"}
+	var state vo.IdentifiedClientState
+	state.ClientId = "07-tendermint-0"
+	state.ClientState.Type = "/ibc.lightclients.tendermint.v1.ClientState"
+	state.ClientState.ChainId = "cosmoshub-4"
+	state.ClientState.TrustingPeriod = "1209600s"
+	state.ClientState.LatestHeight.RevisionNumber = "4"
+	state.ClientState.LatestHeight.RevisionHeight = "100"
+
+	client := newIBCClient("osmosis", "cosmoshub", path, &state, 1000, 2000)
+	if client.ClientId != "07-tendermint-0" || client.LatestHeight != "4-100" || client.TrustingPeriod != 1209600 ||
+		client.ExpireTime != 1210600 || client.Status != entity.ClientStatusActive {
+		t.Fatalf("unexpected client %+v", client)
+	}
+
+	if client = newIBCClient("osmosis", "cosmoshub", path, &state, 1000, 1210600); client.Status != entity.ClientStatusExpired {
+		t.Fatalf("client is not expired, %+v", client)
+	}
+
+	state.ClientState.FrozenHeight.RevisionHeight = "90"
+	if client = newIBCClient("osmosis", "cosmoshub", path, &state, 1000, 1210600); client.Status != entity.ClientStatusFrozen || client.FrozenHeight != "0-90" {
+		t.Fatalf("client is not frozen, %+v", client)
+	}
+}
+
+func Test_newIBCClientWithoutPath(t *testing.T) {
+	var state vo.IdentifiedClientState
+	state.ClientId = "06-solomachine-0"
+	state.ClientState.Type = "/ibc.lightclients.solomachine.v2.ClientState"
+
+	client := newIBCClient("osmosis", "", nil, &state, 0, 2000)
+	if client.ClientId != "06-solomachine-0" || client.PortId != "" || client.ExpireTime != 0 || client.Status != entity.ClientStatusActive {
+		t.Fatalf("unexpected client %+v", client)
+	}
+}
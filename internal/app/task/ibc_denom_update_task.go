@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
@@ -22,7 +23,7 @@ func (t *IbcDenomUpdateTask) Cron() int {
 	return ThreeMinute
 }
 
-func (t *IbcDenomUpdateTask) Run() int {
+func (t *IbcDenomUpdateTask) Run(ctx context.Context) int {
 	denomSymbolMap, err := t.getBaseDenomSysbolMap()
 	if err != nil {
 		return -1
@@ -0,0 +1,318 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// IbcIcaTxRelateTask relate recv_packet, acknowledge_packet and timeout_packet txs to ics27 packets.
+// nothing is refunded for ics27 packets, so error ack and timeout are both recorded as failed.
+type IbcIcaTxRelateTask struct {
+}
+
+var _ Task = new(IbcIcaTxRelateTask)
+var icaRelateCoordinator *stringQueueCoordinator
+
+func (t *IbcIcaTxRelateTask) Name() string {
+	return "ibc_ica_tx_relate_task"
+}
+
+func (t *IbcIcaTxRelateTask) Cron() int {
+	if taskConf.CronTimeIbcIcaTxRelateTask > 0 {
+		return taskConf.CronTimeIbcIcaTxRelateTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcIcaTxRelateTask) workerNum() int {
+	if global.Config.Task.IbcTxRelateWorkerNum > 0 {
+		return global.Config.Task.IbcTxRelateWorkerNum
+	}
+	return ibcTxRelateTaskWorkerNum
+}
+
+func (t *IbcIcaTxRelateTask) Run(ctx context.Context) int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+
+	// init coordinator
+	chainQueue := new(utils.QueueString)
+	for _, v := range chainMap {
+		chainQueue.Push(v.ChainName)
+	}
+	icaRelateCoordinator = &stringQueueCoordinator{
+		stringQueue: chainQueue,
+	}
+
+	workerNum := t.workerNum()
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(workerNum)
+	for i := 1; i <= workerNum; i++ {
+		workName := fmt.Sprintf("worker-%d", i)
+		go func(wn string) {
+			newIbcIcaTxRelateWorker(t.Name(), wn, chainMap).exec(ctx)
+			waitGroup.Done()
+		}(workName)
+	}
+	waitGroup.Wait()
+
+	return 1
+}
+
+// =========================================================================
+// =========================================================================
+// worker
+
+func newIbcIcaTxRelateWorker(taskName, workerName string, chainMap map[string]*entity.ChainConfig) *ibcIcaTxRelateWorker {
+	return &ibcIcaTxRelateWorker{
+		taskName:   taskName,
+		workerName: workerName,
+		chainMap:   chainMap,
+	}
+}
+
+type ibcIcaTxRelateWorker struct {
+	taskName   string
+	workerName string
+	chainMap   map[string]*entity.ChainConfig
+}
+
+func (w *ibcIcaTxRelateWorker) exec(ctx context.Context) {
+	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
+	for {
+		chain, err := icaRelateCoordinator.getOne(ctx)
+		if err != nil {
+			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
+			break
+		}
+
+		if cf, ok := w.chainMap[chain]; ok && cf.Status == entity.ChainStatusClosed {
+			logrus.Infof("task %s worker %s chain %s is closed", w.taskName, w.workerName, chain)
+			continue
+		}
+
+		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
+		startTime := time.Now().Unix()
+		if err = w.relateTx(ctx, chain); err != nil {
+			logrus.Errorf("task %s worker %s relate chain %s ica tx error,time use: %d(s), %v", w.taskName, w.workerName, chain, time.Now().Unix()-startTime, err)
+		} else {
+			logrus.Infof("task %s worker %s relate chain %s ica tx end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
+		}
+	}
+}
+
+func (w *ibcIcaTxRelateWorker) relateTx(ctx context.Context, chain string) error {
+	totalRelateTx := 0
+	maxParseTx := global.Config.Task.SingleChainIbcTxRelateMax
+	if maxParseTx <= 0 {
+		maxParseTx = defaultMaxHandlerTx
+	}
+
+	accounts, err := icaAccountRepo.FindByControllerChain(chain)
+	if err != nil {
+		logrus.Errorf("task %s worker %s chain %s find ica accounts error, %v", w.taskName, w.workerName, chain, err)
+		return err
+	}
+	hostAddrMap := make(map[string]string, len(accounts))
+	for _, v := range accounts {
+		if v.HostAddress != "" {
+			hostAddrMap[genIcaHostAddrMapKey(v.ControllerPort, v.ControllerConnectionId)] = v.HostAddress
+		}
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		txList, err := ibcIcaTxRepo.FindProcessingTxs(chain, constant.DefaultLimit)
+		if err != nil {
+			logrus.Errorf("task %s worker %s chain %s FindProcessingTxs error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+
+		if len(txList) == 0 {
+			return nil
+		}
+
+		w.handlerIbcIcaTxs(chain, txList, hostAddrMap)
+
+		totalRelateTx += len(txList)
+		if len(txList) < constant.DefaultLimit || totalRelateTx >= maxParseTx {
+			break
+		}
+		time.Sleep(200 * time.Millisecond) // avoid master-slave delay problem
+	}
+
+	return nil
+}
+
+func (w *ibcIcaTxRelateWorker) handlerIbcIcaTxs(scChain string, ibcIcaTxList []*entity.ExIbcIcaTx, hostAddrMap map[string]string) {
+	recvPacketTxMap, ackTxMap, timeoutTxMap, timeoutIbcTxMap, noFoundAckMap := findPacketTxs(w.taskName, w.workerName, scChain, w.packetIdsMap(ibcIcaTxList))
+
+	for _, ibcIcaTx := range ibcIcaTxList {
+		if ibcIcaTx.DcChain == "" || ibcIcaTx.PacketId == "" {
+			w.setNextTryTime(ibcIcaTx)
+		} else {
+			if syncTxs, ok := recvPacketTxMap[genPacketTxMapKey(ibcIcaTx.DcChain, ibcIcaTx.PacketId)]; ok {
+				w.loadRecvPacketTx(ibcIcaTx, syncTxs, ackTxMap[genPacketTxMapKey(ibcIcaTx.ScChain, ibcIcaTx.PacketId)])
+			}
+
+			if syncTx, ok := timeoutTxMap[genPacketTxMapKey(ibcIcaTx.ScChain, ibcIcaTx.PacketId)]; ok && ibcIcaTx.Status == entity.IbcTxStatusProcessing {
+				w.loadTimeoutPacketTx(ibcIcaTx, syncTx)
+			}
+		}
+
+		if ibcIcaTx.Status == entity.IbcTxStatusProcessing {
+			w.setNextTryTime(ibcIcaTx)
+			w.updateProcessInfo(ibcIcaTx, timeoutIbcTxMap, noFoundAckMap)
+		} else {
+			ibcIcaTx.ProcessInfo = ""
+		}
+
+		if ibcIcaTx.DcAddr == "" {
+			ibcIcaTx.DcAddr = hostAddrMap[genIcaHostAddrMapKey(ibcIcaTx.ScPort, ibcIcaTx.ScConnectionId)]
+		}
+		repaired := w.repairClientId(ibcIcaTx)
+		if err := ibcIcaTxRepo.UpdateIbcIcaTx(ibcIcaTx, repaired); err != nil {
+			logrus.Errorf("task %s worker %s chain %s UpdateIbcIcaTx error, _id: %s, %v", w.taskName, w.workerName, scChain, ibcIcaTx.Id, err)
+		}
+	}
+}
+
+func (w *ibcIcaTxRelateWorker) updateProcessInfo(ibcIcaTx *entity.ExIbcIcaTx, timeOutMap map[string]struct{}, noFoundAckMap map[string]struct{}) {
+	if ibcIcaTx.DcChain == "" {
+		ibcIcaTx.ProcessInfo = constant.NoFoundDcChain
+	} else if _, ok := timeOutMap[ibcIcaTx.Id.Hex()]; ok {
+		ibcIcaTx.ProcessInfo = constant.NoFoundSuccessTimeoutPacket
+	} else if _, ok := noFoundAckMap[ibcIcaTx.Id.Hex()]; ok {
+		ibcIcaTx.ProcessInfo = constant.NoFoundSuccessAcknowledgePacket
+	} else {
+		ibcIcaTx.ProcessInfo = constant.NoFoundSuccessRecvPacket
+	}
+}
+
+func (w *ibcIcaTxRelateWorker) loadRecvPacketTx(ibcIcaTx *entity.ExIbcIcaTx, txs, ackTxs []*entity.Tx) {
+	var matchAckTx *entity.Tx
+	var matchAckMsg *model.TxMsg
+	for _, ackTx := range ackTxs {
+		for _, msg := range ackTx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeAcknowledgement || msg.CommonMsg().PacketId != ibcIcaTx.PacketId {
+				continue
+			}
+			if matchAckTx == nil || ackTx.Time > matchAckTx.Time {
+				matchAckTx = ackTx
+				matchAckMsg = msg
+			}
+		}
+	}
+
+	for _, tx := range txs {
+		if tx.Status == entity.TxStatusFailed {
+			continue
+		}
+		for msgIndex, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeRecvPacket || msg.CommonMsg().PacketId != ibcIcaTx.PacketId {
+				continue
+			}
+			dcConnection, packetAck, existPacketAck := parseRecvPacketTxEvents(msgIndex, tx)
+			if !existPacketAck {
+				continue
+			}
+
+			if strings.Contains(packetAck, "error") {
+				ibcIcaTx.Status = entity.IbcTxStatusFailed
+			} else {
+				ibcIcaTx.Status = entity.IbcTxStatusSuccess
+			}
+			ibcIcaTx.DcConnectionId = dcConnection
+			ibcIcaTx.DcTxInfo = w.buildTxInfo(tx, msg)
+			if matchAckTx != nil {
+				ibcIcaTx.AckTimeoutTxInfo = w.buildTxInfo(matchAckTx, matchAckMsg)
+			}
+			ibcIcaTx.UpdateAt = time.Now().Unix()
+		}
+	}
+}
+
+func (w *ibcIcaTxRelateWorker) loadTimeoutPacketTx(ibcIcaTx *entity.ExIbcIcaTx, tx *entity.Tx) {
+	for _, msg := range tx.DocTxMsgs {
+		if msg.Type == constant.MsgTypeTimeoutPacket && msg.CommonMsg().PacketId == ibcIcaTx.PacketId {
+			ibcIcaTx.Status = entity.IbcTxStatusFailed
+			ibcIcaTx.AckTimeoutTxInfo = w.buildTxInfo(tx, msg)
+			ibcIcaTx.UpdateAt = time.Now().Unix()
+		}
+	}
+}
+
+func (w *ibcIcaTxRelateWorker) buildTxInfo(tx *entity.Tx, msg *model.TxMsg) *entity.TxInfo {
+	return &entity.TxInfo{
+		Hash:    tx.TxHash,
+		Status:  tx.Status,
+		Time:    tx.Time,
+		Height:  tx.Height,
+		Fee:     tx.Fee,
+		Msg:     msg,
+		Memo:    tx.Memo,
+		Signers: tx.Signers,
+		Log:     tx.Log,
+	}
+}
+
+func (w *ibcIcaTxRelateWorker) setNextTryTime(ibcIcaTx *entity.ExIbcIcaTx) {
+	now := time.Now().Unix()
+	ibcIcaTx.RetryTimes += 1
+	ibcIcaTx.NextTryTime = now + (ibcIcaTx.RetryTimes * 2)
+	ibcIcaTx.UpdateAt = now
+}
+
+func (w *ibcIcaTxRelateWorker) repairClientId(ibcIcaTx *entity.ExIbcIcaTx) bool {
+	var repaired bool
+	if ibcIcaTx.DcClientId == "" {
+		if cf, ok := w.chainMap[ibcIcaTx.DcChain]; ok {
+			ibcIcaTx.DcClientId = cf.GetChannelClient(ibcIcaTx.DcPort, ibcIcaTx.DcChannel)
+			repaired = true
+		}
+	}
+
+	if ibcIcaTx.ScClientId == "" {
+		if cf, ok := w.chainMap[ibcIcaTx.ScChain]; ok {
+			ibcIcaTx.ScClientId = cf.GetChannelClient(ibcIcaTx.ScPort, ibcIcaTx.ScChannel)
+			repaired = true
+		}
+	}
+	return repaired
+}
+
+func (w *ibcIcaTxRelateWorker) packetIdsMap(ibcIcaTxList []*entity.ExIbcIcaTx) map[string][]*dto.PacketIdDTO {
+	res := make(map[string][]*dto.PacketIdDTO)
+	for _, tx := range ibcIcaTxList {
+		if tx.DcChain == "" || tx.PacketId == "" {
+			logrus.Warningf("ibc ica tx dc_chain_id or packet_id exception, record_id: %s", tx.Id)
+			continue
+		}
+
+		res[tx.DcChain] = append(res[tx.DcChain], &dto.PacketIdDTO{
+			DcChain:       tx.DcChain,
+			TimeoutHeight: tx.TimeoutHeight,
+			PacketId:      tx.PacketId,
+			TimeOutTime:   tx.TimeoutTimestamp,
+			ObjectId:      tx.Id.Hex(),
+		})
+	}
+	return res
+}
@@ -0,0 +1,330 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// IbcNftTxRelateTask relate recv_packet, acknowledge_packet and timeout_packet txs to ics721 transfer txs
+type IbcNftTxRelateTask struct {
+}
+
+var _ Task = new(IbcNftTxRelateTask)
+var nftRelateCoordinator *stringQueueCoordinator
+
+func (t *IbcNftTxRelateTask) Name() string {
+	return "ibc_nft_tx_relate_task"
+}
+
+func (t *IbcNftTxRelateTask) Cron() int {
+	if taskConf.CronTimeIbcNftTxRelateTask > 0 {
+		return taskConf.CronTimeIbcNftTxRelateTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcNftTxRelateTask) workerNum() int {
+	if global.Config.Task.IbcTxRelateWorkerNum > 0 {
+		return global.Config.Task.IbcTxRelateWorkerNum
+	}
+	return ibcTxRelateTaskWorkerNum
+}
+
+func (t *IbcNftTxRelateTask) Run(ctx context.Context) int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+
+	// init coordinator
+	chainQueue := new(utils.QueueString)
+	for _, v := range chainMap {
+		chainQueue.Push(v.ChainName)
+	}
+	nftRelateCoordinator = &stringQueueCoordinator{
+		stringQueue: chainQueue,
+	}
+
+	workerNum := t.workerNum()
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(workerNum)
+	for i := 1; i <= workerNum; i++ {
+		workName := fmt.Sprintf("worker-%d", i)
+		go func(wn string) {
+			newIbcNftTxRelateWorker(t.Name(), wn, chainMap).exec(ctx)
+			waitGroup.Done()
+		}(workName)
+	}
+	waitGroup.Wait()
+
+	return 1
+}
+
+// =========================================================================
+// =========================================================================
+// worker
+
+func newIbcNftTxRelateWorker(taskName, workerName string, chainMap map[string]*entity.ChainConfig) *ibcNftTxRelateWorker {
+	return &ibcNftTxRelateWorker{
+		taskName:   taskName,
+		workerName: workerName,
+		chainMap:   chainMap,
+	}
+}
+
+type ibcNftTxRelateWorker struct {
+	taskName   string
+	workerName string
+	chainMap   map[string]*entity.ChainConfig
+}
+
+func (w *ibcNftTxRelateWorker) exec(ctx context.Context) {
+	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
+	for {
+		chain, err := nftRelateCoordinator.getOne(ctx)
+		if err != nil {
+			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
+			break
+		}
+
+		if cf, ok := w.chainMap[chain]; ok && cf.Status == entity.ChainStatusClosed {
+			logrus.Infof("task %s worker %s chain %s is closed", w.taskName, w.workerName, chain)
+			continue
+		}
+
+		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
+		startTime := time.Now().Unix()
+		if err = w.relateTx(ctx, chain); err != nil {
+			logrus.Errorf("task %s worker %s relate chain %s nft tx error,time use: %d(s), %v", w.taskName, w.workerName, chain, time.Now().Unix()-startTime, err)
+		} else {
+			logrus.Infof("task %s worker %s relate chain %s nft tx end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
+		}
+	}
+}
+
+func (w *ibcNftTxRelateWorker) relateTx(ctx context.Context, chain string) error {
+	totalRelateTx := 0
+	maxParseTx := global.Config.Task.SingleChainIbcTxRelateMax
+	if maxParseTx <= 0 {
+		maxParseTx = defaultMaxHandlerTx
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		txList, err := ibcNftTxRepo.FindProcessingTxs(chain, constant.DefaultLimit)
+		if err != nil {
+			logrus.Errorf("task %s worker %s chain %s FindProcessingTxs error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+
+		if len(txList) == 0 {
+			return nil
+		}
+
+		w.handlerIbcNftTxs(chain, txList)
+
+		totalRelateTx += len(txList)
+		if len(txList) < constant.DefaultLimit || totalRelateTx >= maxParseTx {
+			break
+		}
+		time.Sleep(200 * time.Millisecond) // avoid master-slave delay problem
+	}
+
+	return nil
+}
+
+func (w *ibcNftTxRelateWorker) handlerIbcNftTxs(scChain string, ibcNftTxList []*entity.ExIbcNftTx) {
+	recvPacketTxMap, ackTxMap, timeoutTxMap, timeoutIbcTxMap, noFoundAckMap := w.packetIdTx(scChain, ibcNftTxList)
+
+	for _, ibcNftTx := range ibcNftTxList {
+		if ibcNftTx.DcChain == "" || ibcNftTx.ScTxInfo == nil || ibcNftTx.ScTxInfo.Msg == nil {
+			w.setNextTryTime(ibcNftTx)
+		} else {
+			packetId := ibcNftTx.ScTxInfo.Msg.CommonMsg().PacketId
+			if syncTxs, ok := recvPacketTxMap[genPacketTxMapKey(ibcNftTx.DcChain, packetId)]; ok {
+				ackSyncTxs := ackTxMap[genPacketTxMapKey(ibcNftTx.ScChain, packetId)]
+				w.loadRecvPacketTx(ibcNftTx, syncTxs, ackSyncTxs)
+			}
+
+			if syncTx, ok := timeoutTxMap[genPacketTxMapKey(ibcNftTx.ScChain, packetId)]; ok && ibcNftTx.Status == entity.IbcTxStatusProcessing {
+				w.loadTimeoutPacketTx(ibcNftTx, syncTx)
+			}
+		}
+
+		if ibcNftTx.Status == entity.IbcTxStatusProcessing {
+			w.setNextTryTime(ibcNftTx)
+			w.updateProcessInfo(ibcNftTx, timeoutIbcTxMap, noFoundAckMap)
+		} else {
+			ibcNftTx.ProcessInfo = ""
+		}
+
+		repaired := w.repairClientId(ibcNftTx)
+		if err := ibcNftTxRepo.UpdateIbcNftTx(ibcNftTx, repaired); err != nil {
+			logrus.Errorf("task %s worker %s chain %s UpdateIbcNftTx error, _id: %s, %v", w.taskName, w.workerName, scChain, ibcNftTx.Id, err)
+		}
+	}
+}
+
+func (w *ibcNftTxRelateWorker) updateProcessInfo(ibcNftTx *entity.ExIbcNftTx, timeOutMap map[string]struct{}, noFoundAckMap map[string]struct{}) {
+	if ibcNftTx.DcChain == "" {
+		ibcNftTx.ProcessInfo = constant.NoFoundDcChain
+	} else if _, ok := timeOutMap[ibcNftTx.Id.Hex()]; ok {
+		ibcNftTx.ProcessInfo = constant.NoFoundSuccessTimeoutPacket
+	} else if _, ok := noFoundAckMap[ibcNftTx.Id.Hex()]; ok {
+		ibcNftTx.ProcessInfo = constant.NoFoundSuccessAcknowledgePacket
+	} else {
+		ibcNftTx.ProcessInfo = constant.NoFoundSuccessRecvPacket
+	}
+}
+
+func (w *ibcNftTxRelateWorker) loadRecvPacketTx(ibcNftTx *entity.ExIbcNftTx, txs, ackTxs []*entity.Tx) {
+	packetId := ibcNftTx.ScTxInfo.Msg.CommonMsg().PacketId
+	// ics721 ack txs emit no bank transfer event on refund, so the latest ack tx is taken in both cases
+	matchAckTx := func() (*entity.Tx, *entity.TxInfo) {
+		var matchTx *entity.Tx
+		var matchInfo *entity.TxInfo
+		for _, ackTx := range ackTxs {
+			for _, msg := range ackTx.DocTxMsgs {
+				if msg.Type != constant.MsgTypeAcknowledgement || msg.CommonMsg().PacketId != packetId {
+					continue
+				}
+				if matchTx == nil || ackTx.Time > matchTx.Time {
+					matchTx = ackTx
+					matchInfo = w.buildTxInfo(ackTx, msg)
+				}
+			}
+		}
+		return matchTx, matchInfo
+	}
+
+	for _, tx := range txs {
+		if tx.Status == entity.TxStatusFailed {
+			continue
+		}
+		for msgIndex, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeRecvPacket || msg.CommonMsg().PacketId != packetId {
+				continue
+			}
+			dcConnection, packetAck, existPacketAck := parseRecvPacketTxEvents(msgIndex, tx)
+			if !existPacketAck {
+				continue
+			}
+
+			if strings.Contains(packetAck, "error") {
+				ackTx, ackTxInfo := matchAckTx()
+				if ackTx == nil { // 改为refunded状态时，必须要ack_packet交易
+					return
+				}
+				ibcNftTx.Status = entity.IbcTxStatusRefunded
+				ibcNftTx.AckTimeoutTxInfo = ackTxInfo
+			} else {
+				_, ackTxInfo := matchAckTx()
+				ibcNftTx.Status = entity.IbcTxStatusSuccess
+				ibcNftTx.AckTimeoutTxInfo = ackTxInfo
+			}
+
+			ibcNftTx.DcConnectionId = dcConnection
+			ibcNftTx.DcTxInfo = w.buildTxInfo(tx, msg)
+			ibcNftTx.UpdateAt = time.Now().Unix()
+			if ibcNftTx.Class != nil && ibcNftTx.Class.ClassPath != "" {
+				dcClassPath, _ := ibctool.CalculateNextClassPath(ibcNftTx.Class.ClassPath, ibcNftTx.ScPort, ibcNftTx.ScChannel, ibcNftTx.DcPort, ibcNftTx.DcChannel)
+				ibcNftTx.Class.DcClassId = ibctool.CalculateIBCHash(dcClassPath)
+			}
+		}
+	}
+}
+
+func (w *ibcNftTxRelateWorker) loadTimeoutPacketTx(ibcNftTx *entity.ExIbcNftTx, tx *entity.Tx) {
+	packetId := ibcNftTx.ScTxInfo.Msg.CommonMsg().PacketId
+	for _, msg := range tx.DocTxMsgs {
+		if msg.Type == constant.MsgTypeTimeoutPacket && msg.CommonMsg().PacketId == packetId {
+			ibcNftTx.Status = entity.IbcTxStatusRefunded
+			ibcNftTx.AckTimeoutTxInfo = w.buildTxInfo(tx, msg)
+			ibcNftTx.UpdateAt = time.Now().Unix()
+		}
+	}
+}
+
+func (w *ibcNftTxRelateWorker) buildTxInfo(tx *entity.Tx, msg *model.TxMsg) *entity.TxInfo {
+	return &entity.TxInfo{
+		Hash:    tx.TxHash,
+		Status:  tx.Status,
+		Time:    tx.Time,
+		Height:  tx.Height,
+		Fee:     tx.Fee,
+		Msg:     msg,
+		Memo:    tx.Memo,
+		Signers: tx.Signers,
+		Log:     tx.Log,
+	}
+}
+
+func (w *ibcNftTxRelateWorker) setNextTryTime(ibcNftTx *entity.ExIbcNftTx) {
+	now := time.Now().Unix()
+	ibcNftTx.RetryTimes += 1
+	ibcNftTx.NextTryTime = now + (ibcNftTx.RetryTimes * 2)
+	ibcNftTx.UpdateAt = now
+}
+
+func (w *ibcNftTxRelateWorker) repairClientId(ibcNftTx *entity.ExIbcNftTx) bool {
+	var repaired bool
+	if ibcNftTx.DcClientId == "" {
+		if cf, ok := w.chainMap[ibcNftTx.DcChain]; ok {
+			ibcNftTx.DcClientId = cf.GetChannelClient(ibcNftTx.DcPort, ibcNftTx.DcChannel)
+			repaired = true
+		}
+	}
+
+	if ibcNftTx.ScClientId == "" {
+		if cf, ok := w.chainMap[ibcNftTx.ScChain]; ok {
+			ibcNftTx.ScClientId = cf.GetChannelClient(ibcNftTx.ScPort, ibcNftTx.ScChannel)
+			repaired = true
+		}
+	}
+	return repaired
+}
+
+func (w *ibcNftTxRelateWorker) packetIdTx(scChain string, ibcNftTxList []*entity.ExIbcNftTx) (recvPacketTxMap, ackTxMap map[string][]*entity.Tx, timeoutTxMap map[string]*entity.Tx, timeoutIbcTxMap, noFoundAckMap map[string]struct{}) {
+	return findPacketTxs(w.taskName, w.workerName, scChain, w.packetIdsMap(ibcNftTxList))
+}
+
+func (w *ibcNftTxRelateWorker) packetIdsMap(ibcNftTxList []*entity.ExIbcNftTx) map[string][]*dto.PacketIdDTO {
+	res := make(map[string][]*dto.PacketIdDTO)
+	for _, tx := range ibcNftTxList {
+		if tx.DcChain == "" || tx.ScTxInfo == nil || tx.ScTxInfo.Msg == nil {
+			logrus.Warningf("ibc nft tx dc_chain_id or sc_tx_info exception, record_id: %s", tx.Id)
+			continue
+		}
+
+		nftTransferMsg := tx.ScTxInfo.Msg.NftTransferMsg()
+		if nftTransferMsg.PacketId == "" {
+			logrus.Warningf("ibc nft tx packet_id is empty, hash: %s", tx.ScTxInfo.Hash)
+			continue
+		}
+
+		res[tx.DcChain] = append(res[tx.DcChain], &dto.PacketIdDTO{
+			DcChain:       tx.DcChain,
+			TimeoutHeight: nftTransferMsg.TimeoutHeight.RevisionHeight,
+			PacketId:      nftTransferMsg.PacketId,
+			TimeOutTime:   nftTransferMsg.TimeoutTimestamp,
+			ObjectId:      tx.Id.Hex(),
+		})
+	}
+	return res
+}
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -23,7 +24,7 @@ func (t *IbcNodeLcdCronTask) Name() string {
 func (t *IbcNodeLcdCronTask) Cron() int {
 	return OneDay
 }
-func (t *IbcNodeLcdCronTask) Run() int {
+func (t *IbcNodeLcdCronTask) Run(ctx context.Context) int {
 	chainCfgs, err := chainConfigRepo.FindAllChainInfos()
 	if err != nil {
 		logrus.Errorf("task %s run error, %s", t.Name(), err.Error())
@@ -35,13 +36,13 @@ func (t *IbcNodeLcdCronTask) Run() int {
 
 }
 
-func (t *IbcNodeLcdCronTask) RunWithParam(chain string) int {
+func (t *IbcNodeLcdCronTask) RunWithParam(ctx context.Context, chain string) int {
 	if chain != "" {
 		t.CheckAndUpdateTraceSourceNode(chain)
 		return 1
 	}
 
-	return t.Run()
+	return t.Run(ctx)
 }
 
 func (t *IbcNodeLcdCronTask) ExpireTime() time.Duration {
@@ -127,6 +128,7 @@ func (t *IbcNodeLcdCronTask) CheckAndUpdateTraceSourceNode(chain string) {
 				FullNode:      earliestH == 1,
 				TxIndexEnable: ok,
 				LcdAddr:       rest.Address,
+				RpcAddr:       rpcAddress,
 			}
 		}
 	}
@@ -1,11 +1,14 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestCheckAndUpdateTraceSourceNode(t *testing.T) {
 	new(IbcNodeLcdCronTask).CheckAndUpdateTraceSourceNode("crescent_1")
 }
 
 func TestIbcNodeLcdCronTask_Run(t *testing.T) {
-	new(IbcNodeLcdCronTask).Run()
+	new(IbcNodeLcdCronTask).Run(context.Background())
 }
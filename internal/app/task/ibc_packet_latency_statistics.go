@@ -0,0 +1,181 @@
+package task
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils/umath"
+	"github.com/sirupsen/logrus"
+)
+
+// PacketLatencyStatisticsTask roll up send->recv and recv->ack latency into percentiles by channel pair and relayer,
+// both hourly and daily. a day is always counted as a whole, ex_ibc_tx_latest and ex_ibc_tx are both read since the
+// txs of a day may be migrated partly.
+type PacketLatencyStatisticsTask struct {
+}
+
+func (t *PacketLatencyStatisticsTask) Name() string {
+	return "ibc_packet_latency_statistics_task"
+}
+
+func (t *PacketLatencyStatisticsTask) Cron() int {
+	if taskConf.CronTimePacketLatencyStatisticsTask > 0 {
+		return taskConf.CronTimePacketLatencyStatisticsTask
+	}
+	return EveryHour
+}
+
+// Run 增量更新
+func (t *PacketLatencyStatisticsTask) Run(ctx context.Context) int {
+	startTime, endTime := todayUnix()
+	if err := t.deal(&segment{StartTime: startTime, EndTime: endTime}); err != nil {
+		return -1
+	}
+
+	if ok, seg := whetherCheckYesterdayStatistics(t.Name(), t.Cron()); ok {
+		logrus.Infof("task %s check yeaterday statistics", t.Name())
+		if err := t.deal(seg); err != nil {
+			return -1
+		}
+	}
+	return 1
+}
+
+// RunFullStatistics 全量更新
+func (t *PacketLatencyStatisticsTask) RunFullStatistics(ctx context.Context) int {
+	minTxTime, err := ibcTxRepo.GetMinTxTime(true)
+	if err != nil || minTxTime == 0 {
+		if minTxTime, err = ibcTxRepo.GetMinTxTime(false); err != nil {
+			logrus.Errorf("task %s GetMinTxTime err, %v", t.Name(), err)
+			return -1
+		}
+	}
+
+	segments := segmentTool(segmentStepLatest, minTxTime, time.Now().Unix())
+	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
+	for _, v := range segments {
+		if ctx.Err() != nil {
+			logrus.Warningf("task %s is interrupted", t.Name())
+			return -1
+		}
+		if err = t.deal(v); err != nil {
+			return -1
+		}
+	}
+	return 1
+}
+
+// deal seg is a whole day
+func (t *PacketLatencyStatisticsTask) deal(seg *segment) error {
+	logrus.Infof("task %s deal segment [%d, %d]", t.Name(), seg.StartTime, seg.EndTime)
+	var txs []*dto.PacketLatencyTxDTO
+	for _, targetHistory := range []bool{true, false} {
+		res, err := ibcTxRepo.FindLatencyTxs(seg.StartTime, seg.EndTime, targetHistory)
+		if err != nil {
+			logrus.Errorf("task %s FindLatencyTxs segment [%d, %d], targetHistory: %t err, %v", t.Name(), seg.StartTime, seg.EndTime, targetHistory, err)
+			return err
+		}
+		txs = append(txs, res...)
+	}
+
+	statistics := t.statistics(txs, seg)
+	if err := packetLatencyRepo.BatchSwap(seg.StartTime, seg.EndTime, statistics); err != nil {
+		logrus.Errorf("task %s BatchSwap segment [%d, %d] err, %v", t.Name(), seg.StartTime, seg.EndTime, err)
+		return err
+	}
+	return nil
+}
+
+type packetLatencyKey struct {
+	scChain, scChannel, dcChain, dcChannel string
+	stage                                  entity.PacketLatencyStage
+	relayer                                string
+	period                                 entity.PacketLatencyPeriod
+	segmentStartTime, segmentEndTime       int64
+}
+
+func (t *PacketLatencyStatisticsTask) statistics(txs []*dto.PacketLatencyTxDTO, seg *segment) []*entity.IBCPacketLatencyStatistics {
+	latencyMap := make(map[packetLatencyKey][]int64)
+	add := func(tx *dto.PacketLatencyTxDTO, stage entity.PacketLatencyStage, relayer string, latency int64) {
+		hourStart := seg.StartTime + (tx.ScTime-seg.StartTime)/EveryHour*EveryHour
+		key := packetLatencyKey{
+			scChain:          tx.ScChain,
+			scChannel:        tx.ScChannel,
+			dcChain:          tx.DcChain,
+			dcChannel:        tx.DcChannel,
+			stage:            stage,
+			relayer:          relayer,
+			period:           entity.PacketLatencyPeriodHour,
+			segmentStartTime: hourStart,
+			segmentEndTime:   hourStart + EveryHour - 1,
+		}
+		latencyMap[key] = append(latencyMap[key], latency)
+
+		key.period = entity.PacketLatencyPeriodDay
+		key.segmentStartTime, key.segmentEndTime = seg.StartTime, seg.EndTime
+		latencyMap[key] = append(latencyMap[key], latency)
+	}
+
+	for _, v := range txs {
+		if v.ScTime < seg.StartTime || v.ScTime > seg.EndTime {
+			continue
+		}
+		if v.DcTime >= v.ScTime {
+			add(v, entity.PacketLatencyStageSendRecv, packetLatencyRelayer(v.DcChain, v.DcSigners), v.DcTime-v.ScTime)
+		}
+		if v.AckMsgType == constant.MsgTypeAcknowledgement && v.AckTime >= v.DcTime {
+			add(v, entity.PacketLatencyStageRecvAck, packetLatencyRelayer(v.ScChain, v.AckSigners), v.AckTime-v.DcTime)
+		}
+	}
+
+	nowTime := time.Now().Unix()
+	res := make([]*entity.IBCPacketLatencyStatistics, 0, len(latencyMap))
+	for k, latencies := range latencyMap {
+		sort.Slice(latencies, func(i, j int) bool {
+			return latencies[i] < latencies[j]
+		})
+		res = append(res, &entity.IBCPacketLatencyStatistics{
+			ScChain:          k.scChain,
+			ScChannel:        k.scChannel,
+			DcChain:          k.dcChain,
+			DcChannel:        k.dcChannel,
+			Stage:            k.stage,
+			Relayer:          k.relayer,
+			Period:           k.period,
+			TxsNumber:        int64(len(latencies)),
+			P50:              umath.Percentile(latencies, 50),
+			P90:              umath.Percentile(latencies, 90),
+			P99:              umath.Percentile(latencies, 99),
+			Histogram:        packetLatencyHistogram(latencies),
+			SegmentStartTime: k.segmentStartTime,
+			SegmentEndTime:   k.segmentEndTime,
+			CreateAt:         nowTime,
+			UpdateAt:         nowTime,
+		})
+	}
+	return res
+}
+
+func packetLatencyRelayer(chain string, signers []string) string {
+	if len(signers) == 0 {
+		return ""
+	}
+	return entity.GenerateChainAddressComb(chain, signers[0])
+}
+
+// packetLatencyHistogram latencies must be sorted
+func packetLatencyHistogram(latencies []int64) []int64 {
+	histogram := make([]int64, len(entity.PacketLatencyBuckets)+1)
+	bucket := 0
+	for _, v := range latencies {
+		for bucket < len(entity.PacketLatencyBuckets) && v > entity.PacketLatencyBuckets[bucket] {
+			bucket++
+		}
+		histogram[bucket]++
+	}
+	return histogram
+}
@@ -0,0 +1,63 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+var packetLatencyStatisticsTask PacketLatencyStatisticsTask
+
+func Test_PacketLatencyStatisticsTaskRunFullStatistics(t *testing.T) {
+	packetLatencyStatisticsTask.RunFullStatistics(context.Background())
+}
+
+func Test_PacketLatencyStatisticsTaskRun(t *testing.T) {
+	packetLatencyStatisticsTask.Run(context.Background())
+}
+
+func Test_PacketLatencyStatistics(t *testing.T) {
+	seg := &segment{StartTime: 1700006400, EndTime: 1700006400 + 86399}
+	tx := func(scTime, recvLatency, ackLatency int64) *dto.PacketLatencyTxDTO {
+		return &dto.PacketLatencyTxDTO{
+			ScChain:    "irishub_qa",
+			ScChannel:  "channel-0",
+			DcChain:    "cosmoshub_qa",
+			DcChannel:  "channel-1",
+			ScTime:     scTime,
+			DcTime:     scTime + recvLatency,
+			DcSigners:  []string{"cosmos1relayer"},
+			AckTime:    scTime + recvLatency + ackLatency,
+			AckSigners: []string{"iaa1relayer"},
+			AckMsgType: constant.MsgTypeAcknowledgement,
+		}
+	}
+	txs := []*dto.PacketLatencyTxDTO{
+		tx(seg.StartTime+10, 8, 12),
+		tx(seg.StartTime+20, 20, 12),
+		tx(seg.StartTime+3600, 100, 12),
+	}
+
+	res := packetLatencyStatisticsTask.statistics(txs, seg)
+	// send_recv & recv_ack * (2 hours + 1 day)
+	if len(res) != 6 {
+		t.Fatalf("expect 6 statistics, got %d", len(res))
+	}
+	for _, v := range res {
+		if v.Stage != entity.PacketLatencyStageSendRecv || v.Period != entity.PacketLatencyPeriodDay {
+			continue
+		}
+		if v.Relayer != entity.GenerateChainAddressComb("cosmoshub_qa", "cosmos1relayer") {
+			t.Fatalf("unexpected relayer %s", v.Relayer)
+		}
+		if v.TxsNumber != 3 || v.P50 != 20 || v.P99 != 100 {
+			t.Fatalf("unexpected day statistics %+v", v)
+		}
+		if v.Histogram[0] != 0 || v.Histogram[1] != 1 || v.Histogram[3] != 1 || v.Histogram[8] != 1 {
+			t.Fatalf("unexpected histogram %v", v.Histogram)
+		}
+	}
+}
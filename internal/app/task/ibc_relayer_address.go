@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -22,7 +23,7 @@ func (t *IbcRelayerAddressInitTask) Name() string {
 	return "ibc_relayer_address_init_task"
 }
 
-func (t *IbcRelayerAddressInitTask) Run() int {
+func (t *IbcRelayerAddressInitTask) Run(ctx context.Context) int {
 	addrList, err := relayerAddressChannelRepo.DistinctAddr()
 	if err != nil {
 		logrus.Errorf("task %s DistinctAddr err, %v", t.Name(), err)
@@ -61,7 +62,7 @@ func (t *IbcRelayerAddressInitTask) Run() int {
 		var pubKey string
 		if cf, ok := chainInfosMap[v.Chain]; ok && fastFailChainMap[v.Chain] == "" {
 			tempSt := time.Now().Unix()
-			if account, err := lcd.GetAccount(v.Chain, v.Address, cf.GrpcRestGateway, cf.LcdApiPath.AccountsPath, false); err == nil {
+			if account, err := lcd.GetAccount(ctx, cf, v.Address, false); err == nil {
 				pubKey = account.Account.PubKey.Key
 			} else {
 				if isFastFailErr(err) {
@@ -106,7 +107,7 @@ func (t *RelayerAddressGatherTask) Name() string {
 	return "relayer_address_gather_task"
 }
 
-func (t *RelayerAddressGatherTask) Run() int {
+func (t *RelayerAddressGatherTask) Run(ctx context.Context) int {
 	chainMap, err := getAllChainInfosMap()
 	if err != nil {
 		logrus.Errorf("task %s getAllChainInfosMap err, %v", t.Name(), err)
@@ -114,14 +115,14 @@ func (t *RelayerAddressGatherTask) Run() int {
 	}
 	t.chainMap = chainMap
 
-	t.repairEmptyPubKey()
+	t.repairEmptyPubKey(ctx)
 	t.gather()
 
 	return 1
 }
 
 // repairEmptyPubKey 修复pub_key 为空的address
-func (t *RelayerAddressGatherTask) repairEmptyPubKey() {
+func (t *RelayerAddressGatherTask) repairEmptyPubKey(ctx context.Context) {
 	logrus.Infof("task %s repairEmptyPubKey start", t.Name())
 	st := time.Now().Unix()
 	startTime := st - relayerAddressGatherRangeTime
@@ -137,15 +138,12 @@ func (t *RelayerAddressGatherTask) repairEmptyPubKey() {
 			continue
 		}
 
-		var apiHost, apiPath string
-		if cfg, ok := t.chainMap[v.Chain]; ok {
-			apiHost = cfg.GrpcRestGateway
-			apiPath = cfg.LcdApiPath.AccountsPath
-		} else {
+		cfg, ok := t.chainMap[v.Chain]
+		if !ok {
 			continue
 		}
 
-		account, err := lcd.GetAccount(v.Chain, v.Address, apiHost, apiPath, false)
+		account, err := lcd.GetAccount(ctx, cfg, v.Address, false)
 		if err != nil {
 			if isFastFailErr(err) {
 				fastFailChainMap[v.Chain] = err.Error()
@@ -1,11 +1,14 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func Test_IbcRelayerAddressInitTask(t *testing.T) {
-	relayerAddressInitTask.Run()
+	relayerAddressInitTask.Run(context.Background())
 }
 
 func Test_RelayerAddressGatherTask(t *testing.T) {
-	relayerAddressGatherTask.Run()
+	relayerAddressGatherTask.Run(context.Background())
 }
@@ -1,8 +1,10 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
@@ -26,18 +28,43 @@ func (t *RelayerStatisticsTask) Switch() bool {
 	return global.Config.Task.SwitchIbcRelayerStatisticsTask
 }
 
-// Run 全量更新
-func (t *RelayerStatisticsTask) Run() int {
+// Run 全量更新，按链保存每个分段的checkpoint，中断后从最后完成的分段继续
+func (t *RelayerStatisticsTask) Run(ctx context.Context) int {
 	chainMap, err := getAllChainMap()
 	if err != nil {
 		logrus.Errorf("task %s getAllChainMap err, %v", t.Name(), err)
 		return -1
 	}
 
+	checkpoint, err := loadStatisticsCheckpoint(t.Name())
+	if err != nil {
+		logrus.Errorf("task %s loadStatisticsCheckpoint err, %v", t.Name(), err)
+		return -1
+	}
+	chainSegments := t.chainSegments(chainMap)
+	resumed, err := checkpoint.resume(chainSegments, relayerDenomStatisticsRepo, relayerFeeStatisticsRepo)
+	if err != nil {
+		logrus.Errorf("task %s resume checkpoint err, %v", t.Name(), err)
+		return -1
+	}
+	if !resumed {
+		if err = relayerDenomStatisticsRepo.DropNew(); err != nil {
+			logrus.Errorf("task %s relayerDenomStatisticsRepo.DropNew err, %v", t.Name(), err)
+			return -1
+		}
+		if err = relayerFeeStatisticsRepo.DropNew(); err != nil {
+			logrus.Errorf("task %s relayerFeeStatisticsRepo.DropNew err, %v", t.Name(), err)
+			return -1
+		}
+	}
+
 	// init coordinator
+	for chain, segments := range chainSegments {
+		chainSegments[chain] = checkpoint.pending(chain, segments)
+	}
 	chainQueue := new(utils.QueueString)
-	for _, v := range chainMap {
-		chainQueue.Push(v.ChainName)
+	for chain := range chainSegments {
+		chainQueue.Push(chain)
 	}
 	relayerStatisticsCoordinator = &stringQueueCoordinator{
 		stringQueue: chainQueue,
@@ -58,16 +85,29 @@ func (t *RelayerStatisticsTask) Run() int {
 		workerNum = relayerStatisticsWorkerNum
 	}
 	var waitGroup sync.WaitGroup
+	var failedChains int64
 	waitGroup.Add(workerNum)
 	for i := 1; i <= workerNum; i++ {
 		workName := fmt.Sprintf("worker-%d", i)
 		go func(wn string) {
 			defer waitGroup.Done()
-			newRelayerStatisticsWorker(t.Name(), wn, chainMap).exec()
+			w := newRelayerStatisticsWorker(t.Name(), wn, chainMap)
+			w.checkpoint = checkpoint
+			w.chainSegments = chainSegments
+			atomic.AddInt64(&failedChains, int64(w.exec(ctx)))
 		}(workName)
 	}
 	waitGroup.Wait()
 
+	if ctx.Err() != nil {
+		logrus.Warningf("task %s is interrupted, it resumes from the checkpoints next time", t.Name())
+		return -1
+	}
+	if failedChains > 0 {
+		logrus.Errorf("task %s statistics of %d chains failed, it resumes from the checkpoints next time", t.Name(), failedChains)
+		return -1
+	}
+
 	if err = relayerDenomStatisticsRepo.SwitchColl(); err != nil {
 		logrus.Errorf("task %s relayerDenomStatisticsRepo.SwitchColl() err, %v", t.Name(), err)
 		return -1
@@ -78,12 +118,35 @@ func (t *RelayerStatisticsTask) Run() int {
 		return -1
 	}
 
+	if err = checkpoint.clear(); err != nil {
+		logrus.Errorf("task %s clear checkpoint err, %v", t.Name(), err)
+	}
+
 	t.flushCache()
 	return 1
 }
 
+// chainSegments 各链从第一笔交易开始的分段
+func (t *RelayerStatisticsTask) chainSegments(chainMap map[string]*entity.ChainConfig) map[string][]*segment {
+	res := make(map[string][]*segment, len(chainMap))
+	for chain, cf := range chainMap {
+		if cf.Status == entity.ChainStatusClosed {
+			continue
+		}
+
+		firstTx, err := txRepo.GetFirstTx(chain)
+		if err != nil {
+			logrus.Errorf("task %s chain %s GetFirstTx err, %v", t.Name(), chain, err)
+			continue
+		}
+
+		res[chain] = segmentTool(segmentStepLatest, firstTx.Time, time.Now().Unix())
+	}
+	return res
+}
+
 // RunIncrement 增量统计
-func (t *RelayerStatisticsTask) RunIncrement(seg *segment) error {
+func (t *RelayerStatisticsTask) RunIncrement(ctx context.Context, seg *segment) error {
 	chainMap, err := getAllChainMap()
 	if err != nil {
 		logrus.Errorf("task %s IncrementRun getAllChainMap err, %v", t.Name(), err)
@@ -93,14 +156,14 @@ func (t *RelayerStatisticsTask) RunIncrement(seg *segment) error {
 	segs := []*segment{seg}
 	worker := newRelayerStatisticsWorker(t.Name(), "increment", chainMap)
 	for chain, _ := range chainMap {
-		worker.statistics(chain, segs, opUpdate)
+		_ = worker.statistics(ctx, chain, segs, opUpdate)
 	}
 	t.flushCache()
 	return nil
 }
 
 // RunWithParam 自定义统计
-func (t *RelayerStatisticsTask) RunWithParam(chain string, startTime, endTime int64) int {
+func (t *RelayerStatisticsTask) RunWithParam(ctx context.Context, chain string, startTime, endTime int64) int {
 	segments := segmentTool(segmentStepLatest, startTime, endTime)
 	chainMap, err := getAllChainMap()
 	if err != nil {
@@ -113,7 +176,7 @@ func (t *RelayerStatisticsTask) RunWithParam(chain string, startTime, endTime in
 		workerName = workerName[:7]
 	}
 	worker := newRelayerStatisticsWorker(t.Name(), workerName, chainMap)
-	worker.statistics(chain, segments, opUpdate)
+	_ = worker.statistics(ctx, chain, segments, opUpdate)
 	t.flushCache()
 	return 1
 }
@@ -143,20 +206,25 @@ type relayerStatisticsWorker struct {
 	taskName   string
 	workerName string
 	chainMap   map[string]*entity.ChainConfig
+
+	// full statistics only
+	checkpoint    *statisticsCheckpoint
+	chainSegments map[string][]*segment
 }
 
-func (w *relayerStatisticsWorker) getChain() (string, error) {
-	return relayerStatisticsCoordinator.getOne()
+func (w *relayerStatisticsWorker) getChain(ctx context.Context) (string, error) {
+	return relayerStatisticsCoordinator.getOne(ctx)
 }
 
-// exec 全量统计
-func (w *relayerStatisticsWorker) exec() {
+// exec 全量统计, 返回统计失败的链数
+func (w *relayerStatisticsWorker) exec(ctx context.Context) int {
+	var failed int
 	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
 	for {
-		chain, err := w.getChain()
+		chain, err := w.getChain(ctx)
 		if err != nil {
 			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
-			break
+			return failed
 		}
 
 		if cf, ok := w.chainMap[chain]; ok && cf.Status == entity.ChainStatusClosed {
@@ -165,43 +233,76 @@ func (w *relayerStatisticsWorker) exec() {
 		}
 
 		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
-		firstTx, err := txRepo.GetFirstTx(chain)
-		if err != nil {
-			logrus.Errorf("task %s worker %s chain %s GetFirstTx err, %v", w.taskName, w.workerName, chain, err)
-			continue
+		if err = w.statistics(ctx, chain, w.chainSegments[chain], opInsert); err != nil {
+			failed++
 		}
-
-		segments := segmentTool(segmentStepLatest, firstTx.Time, time.Now().Unix())
-		w.statistics(chain, segments, opInsert)
 	}
 }
 
-func (w *relayerStatisticsWorker) statistics(chain string, segments []*segment, op int) {
+// statistics 全量统计时遇到出错的分段即停止该链的统计并返回错误
+func (w *relayerStatisticsWorker) statistics(ctx context.Context, chain string, segments []*segment, op int) error {
 	startTime := time.Now().Unix()
 	logrus.Infof("task %s worker %s statistics chain: %s, total segments: %d", w.taskName, w.workerName, chain, len(segments))
 
 	for _, v := range segments {
-		// denom statistics
-		denomStats, err := txRepo.RelayerDenomStatistics(chain, v.StartTime, v.EndTime)
-		if err != nil {
-			logrus.Errorf("task %s worker %s RelayerDenomStatistics err, %s-%d-%d, %v", w.taskName, w.workerName, chain, v.StartTime, v.EndTime, err)
-		} else {
-			denomStatMap, addrChannelMap := w.aggrDenomStat(chain, v, denomStats)
-			_ = w.saveDenomStat(chain, denomStatMap, v, op)
-			_ = w.saveAddrChannel(addrChannelMap)
-			_ = w.saveRelayerAddr(addrChannelMap)
+		if ctx.Err() != nil {
+			logrus.Infof("task %s worker %s statistics chain %s is interrupted", w.taskName, w.workerName, chain)
+			return errStatisticsInterrupted
 		}
 
-		// fee statistics
-		feeStats, err := txRepo.RelayerFeeStatistics(chain, v.StartTime, v.EndTime)
-		if err != nil {
-			logrus.Errorf("task %s worker %s RelayerFeeStatistics err, %s-%d-%d, %v", w.taskName, w.workerName, chain, v.StartTime, v.EndTime, err)
-		} else {
-			_ = w.saveFeeStat(chain, feeStats, v, op)
+		if err := w.statisticsSegment(chain, v, op); err != nil {
+			// 全量统计时出错的分段及其后的分段都不保存checkpoint, 恢复时重新统计
+			if w.checkpoint != nil {
+				logrus.Errorf("task %s worker %s statistics chain %s is stopped at segment %d-%d", w.taskName, w.workerName, chain, v.StartTime, v.EndTime)
+				return err
+			}
+			continue
+		}
+
+		if w.checkpoint != nil {
+			if err := w.checkpoint.complete(chain, v); err != nil {
+				logrus.Errorf("task %s worker %s save checkpoint err, %s-%d-%d, %v", w.taskName, w.workerName, chain, v.StartTime, v.EndTime, err)
+				return err
+			}
+		}
+	}
+	if w.checkpoint != nil {
+		if err := w.checkpoint.finish(chain); err != nil {
+			logrus.Errorf("task %s worker %s finish checkpoint err, %s, %v", w.taskName, w.workerName, chain, err)
+			return err
 		}
 	}
 
 	logrus.Infof("task %s worker %s statistics chain %s end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
+	return nil
+}
+
+// statisticsSegment the denom and fee statistics of the segment, the first query or save error is returned
+func (w *relayerStatisticsWorker) statisticsSegment(chain string, v *segment, op int) error {
+	// denom statistics
+	denomStats, err := txRepo.RelayerDenomStatistics(chain, v.StartTime, v.EndTime)
+	if err != nil {
+		logrus.Errorf("task %s worker %s RelayerDenomStatistics err, %s-%d-%d, %v", w.taskName, w.workerName, chain, v.StartTime, v.EndTime, err)
+		return err
+	}
+	denomStatMap, addrChannelMap := w.aggrDenomStat(chain, v, denomStats)
+	if err = w.saveDenomStat(chain, denomStatMap, v, op); err != nil {
+		return err
+	}
+	if err = w.saveAddrChannel(addrChannelMap); err != nil {
+		return err
+	}
+	if err = w.saveRelayerAddr(addrChannelMap); err != nil {
+		return err
+	}
+
+	// fee statistics
+	feeStats, err := txRepo.RelayerFeeStatistics(chain, v.StartTime, v.EndTime)
+	if err != nil {
+		logrus.Errorf("task %s worker %s RelayerFeeStatistics err, %s-%d-%d, %v", w.taskName, w.workerName, chain, v.StartTime, v.EndTime, err)
+		return err
+	}
+	return w.saveFeeStat(chain, feeStats, v, op)
 }
 
 func (w *relayerStatisticsWorker) aggrDenomStat(chain string, segment *segment, stats []*dto.RelayerDenomStatisticsDTO) (map[string]*entity.IBCRelayerDenomStatistics, map[string]*entity.IBCRelayerAddressChannel) {
@@ -284,8 +385,8 @@ func (w *relayerStatisticsWorker) saveDenomStat(chain string, denomStatMap map[s
 
 	var err error
 	if op == opInsert {
-		if err = relayerDenomStatisticsRepo.InsertManyToNew(denomStats); err != nil {
-			logrus.Errorf("task %s relayerDenomStatisticsRepo.InsertManyToNew chain: %s err, %v", w.taskName, chain, err)
+		if err = relayerDenomStatisticsRepo.BatchSwapNew(chain, segment.StartTime, segment.EndTime, denomStats); err != nil {
+			logrus.Errorf("task %s relayerDenomStatisticsRepo.BatchSwapNew chain: %s err, %v", w.taskName, chain, err)
 		}
 	} else {
 		if err = relayerDenomStatisticsRepo.BatchSwap(chain, segment.StartTime, segment.EndTime, denomStats); err != nil {
@@ -320,8 +421,8 @@ func (w *relayerStatisticsWorker) saveFeeStat(chain string, feeStats []*dto.Rela
 
 	var err error
 	if op == opInsert {
-		if err = relayerFeeStatisticsRepo.InsertManyToNew(feeStatList); err != nil {
-			logrus.Errorf("task %s relayerFeeStatisticsRepo.InsertManyToNew chain: %s err, %v", w.taskName, chain, err)
+		if err = relayerFeeStatisticsRepo.BatchSwapNew(chain, segment.StartTime, segment.EndTime, feeStatList); err != nil {
+			logrus.Errorf("task %s relayerFeeStatisticsRepo.BatchSwapNew chain: %s err, %v", w.taskName, chain, err)
 		}
 	} else {
 		if err = relayerFeeStatisticsRepo.BatchSwap(chain, segment.StartTime, segment.EndTime, feeStatList); err != nil {
@@ -397,7 +498,7 @@ func (t *FixRelayerStatisticsTask) Switch() bool {
 }
 
 // Run 全量更新
-func (t *FixRelayerStatisticsTask) Run() int {
+func (t *FixRelayerStatisticsTask) Run(ctx context.Context) int {
 	st := time.Now().Unix()
 	chainAddressList, err := relayerDenomStatisticsRepo.AggrChainAddressPair()
 	if err != nil {
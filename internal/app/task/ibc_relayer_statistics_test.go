@@ -1,9 +1,12 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestRelayerStatisticsTask_Run(t *testing.T) {
-	new(RelayerStatisticsTask).Run()
+	new(RelayerStatisticsTask).Run(context.Background())
 }
 
 func Test_RelayerStatisticRunIncrement(t *testing.T) {
@@ -11,13 +14,13 @@ func Test_RelayerStatisticRunIncrement(t *testing.T) {
 		StartTime: 1636761600,
 		EndTime:   1636847999,
 	}
-	_ = relayerStatisticsTask.RunIncrement(&seg)
+	_ = relayerStatisticsTask.RunIncrement(context.Background(), &seg)
 }
 
 func Test_RelayerStatisticsRunWithParam(t *testing.T) {
-	relayerStatisticsTask.RunWithParam("cosmoshub_4", 1640995200, 1641081599)
+	relayerStatisticsTask.RunWithParam(context.Background(), "cosmoshub_4", 1640995200, 1641081599)
 }
 
 func TestFixRelayerStatisticsTask_Run(t *testing.T) {
-	new(FixRelayerStatisticsTask).Run()
+	new(FixRelayerStatisticsTask).Run(context.Background())
 }
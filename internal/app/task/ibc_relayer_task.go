@@ -10,6 +10,7 @@ package task
       5.relayer address 归档到relayer
 */
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -44,17 +45,17 @@ func (t *IbcRelayerCronTask) Cron() int {
 	return ThreeMinute
 }
 
-func (t *IbcRelayerCronTask) Run() int {
+func (t *IbcRelayerCronTask) Run(ctx context.Context) int {
 	if err := t.init(); err != nil {
 		return -1
 	}
 
 	t.denomPriceMap = cache.TokenPriceMap()
-	_ = t.todayStatistics()
-	_ = t.yesterdayStatistics()
-	t.addressGather()
+	_ = t.todayStatistics(ctx)
+	_ = t.yesterdayStatistics(ctx)
+	t.addressGather(ctx)
 
-	t.CheckAndChangeRelayer()
+	t.CheckAndChangeRelayer(ctx)
 	//最后更新chains信息
 	t.updateIbcChainsRelayer()
 
@@ -73,9 +74,9 @@ func (t *IbcRelayerCronTask) init() error {
 	return nil
 }
 
-func (t *IbcRelayerCronTask) updateRelayerUpdateTime(relayer *entity.IBCRelayerNew) {
+func (t *IbcRelayerCronTask) updateRelayerUpdateTime(ctx context.Context, relayer *entity.IBCRelayerNew) {
 	//get latest update_client time
-	updateTime := t.getUpdateTime(relayer)
+	updateTime := t.getUpdateTime(ctx, relayer)
 	if relayer.UpdateTime >= updateTime {
 		return
 	}
@@ -83,7 +84,7 @@ func (t *IbcRelayerCronTask) updateRelayerUpdateTime(relayer *entity.IBCRelayerN
 		logrus.Error("update relayer about update_time fail, ", err.Error())
 	}
 }
-func (t *IbcRelayerCronTask) CheckAndChangeRelayer() {
+func (t *IbcRelayerCronTask) CheckAndChangeRelayer(ctx context.Context) {
 	//并发处理relayer信息
 	handleRelayers := func(workNum int, relayers []*entity.IBCRelayerNew, dowork func(one *entity.IBCRelayerNew)) {
 		var wg sync.WaitGroup
@@ -111,22 +112,24 @@ func (t *IbcRelayerCronTask) CheckAndChangeRelayer() {
 			logrus.Error("find relayer by page fail, ", err.Error())
 			return
 		}
-		handleRelayers(5, relayers, t.updateOneRelayer)
+		handleRelayers(5, relayers, func(one *entity.IBCRelayerNew) {
+			t.updateOneRelayer(ctx, one)
+		})
 
-		if len(relayers) < int(limit) {
+		if len(relayers) < int(limit) || ctx.Err() != nil {
 			break
 		}
 		skip += limit
 	}
 }
 
-func (t *IbcRelayerCronTask) updateOneRelayer(one *entity.IBCRelayerNew) {
+func (t *IbcRelayerCronTask) updateOneRelayer(ctx context.Context, one *entity.IBCRelayerNew) {
 	//更新channel_pair
 	t.handleRelayerChannelPair(one)
 	//更新statistic
 	t.handleRelayerStatistic(t.denomPriceMap, one)
 	//更新relayer的updateTime
-	t.updateRelayerUpdateTime(one)
+	t.updateRelayerUpdateTime(ctx, one)
 	//更新channel的updateTime
 	for _, channelPair := range one.ChannelPairInfo {
 		channelId := generateChannelId(channelPair.ChainA, channelPair.ChannelA, channelPair.ChainB, channelPair.ChannelB)
@@ -213,6 +216,57 @@ func AggrRelayerFeeAmt(relayerNew *entity.IBCRelayerNew) map[string]dto.TxsAmtIt
 	return relayerTxsAmtMap
 }
 
+// AggrRelayerFeeIncomeAmt aggregate ics29 fees paid to relayer addresses and the payees registered by them
+func AggrRelayerFeeIncomeAmt(relayerNew *entity.IBCRelayerNew) map[string]dto.TxsAmtItem {
+	addrCombs := entity.ChannelPairInfoList(relayerNew.ChannelPairInfo).GetChainAddrCombs()
+	if len(addrCombs) == 0 {
+		return nil
+	}
+	combMap := make(map[string]struct{}, len(addrCombs))
+	relayerAddrs := make([]string, 0, len(addrCombs))
+	for _, comb := range addrCombs {
+		combMap[comb] = struct{}{}
+		if split := strings.Split(comb, "|"); len(split) == 2 {
+			relayerAddrs = append(relayerAddrs, split[1])
+		}
+	}
+
+	payees, err := feePayeeRepo.FindByRelayers(relayerAddrs)
+	if err != nil {
+		logrus.Error("find relayer fee payees fail, ", err.Error(), " relayer_id: ", relayerNew.RelayerId)
+		return nil
+	}
+	for _, v := range payees {
+		if _, ok := combMap[entity.GenerateChainAddressComb(v.Chain, v.Relayer)]; !ok || v.PayeeChain == "" {
+			continue
+		}
+		payeeComb := entity.GenerateChainAddressComb(v.PayeeChain, v.Payee)
+		if _, ok := combMap[payeeComb]; !ok {
+			combMap[payeeComb] = struct{}{}
+			addrCombs = append(addrCombs, payeeComb)
+		}
+	}
+
+	res, err := feeDistributionRepo.AggrFeeIncomeDenomAmt(addrCombs)
+	if err != nil {
+		logrus.Error("aggregate relayer fee income fail, ", err.Error(),
+			" relayer_id: ", relayerNew.RelayerId,
+			" relayer_name: ", relayerNew.RelayerName)
+		return nil
+	}
+	relayerIncomeAmtMap := make(map[string]dto.TxsAmtItem, len(res))
+	for _, item := range res {
+		key := fmt.Sprintf("%s%s", item.FeeDenom, item.Chain)
+		relayerIncomeAmtMap[key] = dto.TxsAmtItem{
+			Chain: item.Chain,
+			Denom: item.FeeDenom,
+			Txs:   item.TotalTxs,
+			Amt:   decimal.NewFromFloat(item.Amount),
+		}
+	}
+	return relayerIncomeAmtMap
+}
+
 //dependence: AggrRelayerFeeAmt or AggrRelayerTxsAndAmt
 func caculateRelayerTotalValue(denomPriceMap map[string]dto.CoinItem, relayerTxsDataMap map[string]dto.TxsAmtItem) decimal.Decimal {
 	return dto.CaculateRelayerTotalValue(denomPriceMap, relayerTxsDataMap)
@@ -238,7 +292,7 @@ func (t *IbcRelayerCronTask) updateIbcChainsRelayer() {
 }
 
 //1: updateTime
-func (t *IbcRelayerCronTask) getUpdateTime(relayer *entity.IBCRelayerNew) int64 {
+func (t *IbcRelayerCronTask) getUpdateTime(ctx context.Context, relayer *entity.IBCRelayerNew) int64 {
 	var startTime int64
 
 	//use unbonding_time
@@ -256,7 +310,7 @@ func (t *IbcRelayerCronTask) getUpdateTime(relayer *entity.IBCRelayerNew) int64
 		group.Add(2)
 		go func() {
 			defer group.Done()
-			clientIdA, err = t.getChannelClient(channelPair.ChainA, channelPair.ChannelA)
+			clientIdA, err = t.getChannelClient(ctx, channelPair.ChainA, channelPair.ChannelA)
 			if err != nil {
 				logrus.Warnf("get channel client fail, %s", err.Error())
 				return
@@ -269,7 +323,7 @@ func (t *IbcRelayerCronTask) getUpdateTime(relayer *entity.IBCRelayerNew) int64
 
 		go func() {
 			defer group.Done()
-			clientIdB, err = t.getChannelClient(channelPair.ChainB, channelPair.ChannelB)
+			clientIdB, err = t.getChannelClient(ctx, channelPair.ChainB, channelPair.ChannelB)
 			if err != nil {
 				logrus.Warnf("get channel client fail, %s", err.Error())
 				return
@@ -323,14 +377,14 @@ func (t *IbcRelayerCronTask) getUpdateTime(relayer *entity.IBCRelayerNew) int64
 	return relayerUpdateTime
 }
 
-func (t *IbcRelayerCronTask) getChannelClient(chain, channelId string) (string, error) {
+func (t *IbcRelayerCronTask) getChannelClient(ctx context.Context, chain, channelId string) (string, error) {
 	chainConf, ok := t.chainConfigMap[chain]
 	if !ok {
 		return "", fmt.Errorf("%s config not found", chain)
 	}
 
 	port := chainConf.GetPortId(channelId)
-	state, err := lcd.QueryClientState(chainConf.GrpcRestGateway, chainConf.LcdApiPath.ClientStatePath, port, channelId)
+	state, err := lcd.QueryClientState(ctx, chainConf, port, channelId)
 	if err != nil {
 		return "", err
 	}
@@ -338,7 +392,7 @@ func (t *IbcRelayerCronTask) getChannelClient(chain, channelId string) (string,
 	return state.IdentifiedClientState.ClientId, nil
 }
 
-func (t *IbcRelayerCronTask) todayStatistics() error {
+func (t *IbcRelayerCronTask) todayStatistics(ctx context.Context) error {
 	logrus.Infof("task %s exec today statistics", t.Name())
 	startTime, endTime := todayUnix()
 	segments := []*segment{
@@ -347,7 +401,7 @@ func (t *IbcRelayerCronTask) todayStatistics() error {
 			EndTime:   endTime,
 		},
 	}
-	if err := relayerStatisticsTask.RunIncrement(segments[0]); err != nil {
+	if err := relayerStatisticsTask.RunIncrement(ctx, segments[0]); err != nil {
 		logrus.Errorf("task %s todayStatistics error, %v", t.Name(), err)
 		return err
 	}
@@ -355,14 +409,14 @@ func (t *IbcRelayerCronTask) todayStatistics() error {
 	return nil
 }
 
-func (t *IbcRelayerCronTask) yesterdayStatistics() error {
+func (t *IbcRelayerCronTask) yesterdayStatistics(ctx context.Context) error {
 	ok, seg := whetherCheckYesterdayStatistics(t.Name(), t.Cron())
 	if !ok {
 		return nil
 	}
 
 	logrus.Infof("task %s check yeaterday statistics", t.Name())
-	if err := relayerStatisticsTask.RunIncrement(seg); err != nil {
+	if err := relayerStatisticsTask.RunIncrement(ctx, seg); err != nil {
 		logrus.Errorf("task %s todayStatistics error, %v", t.Name(), err)
 		return err
 	}
@@ -370,8 +424,8 @@ func (t *IbcRelayerCronTask) yesterdayStatistics() error {
 	return nil
 }
 
-func (t *IbcRelayerCronTask) addressGather() {
-	_ = relayerAddressGatherTask.Run()
+func (t *IbcRelayerCronTask) addressGather(ctx context.Context) {
+	_ = relayerAddressGatherTask.Run(ctx)
 }
 
 func (t *IbcRelayerCronTask) handleRelayerChannelPair(relayer *entity.IBCRelayerNew) {
@@ -513,9 +567,11 @@ func matchRelayerChannelPairInfo(pairInfoList []entity.ChannelPairInfo) ([]entit
 
 func getRelayerStatisticData(denomPriceMap map[string]dto.CoinItem, data *entity.IBCRelayerNew) *entity.IBCRelayerNew {
 	wg := sync.WaitGroup{}
-	wg.Add(2)
+	wg.Add(3)
 	var (
 		totalFeeValue                      decimal.Decimal
+		totalFeeIncomeValue                decimal.Decimal
+		feeIncomeRes                       vo.TotalFeeIncomeResp
 		totalTxsValue                      decimal.Decimal
 		relayedTotalTxs, relayedSuccessTxs int64
 	)
@@ -568,7 +624,31 @@ func getRelayerStatisticData(denomPriceMap map[string]dto.CoinItem, data *entity
 		_ = relayerDataCache.SetTotalRelayedValue(data.RelayerId, &res)
 
 	}()
+
+	go func() {
+		defer wg.Done()
+		relayerIncomeAmt := AggrRelayerFeeIncomeAmt(data)
+		totalFeeIncomeValue = caculateRelayerTotalValue(denomPriceMap, relayerIncomeAmt)
+		txsItem := make([]vo.DenomFeeItem, 0, len(relayerIncomeAmt))
+		for _, val := range relayerIncomeAmt {
+			feeIncomeRes.TotalTxs += val.Txs
+			txsItem = append(txsItem, vo.DenomFeeItem{
+				Denom:      val.Denom,
+				DenomChain: val.Chain,
+				Txs:        val.Txs,
+				FeeValue:   val.AmtValue.String(),
+			})
+		}
+		feeIncomeRes.TotalDenomCount = int64(len(txsItem))
+		feeIncomeRes.DenomList = txsItem
+	}()
 	wg.Wait()
+
+	// net revenue depends on both fee cost and fee income
+	feeIncomeRes.TotalFeeIncomeValue = totalFeeIncomeValue.String()
+	feeIncomeRes.TotalFeeCostValue = totalFeeValue.String()
+	feeIncomeRes.NetRevenueValue = totalFeeIncomeValue.Sub(totalFeeValue).String()
+	_ = relayerDataCache.SetTotalFeeIncome(data.RelayerId, &feeIncomeRes)
 	data.RelayedTotalTxsValue = totalTxsValue.String()
 	data.TotalFeeValue = totalFeeValue.String()
 	data.RelayedTotalTxs = relayedTotalTxs
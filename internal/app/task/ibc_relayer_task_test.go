@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"testing"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
@@ -13,7 +14,7 @@ var (
 )
 
 func TestIbcRelayerCronTask_Run(t *testing.T) {
-	task.Run()
+	task.Run(context.Background())
 }
 
 func Test_updateRegisterRelayerChannelPairInfo(t *testing.T) {
@@ -25,7 +26,7 @@ func TestIbcRelayerCronTask_getUpdateTime(t *testing.T) {
 	if err != nil {
 		t.Fatal(err.Error())
 	}
-	data := task.getUpdateTime(one)
+	data := task.getUpdateTime(context.Background(), one)
 	t.Log("updateTime:", data)
 }
 
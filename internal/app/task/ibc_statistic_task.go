@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
@@ -39,7 +40,7 @@ func (t *IbcStatisticCronTask) NewRun() int {
 	return 1
 }
 
-func (t *IbcStatisticCronTask) Run() int {
+func (t *IbcStatisticCronTask) Run(ctx context.Context) int {
 	if err := t.updateChannelAndChains24h(); err != nil {
 		logrus.Error("updateChannelAndChains24h have error,"+err.Error(), " task:", t.Name())
 		return -1
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
@@ -27,7 +28,7 @@ func (t *IbcSyncAcknowledgeTxTask) Cron() int {
 	return ThreeMinute
 }
 
-func (t *IbcSyncAcknowledgeTxTask) Run() int {
+func (t *IbcSyncAcknowledgeTxTask) Run(ctx context.Context) int {
 	syncAcknowledge := func(history bool) error {
 		startTime := time.Now().Add(-3 * time.Hour).Unix()
 		//只处理最近3h的数据
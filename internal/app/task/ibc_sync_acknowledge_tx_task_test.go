@@ -1,7 +1,10 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func Test_IbcSyncAcknowledgeTxTask(t *testing.T) {
-	new(IbcSyncAcknowledgeTxTask).Run()
+	new(IbcSyncAcknowledgeTxTask).Run(context.Background())
 }
@@ -0,0 +1,451 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IbcSyncIcaTxTask sync interchain account(ics27) controller txs from sync_{chain}_tx.
+// MsgRegisterInterchainAccount and channel_open_ack txs are saved as account mappings to ibc_ica_account,
+// packets sent by controller are saved to ex_ibc_ica_tx.
+type IbcSyncIcaTxTask struct {
+}
+
+var _ Task = new(IbcSyncIcaTxTask)
+var icaTxCoordinator *stringQueueCoordinator
+
+func (t *IbcSyncIcaTxTask) Name() string {
+	return "ibc_sync_ica_tx_task"
+}
+
+func (t *IbcSyncIcaTxTask) Cron() int {
+	if taskConf.CronTimeSyncIcaTxTask > 0 {
+		return taskConf.CronTimeSyncIcaTxTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcSyncIcaTxTask) workerNum() int {
+	if global.Config.Task.SyncTransferTxWorkerNum > 0 {
+		return global.Config.Task.SyncTransferTxWorkerNum
+	}
+	return syncTransferTxTaskWorkerNum
+}
+
+func (t *IbcSyncIcaTxTask) Run(ctx context.Context) int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+
+	// init coordinator
+	chainQueue := new(utils.QueueString)
+	for _, v := range chainMap {
+		chainQueue.Push(v.ChainName)
+	}
+	icaTxCoordinator = &stringQueueCoordinator{
+		stringQueue: chainQueue,
+	}
+
+	workerNum := t.workerNum()
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(workerNum)
+	for i := 1; i <= workerNum; i++ {
+		workName := fmt.Sprintf("worker-%d", i)
+		go func(wn string) {
+			newSyncIcaTxWorker(t.Name(), wn, chainMap).exec(ctx)
+			waitGroup.Done()
+		}(workName)
+	}
+	waitGroup.Wait()
+
+	return 1
+}
+
+// =========================================================================
+// =========================================================================
+// worker
+
+func newSyncIcaTxWorker(taskName, workerName string, chainMap map[string]*entity.ChainConfig) *syncIcaTxWorker {
+	return &syncIcaTxWorker{
+		taskName:   taskName,
+		workerName: workerName,
+		chainMap:   chainMap,
+	}
+}
+
+type syncIcaTxWorker struct {
+	taskName   string
+	workerName string
+	chainMap   map[string]*entity.ChainConfig
+}
+
+func (w *syncIcaTxWorker) exec(ctx context.Context) {
+	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
+	for {
+		chain, err := icaTxCoordinator.getOne(ctx)
+		if err != nil {
+			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
+			break
+		}
+
+		if cf, ok := w.chainMap[chain]; ok && cf.Status == entity.ChainStatusClosed {
+			logrus.Infof("task %s worker %s chain %s is closed", w.taskName, w.workerName, chain)
+			continue
+		}
+
+		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
+		startTime := time.Now().Unix()
+		if err = w.parseChainIcaTx(ctx, chain); err != nil {
+			logrus.Errorf("task %s worker %s parse chain %s ica tx error,time use: %d(s), %v", w.taskName, w.workerName, chain, time.Now().Unix()-startTime, err)
+		} else {
+			logrus.Infof("task %s worker %s parse chain %s ica tx end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
+		}
+	}
+}
+
+func (w *syncIcaTxWorker) parseChainIcaTx(ctx context.Context, chain string) error {
+	totalParseTx := 0
+	maxParseTx := global.Config.Task.SingleChainSyncTransferTxMax
+	if maxParseTx <= 0 {
+		maxParseTx = defaultMaxHandlerTx
+	}
+
+	taskRecord, err := w.checkTaskRecord(chain)
+	if err != nil {
+		return err
+	}
+	if taskRecord.Status == entity.TaskRecordStatusClose {
+		return nil
+	}
+
+	hostAddrMap, err := w.getHostAddrMap(chain)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		checkFollowingStatus, err := syncTaskRepo.CheckFollowingStatus(chain)
+		if err != nil {
+			logrus.Errorf("task %s worker %s checkFollowingStatus %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+		if !checkFollowingStatus {
+			logrus.Warningf("chain %s is not follow status", chain)
+			return nil
+		}
+
+		txList, err := w.getTxList(chain, taskRecord.Height, int64(constant.DefaultLimit))
+		if err != nil {
+			return err
+		}
+
+		if len(txList) == 0 {
+			return nil
+		}
+
+		ibcIcaTxList, err := w.handleSourceTx(chain, txList, hostAddrMap)
+		if err != nil {
+			return err
+		}
+		if len(ibcIcaTxList) > 0 {
+			if err = ibcIcaTxRepo.InsertBatch(ibcIcaTxList); err != nil {
+				logrus.Errorf("task %s worker %s ibcIcaTxRepo.InsertBatch %s error, %v", w.taskName, w.workerName, chain, err)
+				return err
+			}
+		}
+
+		taskRecord.Height = txList[len(txList)-1].Height
+		if err = taskRecordRepo.UpdateHeight(taskRecord.TaskName, taskRecord.Height); err != nil {
+			logrus.Errorf("task %s worker %s taskRecordRepo.UpdateHeight %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+
+		totalParseTx += len(txList)
+		if len(txList) < constant.DefaultLimit || totalParseTx >= maxParseTx {
+			break
+		}
+	}
+
+	return nil
+}
+
+// handleSourceTx account mappings are saved immediately, so that packets in the same batch can be matched with host address.
+func (w *syncIcaTxWorker) handleSourceTx(chain string, txList []*entity.Tx, hostAddrMap map[string]string) ([]*entity.ExIbcIcaTx, error) {
+	var ibcIcaTxList []*entity.ExIbcIcaTx
+	for _, tx := range txList {
+		for msgIndex, msg := range tx.DocTxMsgs {
+			switch msg.Type {
+			case constant.MsgTypeRegisterIca:
+				if tx.Status != entity.TxStatusSuccess {
+					continue
+				}
+				if err := w.saveRegister(chain, msgIndex, tx, msg); err != nil {
+					return nil, err
+				}
+			case constant.MsgTypeChannelOpenAck:
+				if tx.Status != entity.TxStatusSuccess || !ibctool.IsIcaControllerPort(msg.ChannelOpenAckMsg().PortId) {
+					continue
+				}
+				if err := w.saveHost(chain, msgIndex, tx, msg, hostAddrMap); err != nil {
+					return nil, err
+				}
+			case constant.MsgTypeIcaSendTx, constant.MsgTypeIcaSubmitTx:
+				ibcIcaTxList = append(ibcIcaTxList, w.buildIcaTx(chain, msgIndex, tx, msg, hostAddrMap))
+			}
+		}
+	}
+	return ibcIcaTxList, nil
+}
+
+func (w *syncIcaTxWorker) saveRegister(chain string, msgIndex int, tx *entity.Tx, msg *model.TxMsg) error {
+	registerMsg := msg.RegisterIcaMsg()
+	port, channel, connection, _, _ := parseChannelOpenEvents(msgIndex, tx, "channel_open_init")
+	if port == "" {
+		port = constant.PortIcaControllerPre + registerMsg.Owner
+	}
+	if connection == "" {
+		connection = registerMsg.ConnectionId
+	}
+
+	account := &entity.IbcIcaAccount{
+		ControllerChain:        chain,
+		ControllerAddress:      registerMsg.Owner,
+		ControllerPort:         port,
+		ControllerChannel:      channel,
+		ControllerConnectionId: connection,
+		RegisterTxHash:         tx.TxHash,
+		RegisterTime:           tx.Time,
+	}
+	if err := icaAccountRepo.SaveRegister(account); err != nil {
+		logrus.Errorf("task %s worker %s chain %s save ica register(%s) error, %v", w.taskName, w.workerName, chain, tx.TxHash, err)
+		return err
+	}
+	return nil
+}
+
+func (w *syncIcaTxWorker) saveHost(chain string, msgIndex int, tx *entity.Tx, msg *model.TxMsg, hostAddrMap map[string]string) error {
+	ackMsg := msg.ChannelOpenAckMsg()
+	var metadata model.IcaMetadata
+	if err := json.Unmarshal([]byte(ackMsg.CounterpartyVersion), &metadata); err != nil {
+		logrus.Warningf("task %s worker %s chain %s tx %s invalid ica metadata: %s", w.taskName, w.workerName, chain, tx.TxHash, ackMsg.CounterpartyVersion)
+		return nil
+	}
+
+	_, _, connection, counterpartyPort, counterpartyChannel := parseChannelOpenEvents(msgIndex, tx, "channel_open_ack")
+	if connection == "" {
+		connection = metadata.ControllerConnectionId
+	}
+	if counterpartyPort == "" {
+		counterpartyPort = constant.PortIcaHost
+	}
+	if counterpartyChannel == "" {
+		counterpartyChannel = ackMsg.CounterpartyChannelId
+	}
+	hostChain, _, _ := ibctool.MatchDcInfo(chain, ackMsg.PortId, ackMsg.ChannelId, w.chainMap)
+
+	account := &entity.IbcIcaAccount{
+		ControllerChain:        chain,
+		ControllerAddress:      ibctool.IcaOwnerFromPort(ackMsg.PortId),
+		ControllerPort:         ackMsg.PortId,
+		ControllerChannel:      ackMsg.ChannelId,
+		ControllerConnectionId: connection,
+		HostChain:              hostChain,
+		HostAddress:            metadata.Address,
+		HostPort:               counterpartyPort,
+		HostChannel:            counterpartyChannel,
+		HostConnectionId:       metadata.HostConnectionId,
+	}
+	if err := icaAccountRepo.SaveHost(account); err != nil {
+		logrus.Errorf("task %s worker %s chain %s save ica host(%s) error, %v", w.taskName, w.workerName, chain, tx.TxHash, err)
+		return err
+	}
+	hostAddrMap[genIcaHostAddrMapKey(account.ControllerPort, account.ControllerConnectionId)] = account.HostAddress
+	return nil
+}
+
+func (w *syncIcaTxWorker) buildIcaTx(chain string, msgIndex int, tx *entity.Tx, msg *model.TxMsg, hostAddrMap map[string]string) *entity.ExIbcIcaTx {
+	var ibcTxStatus entity.IbcTxStatus
+	switch tx.Status {
+	case entity.TxStatusSuccess:
+		ibcTxStatus = entity.IbcTxStatusProcessing
+	case entity.TxStatusFailed:
+		ibcTxStatus = entity.IbcTxStatusFailed
+	}
+
+	sendTxMsg := msg.IcaSendTxMsg()
+	scPort := constant.PortIcaControllerPre + sendTxMsg.Owner
+	scConnection := sendTxMsg.ConnectionId
+	var scChannel, dcPort, dcChannel, sequence string
+	var packet model.Packet
+	var packetData model.IcaPacketData
+	if ibcTxStatus != entity.IbcTxStatusFailed {
+		var eventConnection string
+		packet, eventConnection, packetData = parseIcaSendTxEvents(msgIndex, tx)
+		if packet.SourcePort != "" {
+			scPort = packet.SourcePort
+		}
+		if eventConnection != "" {
+			scConnection = eventConnection
+		}
+		scChannel, dcPort, dcChannel = packet.SourceChannel, packet.DestinationPort, packet.DestinationChannel
+		sequence = strconv.FormatInt(packet.Sequence, 10)
+	}
+
+	dcChain, _, _ := ibctool.MatchDcInfo(chain, scPort, scChannel, w.chainMap)
+	if dcChain == "" && ibcTxStatus != entity.IbcTxStatusFailed {
+		ibcTxStatus = entity.IbcTxStatusSetting
+	}
+
+	packetId := sendTxMsg.PacketId
+	if packetId == "" && sequence != "" {
+		packetId = ibctool.BuildPacketId(scPort, scChannel, dcPort, dcChannel, sequence)
+	}
+
+	recordIdStr := fmt.Sprintf("%s%s%s%s%s%s%s%d", scPort, scChannel, dcPort, dcChannel, sequence, chain, tx.TxHash, msgIndex)
+	nowUnix := time.Now().Unix()
+	ibcIcaTx := &entity.ExIbcIcaTx{
+		Id:               primitive.NewObjectID(),
+		RecordId:         utils.Md5(recordIdStr),
+		TxTime:           tx.Time,
+		ScAddr:           ibctool.IcaOwnerFromPort(scPort),
+		DcAddr:           hostAddrMap[genIcaHostAddrMapKey(scPort, scConnection)],
+		ScPort:           scPort,
+		ScChannel:        scChannel,
+		ScConnectionId:   scConnection,
+		ScChain:          chain,
+		DcPort:           dcPort,
+		DcChannel:        dcChannel,
+		DcChain:          dcChain,
+		Sequence:         sequence,
+		PacketId:         packetId,
+		PacketType:       packetData.Type,
+		PacketMemo:       packetData.Memo,
+		TimeoutHeight:    packet.TimeoutHeight.RevisionHeight,
+		TimeoutTimestamp: packet.TimeoutTimestamp,
+		Status:           ibcTxStatus,
+		ScTxInfo: &entity.TxInfo{
+			Hash:    tx.TxHash,
+			Status:  tx.Status,
+			Time:    tx.Time,
+			Height:  tx.Height,
+			Fee:     tx.Fee,
+			Msg:     msg,
+			Memo:    tx.Memo,
+			Signers: tx.Signers,
+			Log:     tx.Log,
+		},
+		RetryTimes:  0,
+		NextTryTime: nowUnix,
+		CreateAt:    nowUnix,
+		UpdateAt:    nowUnix,
+	}
+	if chainConf, ok := w.chainMap[chain]; ok {
+		ibcIcaTx.ScClientId = chainConf.GetChannelClient(scPort, scChannel)
+	}
+	if chainConf, ok := w.chainMap[dcChain]; ok {
+		ibcIcaTx.DcClientId = chainConf.GetChannelClient(dcPort, dcChannel)
+	}
+	return ibcIcaTx
+}
+
+func genIcaHostAddrMapKey(controllerPort, controllerConnection string) string {
+	return fmt.Sprintf("%s_%s", controllerPort, controllerConnection)
+}
+
+func (w *syncIcaTxWorker) getHostAddrMap(chain string) (map[string]string, error) {
+	accounts, err := icaAccountRepo.FindByControllerChain(chain)
+	if err != nil {
+		logrus.Errorf("task %s worker %s getHostAddrMap %s error, %v", w.taskName, w.workerName, chain, err)
+		return nil, err
+	}
+
+	res := make(map[string]string, len(accounts))
+	for _, v := range accounts {
+		if v.HostAddress != "" {
+			res[genIcaHostAddrMapKey(v.ControllerPort, v.ControllerConnectionId)] = v.HostAddress
+		}
+	}
+	return res, nil
+}
+
+// checkTaskRecord 检查ica task_record的状态，如果不存在task_record 记录，则新增
+func (w *syncIcaTxWorker) checkTaskRecord(chain string) (*entity.IbcTaskRecord, error) {
+	taskName := fmt.Sprintf(entity.IcaTaskNameFmt, chain)
+	taskRecord, err := taskRecordRepo.FindByTaskName(taskName)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			logrus.Errorf("task %s worker %s checkTaskRecord %s error, %v", w.taskName, w.workerName, chain, err)
+			return nil, err
+		}
+
+		taskRecord = &entity.IbcTaskRecord{
+			TaskName: taskName,
+			Height:   0,
+			Status:   entity.TaskRecordStatusOpen,
+			CreateAt: time.Now().Unix(),
+			UpdateAt: time.Now().Unix(),
+		}
+
+		if err := taskRecordRepo.Insert(taskRecord); err != nil {
+			logrus.Errorf("task %s worker %s checkTaskRecord %s error, %v", w.taskName, w.workerName, chain, err)
+			return nil, err
+		}
+	}
+
+	return taskRecord, nil
+}
+
+func (w *syncIcaTxWorker) getTxList(chain string, height, limit int64) ([]*entity.Tx, error) {
+	txList, err := txRepo.GetIcaTx(chain, height, limit)
+	if err != nil {
+		logrus.Errorf("task %s worker %s GetIcaTx %s error, %v", w.taskName, w.workerName, chain, err)
+		return nil, err
+	}
+
+	if len(txList) < int(limit) {
+		return txList, nil
+	}
+
+	// make sure all txs of the max height are handled in this batch
+	maxHeight := txList[len(txList)-1].Height
+	txHashMap := make(map[string]string)
+	for _, v := range txList {
+		if v.Height == maxHeight {
+			txHashMap[v.TxHash] = ""
+		}
+	}
+
+	heightTxList, err := txRepo.FindByTypesAndHeight(chain, constant.IcaControllerMsgTypes, maxHeight)
+	if err != nil {
+		logrus.Errorf("task %s worker %s FindByTypesAndHeight %s error, %v", w.taskName, w.workerName, chain, err)
+		return nil, err
+	}
+
+	for _, v := range heightTxList {
+		if _, ok := txHashMap[v.TxHash]; !ok {
+			txList = append(txList, v)
+		}
+	}
+
+	return txList, nil
+}
@@ -0,0 +1,124 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_SyncIcaTx(t *testing.T) {
+	if res := new(IbcSyncIcaTxTask).Run(context.Background()); res != 1 {
+		t.Fatalf("unexpected exit status %d", res)
+	}
+}
+
+func icaChainMap() map[string]*entity.ChainConfig {
+	controllerPort := constant.PortIcaControllerPre + "cosmos1owner"
+	return map[string]*entity.ChainConfig{
+		"cosmoshub": {ChainName: "cosmoshub", IbcInfo: []*entity.IbcInfo{{Chain: "osmosis", Paths: []*entity.ChannelPath{{
+			PortId: controllerPort, ChannelId: "channel-20", Chain: "osmosis", ClientId: "07-tendermint-20",
+			Counterparty: entity.CounterParty{PortId: constant.PortIcaHost, ChannelId: "channel-40"},
+		}}}}},
+		"osmosis": {ChainName: "osmosis", IbcInfo: []*entity.IbcInfo{{Chain: "cosmoshub", Paths: []*entity.ChannelPath{{
+			PortId: constant.PortIcaHost, ChannelId: "channel-40", Chain: "cosmoshub", ClientId: "07-tendermint-40",
+			Counterparty: entity.CounterParty{PortId: controllerPort, ChannelId: "channel-20"},
+		}}}}},
+	}
+}
+
+func Test_BuildIcaTx(t *testing.T) {
+	w := newSyncIcaTxWorker("ica", "worker", icaChainMap())
+	controllerPort := constant.PortIcaControllerPre + "cosmos1owner"
+	hostAddrMap := map[string]string{genIcaHostAddrMapKey(controllerPort, "connection-2"): "osmo1host"}
+	msg := &model.TxMsg{Type: constant.MsgTypeIcaSendTx, Msg: bson.M{"owner": "cosmos1owner", "connection_id": "connection-2"}}
+	tx := &entity.Tx{
+		TxHash:    "ica_send_tx",
+		Status:    entity.TxStatusSuccess,
+		DocTxMsgs: []*model.TxMsg{msg},
+		EventsNew: []entity.EventNew{{Events: []entity.Event{{
+			Type: "send_packet",
+			Attributes: []entity.KvPair{
+				{Key: "packet_src_port", Value: controllerPort},
+				{Key: "packet_src_channel", Value: "channel-20"},
+				{Key: "packet_dst_port", Value: constant.PortIcaHost},
+				{Key: "packet_dst_channel", Value: "channel-40"},
+				{Key: "packet_sequence", Value: "7"},
+				{Key: "packet_timeout_height", Value: "1-1000"},
+				{Key: "packet_connection", Value: "connection-2"},
+				{Key: "packet_data", Value: `{"type":"TYPE_EXECUTE_TX","data":"CgQ=","memo":"ica memo"}`},
+			},
+		}}}},
+	}
+
+	ibcIcaTx := w.buildIcaTx("cosmoshub", 0, tx, msg, hostAddrMap)
+	if ibcIcaTx.Status != entity.IbcTxStatusProcessing || ibcIcaTx.ScAddr != "cosmos1owner" || ibcIcaTx.DcAddr != "osmo1host" ||
+		ibcIcaTx.DcChain != "osmosis" || ibcIcaTx.DcChannel != "channel-40" || ibcIcaTx.Sequence != "7" ||
+		ibcIcaTx.PacketId != controllerPort+"channel-20"+constant.PortIcaHost+"channel-407" || ibcIcaTx.TimeoutHeight != 1000 ||
+		ibcIcaTx.PacketType != "TYPE_EXECUTE_TX" || ibcIcaTx.PacketMemo != "ica memo" ||
+		ibcIcaTx.ScClientId != "07-tendermint-20" || ibcIcaTx.DcClientId != "07-tendermint-40" {
+		t.Fatalf("unexpected ibc ica tx %s", utils.MustMarshalJsonToStr(ibcIcaTx))
+	}
+
+	// the events of a failed tx are not parsed, the packet is located by the msg only
+	tx.Status = entity.TxStatusFailed
+	ibcIcaTx = w.buildIcaTx("cosmoshub", 0, tx, msg, hostAddrMap)
+	if ibcIcaTx.Status != entity.IbcTxStatusFailed || ibcIcaTx.ScPort != controllerPort || ibcIcaTx.ScChannel != "" ||
+		ibcIcaTx.PacketId != "" || ibcIcaTx.DcAddr != "osmo1host" {
+		t.Fatalf("unexpected failed ibc ica tx %s", utils.MustMarshalJsonToStr(ibcIcaTx))
+	}
+}
+
+func Test_IcaLoadPacketTx(t *testing.T) {
+	w := newIbcIcaTxRelateWorker("ica_relate", "worker", icaChainMap())
+	packetId := "icacontroller-cosmos1ownerchannel-20icahostchannel-407"
+	recvTx := func(packetAck string) *entity.Tx {
+		return &entity.Tx{
+			TxHash:    "recv_tx",
+			Status:    entity.TxStatusSuccess,
+			DocTxMsgs: []*model.TxMsg{{Type: constant.MsgTypeRecvPacket, Msg: bson.M{"packet_id": packetId}}},
+			EventsNew: []entity.EventNew{{Events: []entity.Event{
+				{Type: "recv_packet", Attributes: []entity.KvPair{{Key: "packet_connection", Value: "connection-4"}}},
+				{Type: "write_acknowledgement", Attributes: []entity.KvPair{{Key: "packet_ack", Value: packetAck}}},
+			}}},
+		}
+	}
+	ackTx := &entity.Tx{
+		TxHash:    "ack_tx",
+		Status:    entity.TxStatusSuccess,
+		DocTxMsgs: []*model.TxMsg{{Type: constant.MsgTypeAcknowledgement, Msg: bson.M{"packet_id": packetId}}},
+	}
+
+	ibcIcaTx := &entity.ExIbcIcaTx{PacketId: packetId, Status: entity.IbcTxStatusProcessing}
+	w.loadRecvPacketTx(ibcIcaTx, []*entity.Tx{recvTx(`{"result":"Eg=="}`)}, []*entity.Tx{ackTx})
+	if ibcIcaTx.Status != entity.IbcTxStatusSuccess || ibcIcaTx.DcConnectionId != "connection-4" ||
+		ibcIcaTx.DcTxInfo.Hash != "recv_tx" || ibcIcaTx.AckTimeoutTxInfo.Hash != "ack_tx" {
+		t.Fatalf("unexpected success ibc ica tx %s", utils.MustMarshalJsonToStr(ibcIcaTx))
+	}
+
+	// the host failed to execute the msgs, nothing is refunded
+	ibcIcaTx = &entity.ExIbcIcaTx{PacketId: packetId, Status: entity.IbcTxStatusProcessing}
+	w.loadRecvPacketTx(ibcIcaTx, []*entity.Tx{recvTx(`{"error":"ABCI code: 5"}`)}, nil)
+	if ibcIcaTx.Status != entity.IbcTxStatusFailed || ibcIcaTx.DcTxInfo.Hash != "recv_tx" || ibcIcaTx.AckTimeoutTxInfo != nil {
+		t.Fatalf("unexpected failed ibc ica tx %s", utils.MustMarshalJsonToStr(ibcIcaTx))
+	}
+
+	ibcIcaTx = &entity.ExIbcIcaTx{PacketId: packetId, Status: entity.IbcTxStatusProcessing}
+	w.loadTimeoutPacketTx(ibcIcaTx, &entity.Tx{
+		TxHash:    "timeout_tx",
+		DocTxMsgs: []*model.TxMsg{{Type: constant.MsgTypeTimeoutPacket, Msg: bson.M{"packet_id": packetId}}},
+	})
+	if ibcIcaTx.Status != entity.IbcTxStatusFailed || ibcIcaTx.AckTimeoutTxInfo.Hash != "timeout_tx" {
+		t.Fatalf("unexpected timeout ibc ica tx %s", utils.MustMarshalJsonToStr(ibcIcaTx))
+	}
+}
+
+func Test_IcaTxRelateTask(t *testing.T) {
+	if res := new(IbcIcaTxRelateTask).Run(context.Background()); res != 1 {
+		t.Fatalf("unexpected exit status %d", res)
+	}
+}
@@ -0,0 +1,342 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IbcSyncNftTransferTxTask sync ics721 transfer txs from sync_{chain}_tx to ex_ibc_nft_tx
+type IbcSyncNftTransferTxTask struct {
+}
+
+var _ Task = new(IbcSyncNftTransferTxTask)
+var nftTransferTxCoordinator *stringQueueCoordinator
+
+func (t *IbcSyncNftTransferTxTask) Name() string {
+	return "ibc_sync_nft_transfer_tx_task"
+}
+
+func (t *IbcSyncNftTransferTxTask) Cron() int {
+	if taskConf.CronTimeSyncNftTransferTxTask > 0 {
+		return taskConf.CronTimeSyncNftTransferTxTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcSyncNftTransferTxTask) workerNum() int {
+	if global.Config.Task.SyncTransferTxWorkerNum > 0 {
+		return global.Config.Task.SyncTransferTxWorkerNum
+	}
+	return syncTransferTxTaskWorkerNum
+}
+
+func (t *IbcSyncNftTransferTxTask) Run(ctx context.Context) int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+
+	// init coordinator
+	chainQueue := new(utils.QueueString)
+	for _, v := range chainMap {
+		chainQueue.Push(v.ChainName)
+	}
+	nftTransferTxCoordinator = &stringQueueCoordinator{
+		stringQueue: chainQueue,
+	}
+
+	workerNum := t.workerNum()
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(workerNum)
+	for i := 1; i <= workerNum; i++ {
+		workName := fmt.Sprintf("worker-%d", i)
+		go func(wn string) {
+			newSyncNftTransferTxWorker(t.Name(), wn, chainMap).exec(ctx)
+			waitGroup.Done()
+		}(workName)
+	}
+	waitGroup.Wait()
+
+	return 1
+}
+
+// =========================================================================
+// =========================================================================
+// worker
+
+func newSyncNftTransferTxWorker(taskName, workerName string, chainMap map[string]*entity.ChainConfig) *syncNftTransferTxWorker {
+	return &syncNftTransferTxWorker{
+		taskName:   taskName,
+		workerName: workerName,
+		chainMap:   chainMap,
+	}
+}
+
+type syncNftTransferTxWorker struct {
+	taskName   string
+	workerName string
+	chainMap   map[string]*entity.ChainConfig
+}
+
+func (w *syncNftTransferTxWorker) exec(ctx context.Context) {
+	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
+	for {
+		chain, err := nftTransferTxCoordinator.getOne(ctx)
+		if err != nil {
+			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
+			break
+		}
+
+		if cf, ok := w.chainMap[chain]; ok && cf.Status == entity.ChainStatusClosed {
+			logrus.Infof("task %s worker %s chain %s is closed", w.taskName, w.workerName, chain)
+			continue
+		}
+
+		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
+		startTime := time.Now().Unix()
+		if err = w.parseChainIbcNftTx(ctx, chain); err != nil {
+			logrus.Errorf("task %s worker %s parse chain %s nft tx error,time use: %d(s), %v", w.taskName, w.workerName, chain, time.Now().Unix()-startTime, err)
+		} else {
+			logrus.Infof("task %s worker %s parse chain %s nft tx end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
+		}
+	}
+}
+
+func (w *syncNftTransferTxWorker) parseChainIbcNftTx(ctx context.Context, chain string) error {
+	totalParseTx := 0
+	maxParseTx := global.Config.Task.SingleChainSyncTransferTxMax
+	if maxParseTx <= 0 {
+		maxParseTx = defaultMaxHandlerTx
+	}
+
+	taskRecord, err := w.checkTaskRecord(chain)
+	if err != nil {
+		return err
+	}
+	if taskRecord.Status == entity.TaskRecordStatusClose {
+		return nil
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		checkFollowingStatus, err := syncTaskRepo.CheckFollowingStatus(chain)
+		if err != nil {
+			logrus.Errorf("task %s worker %s checkFollowingStatus %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+		if !checkFollowingStatus {
+			logrus.Warningf("chain %s is not follow status", chain)
+			return nil
+		}
+
+		txList, err := w.getTxList(chain, taskRecord.Height, int64(constant.DefaultLimit))
+		if err != nil {
+			return err
+		}
+
+		if len(txList) == 0 {
+			return nil
+		}
+
+		ibcNftTxList := w.handleSourceTx(chain, txList)
+		if len(ibcNftTxList) > 0 {
+			if err = ibcNftTxRepo.InsertBatch(ibcNftTxList); err != nil {
+				logrus.Errorf("task %s worker %s ibcNftTxRepo.InsertBatch %s error, %v", w.taskName, w.workerName, chain, err)
+				return err
+			}
+		}
+
+		taskRecord.Height = txList[len(txList)-1].Height
+		if err = taskRecordRepo.UpdateHeight(taskRecord.TaskName, taskRecord.Height); err != nil {
+			logrus.Errorf("task %s worker %s taskRecordRepo.UpdateHeight %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+
+		totalParseTx += len(txList)
+		if len(txList) < constant.DefaultLimit || totalParseTx >= maxParseTx {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (w *syncNftTransferTxWorker) handleSourceTx(chain string, txList []*entity.Tx) []*entity.ExIbcNftTx {
+	var ibcNftTxList []*entity.ExIbcNftTx
+	for _, tx := range txList {
+		for msgIndex, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeNftTransfer {
+				continue
+			}
+
+			var ibcTxStatus entity.IbcTxStatus
+			switch tx.Status {
+			case entity.TxStatusSuccess:
+				ibcTxStatus = entity.IbcTxStatusProcessing
+			case entity.TxStatusFailed:
+				ibcTxStatus = entity.IbcTxStatusFailed
+			}
+
+			nftTransferMsg := msg.NftTransferMsg()
+			scPort := nftTransferMsg.SourcePort
+			scChannel := nftTransferMsg.SourceChannel
+			dcChain, dcPort, dcChannel := ibctool.MatchDcInfo(chain, scPort, scChannel, w.chainMap)
+
+			class := &entity.NftClass{
+				ScClassId: nftTransferMsg.ClassId,
+			}
+			tokenIds := nftTransferMsg.TokenIds
+			var tokenUris []string
+			var sequence, scConnection string
+			if ibcTxStatus != entity.IbcTxStatusFailed {
+				var packetData model.NftTransferTxPacketData
+				dcPort, dcChannel, sequence, scConnection, packetData = parseNftTransferTxEvents(msgIndex, tx)
+				class.ClassPath = packetData.ClassId
+				class.ClassUri = packetData.ClassUri
+				tokenUris = packetData.TokenUris
+				if len(packetData.TokenIds) > 0 {
+					tokenIds = packetData.TokenIds
+				}
+				// class trace follows the same rule as ics20 denom trace
+				if traced := ibctool.TraceDenom(class.ClassPath, chain, w.chainMap); traced != nil {
+					class.BaseClassId = traced.BaseDenom
+					class.BaseClassChain = traced.BaseDenomChain
+				}
+			}
+
+			if dcChain == "" && ibcTxStatus != entity.IbcTxStatusFailed {
+				ibcTxStatus = entity.IbcTxStatusSetting
+			}
+
+			recordIdStr := fmt.Sprintf("%s%s%s%s%s%s%s%d", scPort, scChannel, dcPort, dcChannel, sequence, chain, tx.TxHash, msgIndex)
+			nowUnix := time.Now().Unix()
+			ibcNftTx := &entity.ExIbcNftTx{
+				Id:             primitive.NewObjectID(),
+				RecordId:       utils.Md5(recordIdStr),
+				TxTime:         tx.Time,
+				ScAddr:         nftTransferMsg.Sender,
+				DcAddr:         nftTransferMsg.Receiver,
+				ScPort:         scPort,
+				ScChannel:      scChannel,
+				ScConnectionId: scConnection,
+				ScChain:        chain,
+				DcPort:         dcPort,
+				DcChannel:      dcChannel,
+				DcChain:        dcChain,
+				Sequence:       sequence,
+				Status:         ibcTxStatus,
+				ScTxInfo: &entity.TxInfo{
+					Hash:    tx.TxHash,
+					Status:  tx.Status,
+					Time:    tx.Time,
+					Height:  tx.Height,
+					Fee:     tx.Fee,
+					Msg:     msg,
+					Memo:    tx.Memo,
+					Signers: tx.Signers,
+					Log:     tx.Log,
+				},
+				Class:       class,
+				TokenIds:    tokenIds,
+				TokenUris:   tokenUris,
+				RetryTimes:  0,
+				NextTryTime: nowUnix,
+				CreateAt:    nowUnix,
+				UpdateAt:    nowUnix,
+			}
+			w.setClientId(ibcNftTx)
+			ibcNftTxList = append(ibcNftTxList, ibcNftTx)
+		}
+	}
+	return ibcNftTxList
+}
+
+func (w *syncNftTransferTxWorker) setClientId(ibcNftTx *entity.ExIbcNftTx) {
+	if chainConf, ok := w.chainMap[ibcNftTx.ScChain]; ok {
+		ibcNftTx.ScClientId = chainConf.GetChannelClient(ibcNftTx.ScPort, ibcNftTx.ScChannel)
+	}
+
+	if ibcNftTx.DcChain != "" {
+		if chainConf, ok := w.chainMap[ibcNftTx.DcChain]; ok {
+			ibcNftTx.DcClientId = chainConf.GetChannelClient(ibcNftTx.DcPort, ibcNftTx.DcChannel)
+		}
+	}
+}
+
+// checkTaskRecord 检查nft task_record的状态，如果不存在task_record 记录，则新增
+func (w *syncNftTransferTxWorker) checkTaskRecord(chain string) (*entity.IbcTaskRecord, error) {
+	taskName := fmt.Sprintf(entity.NftTaskNameFmt, chain)
+	taskRecord, err := taskRecordRepo.FindByTaskName(taskName)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			logrus.Errorf("task %s worker %s checkTaskRecord %s error, %v", w.taskName, w.workerName, chain, err)
+			return nil, err
+		}
+
+		taskRecord = &entity.IbcTaskRecord{
+			TaskName: taskName,
+			Height:   0,
+			Status:   entity.TaskRecordStatusOpen,
+			CreateAt: time.Now().Unix(),
+			UpdateAt: time.Now().Unix(),
+		}
+
+		if err := taskRecordRepo.Insert(taskRecord); err != nil {
+			logrus.Errorf("task %s worker %s checkTaskRecord %s error, %v", w.taskName, w.workerName, chain, err)
+			return nil, err
+		}
+	}
+
+	return taskRecord, nil
+}
+
+func (w *syncNftTransferTxWorker) getTxList(chain string, height, limit int64) ([]*entity.Tx, error) {
+	txList, err := txRepo.GetNftTransferTx(chain, height, limit)
+	if err != nil {
+		logrus.Errorf("task %s worker %s GetNftTransferTx %s error, %v", w.taskName, w.workerName, chain, err)
+		return nil, err
+	}
+
+	if len(txList) < int(limit) {
+		return txList, nil
+	}
+
+	// make sure all txs of the max height are handled in this batch
+	maxHeight := txList[len(txList)-1].Height
+	txHashMap := make(map[string]string)
+	for _, v := range txList {
+		if v.Height == maxHeight {
+			txHashMap[v.TxHash] = ""
+		}
+	}
+
+	heightTxList, err := txRepo.FindByTypeAndHeight(chain, constant.MsgTypeNftTransfer, maxHeight)
+	if err != nil {
+		logrus.Errorf("task %s worker %s FindByTypeAndHeight %s error, %v", w.taskName, w.workerName, chain, err)
+		return nil, err
+	}
+
+	for _, v := range heightTxList {
+		if _, ok := txHashMap[v.TxHash]; !ok {
+			txList = append(txList, v)
+		}
+	}
+
+	return txList, nil
+}
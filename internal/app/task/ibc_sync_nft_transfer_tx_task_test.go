@@ -0,0 +1,136 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_SyncNftTransferTx(t *testing.T) {
+	if res := new(IbcSyncNftTransferTxTask).Run(context.Background()); res != 1 {
+		t.Fatalf("unexpected exit status %d", res)
+	}
+}
+
+func nftTransferChainMap() map[string]*entity.ChainConfig {
+	return map[string]*entity.ChainConfig{
+		"irishub_qa": {ChainName: "irishub_qa", IbcInfo: []*entity.IbcInfo{{Chain: "uptick_qa", Paths: []*entity.ChannelPath{{
+			PortId: constant.PortNftTransfer, ChannelId: "channel-5", Chain: "uptick_qa", ClientId: "07-tendermint-5",
+			Counterparty: entity.CounterParty{PortId: constant.PortNftTransfer, ChannelId: "channel-9"},
+		}}}}},
+		"uptick_qa": {ChainName: "uptick_qa", IbcInfo: []*entity.IbcInfo{{Chain: "irishub_qa", Paths: []*entity.ChannelPath{{
+			PortId: constant.PortNftTransfer, ChannelId: "channel-9", Chain: "irishub_qa", ClientId: "07-tendermint-9",
+			Counterparty: entity.CounterParty{PortId: constant.PortNftTransfer, ChannelId: "channel-5"},
+		}}}}},
+	}
+}
+
+func Test_HandleNftSourceTx(t *testing.T) {
+	w := newSyncNftTransferTxWorker("nft_transfer", "worker", nftTransferChainMap())
+	msg := &model.TxMsg{Type: constant.MsgTypeNftTransfer, Msg: bson.M{
+		"source_port": constant.PortNftTransfer, "source_channel": "channel-5", "class_id": "cat",
+		"token_ids": []string{"cat1"}, "sender": "iaa1sender", "receiver": "uptick1receiver",
+	}}
+	tx := &entity.Tx{
+		TxHash:    "nft_transfer_tx",
+		Status:    entity.TxStatusSuccess,
+		Height:    100,
+		DocTxMsgs: []*model.TxMsg{{Type: constant.MsgTypeTransfer}, msg},
+		EventsNew: []entity.EventNew{{MsgIndex: 0}, {MsgIndex: 1, Events: []entity.Event{{
+			Type: "send_packet",
+			Attributes: []entity.KvPair{
+				{Key: "packet_dst_port", Value: constant.PortNftTransfer},
+				{Key: "packet_dst_channel", Value: "channel-9"},
+				{Key: "packet_sequence", Value: "3"},
+				{Key: "packet_connection", Value: "connection-5"},
+				{Key: "packet_data", Value: `{"classId":"cat","classUri":"ipfs://cat","tokenIds":["cat1","cat2"],"tokenUris":["ipfs://cat1","ipfs://cat2"]}`},
+			},
+		}}}},
+	}
+	failedTx := &entity.Tx{TxHash: "failed_nft_transfer_tx", Status: entity.TxStatusFailed, DocTxMsgs: []*model.TxMsg{msg}}
+
+	ibcNftTxList := w.handleSourceTx("irishub_qa", []*entity.Tx{tx, failedTx})
+	if len(ibcNftTxList) != 2 {
+		t.Fatalf("unexpected ibc nft txs %s", utils.MustMarshalJsonToStr(ibcNftTxList))
+	}
+
+	ibcNftTx := ibcNftTxList[0]
+	if ibcNftTx.Status != entity.IbcTxStatusProcessing || ibcNftTx.DcChain != "uptick_qa" || ibcNftTx.DcChannel != "channel-9" ||
+		ibcNftTx.Sequence != "3" || ibcNftTx.ScConnectionId != "connection-5" || ibcNftTx.ScClientId != "07-tendermint-5" ||
+		ibcNftTx.DcClientId != "07-tendermint-9" || ibcNftTx.ScAddr != "iaa1sender" || ibcNftTx.DcAddr != "uptick1receiver" {
+		t.Fatalf("unexpected ibc nft tx %s", utils.MustMarshalJsonToStr(ibcNftTx))
+	}
+	if len(ibcNftTx.TokenIds) != 2 || len(ibcNftTx.TokenUris) != 2 || ibcNftTx.Class.ClassPath != "cat" ||
+		ibcNftTx.Class.ClassUri != "ipfs://cat" || ibcNftTx.Class.BaseClassId != "cat" || ibcNftTx.Class.BaseClassChain != "irishub_qa" {
+		t.Fatalf("unexpected class or tokens %s", utils.MustMarshalJsonToStr(ibcNftTx))
+	}
+
+	// the events of a failed tx are not parsed, the tokens of the msg are kept
+	if failed := ibcNftTxList[1]; failed.Status != entity.IbcTxStatusFailed || failed.Sequence != "" || len(failed.TokenIds) != 1 {
+		t.Fatalf("unexpected failed ibc nft tx %s", utils.MustMarshalJsonToStr(failed))
+	}
+}
+
+func Test_NftLoadRecvPacketTx(t *testing.T) {
+	w := newIbcNftTxRelateWorker("nft_relate", "worker", nftTransferChainMap())
+	packetId := "nft-transferchannel-5nft-transferchannel-93"
+	newIbcNftTx := func() *entity.ExIbcNftTx {
+		return &entity.ExIbcNftTx{
+			ScChain: "irishub_qa", ScPort: constant.PortNftTransfer, ScChannel: "channel-5",
+			DcChain: "uptick_qa", DcPort: constant.PortNftTransfer, DcChannel: "channel-9",
+			Status:   entity.IbcTxStatusProcessing,
+			Class:    &entity.NftClass{ScClassId: "cat", ClassPath: "cat"},
+			ScTxInfo: &entity.TxInfo{Msg: &model.TxMsg{Type: constant.MsgTypeNftTransfer, Msg: bson.M{"packet_id": packetId}}},
+		}
+	}
+	recvTx := func(packetAck string) *entity.Tx {
+		return &entity.Tx{
+			TxHash:    "recv_tx",
+			Status:    entity.TxStatusSuccess,
+			DocTxMsgs: []*model.TxMsg{{Type: constant.MsgTypeRecvPacket, Msg: bson.M{"packet_id": packetId}}},
+			EventsNew: []entity.EventNew{{Events: []entity.Event{
+				{Type: "recv_packet", Attributes: []entity.KvPair{{Key: "packet_connection", Value: "connection-9"}}},
+				{Type: "write_acknowledgement", Attributes: []entity.KvPair{{Key: "packet_ack", Value: packetAck}}},
+			}}},
+		}
+	}
+	ackTx := &entity.Tx{
+		TxHash:    "ack_tx",
+		Status:    entity.TxStatusSuccess,
+		Time:      10,
+		DocTxMsgs: []*model.TxMsg{{Type: constant.MsgTypeAcknowledgement, Msg: bson.M{"packet_id": packetId}}},
+	}
+
+	ibcNftTx := newIbcNftTx()
+	w.loadRecvPacketTx(ibcNftTx, []*entity.Tx{recvTx(`{"result":"AQ=="}`)}, []*entity.Tx{ackTx})
+	if ibcNftTx.Status != entity.IbcTxStatusSuccess || ibcNftTx.DcConnectionId != "connection-9" ||
+		ibcNftTx.DcTxInfo.Hash != "recv_tx" || ibcNftTx.AckTimeoutTxInfo.Hash != "ack_tx" {
+		t.Fatalf("unexpected success ibc nft tx %s", utils.MustMarshalJsonToStr(ibcNftTx))
+	}
+	if ibcNftTx.Class.DcClassId != ibctool.CalculateIBCHash("nft-transfer/channel-9/cat") {
+		t.Fatalf("unexpected dc class id %s", ibcNftTx.Class.DcClassId)
+	}
+
+	// an error ack is refunded only with its ack_packet tx
+	ibcNftTx = newIbcNftTx()
+	w.loadRecvPacketTx(ibcNftTx, []*entity.Tx{recvTx(`{"error":"class not found"}`)}, nil)
+	if ibcNftTx.Status != entity.IbcTxStatusProcessing || ibcNftTx.DcTxInfo != nil {
+		t.Fatalf("refunded without ack tx %s", utils.MustMarshalJsonToStr(ibcNftTx))
+	}
+	w.loadRecvPacketTx(ibcNftTx, []*entity.Tx{recvTx(`{"error":"class not found"}`)}, []*entity.Tx{ackTx})
+	if ibcNftTx.Status != entity.IbcTxStatusRefunded || ibcNftTx.AckTimeoutTxInfo.Hash != "ack_tx" {
+		t.Fatalf("unexpected refunded ibc nft tx %s", utils.MustMarshalJsonToStr(ibcNftTx))
+	}
+}
+
+func Test_NftTxRelateTask(t *testing.T) {
+	if res := new(IbcNftTxRelateTask).Run(context.Background()); res != 1 {
+		t.Fatalf("unexpected exit status %d", res)
+	}
+}
@@ -0,0 +1,415 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IbcSyncPacketFeeTask sync ics29 fee middleware txs from sync_{chain}_tx.
+// fees escrowed by MsgPayPacketFee/MsgPayPacketFeeAsync are saved to ibc_packet_fee, registered payees are saved to ibc_fee_payee,
+// fees paid out in acknowledge/timeout txs are saved to ibc_fee_distribution.
+type IbcSyncPacketFeeTask struct {
+}
+
+var _ Task = new(IbcSyncPacketFeeTask)
+var packetFeeCoordinator *stringQueueCoordinator
+
+func (t *IbcSyncPacketFeeTask) Name() string {
+	return "ibc_sync_packet_fee_task"
+}
+
+func (t *IbcSyncPacketFeeTask) Cron() int {
+	if taskConf.CronTimeSyncPacketFeeTask > 0 {
+		return taskConf.CronTimeSyncPacketFeeTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcSyncPacketFeeTask) workerNum() int {
+	if global.Config.Task.SyncTransferTxWorkerNum > 0 {
+		return global.Config.Task.SyncTransferTxWorkerNum
+	}
+	return syncTransferTxTaskWorkerNum
+}
+
+func (t *IbcSyncPacketFeeTask) Run(ctx context.Context) int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+
+	// init coordinator
+	chainQueue := new(utils.QueueString)
+	for _, v := range chainMap {
+		chainQueue.Push(v.ChainName)
+	}
+	packetFeeCoordinator = &stringQueueCoordinator{
+		stringQueue: chainQueue,
+	}
+
+	workerNum := t.workerNum()
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(workerNum)
+	for i := 1; i <= workerNum; i++ {
+		workName := fmt.Sprintf("worker-%d", i)
+		go func(wn string) {
+			newSyncPacketFeeWorker(t.Name(), wn, chainMap).exec(ctx)
+			waitGroup.Done()
+		}(workName)
+	}
+	waitGroup.Wait()
+
+	return 1
+}
+
+// =========================================================================
+// =========================================================================
+// worker
+
+func newSyncPacketFeeWorker(taskName, workerName string, chainMap map[string]*entity.ChainConfig) *syncPacketFeeWorker {
+	return &syncPacketFeeWorker{
+		taskName:   taskName,
+		workerName: workerName,
+		chainMap:   chainMap,
+	}
+}
+
+type syncPacketFeeWorker struct {
+	taskName   string
+	workerName string
+	chainMap   map[string]*entity.ChainConfig
+}
+
+func (w *syncPacketFeeWorker) exec(ctx context.Context) {
+	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
+	for {
+		chain, err := packetFeeCoordinator.getOne(ctx)
+		if err != nil {
+			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
+			break
+		}
+
+		if cf, ok := w.chainMap[chain]; ok && cf.Status == entity.ChainStatusClosed {
+			logrus.Infof("task %s worker %s chain %s is closed", w.taskName, w.workerName, chain)
+			continue
+		}
+
+		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
+		startTime := time.Now().Unix()
+		if err = w.parseChainPacketFee(ctx, chain); err != nil {
+			logrus.Errorf("task %s worker %s parse chain %s packet fee error,time use: %d(s), %v", w.taskName, w.workerName, chain, time.Now().Unix()-startTime, err)
+		} else {
+			logrus.Infof("task %s worker %s parse chain %s packet fee end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
+		}
+	}
+}
+
+func (w *syncPacketFeeWorker) parseChainPacketFee(ctx context.Context, chain string) error {
+	totalParseTx := 0
+	maxParseTx := global.Config.Task.SingleChainSyncTransferTxMax
+	if maxParseTx <= 0 {
+		maxParseTx = defaultMaxHandlerTx
+	}
+
+	taskRecord, err := w.checkTaskRecord(chain)
+	if err != nil {
+		return err
+	}
+	if taskRecord.Status == entity.TaskRecordStatusClose {
+		return nil
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		checkFollowingStatus, err := syncTaskRepo.CheckFollowingStatus(chain)
+		if err != nil {
+			logrus.Errorf("task %s worker %s checkFollowingStatus %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+		if !checkFollowingStatus {
+			logrus.Warningf("chain %s is not follow status", chain)
+			return nil
+		}
+
+		txList, err := w.getTxList(chain, taskRecord.Height, int64(constant.DefaultLimit))
+		if err != nil {
+			return err
+		}
+
+		if len(txList) == 0 {
+			return nil
+		}
+
+		if err = w.handleFeeTx(chain, txList); err != nil {
+			return err
+		}
+
+		taskRecord.Height = txList[len(txList)-1].Height
+		if err = taskRecordRepo.UpdateHeight(taskRecord.TaskName, taskRecord.Height); err != nil {
+			logrus.Errorf("task %s worker %s taskRecordRepo.UpdateHeight %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+
+		totalParseTx += len(txList)
+		if len(txList) < constant.DefaultLimit || totalParseTx >= maxParseTx {
+			break
+		}
+	}
+
+	return nil
+}
+
+// handleFeeTx escrowed fees are saved before distributions, so that refunds in the same batch can be recognized
+func (w *syncPacketFeeWorker) handleFeeTx(chain string, txList []*entity.Tx) error {
+	var packetFeeList []*entity.IbcPacketFee
+	var distributeTxList []*entity.Tx
+	for _, tx := range txList {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		var distribute bool
+		for msgIndex, msg := range tx.DocTxMsgs {
+			switch msg.Type {
+			case constant.MsgTypePayPacketFee, constant.MsgTypePayPacketFeeAsync:
+				if packetFee, ok := w.buildPacketFee(chain, msgIndex, tx, msg); ok {
+					packetFeeList = append(packetFeeList, packetFee)
+				}
+			case constant.MsgTypeRegisterPayee, constant.MsgTypeRegisterCpPayee:
+				if err := w.savePayee(chain, tx, msg); err != nil {
+					return err
+				}
+			case constant.MsgTypeAcknowledgement, constant.MsgTypeTimeoutPacket:
+				distribute = true
+			}
+		}
+		if distribute {
+			distributeTxList = append(distributeTxList, tx)
+		}
+	}
+
+	if len(packetFeeList) > 0 {
+		if err := packetFeeRepo.InsertBatch(packetFeeList); err != nil {
+			logrus.Errorf("task %s worker %s packetFeeRepo.InsertBatch %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+	}
+
+	var distributionList []*entity.IbcFeeDistribution
+	for _, tx := range distributeTxList {
+		distributions, err := w.buildDistributions(chain, tx)
+		if err != nil {
+			return err
+		}
+		distributionList = append(distributionList, distributions...)
+	}
+	if len(distributionList) > 0 {
+		if err := feeDistributionRepo.InsertBatch(distributionList); err != nil {
+			logrus.Errorf("task %s worker %s feeDistributionRepo.InsertBatch %s error, %v", w.taskName, w.workerName, chain, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *syncPacketFeeWorker) buildPacketFee(chain string, msgIndex int, tx *entity.Tx, msg *model.TxMsg) (*entity.IbcPacketFee, bool) {
+	port, channel, sequence, fee, exist := parseIncentivizedPacketEvents(msgIndex, tx)
+	if !exist || sequence == "" {
+		logrus.Warningf("task %s worker %s chain %s tx %s no incentivized packet event", w.taskName, w.workerName, chain, tx.TxHash)
+		return nil, false
+	}
+
+	var refundAddress string
+	if msg.Type == constant.MsgTypePayPacketFee {
+		refundAddress = msg.PayPacketFeeMsg().Signer
+	} else {
+		refundAddress = msg.PayPacketFeeAsyncMsg().PacketFee.RefundAddress
+	}
+
+	nowUnix := time.Now().Unix()
+	return &entity.IbcPacketFee{
+		Chain:         chain,
+		PortId:        port,
+		ChannelId:     channel,
+		Sequence:      sequence,
+		MsgType:       msg.Type,
+		RefundAddress: refundAddress,
+		RecvFee:       fee.RecvFee,
+		AckFee:        fee.AckFee,
+		TimeoutFee:    fee.TimeoutFee,
+		TxHash:        tx.TxHash,
+		MsgIndex:      msgIndex,
+		Height:        tx.Height,
+		TxTime:        tx.Time,
+		CreateAt:      nowUnix,
+		UpdateAt:      nowUnix,
+	}, true
+}
+
+func (w *syncPacketFeeWorker) savePayee(chain string, tx *entity.Tx, msg *model.TxMsg) error {
+	registerMsg := msg.RegisterPayeeMsg()
+	payee := &entity.IbcFeePayee{
+		Chain:     chain,
+		PortId:    registerMsg.PortId,
+		ChannelId: registerMsg.ChannelId,
+		Relayer:   registerMsg.Relayer,
+		TxHash:    tx.TxHash,
+		TxTime:    tx.Time,
+	}
+	if msg.Type == constant.MsgTypeRegisterPayee {
+		payee.PayeeType = entity.FeePayeeTypePayee
+		payee.Payee = registerMsg.Payee
+		payee.PayeeChain = chain
+	} else {
+		// counterparty payee is paid the recv fee on the source chain of packets received on this channel
+		payee.PayeeType = entity.FeePayeeTypeCounterpartyPayee
+		payee.Payee = registerMsg.CounterpartyPayee
+		payee.PayeeChain, _, _ = ibctool.MatchDcInfo(chain, registerMsg.PortId, registerMsg.ChannelId, w.chainMap)
+	}
+
+	if err := feePayeeRepo.Save(payee); err != nil {
+		logrus.Errorf("task %s worker %s chain %s save fee payee(%s) error, %v", w.taskName, w.workerName, chain, tx.TxHash, err)
+		return err
+	}
+	return nil
+}
+
+func (w *syncPacketFeeWorker) buildDistributions(chain string, tx *entity.Tx) ([]*entity.IbcFeeDistribution, error) {
+	var res []*entity.IbcFeeDistribution
+	for msgIndex, msg := range tx.DocTxMsgs {
+		if msg.Type != constant.MsgTypeAcknowledgement && msg.Type != constant.MsgTypeTimeoutPacket {
+			continue
+		}
+
+		distributeEvents := parseDistributeFeeEvents(msgIndex, tx)
+		if len(distributeEvents) == 0 {
+			continue
+		}
+
+		packet := msg.PacketDataMsg().Packet
+		sequence := strconv.FormatInt(packet.Sequence, 10)
+		packetFees, err := packetFeeRepo.FindByPacket(chain, packet.SourcePort, packet.SourceChannel, sequence)
+		if err != nil {
+			logrus.Errorf("task %s worker %s chain %s packetFeeRepo.FindByPacket error, %v", w.taskName, w.workerName, chain, err)
+			return nil, err
+		}
+		refundAddrMap := make(map[string]struct{}, len(packetFees))
+		for _, v := range packetFees {
+			refundAddrMap[v.RefundAddress] = struct{}{}
+		}
+		res = append(res, newFeeDistributions(chain, msgIndex, tx, msg, distributeEvents, refundAddrMap)...)
+	}
+	return res, nil
+}
+
+// newFeeDistributions a distribution for each coin paid out by the ack or timeout msg, the fees paid to the refund
+// addresses of the packet are refunds
+func newFeeDistributions(chain string, msgIndex int, tx *entity.Tx, msg *model.TxMsg, distributeEvents []distributeFeeEvent, refundAddrMap map[string]struct{}) []*entity.IbcFeeDistribution {
+	var res []*entity.IbcFeeDistribution
+	packet := msg.PacketDataMsg().Packet
+	sequence := strconv.FormatInt(packet.Sequence, 10)
+	nowUnix := time.Now().Unix()
+	relayer := msg.CommonMsg().Signer
+	for distributeIndex, evt := range distributeEvents {
+		_, isRefund := refundAddrMap[evt.receiver]
+		for _, coin := range evt.fee {
+			amount, _ := strconv.ParseFloat(coin.Amount, 64)
+			res = append(res, &entity.IbcFeeDistribution{
+				Chain:            chain,
+				PortId:           packet.SourcePort,
+				ChannelId:        packet.SourceChannel,
+				Sequence:         sequence,
+				TxType:           entity.TxType(msg.Type),
+				TxHash:           tx.TxHash,
+				MsgIndex:         msgIndex,
+				DistributeIndex:  distributeIndex,
+				Relayer:          relayer,
+				Receiver:         evt.receiver,
+				ChainAddressComb: entity.GenerateChainAddressComb(chain, evt.receiver),
+				FeeDenom:         coin.Denom,
+				FeeAmount:        amount,
+				IsRefund:         isRefund,
+				TxTime:           tx.Time,
+				CreateAt:         nowUnix,
+			})
+		}
+	}
+	return res
+}
+
+// checkTaskRecord 检查packet fee task_record的状态，如果不存在task_record 记录，则新增
+func (w *syncPacketFeeWorker) checkTaskRecord(chain string) (*entity.IbcTaskRecord, error) {
+	taskName := fmt.Sprintf(entity.PacketFeeTaskNameFmt, chain)
+	taskRecord, err := taskRecordRepo.FindByTaskName(taskName)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			logrus.Errorf("task %s worker %s checkTaskRecord %s error, %v", w.taskName, w.workerName, chain, err)
+			return nil, err
+		}
+
+		taskRecord = &entity.IbcTaskRecord{
+			TaskName: taskName,
+			Height:   0,
+			Status:   entity.TaskRecordStatusOpen,
+			CreateAt: time.Now().Unix(),
+			UpdateAt: time.Now().Unix(),
+		}
+
+		if err := taskRecordRepo.Insert(taskRecord); err != nil {
+			logrus.Errorf("task %s worker %s checkTaskRecord %s error, %v", w.taskName, w.workerName, chain, err)
+			return nil, err
+		}
+	}
+
+	return taskRecord, nil
+}
+
+func (w *syncPacketFeeWorker) getTxList(chain string, height, limit int64) ([]*entity.Tx, error) {
+	txList, err := txRepo.GetPacketFeeTx(chain, height, limit)
+	if err != nil {
+		logrus.Errorf("task %s worker %s GetPacketFeeTx %s error, %v", w.taskName, w.workerName, chain, err)
+		return nil, err
+	}
+
+	if len(txList) < int(limit) {
+		return txList, nil
+	}
+
+	// make sure all txs of the max height are handled in this batch
+	maxHeight := txList[len(txList)-1].Height
+	txHashMap := make(map[string]string)
+	for _, v := range txList {
+		if v.Height == maxHeight {
+			txHashMap[v.TxHash] = ""
+		}
+	}
+
+	heightTxList, err := txRepo.FindByTypesAndHeight(chain, constant.PacketFeeMsgTypes, maxHeight)
+	if err != nil {
+		logrus.Errorf("task %s worker %s FindByTypesAndHeight %s error, %v", w.taskName, w.workerName, chain, err)
+		return nil, err
+	}
+
+	for _, v := range heightTxList {
+		if _, ok := txHashMap[v.TxHash]; !ok {
+			txList = append(txList, v)
+		}
+	}
+
+	return txList, nil
+}
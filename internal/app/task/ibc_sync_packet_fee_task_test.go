@@ -0,0 +1,128 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_SyncPacketFee(t *testing.T) {
+	if res := new(IbcSyncPacketFeeTask).Run(context.Background()); res != 1 {
+		t.Fatalf("unexpected exit status %d", res)
+	}
+}
+
+func Test_ParsePacketFeeEvents(t *testing.T) {
+	tx := &entity.Tx{
+		TxHash: "pay_packet_fee_tx",
+		Status: entity.TxStatusSuccess,
+		EventsNew: []entity.EventNew{{
+			MsgIndex: 0,
+			Events: []entity.Event{{
+				Type: "incentivized_ibc_packet",
+				Attributes: []entity.KvPair{
+					{Key: "port_id", Value: "transfer"},
+					{Key: "channel_id", Value: "channel-0"},
+					{Key: "packet_sequence", Value: "12"},
+					{Key: "recv_fee", Value: "100uatom"},
+					{Key: "ack_fee", Value: "50uatom,10ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"},
+					{Key: "timeout_fee", Value: ""},
+				},
+			}},
+		}, {
+			MsgIndex: 1,
+			Events: []entity.Event{{
+				Type: "distribute_fee",
+				Attributes: []entity.KvPair{
+					{Key: "receiver", Value: "cosmos1relayer"},
+					{Key: "fee", Value: "100uatom"},
+				},
+			}, {
+				Type: "distribute_fee",
+				Attributes: []entity.KvPair{
+					{Key: "receiver", Value: "cosmos1refund"},
+					{Key: "fee", Value: ""},
+				},
+			}},
+		}},
+	}
+
+	port, channel, sequence, fee, exist := parseIncentivizedPacketEvents(0, tx)
+	if !exist || port != "transfer" || channel != "channel-0" || sequence != "12" {
+		t.Fatalf("incentivized packet error, %s %s %s", port, channel, sequence)
+	}
+	if len(fee.RecvFee) != 1 || len(fee.AckFee) != 2 || len(fee.TimeoutFee) != 0 {
+		t.Fatalf("packet fee error, %s", utils.MustMarshalJsonToStr(fee))
+	}
+
+	distributions := parseDistributeFeeEvents(1, tx)
+	if len(distributions) != 1 || distributions[0].receiver != "cosmos1relayer" || distributions[0].fee[0].Amount != "100" {
+		t.Fatalf("distribute fee error, %+v", distributions)
+	}
+}
+
+func Test_BuildPacketFee(t *testing.T) {
+	w := newSyncPacketFeeWorker("packet_fee", "worker", nil)
+	tx := &entity.Tx{
+		TxHash: "pay_packet_fee_async_tx",
+		Status: entity.TxStatusSuccess,
+		Height: 100,
+		DocTxMsgs: []*model.TxMsg{{Type: constant.MsgTypePayPacketFeeAsync, Msg: bson.M{
+			"packet_fee": bson.M{"refund_address": "cosmos1refund"},
+		}}},
+		EventsNew: []entity.EventNew{{Events: []entity.Event{{
+			Type: "incentivized_ibc_packet",
+			Attributes: []entity.KvPair{
+				{Key: "port_id", Value: "transfer"},
+				{Key: "channel_id", Value: "channel-0"},
+				{Key: "packet_sequence", Value: "12"},
+				{Key: "recv_fee", Value: "100uatom"},
+				{Key: "ack_fee", Value: "50uatom"},
+				{Key: "timeout_fee", Value: "20uatom"},
+			},
+		}}}},
+	}
+
+	packetFee, ok := w.buildPacketFee("cosmoshub", 0, tx, tx.DocTxMsgs[0])
+	if !ok || packetFee.PortId != "transfer" || packetFee.ChannelId != "channel-0" || packetFee.Sequence != "12" ||
+		packetFee.RefundAddress != "cosmos1refund" || packetFee.Height != 100 || len(packetFee.RecvFee) != 1 ||
+		len(packetFee.AckFee) != 1 || len(packetFee.TimeoutFee) != 1 {
+		t.Fatalf("unexpected packet fee %s", utils.MustMarshalJsonToStr(packetFee))
+	}
+
+	tx.EventsNew = nil
+	if _, ok = w.buildPacketFee("cosmoshub", 0, tx, tx.DocTxMsgs[0]); ok {
+		t.Fatal("packet fee is built without incentivized packet event")
+	}
+}
+
+func Test_NewFeeDistributions(t *testing.T) {
+	msg := &model.TxMsg{Type: constant.MsgTypeAcknowledgement, Msg: bson.M{
+		"signer": "cosmos1relayer",
+		"packet": bson.M{"source_port": "transfer", "source_channel": "channel-0", "sequence": 12},
+	}}
+	tx := &entity.Tx{TxHash: "ack_tx", Time: 1000, DocTxMsgs: []*model.TxMsg{msg}}
+	events := []distributeFeeEvent{
+		{receiver: "cosmos1payee", fee: []*model.Coin{{Denom: "uatom", Amount: "100"}, {Denom: "uosmo", Amount: "5"}}},
+		{receiver: "cosmos1refund", fee: []*model.Coin{{Denom: "uatom", Amount: "20"}}},
+	}
+
+	distributions := newFeeDistributions("cosmoshub", 0, tx, msg, events, map[string]struct{}{"cosmos1refund": {}})
+	if len(distributions) != 3 {
+		t.Fatalf("unexpected fee distributions %s", utils.MustMarshalJsonToStr(distributions))
+	}
+	paid := distributions[1]
+	if paid.Relayer != "cosmos1relayer" || paid.Receiver != "cosmos1payee" || paid.IsRefund || paid.FeeDenom != "uosmo" ||
+		paid.FeeAmount != 5 || paid.Sequence != "12" || paid.ChannelId != "channel-0" || paid.DistributeIndex != 0 ||
+		paid.TxType != entity.TxType(constant.MsgTypeAcknowledgement) {
+		t.Fatalf("unexpected paid fee %s", utils.MustMarshalJsonToStr(paid))
+	}
+	if refund := distributions[2]; !refund.IsRefund || refund.Receiver != "cosmos1refund" || refund.FeeAmount != 20 || refund.DistributeIndex != 1 {
+		t.Fatalf("unexpected refund fee %s", utils.MustMarshalJsonToStr(refund))
+	}
+}
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -18,7 +19,7 @@ import (
 type IbcSyncTransferTxTask struct {
 }
 
-var _ Task = new(IbcSyncTransferTxTask)
+var _ chainLeasedTask = new(IbcSyncTransferTxTask)
 var transferTxCoordinator *stringQueueCoordinator
 
 func (t *IbcSyncTransferTxTask) Name() string {
@@ -32,6 +33,8 @@ func (t *IbcSyncTransferTxTask) Cron() int {
 	return ThreeMinute
 }
 
+func (t *IbcSyncTransferTxTask) chainLeased() {}
+
 func (t *IbcSyncTransferTxTask) workerNum() int {
 	if global.Config.Task.SyncTransferTxWorkerNum > 0 {
 		return global.Config.Task.SyncTransferTxWorkerNum
@@ -39,7 +42,7 @@ func (t *IbcSyncTransferTxTask) workerNum() int {
 	return syncTransferTxTaskWorkerNum
 }
 
-func (t *IbcSyncTransferTxTask) Run() int {
+func (t *IbcSyncTransferTxTask) Run(ctx context.Context) int {
 	chainMap, err := getAllChainMap()
 	if err != nil {
 		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
@@ -61,7 +64,7 @@ func (t *IbcSyncTransferTxTask) Run() int {
 	for i := 1; i <= workerNum; i++ {
 		workName := fmt.Sprintf("worker-%d", i)
 		go func(wn string) {
-			newSyncTransferTxWorker(t.Name(), wn, chainMap).exec()
+			newSyncTransferTxWorker(t.Name(), wn, chainMap).exec(ctx)
 			waitGroup.Done()
 		}(workName)
 	}
@@ -86,12 +89,13 @@ type syncTransferTxWorker struct {
 	taskName   string
 	workerName string
 	chainMap   map[string]*entity.ChainConfig
+	lease      *chainLease
 }
 
-func (w *syncTransferTxWorker) exec() {
+func (w *syncTransferTxWorker) exec(ctx context.Context) {
 	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
 	for {
-		chain, err := transferTxCoordinator.getOne()
+		chain, err := transferTxCoordinator.getOne(ctx)
 		if err != nil {
 			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
 			break
@@ -102,17 +106,25 @@ func (w *syncTransferTxWorker) exec() {
 			continue
 		}
 
+		if w.lease, err = acquireChainLease(w.taskName, chain); err != nil {
+			logrus.Infof("task %s worker %s chain %s is leased by another instance", w.taskName, w.workerName, chain)
+			continue
+		}
+
 		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
 		startTime := time.Now().Unix()
-		if err = w.parseChainIbcTx(chain); err != nil {
+		if err = w.parseChainIbcTx(ctx, chain); err != nil {
 			logrus.Errorf("task %s worker %s parse chain %s tx error,time use: %d(s), %v", w.taskName, w.workerName, chain, time.Now().Unix()-startTime, err)
+			addTaskRunError(w.taskName, chain, err)
 		} else {
 			logrus.Infof("task %s worker %s parse chain %s tx end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
 		}
+		w.lease.release()
+		w.lease = nil
 	}
 }
 
-func (w *syncTransferTxWorker) parseChainIbcTx(chain string) error {
+func (w *syncTransferTxWorker) parseChainIbcTx(ctx context.Context, chain string) error {
 	totalParseTx := 0
 	//const limit = 500
 	maxParseTx := global.Config.Task.SingleChainSyncTransferTxMax
@@ -134,6 +146,13 @@ func (w *syncTransferTxWorker) parseChainIbcTx(chain string) error {
 	}
 
 	for {
+		if w.lease.lost() {
+			return fmt.Errorf("chain lease is lost")
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
 		checkFollowingStatus, err := w.checkFollowingStatus(chain)
 		if err != nil {
 			return err
@@ -171,6 +190,8 @@ func (w *syncTransferTxWorker) parseChainIbcTx(chain string) error {
 			logrus.Errorf("task %s worker %s taskRecordRepo.UpdateHeight %s error, %v", w.taskName, w.workerName, chain, err)
 			return err
 		}
+		addTaskRunCounter(w.taskName, chain, "txs_parsed", int64(len(txList)))
+		addTaskRunCounter(w.taskName, chain, "ibc_txs_inserted", int64(len(ibcTxList)))
 
 		totalParseTx += len(txList)
 		if len(txList) < constant.DefaultLimit || totalParseTx >= maxParseTx {
@@ -199,33 +220,52 @@ func (w *syncTransferTxWorker) handleSourceTx(chain string, txList []*entity.Tx,
 			}
 
 			transferTxMsg := msg.TransferMsg()
+			tokens := transferTxMsg.AllTokens()
+			if len(tokens) == 0 {
+				continue
+			}
 			scPort := transferTxMsg.SourcePort
 			scChannel := transferTxMsg.SourceChannel
-			scDenom := transferTxMsg.Token.Denom
 			dcChain, dcPort, dcChannel := ibctool.MatchDcInfo(chain, scPort, scChannel, w.chainMap)
 
-			var fullDenomPath, sequence, scConnection string
-			ibcDenom, isExisted := denomMap[scDenom]
+			var denomFullPaths []string
+			var sequence, scConnection string
 			if ibcTxStatus != entity.IbcTxStatusFailed {
-				dcPort, dcChannel, fullDenomPath, sequence, scConnection = parseTransferTxEvents(msgIndex, tx)
-				if !isExisted { // denom 不存在
-					ibcDenom = ibctool.TraceDenom(fullDenomPath, chain, w.chainMap)
-				}
+				dcPort, dcChannel, denomFullPaths, sequence, scConnection = parseTransferTxEvents(msgIndex, tx)
 			}
 
 			if dcChain == "" && ibcTxStatus != entity.IbcTxStatusFailed {
 				ibcTxStatus = entity.IbcTxStatusSetting
 			}
 
-			if ibcTxStatus == entity.IbcTxStatusProcessing && !isExisted && ibcDenom != nil {
-				ibcDenomList = append(ibcDenomList, ibcDenom)
-				denomMap[ibcDenom.Denom] = ibcDenom
-			}
+			transferTokens := make([]*entity.TransferToken, 0, len(tokens))
+			for i, token := range tokens {
+				ibcDenom, isExisted := denomMap[token.Denom]
+				if ibcTxStatus != entity.IbcTxStatusFailed && !isExisted && len(denomFullPaths) > i { // denom 不存在
+					ibcDenom = ibctool.TraceDenom(denomFullPaths[i], chain, w.chainMap)
+				}
 
-			var baseDemom, baseDenomChain string
-			if ibcDenom != nil {
-				baseDemom = ibcDenom.BaseDenom
-				baseDenomChain = ibcDenom.BaseDenomChain
+				if ibcTxStatus == entity.IbcTxStatusProcessing && !isExisted && ibcDenom != nil {
+					ibcDenomList = append(ibcDenomList, ibcDenom)
+					denomMap[ibcDenom.Denom] = ibcDenom
+				}
+
+				transferToken := &entity.TransferToken{
+					ScDenom: token.Denom,
+					Amount:  token.Amount,
+				}
+				if ibcDenom != nil {
+					transferToken.BaseDenom = ibcDenom.BaseDenom
+					transferToken.BaseDenomChain = ibcDenom.BaseDenomChain
+				}
+				transferTokens = append(transferTokens, transferToken)
+			}
+			// the first token is kept in Denoms, BaseDenom and BaseDenomChain, Tokens is only saved for multi-denom packet
+			scDenom := transferTokens[0].ScDenom
+			baseDemom := transferTokens[0].BaseDenom
+			baseDenomChain := transferTokens[0].BaseDenomChain
+			if len(transferTokens) == 1 {
+				transferTokens = nil
 			}
 			recordIdStr := fmt.Sprintf("%s%s%s%s%s%s%s%d", scPort, scChannel, dcPort, dcChannel, sequence, chain, tx.TxHash, msgIndex)
 			recordId := utils.Md5(recordIdStr)
@@ -255,7 +295,7 @@ func (w *syncTransferTxWorker) handleSourceTx(chain string, txList []*entity.Tx,
 					Time:      tx.Time,
 					Height:    tx.Height,
 					Fee:       tx.Fee,
-					MsgAmount: transferTxMsg.Token,
+					MsgAmount: tokens[0],
 					Msg:       msg,
 					Memo:      tx.Memo,
 					Signers:   tx.Signers,
@@ -269,6 +309,7 @@ func (w *syncTransferTxWorker) handleSourceTx(chain string, txList []*entity.Tx,
 				},
 				BaseDenom:      baseDemom,
 				BaseDenomChain: baseDenomChain,
+				Tokens:         transferTokens,
 				RetryTimes:     0,
 				NextTryTime:    nowUnix,
 				CreateAt:       nowUnix,
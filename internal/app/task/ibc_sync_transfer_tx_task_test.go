@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"testing"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
@@ -16,7 +17,7 @@ func Test_TraceDenom(t *testing.T) {
 }
 
 func Test_SyncTransferTx(t *testing.T) {
-	new(IbcSyncTransferTxTask).Run()
+	new(IbcSyncTransferTxTask).Run(context.Background())
 }
 
 func Test_HandleSourceTx(t *testing.T) {
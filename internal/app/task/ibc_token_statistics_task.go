@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
@@ -30,20 +31,11 @@ func TokenIncrementStatistics(segments []*segment) error {
 	return tokenStatisticsTask.deal(segments, false)
 }
 
-func (t *TokenStatisticsTask) Run() int {
+// Run 全量统计，每个分段完成后保存checkpoint，中断后从最后完成的分段继续
+func (t *TokenStatisticsTask) Run(ctx context.Context) int {
 	t.segmentMinTime = math.MaxInt64
 	t.segmentRecvTxsMap = make(map[string][]*dto.CountIBCTokenRecvTxsDTO)
 
-	if err := tokenTraceStatisticsRepo.CreateNew(); err != nil {
-		logrus.Errorf("task %s tokenTraceStatisticsRepo.CreateNew err, %v", t.Name(), err)
-		return -1
-	}
-
-	//if err := tokenStatisticsRepo.CreateNew(); err != nil {
-	//	logrus.Errorf("task %s tokenStatisticsRepo.CreateNew err, %v", t.Name(), err)
-	//	return -1
-	//}
-
 	segments, err := getTxTimeSegment(false, segmentStepLatest)
 	if err != nil {
 		logrus.Errorf("task %s getSegment err, %v", t.Name(), err)
@@ -56,16 +48,51 @@ func (t *TokenStatisticsTask) Run() int {
 		return -1
 	}
 
+	checkpoint, err := loadStatisticsCheckpoint(t.Name())
+	if err != nil {
+		logrus.Errorf("task %s loadStatisticsCheckpoint err, %v", t.Name(), err)
+		return -1
+	}
+	scopeSegments := map[string][]*segment{checkpointScopeHistory: historySegments, checkpointScopeLatest: segments}
+	resumed, err := checkpoint.resume(scopeSegments, tokenTraceStatisticsRepo)
+	if err != nil {
+		logrus.Errorf("task %s resume checkpoint err, %v", t.Name(), err)
+		return -1
+	}
+	if !resumed {
+		if err = tokenTraceStatisticsRepo.DropNew(); err != nil {
+			logrus.Errorf("task %s tokenTraceStatisticsRepo.DropNew err, %v", t.Name(), err)
+			return -1
+		}
+	}
+
+	if err := tokenTraceStatisticsRepo.CreateNew(); err != nil {
+		logrus.Errorf("task %s tokenTraceStatisticsRepo.CreateNew err, %v", t.Name(), err)
+		return -1
+	}
+
+	//if err := tokenStatisticsRepo.CreateNew(); err != nil {
+	//	logrus.Errorf("task %s tokenStatisticsRepo.CreateNew err, %v", t.Name(), err)
+	//	return -1
+	//}
+
 	t.segmentMinTime = segments[0].StartTime
+	pendingHistorySegments := checkpoint.pending(checkpointScopeHistory, historySegments)
+	pendingSegments := checkpoint.pending(checkpointScopeLatest, segments)
+
 	// 优先处理历史分段
-	logrus.Infof("task %s deal history segment total: %d", t.Name(), len(historySegments))
-	if err = t.dealHistory(historySegments); err != nil {
+	logrus.Infof("task %s deal history segment total: %d, pending: %d", t.Name(), len(historySegments), len(pendingHistorySegments))
+	if err = t.dealFull(ctx, pendingHistorySegments, true, checkpoint); err != nil {
 		logrus.Errorf("task %s dealHistory err, %v", t.Name(), err)
 		return -1
 	}
+	if err = t.loadOverlapSegments(historySegments); err != nil {
+		logrus.Errorf("task %s loadOverlapSegments err, %v", t.Name(), err)
+		return -1
+	}
 
-	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
-	if err = t.deal(segments, true); err != nil {
+	logrus.Infof("task %s deal segment total: %d, pending: %d", t.Name(), len(segments), len(pendingSegments))
+	if err = t.dealFull(ctx, pendingSegments, false, checkpoint); err != nil {
 		logrus.Errorf("task %s deal err, %v", t.Name(), err)
 		return -1
 	}
@@ -74,6 +101,9 @@ func (t *TokenStatisticsTask) Run() int {
 		logrus.Errorf("task %s tokenTraceStatisticsRepo.SwitchColl err, %v", t.Name(), err)
 		return -1
 	}
+	if err = checkpoint.clear(); err != nil {
+		logrus.Errorf("task %s clear checkpoint err, %v", t.Name(), err)
+	}
 
 	//if err = tokenStatisticsRepo.SwitchColl(); err != nil {
 	//	logrus.Errorf("task %s tokenStatisticsRepo.SwitchColl err, %v", t.Name(), err)
@@ -83,6 +113,54 @@ func (t *TokenStatisticsTask) Run() int {
 	return 1
 }
 
+// dealFull 全量统计，按分段保存checkpoint
+func (t *TokenStatisticsTask) dealFull(ctx context.Context, segments []*segment, targetHistory bool, checkpoint *statisticsCheckpoint) error {
+	scope := checkpointScopeLatest
+	if targetHistory {
+		scope = checkpointScopeHistory
+	}
+
+	for _, v := range segments {
+		if ctx.Err() != nil {
+			return errStatisticsInterrupted
+		}
+
+		var err error
+		if targetHistory {
+			err = t.dealHistory([]*segment{v})
+		} else {
+			err = t.deal([]*segment{v}, true)
+		}
+		if err != nil {
+			return err
+		}
+		if err = checkpoint.complete(scope, v); err != nil {
+			return err
+		}
+	}
+	return checkpoint.finish(scope)
+}
+
+// loadOverlapSegments 中断前已完成的历史分段不在segmentRecvTxsMap中，重新统计与新表重叠的分段
+func (t *TokenStatisticsTask) loadOverlapSegments(historySegments []*segment) error {
+	for _, v := range historySegments {
+		key := fmt.Sprintf("%d-%d", v.StartTime, v.EndTime)
+		if _, ok := t.segmentRecvTxsMap[key]; ok || v.StartTime < t.segmentMinTime {
+			continue
+		}
+
+		traceReceiveTxs, err := ibcTxRepo.CountIBCTokenHistoryRecvTxs(v.StartTime, v.EndTime)
+		if err != nil {
+			logrus.Errorf("task %s CountIBCTokenHistoryRecvTxs err, %v", t.Name(), err)
+			return err
+		}
+		if len(traceReceiveTxs) > 0 {
+			t.segmentRecvTxsMap[key] = traceReceiveTxs
+		}
+	}
+	return nil
+}
+
 // dealHistory 处理历史记录，针对ex_ibc_tx
 func (t *TokenStatisticsTask) dealHistory(segments []*segment) error {
 	for _, v := range segments {
@@ -232,11 +310,8 @@ func (t *TokenStatisticsTask) saveTraceReceiveData(dtoList []*dto.CountIBCTokenR
 
 	var err error
 	if fullStatistics {
-		if targetHistory {
-			err = tokenTraceStatisticsRepo.BatchInsertToNew(statistics)
-		} else {
-			err = tokenTraceStatisticsRepo.BatchSwapNew(seg.StartTime, seg.EndTime, statistics)
-		}
+		// 按分段替换，中断后重新处理的分段不会重复写入
+		err = tokenTraceStatisticsRepo.BatchSwapNew(seg.StartTime, seg.EndTime, statistics)
 	} else {
 		err = tokenTraceStatisticsRepo.BatchSwap(seg.StartTime, seg.EndTime, statistics)
 	}
@@ -1,18 +1,17 @@
 package task
 
 import (
+	"context"
 	"crypto/sha256"
-	"encoding/json"
 	"fmt"
 	"math"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils/bech32"
 	v8 "github.com/go-redis/redis/v8"
@@ -22,12 +21,11 @@ import (
 )
 
 type TokenTask struct {
-	chains           []string                  // 系统支持的chain列表
-	chainLcdMap      map[string]string         // chain lcd地址
-	chainLcdApiMap   map[string]entity.ApiPath // chain lcd api地址
-	escrowAddressMap map[string][]string       // chain ibc跨链托管地址
-	baseDenomList    entity.AuthDenomList      // 所有的base denom
-	ibcReceiveTxsMap map[string]int64          // ibc hash token denom的recv txs
+	chains           []string                       // 系统支持的chain列表
+	chainConfigMap   map[string]*entity.ChainConfig // chain配置
+	escrowAddressMap map[string][]string            // chain ibc跨链托管地址
+	baseDenomList    entity.AuthDenomList           // 所有的base denom
+	ibcReceiveTxsMap map[string]int64               // ibc hash token denom的recv txs
 }
 
 func (t *TokenTask) Name() string {
@@ -38,7 +36,7 @@ func (t *TokenTask) Cron() int {
 	return ThreeMinute
 }
 
-func (t *TokenTask) Run() int {
+func (t *TokenTask) Run(ctx context.Context) int {
 	err := t.analyzeChainConf()
 	if err != nil {
 		logrus.Errorf("task %s run error, %v", t.Name(), err)
@@ -67,7 +65,7 @@ func (t *TokenTask) Run() int {
 
 	_ = t.setIbcTransferTxs(existedTokenList, newTokenList)
 
-	_ = t.setIbcTransferAmount(existedTokenList, newTokenList)
+	_ = t.setIbcTransferAmount(ctx, existedTokenList, newTokenList)
 
 	if err = t.ibcReceiveTxs(); err != nil {
 		return -1
@@ -209,13 +207,11 @@ func (t *TokenTask) analyzeChainConf() error {
 	}
 
 	chains := make([]string, 0, len(configList))
-	chainLcdMap := make(map[string]string)
-	chainLcdApiMap := make(map[string]entity.ApiPath)
+	chainConfigMap := make(map[string]*entity.ChainConfig)
 	escrowAddressMap := make(map[string][]string)
 	for _, v := range configList {
 		chains = append(chains, v.ChainName)
-		chainLcdMap[v.ChainName] = v.GrpcRestGateway
-		chainLcdApiMap[v.ChainName] = v.LcdApiPath
+		chainConfigMap[v.ChainName] = v
 		address, err := t.analyzeChainEscrowAddress(v.IbcInfo, v.AddrPrefix)
 		if err != nil {
 			continue
@@ -223,8 +219,7 @@ func (t *TokenTask) analyzeChainConf() error {
 		escrowAddressMap[v.ChainName] = address
 	}
 	t.chains = chains
-	t.chainLcdMap = chainLcdMap
-	t.chainLcdApiMap = chainLcdApiMap
+	t.chainConfigMap = chainConfigMap
 	t.escrowAddressMap = escrowAddressMap
 	return nil
 }
@@ -311,13 +306,13 @@ func (t *TokenTask) setIbcTransferTxs(existedTokenList, newTokenList entity.IBCT
 	return nil
 }
 
-func (t *TokenTask) setIbcTransferAmount(existedTokenList, newTokenList entity.IBCTokenList) error {
+func (t *TokenTask) setIbcTransferAmount(ctx context.Context, existedTokenList, newTokenList entity.IBCTokenList) error {
 	var waitGroup sync.WaitGroup
 	waitGroup.Add(len(t.chains))
 	for _, v := range t.chains {
 		go func(c string, addrs []string) {
 			defer waitGroup.Done()
-			t.getTransAmountFromLcd(c, addrs)
+			t.getTransAmountFromLcd(ctx, c, addrs)
 		}(v, t.escrowAddressMap[v])
 	}
 	waitGroup.Wait()
@@ -342,25 +337,19 @@ func (t *TokenTask) setIbcTransferAmount(existedTokenList, newTokenList entity.I
 	return nil
 }
 
-func (t *TokenTask) getTransAmountFromLcd(chain string, addrList []string) {
+func (t *TokenTask) getTransAmountFromLcd(ctx context.Context, chain string, addrList []string) {
 	denomTransAmountMap := make(map[string]decimal.Decimal)
-	lcd := t.chainLcdMap[chain]
-	apiPath := t.chainLcdApiMap[chain].BalancesPath
+	chainCfg, ok := t.chainConfigMap[chain]
+	if !ok {
+		return
+	}
 	for _, addr := range addrList { // 一条链上的所有地址都要查询一遍，并按denom分组计数
 		limit := 500
 		key := ""
 		earlyTermination := false
-		baseUrl := strings.ReplaceAll(fmt.Sprintf("%s%s", lcd, apiPath), entity.ApiBalancesPathPlaceholder, addr)
 
 		for { // 计算地址上所锁定的denom的数量
-			var url string
-			if key == "" {
-				url = fmt.Sprintf("%s?pagination.limit=%d", baseUrl, limit)
-			} else {
-				url = fmt.Sprintf("%s?pagination.limit=%d&pagination.key=%s", baseUrl, limit, key)
-			}
-
-			bz, err := utils.HttpGet(url)
+			balancesResp, err := lcd.QueryBalances(ctx, chainCfg, addr, limit, key)
 			if err != nil {
 				if isConnectionErr(err) {
 					earlyTermination = true
@@ -369,13 +358,6 @@ func (t *TokenTask) getTransAmountFromLcd(chain string, addrList []string) {
 				break
 			}
 
-			var balancesResp vo.BalancesResp
-			err = json.Unmarshal(bz, &balancesResp)
-			if err != nil {
-				logrus.Errorf("task %s chain: %s getTransAmountFromLcd error, %v", t.Name(), chain, err)
-				break
-			}
-
 			for _, v := range balancesResp.Balances {
 				amount, err := decimal.NewFromString(v.Amount)
 				if err != nil {
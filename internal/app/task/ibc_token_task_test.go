@@ -1,11 +1,14 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 var tokenTask TokenTask
 
 func TestTokenTaskRun(t *testing.T) {
-	tokenTask.Run()
+	tokenTask.Run(context.Background())
 }
 
 func TestUpdateIBCChain(t *testing.T) {
@@ -13,5 +16,5 @@ func TestUpdateIBCChain(t *testing.T) {
 }
 
 func TestTokenStatisticsTaskRun(t *testing.T) {
-	tokenStatisticsTask.Run()
+	tokenStatisticsTask.Run(context.Background())
 }
@@ -0,0 +1,201 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// IbcTxBackfillTask rebuild ibc txs of a chain in [fromHeight, toHeight] after the sync data of the chain is repaired.
+// ibc txs are upserted by record_id, so it is safe to run again. It never touches the task record of
+// IbcSyncTransferTxTask, the live cursor keeps going on.
+type IbcTxBackfillTask struct {
+}
+
+var _ OneOffTask = new(IbcTxBackfillTask)
+
+type IbcTxBackfillResult struct {
+	Inserted  int64 `json:"inserted"`
+	Updated   int64 `json:"updated"`
+	Unchanged int64 `json:"unchanged"`
+	Related   int64 `json:"related"`
+}
+
+func (t *IbcTxBackfillTask) Name() string {
+	return "ibc_tx_backfill_task"
+}
+
+func (t *IbcTxBackfillTask) Switch() bool {
+	return false
+}
+
+func (t *IbcTxBackfillTask) Run(ctx context.Context) int {
+	logrus.Errorf("task %s need chain and height range, use RunWithParam", t.Name())
+	return -1
+}
+
+func (t *IbcTxBackfillTask) RunWithParam(ctx context.Context, chain string, fromHeight, toHeight int64) (*IbcTxBackfillResult, error) {
+	if chain == "" || fromHeight <= 0 || toHeight < fromHeight {
+		return nil, fmt.Errorf("invalid param, chain: %s, from height: %d, to height: %d", chain, fromHeight, toHeight)
+	}
+
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return nil, err
+	}
+	if _, ok := chainMap[chain]; !ok {
+		return nil, fmt.Errorf("chain %s is not found", chain)
+	}
+
+	sw := newSyncTransferTxWorker(t.Name(), chain, chainMap)
+	denomMap, err := sw.getChainDenomMap(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(IbcTxBackfillResult)
+	// the counts are added to the running record after each batch, the ones of an interrupted batch when it returns
+	var recorded IbcTxBackfillResult
+	record := func() {
+		t.addRunCounters(chain, recorded, *res)
+		recorded = *res
+	}
+	defer record()
+
+	height := fromHeight - 1
+	for {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		txList, err := t.getTxList(sw, chain, height, toHeight, int64(constant.DefaultLimit))
+		if err != nil {
+			return res, err
+		}
+		if len(txList) == 0 {
+			break
+		}
+
+		ibcTxList, ibcDenomList := sw.handleSourceTx(chain, txList, denomMap)
+		if len(ibcDenomList) > 0 {
+			if err = denomRepo.InsertBatch(ibcDenomList); err != nil {
+				logrus.Errorf("task %s denomRepo.InsertBatch %s error, %v", t.Name(), chain, err)
+				return res, err
+			}
+		}
+		if err = t.upsert(chain, ibcTxList, res); err != nil {
+			return res, err
+		}
+		if err = t.relate(ctx, chain, ibcTxList, chainMap, res); err != nil {
+			return res, err
+		}
+
+		record()
+		height = txList[len(txList)-1].Height
+		logrus.Infof("task %s chain %s backfill to height %d, %+v", t.Name(), chain, height, *res)
+		if len(txList) < constant.DefaultLimit {
+			break
+		}
+	}
+
+	logrus.Infof("task %s chain %s backfill [%d, %d] finished, %+v", t.Name(), chain, fromHeight, toHeight, *res)
+	return res, nil
+}
+
+// addRunCounters add the counts of res since prev to the running record of the task
+func (t *IbcTxBackfillTask) addRunCounters(chain string, prev, res IbcTxBackfillResult) {
+	addTaskRunCounter(t.Name(), chain, "ibc_txs_inserted", res.Inserted-prev.Inserted)
+	addTaskRunCounter(t.Name(), chain, "ibc_txs_updated", res.Updated-prev.Updated)
+	addTaskRunCounter(t.Name(), chain, "ibc_txs_unchanged", res.Unchanged-prev.Unchanged)
+	addTaskRunCounter(t.Name(), chain, "ibc_txs_related", res.Related-prev.Related)
+}
+
+// getTxList same as syncTransferTxWorker.getTxList, but limited by toHeight
+func (t *IbcTxBackfillTask) getTxList(sw *syncTransferTxWorker, chain string, height, toHeight, limit int64) ([]*entity.Tx, error) {
+	transferTxList, err := txRepo.GetTransferTxByHeightRange(chain, height, toHeight, limit)
+	if err != nil {
+		logrus.Errorf("task %s GetTransferTxByHeightRange %s error, %v", t.Name(), chain, err)
+		return nil, err
+	}
+
+	if len(transferTxList) < int(limit) {
+		return transferTxList, nil
+	}
+
+	maxHeight := transferTxList[len(transferTxList)-1].Height
+	txHashMap := make(map[string]string)
+	for _, v := range transferTxList {
+		if v.Height == maxHeight {
+			txHashMap[v.TxHash] = ""
+		}
+	}
+
+	heightTxList, err := txRepo.FindByTypeAndHeight(chain, constant.MsgTypeTransfer, maxHeight)
+	if err != nil {
+		logrus.Errorf("task %s FindByTypeAndHeight %s error, %v", t.Name(), chain, err)
+		return nil, err
+	}
+
+	for _, v := range heightTxList {
+		if _, ok := txHashMap[v.TxHash]; !ok {
+			transferTxList = append(transferTxList, v)
+		}
+	}
+
+	return transferTxList, nil
+}
+
+func (t *IbcTxBackfillTask) upsert(chain string, ibcTxList []*entity.ExIbcTx, res *IbcTxBackfillResult) error {
+	for _, v := range ibcTxList {
+		updateRes, err := ibcTxRepo.BackfillUpsert(v)
+		if err != nil {
+			logrus.Errorf("task %s ibcTxRepo.BackfillUpsert %s %s error, %v", t.Name(), chain, v.RecordId, err)
+			return err
+		}
+
+		switch {
+		case updateRes.UpsertedCount > 0:
+			res.Inserted++
+		case updateRes.ModifiedCount > 0:
+			res.Updated++
+		default:
+			res.Unchanged++
+		}
+	}
+	return nil
+}
+
+// relate relate the processing ibc txs of this batch, both in ex_ibc_tx_latest and ex_ibc_tx
+func (t *IbcTxBackfillTask) relate(ctx context.Context, chain string, ibcTxList []*entity.ExIbcTx, chainMap map[string]*entity.ChainConfig, res *IbcTxBackfillResult) error {
+	if len(ibcTxList) == 0 {
+		return nil
+	}
+
+	recordIds := make([]string, 0, len(ibcTxList))
+	for _, v := range ibcTxList {
+		recordIds = append(recordIds, v.RecordId)
+	}
+
+	for _, target := range []string{ibcTxTargetLatest, ibcTxTargetHistory} {
+		txs, err := ibcTxRepo.FindProcessingTxsByRecordIds(recordIds, target == ibcTxTargetHistory)
+		if err != nil {
+			logrus.Errorf("task %s FindProcessingTxsByRecordIds %s error, %v", t.Name(), chain, err)
+			return err
+		}
+		if len(txs) == 0 {
+			continue
+		}
+
+		rw := newIbcTxRelateWorker(t.Name(), chain, target, chainMap)
+		denomMap, err := rw.getChainDenomMap(chain)
+		if err != nil {
+			return err
+		}
+		rw.handlerIbcTxs(ctx, chain, txs, denomMap)
+		res.Related += int64(len(txs))
+	}
+	return nil
+}
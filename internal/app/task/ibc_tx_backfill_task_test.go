@@ -0,0 +1,23 @@
+package task
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_IbcTxBackfillTask(t *testing.T) {
+	res, err := new(IbcTxBackfillTask).RunWithParam(context.Background(), "irishub_qa", 1, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%+v", *res)
+
+	// run again, nothing is inserted
+	res, err = new(IbcTxBackfillTask).RunWithParam(context.Background(), "irishub_qa", 1, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Inserted != 0 {
+		t.Fatalf("backfill is not idempotent, %+v", *res)
+	}
+}
@@ -0,0 +1,84 @@
+package task
+
+import (
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/sirupsen/logrus"
+)
+
+func deadLetterRetryTimes() int64 {
+	if taskConf.IbcTxDeadLetterRetryTimes > 0 {
+		return taskConf.IbcTxDeadLetterRetryTimes
+	}
+	return ibcTxDeadLetterRetryTimes
+}
+
+// shouldMoveToDeadLetter expired ibc txs are waiting for the timeout packet to be relayed, they can be refunded at any
+// time and are kept in the transfer list no matter how many times they are retried
+func shouldMoveToDeadLetter(ibcTx *entity.ExIbcTx) bool {
+	return ibcTx.Status != entity.IbcTxStatusExpired && ibcTx.RetryTimes >= deadLetterRetryTimes()
+}
+
+// moveToDeadLetter the ibc tx is saved to ibc_tx_dead_letter before it is deleted, a failed deletion leaves the ibc tx
+// to be moved again next time
+func (w *ibcTxRelateWorker) moveToDeadLetter(ibcTx *entity.ExIbcTx) error {
+	source := entity.DeadLetterSourceLatest
+	if w.target == ibcTxTargetHistory {
+		source = entity.DeadLetterSourceHistory
+	}
+
+	reasonCode, packetIds := deadLetterReason(ibcTx)
+	nowUnix := time.Now().Unix()
+	deadLetter := &entity.IbcTxDeadLetter{
+		RecordId:    ibcTx.RecordId,
+		Source:      source,
+		ScChain:     ibcTx.ScChain,
+		DcChain:     ibcTx.DcChain,
+		ReasonCode:  reasonCode,
+		ProcessInfo: ibcTx.ProcessInfo,
+		RetryTimes:  ibcTx.RetryTimes,
+		PacketIds:   packetIds,
+		IbcTx:       ibcTx,
+		CreateAt:    nowUnix,
+		UpdateAt:    nowUnix,
+	}
+	if err := ibcTxDeadLetterRepo.Save(deadLetter); err != nil {
+		return err
+	}
+	if err := ibcTxRepo.DelById(ibcTx.Id, w.target == ibcTxTargetHistory); err != nil {
+		return err
+	}
+
+	addTaskRunCounter(w.taskName, ibcTx.ScChain, "dead_letters", 1)
+	logrus.Warningf("task %s worker %s move ibc tx to dead letter, record_id: %s, reason: %s, retry times: %d", w.taskName, w.workerName, ibcTx.RecordId, reasonCode, ibcTx.RetryTimes)
+	return nil
+}
+
+// deadLetterReason reason code and the packet txs the relate task was looking for, derived from the process info
+func deadLetterReason(ibcTx *entity.ExIbcTx) (entity.DeadLetterReason, []entity.DeadLetterPacket) {
+	if ibcTx.DcChain == "" {
+		return entity.DeadLetterReasonDcChainNotFound, nil
+	}
+	if ibcTx.ScTxInfo == nil || ibcTx.ScTxInfo.Msg == nil || ibcTx.ScTxInfo.Msg.CommonMsg().PacketId == "" {
+		return entity.DeadLetterReasonInvalidScTxInfo, nil
+	}
+
+	packetId := ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
+	switch ibcTx.ProcessInfo {
+	case constant.NoFoundSuccessTimeoutPacket:
+		return entity.DeadLetterReasonTimeoutPacketNotFound, []entity.DeadLetterPacket{
+			{Chain: ibcTx.DcChain, TxType: entity.TxTypeRecvPacket, PacketId: packetId},
+			{Chain: ibcTx.ScChain, TxType: entity.TxTypeTimeoutPacket, PacketId: packetId},
+		}
+	case constant.NoFoundSuccessAcknowledgePacket:
+		return entity.DeadLetterReasonAckPacketNotFound, []entity.DeadLetterPacket{
+			{Chain: ibcTx.ScChain, TxType: entity.TxTypeAckPacket, PacketId: packetId},
+		}
+	default:
+		return entity.DeadLetterReasonRecvPacketNotFound, []entity.DeadLetterPacket{
+			{Chain: ibcTx.DcChain, TxType: entity.TxTypeRecvPacket, PacketId: packetId},
+		}
+	}
+}
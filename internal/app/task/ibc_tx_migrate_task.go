@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
@@ -28,14 +29,14 @@ func (t *IbcTxMigrateTask) Cron() int {
 	return EveryHour
 }
 
-func (t *IbcTxMigrateTask) Run() int {
+func (t *IbcTxMigrateTask) Run(ctx context.Context) int {
 	if !t.Switch() {
 		logrus.Infof("task %s closed", t.Name())
 		return 1
 	}
 
-	err1 := t.migrateSetting()
-	err2 := t.migrateNormal()
+	err1 := t.migrateSetting(ctx)
+	err2 := t.migrateNormal(ctx)
 
 	if err1 != nil || err2 != nil {
 		return -1
@@ -43,11 +44,15 @@ func (t *IbcTxMigrateTask) Run() int {
 	return 1
 }
 
-func (t *IbcTxMigrateTask) migrateSetting() error {
+func (t *IbcTxMigrateTask) migrateSetting(ctx context.Context) error {
 	const limit = 1000
 	status := []entity.IbcTxStatus{entity.IbcTxStatusSetting}
 	totalMigrate := 0
 	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
 		txList, err := ibcTxRepo.FindByStatus(status, limit)
 		if err != nil {
 			logrus.Errorf("task %s find setting txs error, %v", t.Name(), err)
@@ -71,7 +76,7 @@ func (t *IbcTxMigrateTask) migrateSetting() error {
 	return nil
 }
 
-func (t *IbcTxMigrateTask) migrateNormal() error {
+func (t *IbcTxMigrateTask) migrateNormal(ctx context.Context) error {
 	const limit = 1000
 	status := entity.IbcTxUsefulStatus
 	count, err := ibcTxRepo.CountByStatus(status)
@@ -87,6 +92,10 @@ func (t *IbcTxMigrateTask) migrateNormal() error {
 
 	totalMigrate := 0
 	for ; batch > 0; batch-- {
+		if ctx.Err() != nil {
+			return nil
+		}
+
 		txList, err := ibcTxRepo.FindByStatus(status, limit)
 		if err != nil {
 			logrus.Errorf("task %s find mormal txs error, %v", t.Name(), err)
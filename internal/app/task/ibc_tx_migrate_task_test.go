@@ -1,19 +1,22 @@
 package task
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func Test_IbcTxMigrate(t *testing.T) {
-	new(IbcTxMigrateTask).Run()
+	new(IbcTxMigrateTask).Run(context.Background())
 }
 
 func Test_MigrateSetting(t *testing.T) {
-	if err := new(IbcTxMigrateTask).migrateSetting(); err != nil {
+	if err := new(IbcTxMigrateTask).migrateSetting(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 }
 
 func Test_MigrateNormal(t *testing.T) {
-	if err := new(IbcTxMigrateTask).migrateNormal(); err != nil {
+	if err := new(IbcTxMigrateTask).migrateNormal(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 }
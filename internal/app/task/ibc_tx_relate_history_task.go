@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -12,7 +13,7 @@ import (
 type IbcTxRelateHistoryTask struct {
 }
 
-var _ Task = new(IbcTxRelateHistoryTask)
+var _ chainLeasedTask = new(IbcTxRelateHistoryTask)
 var relateHistoryCoordinator *stringQueueCoordinator
 
 func (t *IbcTxRelateHistoryTask) Name() string {
@@ -26,6 +27,8 @@ func (t *IbcTxRelateHistoryTask) Cron() int {
 	return ThreeMinute
 }
 
+func (t *IbcTxRelateHistoryTask) chainLeased() {}
+
 func (t *IbcTxRelateHistoryTask) workerNum() int {
 	if global.Config.Task.IbcTxRelateWorkerNum > 0 {
 		return global.Config.Task.IbcTxRelateWorkerNum
@@ -33,7 +36,7 @@ func (t *IbcTxRelateHistoryTask) workerNum() int {
 	return ibcTxRelateTaskWorkerNum
 }
 
-func (t *IbcTxRelateHistoryTask) Run() int {
+func (t *IbcTxRelateHistoryTask) Run(ctx context.Context) int {
 	chainMap, err := getAllChainMap()
 	if err != nil {
 		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
@@ -55,7 +58,7 @@ func (t *IbcTxRelateHistoryTask) Run() int {
 	for i := 1; i <= workerNum; i++ {
 		workName := fmt.Sprintf("worker-%d", i)
 		go func(wn string) {
-			newIbcTxRelateWorker(t.Name(), wn, ibcTxTargetHistory, chainMap).exec()
+			newIbcTxRelateWorker(t.Name(), wn, ibcTxTargetHistory, chainMap).exec(ctx)
 			waitGroup.Done()
 		}(workName)
 	}
@@ -0,0 +1,214 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	ibcTxRelateRpcSearchMax   = 20 // packets searched by rpc in a batch of ibc txs
+	ibcTxRelateRpcSearchLimit = 10 // txs of a packet searched by rpc
+)
+
+// rpcMsgTypes packet msgs of the rpc txs which are converted to the msgs of sync_{chain}_tx
+var rpcMsgTypes = map[string]string{
+	"/ibc.core.channel.v1.MsgRecvPacket":      constant.MsgTypeRecvPacket,
+	"/ibc.core.channel.v1.MsgAcknowledgement": constant.MsgTypeAcknowledgement,
+	"/ibc.core.channel.v1.MsgTimeout":         constant.MsgTypeTimeoutPacket,
+}
+
+// rpcPacketMsg packet msg of lcd format, see lcd.RpcTx
+type rpcPacketMsg struct {
+	Type   string `json:"@type"`
+	Packet struct {
+		Sequence           string `json:"sequence"`
+		SourcePort         string `json:"source_port"`
+		SourceChannel      string `json:"source_channel"`
+		DestinationPort    string `json:"destination_port"`
+		DestinationChannel string `json:"destination_channel"`
+		Data               []byte `json:"data"`
+		TimeoutHeight      struct {
+			RevisionNumber int64 `json:"revision_number,string"`
+			RevisionHeight int64 `json:"revision_height,string"`
+		} `json:"timeout_height"`
+		TimeoutTimestamp int64 `json:"timeout_timestamp,string"`
+	} `json:"packet"`
+	Acknowledgement  []byte `json:"acknowledgement"`
+	NextSequenceRecv int64  `json:"next_sequence_recv,string"`
+	Signer           string `json:"signer"`
+}
+
+// fillMissedPacketTxs the recv packet txs and ack txs missed by the indexer are searched by the tx index of the rpc
+// nodes, see switch_ibc_tx_relate_rpc_fill
+func (w *ibcTxRelateWorker) fillMissedPacketTxs(ctx context.Context, ibcTxList []*entity.ExIbcTx, recvPacketTxMap, ackTxMap map[string][]*entity.Tx, noFoundAckMap map[string]struct{}) {
+	if !global.Config.Task.SwitchIbcTxRelateRpcFill {
+		return
+	}
+
+	var searched int
+	for _, ibcTx := range ibcTxList {
+		if searched >= ibcTxRelateRpcSearchMax {
+			return
+		}
+		if !w.toBeRelated(ibcTx) || ibcTx.DcChain == "" || ibcTx.Sequence == "" || ibcTx.ScTxInfo == nil || ibcTx.ScTxInfo.Msg == nil {
+			continue
+		}
+
+		packetId := ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
+		recvKey := w.genPacketTxMapKey(ibcTx.DcChain, packetId)
+		recvTxs, ok := recvPacketTxMap[recvKey]
+		if !ok {
+			searched++
+			if recvTxs = w.searchPacketTxs(ctx, ibcTx.DcChain, constant.MsgTypeRecvPacket, ibcTx, packetId); len(recvTxs) == 0 {
+				continue
+			}
+			recvPacketTxMap[recvKey] = recvTxs
+		}
+
+		var received bool
+		for _, tx := range recvTxs {
+			if tx.Status == entity.TxStatusSuccess {
+				received = true
+			}
+		}
+		ackKey := w.genPacketTxMapKey(ibcTx.ScChain, packetId)
+		if _, ok = ackTxMap[ackKey]; ok || !received {
+			continue
+		}
+
+		noFoundAckMap[ibcTx.Id.Hex()] = struct{}{}
+		searched++
+		var ackTxs []*entity.Tx
+		for _, tx := range w.searchPacketTxs(ctx, ibcTx.ScChain, constant.MsgTypeAcknowledgement, ibcTx, packetId) {
+			if tx.Status == entity.TxStatusSuccess {
+				ackTxs = append(ackTxs, tx)
+			}
+		}
+		if len(ackTxs) > 0 {
+			ackTxMap[ackKey] = ackTxs
+		}
+	}
+}
+
+// searchPacketTxs search the txs of the packet by the event of msgType, the event type is the same as the msg type
+func (w *ibcTxRelateWorker) searchPacketTxs(ctx context.Context, chain, msgType string, ibcTx *entity.ExIbcTx, packetId string) []*entity.Tx {
+	query := fmt.Sprintf("%s.packet_src_port='%s' AND %s.packet_src_channel='%s' AND %s.packet_sequence='%s'",
+		msgType, ibcTx.ScPort, msgType, ibcTx.ScChannel, msgType, ibcTx.Sequence)
+	rpcTxs, err := lcd.SearchTxs(ctx, chain, query, ibcTxRelateRpcSearchLimit)
+	if err != nil {
+		logrus.Warningf("task %s worker %s chain %s search %s txs by rpc error, packet_id: %s, %v", w.taskName, w.workerName, chain, msgType, packetId, err)
+		return nil
+	}
+
+	var txs []*entity.Tx
+	for _, rpcTx := range rpcTxs {
+		blockTime, err := lcd.GetBlockTime(ctx, chain, rpcTx.Height)
+		if err != nil {
+			logrus.Warningf("task %s worker %s chain %s get block %d time by rpc error, %v", w.taskName, w.workerName, chain, rpcTx.Height, err)
+			continue
+		}
+
+		tx := rpcTxToTx(rpcTx, blockTime.Unix())
+		for _, msg := range tx.DocTxMsgs {
+			if msg.Type == msgType && msg.CommonMsg().PacketId == packetId {
+				txs = append(txs, tx)
+				logrus.Infof("task %s worker %s chain %s found %s tx %s missed by the indexer, packet_id: %s", w.taskName, w.workerName, chain, msgType, tx.TxHash, packetId)
+				break
+			}
+		}
+	}
+	return txs
+}
+
+// rpcTxToTx convert the rpc tx to the format of sync_{chain}_tx, only the packet msgs are converted. fee is unknown
+func rpcTxToTx(rpcTx *lcd.RpcTx, txTime int64) *entity.Tx {
+	tx := &entity.Tx{
+		Time:    txTime,
+		Height:  rpcTx.Height,
+		TxHash:  rpcTx.Hash,
+		Memo:    rpcTx.Memo,
+		Status:  entity.TxStatusFailed,
+		Log:     rpcTx.Log,
+		GasUsed: rpcTx.GasUsed,
+		TxIndex: rpcTx.Index,
+	}
+	if rpcTx.Code == 0 {
+		tx.Status = entity.TxStatusSuccess
+	}
+
+	tx.EventsNew = make([]entity.EventNew, len(rpcTx.Messages))
+	for i := range tx.EventsNew {
+		tx.EventsNew[i].MsgIndex = uint32(i)
+	}
+	for _, v := range rpcTx.Logs {
+		if v.MsgIndex >= 0 && v.MsgIndex < len(tx.EventsNew) {
+			tx.EventsNew[v.MsgIndex].Events = v.Events
+		}
+	}
+
+	signers := make(map[string]struct{})
+	for _, v := range rpcTx.Messages {
+		msg, signer := rpcTxMsg(v)
+		tx.DocTxMsgs = append(tx.DocTxMsgs, msg)
+		tx.Types = append(tx.Types, msg.Type)
+		if _, ok := signers[signer]; !ok && signer != "" {
+			signers[signer] = struct{}{}
+			tx.Signers = append(tx.Signers, signer)
+		}
+	}
+	if len(tx.Types) > 0 {
+		tx.Type = tx.Types[0]
+	}
+	return tx
+}
+
+func rpcTxMsg(bz []byte) (*model.TxMsg, string) {
+	var raw rpcPacketMsg
+	_ = json.Unmarshal(bz, &raw)
+	msgType, ok := rpcMsgTypes[raw.Type]
+	if !ok {
+		return &model.TxMsg{Type: raw.Type}, raw.Signer
+	}
+
+	packet := model.Packet{
+		SourcePort:         raw.Packet.SourcePort,
+		SourceChannel:      raw.Packet.SourceChannel,
+		DestinationPort:    raw.Packet.DestinationPort,
+		DestinationChannel: raw.Packet.DestinationChannel,
+		TimeoutHeight: model.TimeoutHeight{
+			RevisionNumber: raw.Packet.TimeoutHeight.RevisionNumber,
+			RevisionHeight: raw.Packet.TimeoutHeight.RevisionHeight,
+		},
+		TimeoutTimestamp: raw.Packet.TimeoutTimestamp,
+	}
+	_, _ = fmt.Sscanf(raw.Packet.Sequence, "%d", &packet.Sequence)
+	_ = json.Unmarshal(raw.Packet.Data, &packet.Data)
+	packetId := ibctool.BuildPacketId(packet.SourcePort, packet.SourceChannel, packet.DestinationPort, packet.DestinationChannel, raw.Packet.Sequence)
+
+	var msg interface{}
+	switch msgType {
+	case constant.MsgTypeRecvPacket:
+		msg = model.RecvPacketMsg{PacketId: packetId, Packet: packet, Signer: raw.Signer}
+	case constant.MsgTypeAcknowledgement:
+		msg = model.AckPacketMsg{PacketId: packetId, Packet: packet, Acknowledgement: string(raw.Acknowledgement), Signer: raw.Signer}
+	default:
+		msg = model.TimeoutPacketMsg{PacketId: packetId, Packet: packet, NextSequenceRecv: raw.NextSequenceRecv, Signer: raw.Signer}
+	}
+
+	// converted by bson, so the numbers are int64 the same as the msgs written by the indexer
+	var m bson.M
+	if bz, err := bson.Marshal(msg); err == nil {
+		_ = bson.Unmarshal(bz, &m)
+	}
+	return &model.TxMsg{Type: msgType, Msg: m}, raw.Signer
+}
@@ -0,0 +1,49 @@
+package task
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func Test_rpcTxToTx(t *testing.T) {
+	rpcTx := &lcd.RpcTx{
+		Hash:   "ABCD",
+		Height: 100,
+		Messages: []json.RawMessage{
+			[]byte(`{"@type":"/ibc.core.client.v1.MsgUpdateClient"}`),
+			[]byte(`{"@type":"/ibc.core.channel.v1.MsgRecvPacket","packet":{"sequence":"5","source_port":"transfer",
+			"source_channel":"channel-0","destination_port":"transfer","destination_channel":"channel-141",
+			"data":"eyJhbW91bnQiOiIxMDAiLCJkZW5vbSI6InVhdG9tIn0=","timeout_timestamp":"1700000000000000001"},
+			"signer":"osmo1relayer"}`),
+		},
+		Logs: []lcd.RpcTxLog{{MsgIndex: 1, Events: []entity.Event{{Type: "write_acknowledgement"}}}},
+	}
+
+	tx := rpcTxToTx(rpcTx, 1700000000)
+	if tx.Status != entity.TxStatusSuccess || len(tx.DocTxMsgs) != 2 || len(tx.EventsNew) != 2 || len(tx.EventsNew[1].Events) != 1 {
+		t.Fatalf("unexpected tx %+v", tx)
+	}
+
+	msg := tx.DocTxMsgs[1]
+	recvMsg := msg.RecvPacketMsg()
+	if msg.Type != constant.MsgTypeRecvPacket || recvMsg.PacketId != "transferchannel-0transferchannel-1415" {
+		t.Fatalf("unexpected msg %+v", msg)
+	}
+	if recvMsg.Packet.TimeoutTimestamp != 1700000000000000001 || recvMsg.Packet.Data.Denom != "uatom" {
+		t.Fatalf("unexpected packet %+v", recvMsg.Packet)
+	}
+
+	// the same types as the msgs written by the indexer
+	packet, _ := msg.Msg["packet"].(bson.M)
+	if _, ok := packet["sequence"].(int64); !ok {
+		t.Fatalf("sequence is %T", packet["sequence"])
+	}
+	if _, ok := packet["timeout_timestamp"].(int64); !ok {
+		t.Fatalf("timeout_timestamp is %T", packet["timeout_timestamp"])
+	}
+}
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -14,13 +15,16 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/qiniu/qmgo"
 	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type IbcTxRelateTask struct {
 }
 
-var _ Task = new(IbcTxRelateTask)
+var _ chainLeasedTask = new(IbcTxRelateTask)
 var relateCoordinator *stringQueueCoordinator
 
 func (t *IbcTxRelateTask) Name() string {
@@ -34,6 +38,8 @@ func (t *IbcTxRelateTask) Cron() int {
 	return ThreeMinute
 }
 
+func (t *IbcTxRelateTask) chainLeased() {}
+
 func (t *IbcTxRelateTask) workerNum() int {
 	if global.Config.Task.IbcTxRelateWorkerNum > 0 {
 		return global.Config.Task.IbcTxRelateWorkerNum
@@ -41,7 +47,7 @@ func (t *IbcTxRelateTask) workerNum() int {
 	return ibcTxRelateTaskWorkerNum
 }
 
-func (t *IbcTxRelateTask) Run() int {
+func (t *IbcTxRelateTask) Run(ctx context.Context) int {
 	chainMap, err := getAllChainMap()
 	if err != nil {
 		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
@@ -63,7 +69,7 @@ func (t *IbcTxRelateTask) Run() int {
 	for i := 1; i <= workerNum; i++ {
 		workName := fmt.Sprintf("worker-%d", i)
 		go func(wn string) {
-			newIbcTxRelateWorker(t.Name(), wn, ibcTxTargetLatest, chainMap).exec()
+			newIbcTxRelateWorker(t.Name(), wn, ibcTxTargetLatest, chainMap).exec(ctx)
 			waitGroup.Done()
 		}(workName)
 	}
@@ -90,12 +96,13 @@ type ibcTxRelateWorker struct {
 	workerName string
 	target     string
 	chainMap   map[string]*entity.ChainConfig
+	lease      *chainLease
 }
 
-func (w *ibcTxRelateWorker) exec() {
+func (w *ibcTxRelateWorker) exec(ctx context.Context) {
 	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
 	for {
-		chain, err := w.getChain()
+		chain, err := w.getChain(ctx)
 		if err != nil {
 			logrus.Infof("task %s worker %s exit", w.taskName, w.workerName)
 			break
@@ -106,24 +113,32 @@ func (w *ibcTxRelateWorker) exec() {
 			continue
 		}
 
+		if w.lease, err = acquireChainLease(w.taskName, chain); err != nil {
+			logrus.Infof("task %s worker %s chain %s is leased by another instance", w.taskName, w.workerName, chain)
+			continue
+		}
+
 		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chain)
 		startTime := time.Now().Unix()
-		if err = w.relateTx(chain); err != nil {
+		if err = w.relateTx(ctx, chain); err != nil {
 			logrus.Errorf("task %s worker %s relate chain %s tx error,time use: %d(s), %v", w.taskName, w.workerName, chain, time.Now().Unix()-startTime, err)
+			addTaskRunError(w.taskName, chain, err)
 		} else {
 			logrus.Infof("task %s worker %s relate chain %s tx end,time use: %d(s)", w.taskName, w.workerName, chain, time.Now().Unix()-startTime)
 		}
+		w.lease.release()
+		w.lease = nil
 	}
 }
 
-func (w *ibcTxRelateWorker) getChain() (string, error) {
+func (w *ibcTxRelateWorker) getChain(ctx context.Context) (string, error) {
 	if w.target == ibcTxTargetHistory {
-		return relateHistoryCoordinator.getOne()
+		return relateHistoryCoordinator.getOne(ctx)
 	}
-	return relateCoordinator.getOne()
+	return relateCoordinator.getOne(ctx)
 }
 
-func (w *ibcTxRelateWorker) relateTx(chain string) error {
+func (w *ibcTxRelateWorker) relateTx(ctx context.Context, chain string) error {
 	totalRelateTx := 0
 	//const limit = 500
 	maxParseTx := global.Config.Task.SingleChainIbcTxRelateMax
@@ -137,6 +152,13 @@ func (w *ibcTxRelateWorker) relateTx(chain string) error {
 	}
 
 	for {
+		if w.lease.lost() {
+			return fmt.Errorf("chain lease is lost")
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
 		txList, err := w.getToBeRelatedTxs(chain, constant.DefaultLimit)
 		if err != nil {
 			logrus.Errorf("task %s worker %s chain %s getToBeRelatedTxs error, %v", w.taskName, w.workerName, chain, err)
@@ -147,7 +169,8 @@ func (w *ibcTxRelateWorker) relateTx(chain string) error {
 			return nil
 		}
 
-		w.handlerIbcTxs(chain, txList, denomMap)
+		w.handlerIbcTxs(ctx, chain, txList, denomMap)
+		addTaskRunCounter(w.taskName, chain, "ibc_txs_handled", int64(len(txList)))
 
 		totalRelateTx += len(txList)
 		if len(txList) < constant.DefaultLimit || totalRelateTx >= maxParseTx {
@@ -159,10 +182,12 @@ func (w *ibcTxRelateWorker) relateTx(chain string) error {
 	return nil
 }
 
-func (w *ibcTxRelateWorker) handlerIbcTxs(scChain string, ibcTxList []*entity.ExIbcTx, denomMap map[string]*entity.IBCDenom) {
+func (w *ibcTxRelateWorker) handlerIbcTxs(ctx context.Context, scChain string, ibcTxList []*entity.ExIbcTx, denomMap map[string]*entity.IBCDenom) {
 	recvPacketTxMap, ackTxMap, timeoutTxMap, timeoutIbcTxMap, noFoundAckMap := w.packetIdTx(scChain, ibcTxList)
+	w.fillMissedPacketTxs(ctx, ibcTxList, recvPacketTxMap, ackTxMap, noFoundAckMap)
 
 	var ibcDenomNewList entity.IBCDenomList
+	var forwardTxList []*entity.ExIbcTx
 	for _, ibcTx := range ibcTxList {
 		if ibcTx.DcChain == "" || ibcTx.ScTxInfo == nil || ibcTx.ScTxInfo.Msg == nil {
 			w.setNextTryTime(ibcTx)
@@ -170,27 +195,41 @@ func (w *ibcTxRelateWorker) handlerIbcTxs(scChain string, ibcTxList []*entity.Ex
 			packetId := ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
 			if syncTxs, ok := recvPacketTxMap[w.genPacketTxMapKey(ibcTx.DcChain, packetId)]; ok {
 				ackSyncTxs := ackTxMap[w.genPacketTxMapKey(ibcTx.ScChain, packetId)]
-				ibcDenom := w.loadRecvPacketTx(ibcTx, syncTxs, ackSyncTxs)
-				if ibcDenom != nil && denomMap[ibcDenom.Denom] == nil {
-					denomMap[ibcDenom.Denom] = ibcDenom
-					ibcDenomNewList = append(ibcDenomNewList, ibcDenom)
+				for _, ibcDenom := range w.loadRecvPacketTx(ibcTx, syncTxs, ackSyncTxs) {
+					if denomMap[ibcDenom.Denom] == nil {
+						denomMap[ibcDenom.Denom] = ibcDenom
+						ibcDenomNewList = append(ibcDenomNewList, ibcDenom)
+					}
+				}
+				if forwardTx := w.loadForwardTx(ibcTx, syncTxs); forwardTx != nil {
+					forwardTxList = append(forwardTxList, forwardTx)
 				}
 			}
 
-			if syncTxs, ok := timeoutTxMap[w.genPacketTxMapKey(ibcTx.ScChain, packetId)]; ok && ibcTx.Status == entity.IbcTxStatusProcessing {
+			if syncTxs, ok := timeoutTxMap[w.genPacketTxMapKey(ibcTx.ScChain, packetId)]; ok && w.toBeRelated(ibcTx) {
 				recvSyncTxs := recvPacketTxMap[w.genPacketTxMapKey(ibcTx.DcChain, packetId)]
 				w.loadTimeoutPacketTx(ibcTx, syncTxs, recvSyncTxs)
 			}
 		}
 
-		if ibcTx.Status == entity.IbcTxStatusProcessing {
+		if w.toBeRelated(ibcTx) {
 			w.setNextTryTime(ibcTx)
+			w.updateExpiredStatus(ibcTx, timeoutIbcTxMap, noFoundAckMap)
 			//记录"处理中"状态
 			ibcTx = w.updateProcessInfo(ibcTx, timeoutIbcTxMap, noFoundAckMap)
+			if shouldMoveToDeadLetter(ibcTx) {
+				err := w.moveToDeadLetter(ibcTx)
+				if err == nil {
+					continue
+				}
+				logrus.Errorf("task %s worker %s chain %s moveToDeadLetter error, record_id: %s, %v", w.taskName, w.workerName, scChain, ibcTx.RecordId, err)
+			}
 		}
 		var repaired bool
 		ibcTx, repaired = w.repairTxInfo(ibcTx)
-		if err := w.updateIbcTx(ibcTx, repaired); err != nil {
+		if err := w.updateIbcTx(ibcTx, repaired); err == qmgo.ErrNoSuchDocuments {
+			logrus.Debugf("task %s worker %s chain %s ibc tx has been related by others, _id: %s", w.taskName, w.workerName, scChain, ibcTx.Id)
+		} else if err != nil {
 			logrus.Errorf("task %s worker %s chain %s updateIbcTx error, _id: %s, %v", w.taskName, w.workerName, scChain, ibcTx.Id, err)
 		}
 	}
@@ -201,10 +240,34 @@ func (w *ibcTxRelateWorker) handlerIbcTxs(scChain string, ibcTxList []*entity.Ex
 			logrus.Errorf("task %s worker %s chain %s insert denoms error, %v", w.taskName, w.workerName, scChain, err)
 		}
 	}
+
+	// add next hops of packet-forward-middleware
+	for _, forwardTx := range forwardTxList {
+		if err := ibcTxRepo.SaveForwardTx(forwardTx, w.target == ibcTxTargetHistory); err != nil {
+			logrus.Errorf("task %s worker %s chain %s save forward tx error, parent record_id: %s, %v", w.taskName, w.workerName, scChain, forwardTx.ParentRecordId, err)
+		}
+	}
+}
+
+func (w *ibcTxRelateWorker) toBeRelated(ibcTx *entity.ExIbcTx) bool {
+	return ibcTx.Status == entity.IbcTxStatusProcessing || ibcTx.Status == entity.IbcTxStatusExpired
+}
+
+// updateExpiredStatus the packet which is timed out on dc chain and not received is marked as expired until the
+// timeout packet is relayed. A received packet can not be refunded by timeout, it is waiting for ack.
+func (w *ibcTxRelateWorker) updateExpiredStatus(ibcTx *entity.ExIbcTx, timeOutMap map[string]struct{}, noFoundAckMap map[string]struct{}) {
+	_, timeout := timeOutMap[ibcTx.Id.Hex()]
+	_, received := noFoundAckMap[ibcTx.Id.Hex()]
+	switch {
+	case received:
+		ibcTx.Status = entity.IbcTxStatusProcessing
+	case timeout:
+		ibcTx.Status = entity.IbcTxStatusExpired
+	}
 }
 
 func (w *ibcTxRelateWorker) updateProcessInfo(ibcTx *entity.ExIbcTx, timeOutMap map[string]struct{}, noFoundAckMap map[string]struct{}) *entity.ExIbcTx {
-	if ibcTx.Status == entity.IbcTxStatusProcessing {
+	if w.toBeRelated(ibcTx) {
 		if ibcTx.DcChain == "" {
 			ibcTx.ProcessInfo = constant.NoFoundDcChain
 		} else {
@@ -222,7 +285,7 @@ func (w *ibcTxRelateWorker) updateProcessInfo(ibcTx *entity.ExIbcTx, timeOutMap
 	return ibcTx
 }
 
-func (w *ibcTxRelateWorker) loadRecvPacketTx(ibcTx *entity.ExIbcTx, txs, ackTxs []*entity.Tx) *entity.IBCDenom {
+func (w *ibcTxRelateWorker) loadRecvPacketTx(ibcTx *entity.ExIbcTx, txs, ackTxs []*entity.Tx) entity.IBCDenomList {
 	refundedMatchAckTx := func() *entity.Tx {
 		var matchTx *entity.Tx
 		for _, ackTx := range ackTxs {
@@ -258,8 +321,9 @@ func (w *ibcTxRelateWorker) loadRecvPacketTx(ibcTx *entity.ExIbcTx, txs, ackTxs
 		return matchTx
 	}
 
-	var ibcDenom *entity.IBCDenom
+	var ibcDenomList entity.IBCDenomList
 	var matchAckTx *entity.Tx
+	w.loadFailedRecvErrorCategory(ibcTx, txs)
 	for _, tx := range txs {
 		if tx.Status == entity.TxStatusFailed {
 			continue
@@ -270,10 +334,14 @@ func (w *ibcTxRelateWorker) loadRecvPacketTx(ibcTx *entity.ExIbcTx, txs, ackTxs
 			}
 			dcConnection, packetAck, existPacketAck := parseRecvPacketTxEvents(msgIndex, tx)
 			if !existPacketAck {
-				continue
+				// packet forwarded by packet-forward-middleware is acknowledged after the next hop is finished
+				if packetAck, existPacketAck = w.forwardPacketAck(msg, ackTxs); !existPacketAck {
+					continue
+				}
 			}
 
 			if strings.Contains(packetAck, "error") {
+				ibcTx.ErrorCategory = ibctool.ClassifyAckError(packetAck, parseFungibleTokenPacketError(msgIndex, tx))
 				matchAckTx = refundedMatchAckTx()
 				if matchAckTx == nil { // 改为refunded状态时，必须要ack_packet交易
 					return nil
@@ -282,6 +350,7 @@ func (w *ibcTxRelateWorker) loadRecvPacketTx(ibcTx *entity.ExIbcTx, txs, ackTxs
 			} else {
 				matchAckTx = successMatchAckTx()
 				ibcTx.Status = entity.IbcTxStatusSuccess
+				ibcTx.ErrorCategory = ""
 			}
 
 			ibcTx.DcConnectionId = dcConnection
@@ -299,29 +368,7 @@ func (w *ibcTxRelateWorker) loadRecvPacketTx(ibcTx *entity.ExIbcTx, txs, ackTxs
 			}
 			ibcTx.UpdateAt = time.Now().Unix()
 
-			dcDenomFullPath, isCrossBack := ibctool.CalculateNextDenomPath(msg.RecvPacketMsg().Packet)
-			dcDenom := ibctool.CalculateIBCHash(dcDenomFullPath)
-			ibcTx.Denoms.DcDenom = dcDenom // set ibc tx dc denom
-			if ibcTx.Status == entity.IbcTxStatusSuccess {
-				if !isCrossBack {
-					dcDenomPath, rootDenom := ibctool.SplitFullPath(dcDenomFullPath)
-					ibcDenom = &entity.IBCDenom{
-						Symbol:         "",
-						Chain:          ibcTx.DcChain,
-						Denom:          dcDenom,
-						PrevDenom:      ibcTx.Denoms.ScDenom,
-						PrevChain:      ibcTx.ScChain,
-						BaseDenom:      ibcTx.BaseDenom,
-						BaseDenomChain: ibcTx.BaseDenomChain,
-						DenomPath:      dcDenomPath,
-						IBCHops:        ibctool.IBCHops(dcDenomPath),
-						IsBaseDenom:    false,
-						RootDenom:      rootDenom,
-						CreateAt:       time.Now().Unix(),
-						UpdateAt:       time.Now().Unix(),
-					}
-				}
-			}
+			ibcDenomList = w.loadRecvPacketDenoms(ibcTx, msg.RecvPacketMsg().Packet)
 		}
 	}
 
@@ -344,7 +391,229 @@ func (w *ibcTxRelateWorker) loadRecvPacketTx(ibcTx *entity.ExIbcTx, txs, ackTxs
 			}
 		}
 	}
-	return ibcDenom
+	return ibcDenomList
+}
+
+// loadFailedRecvErrorCategory the packet is not received yet, but the recv packet txs fail for a reason of the route,
+// such as out of gas or rate limit. errors of relayers(eg: redundant packet) are classified as unknown and ignored.
+func (w *ibcTxRelateWorker) loadFailedRecvErrorCategory(ibcTx *entity.ExIbcTx, txs []*entity.Tx) {
+	var latestFailedTx *entity.Tx
+	for _, tx := range txs {
+		if tx.Status != entity.TxStatusFailed {
+			continue
+		}
+		for _, msg := range tx.DocTxMsgs {
+			if msg.Type == constant.MsgTypeRecvPacket && msg.CommonMsg().PacketId == ibcTx.ScTxInfo.Msg.CommonMsg().PacketId {
+				if latestFailedTx == nil || tx.Time > latestFailedTx.Time {
+					latestFailedTx = tx
+				}
+			}
+		}
+	}
+	if latestFailedTx == nil {
+		return
+	}
+
+	if category := ibctool.ClassifyAckError(latestFailedTx.Log); category != entity.IbcTxErrorUnknown {
+		ibcTx.ErrorCategory = category
+	}
+}
+
+// loadRecvPacketDenoms set dc denoms of the ibc tx, and return the new denoms on dc chain if the packet is received
+// successfully. every coin of an ics20 v2 packet gets its own denom.
+func (w *ibcTxRelateWorker) loadRecvPacketDenoms(ibcTx *entity.ExIbcTx, packet model.Packet) entity.IBCDenomList {
+	var ibcDenomList entity.IBCDenomList
+	for i, coin := range packet.FullPathCoins() {
+		dcDenomFullPath, isCrossBack := ibctool.CalculateNextCoinPath(packet, coin.Denom)
+		dcDenom := ibctool.CalculateIBCHash(dcDenomFullPath)
+		prevDenom, baseDenom, baseDenomChain := ibcTx.Denoms.ScDenom, ibcTx.BaseDenom, ibcTx.BaseDenomChain
+		if i == 0 {
+			ibcTx.Denoms.DcDenom = dcDenom // set ibc tx dc denom
+		}
+		if i < len(ibcTx.Tokens) {
+			ibcTx.Tokens[i].DcDenom = dcDenom
+			prevDenom, baseDenom, baseDenomChain = ibcTx.Tokens[i].ScDenom, ibcTx.Tokens[i].BaseDenom, ibcTx.Tokens[i].BaseDenomChain
+		} else if i > 0 {
+			break
+		}
+
+		if ibcTx.Status != entity.IbcTxStatusSuccess || isCrossBack {
+			continue
+		}
+		dcDenomPath, rootDenom := ibctool.SplitFullPath(dcDenomFullPath)
+		ibcDenomList = append(ibcDenomList, &entity.IBCDenom{
+			Symbol:         "",
+			Chain:          ibcTx.DcChain,
+			Denom:          dcDenom,
+			PrevDenom:      prevDenom,
+			PrevChain:      ibcTx.ScChain,
+			BaseDenom:      baseDenom,
+			BaseDenomChain: baseDenomChain,
+			DenomPath:      dcDenomPath,
+			IBCHops:        ibctool.IBCHops(dcDenomPath),
+			IsBaseDenom:    false,
+			RootDenom:      rootDenom,
+			CreateAt:       time.Now().Unix(),
+			UpdateAt:       time.Now().Unix(),
+		})
+	}
+	return ibcDenomList
+}
+
+// forwardPacketAck get the async ack of the packet forwarded by packet-forward-middleware from ack packet txs on sc chain
+func (w *ibcTxRelateWorker) forwardPacketAck(recvMsg *model.TxMsg, ackTxs []*entity.Tx) (string, bool) {
+	recvPacketMsg := recvMsg.RecvPacketMsg()
+	if _, ok := ibctool.ParsePacketForward(recvPacketMsg.Packet); !ok {
+		return "", false
+	}
+
+	var matchTx *entity.Tx
+	var packetAck string
+	for _, ackTx := range ackTxs {
+		for _, msg := range ackTx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeAcknowledgement || msg.CommonMsg().PacketId != recvPacketMsg.PacketId {
+				continue
+			}
+			if matchTx == nil || ackTx.Time > matchTx.Time {
+				matchTx = ackTx
+				packetAck = msg.AckPacketMsg().Acknowledgement
+			}
+		}
+	}
+	return packetAck, matchTx != nil
+}
+
+// loadForwardTx build the next hop when the packet is forwarded by packet-forward-middleware or ics20 v2 forwarding on dc chain.
+// the next hop is sent in the recv packet tx, so it is found in the send_packet event of that tx.
+func (w *ibcTxRelateWorker) loadForwardTx(ibcTx *entity.ExIbcTx, txs []*entity.Tx) *entity.ExIbcTx {
+	packetId := ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
+	for _, tx := range txs {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		for msgIndex, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeRecvPacket || msg.CommonMsg().PacketId != packetId {
+				continue
+			}
+			forward, ok := ibctool.ParsePacketForward(msg.RecvPacketMsg().Packet)
+			if !ok {
+				return nil
+			}
+			packet, scConnection, packetData, exist := parseForwardTxEvents(msgIndex, tx, forward)
+			if !exist {
+				continue
+			}
+			return w.buildForwardTx(ibcTx, tx, msgIndex, packet, scConnection, packetData)
+		}
+	}
+	return nil
+}
+
+func (w *ibcTxRelateWorker) buildForwardTx(parent *entity.ExIbcTx, tx *entity.Tx, msgIndex int, packet model.Packet, scConnection string, packetData model.TransferTxPacketData) *entity.ExIbcTx {
+	if parent.JourneyId == "" {
+		parent.JourneyId = parent.RecordId
+	}
+
+	scChain := parent.DcChain
+	scPort, scChannel := packet.SourcePort, packet.SourceChannel
+	dcPort, dcChannel := packet.DestinationPort, packet.DestinationChannel
+	sequence := strconv.FormatInt(packet.Sequence, 10)
+	dcChain, _, _ := ibctool.MatchDcInfo(scChain, scPort, scChannel, w.chainMap)
+	ibcTxStatus := entity.IbcTxStatusProcessing
+	if dcChain == "" {
+		ibcTxStatus = entity.IbcTxStatusSetting
+	}
+
+	// the middleware sends the packet without a transfer msg, so a transfer msg is built from the send_packet event
+	// to let the hop be related and displayed the same way as the other transfers
+	var tokens []*model.Coin
+	var msgTokens []bson.M
+	var transferTokens []*entity.TransferToken
+	for i, coin := range packetData.FullPathCoins() {
+		token := &model.Coin{Denom: ibctool.CalculateIBCHash(coin.Denom), Amount: coin.Amount}
+		tokens = append(tokens, token)
+		msgTokens = append(msgTokens, bson.M{"denom": token.Denom, "amount": token.Amount})
+		transferToken := &entity.TransferToken{ScDenom: token.Denom, Amount: token.Amount}
+		if i < len(parent.Tokens) {
+			transferToken.BaseDenom = parent.Tokens[i].BaseDenom
+			transferToken.BaseDenomChain = parent.Tokens[i].BaseDenomChain
+		}
+		transferTokens = append(transferTokens, transferToken)
+	}
+	token := tokens[0]
+	scDenom := token.Denom
+	if len(tokens) == 1 {
+		msgTokens, transferTokens = nil, nil
+	}
+	transferMsg := &model.TxMsg{
+		Type: constant.MsgTypeTransfer,
+		Msg: bson.M{
+			"packet_id":      ibctool.BuildPacketId(scPort, scChannel, dcPort, dcChannel, sequence),
+			"source_port":    scPort,
+			"source_channel": scChannel,
+			"token":          bson.M{"denom": token.Denom, "amount": token.Amount},
+			"sender":         packetData.Sender,
+			"receiver":       packetData.Receiver,
+			"timeout_height": bson.M{
+				"revision_number": packet.TimeoutHeight.RevisionNumber,
+				"revision_height": packet.TimeoutHeight.RevisionHeight,
+			},
+			"timeout_timestamp": packet.TimeoutTimestamp,
+		},
+	}
+
+	if len(msgTokens) > 0 {
+		transferMsg.Msg["tokens"] = msgTokens
+	}
+
+	recordIdStr := fmt.Sprintf("%s%s%s%s%s%s%s%d", scPort, scChannel, dcPort, dcChannel, sequence, scChain, tx.TxHash, msgIndex)
+	nowUnix := time.Now().Unix()
+	forwardTx := &entity.ExIbcTx{
+		Id:             primitive.NewObjectID(),
+		RecordId:       utils.Md5(recordIdStr),
+		TxTime:         tx.Time,
+		ScAddr:         packetData.Sender,
+		DcAddr:         packetData.Receiver,
+		ScPort:         scPort,
+		ScChannel:      scChannel,
+		ScConnectionId: scConnection,
+		ScChain:        scChain,
+		DcPort:         dcPort,
+		DcChannel:      dcChannel,
+		DcChain:        dcChain,
+		Sequence:       sequence,
+		Status:         ibcTxStatus,
+		ScTxInfo: &entity.TxInfo{
+			Hash:      tx.TxHash,
+			Status:    tx.Status,
+			Time:      tx.Time,
+			Height:    tx.Height,
+			Fee:       tx.Fee,
+			MsgAmount: token,
+			Msg:       transferMsg,
+			Memo:      tx.Memo,
+			Signers:   tx.Signers,
+			Log:       tx.Log,
+		},
+		Denoms: &entity.Denoms{
+			ScDenom: scDenom,
+		},
+		BaseDenom:      parent.BaseDenom,
+		BaseDenomChain: parent.BaseDenomChain,
+		Tokens:         transferTokens,
+		ParentRecordId: parent.RecordId,
+		JourneyId:      parent.JourneyId,
+		NextTryTime:    nowUnix,
+		CreateAt:       nowUnix,
+		UpdateAt:       nowUnix,
+	}
+	if cf, ok := w.chainMap[scChain]; ok {
+		forwardTx.ScClientId = cf.GetChannelClient(scPort, scChannel)
+	}
+	if cf, ok := w.chainMap[dcChain]; ok {
+		forwardTx.DcClientId = cf.GetChannelClient(dcPort, dcChannel)
+	}
+	return forwardTx
 }
 
 func (w *ibcTxRelateWorker) loadAckPacketTx(ibcTx *entity.ExIbcTx, tx *entity.Tx) {
@@ -393,7 +662,7 @@ func (w *ibcTxRelateWorker) loadTimeoutPacketTx(ibcTx *entity.ExIbcTx, tx *entit
 		}
 	}
 
-	if ibcTx.Status != entity.IbcTxStatusProcessing {
+	if ibcTx.Status == entity.IbcTxStatusRefunded {
 		var matchRecvTx *entity.Tx
 		var matchRecvTxMsg *model.TxMsg
 		for _, recvTx := range recvSyncTxs {
@@ -492,20 +761,11 @@ func (w *ibcTxRelateWorker) packetIdTx(scChain string, ibcTxList []*entity.ExIbc
 		for _, packet := range packetIds { // recv && refunded
 			packetIdRecordMap[dcChain+packet.PacketId] = packet.ObjectId
 			recvPacketIds = append(recvPacketIds, packet.PacketId)
-			timeoutStr := strconv.FormatInt(packet.TimeOutTime, 10)
-			if len(timeoutStr) > 10 { // 非秒级时间
-				if len(timeoutStr) == 19 && time.Now().UnixNano() > packet.TimeOutTime { // Nano
-					timeoutTxPacketIds = append(timeoutTxPacketIds, packet.PacketId)
-					timeoutIbcTxMap[packet.ObjectId] = struct{}{}
-				} else {
-					//logrus.Warningf("unkonwn timeout time %s, chain: %s, packet id: %s", timeoutStr, dcChain, packet.PacketId)
-					timeoutTxPacketIds = append(timeoutTxPacketIds, packet.PacketId)
-				}
-			} else if latestBlock != nil {
-				if latestBlock.Height > packet.TimeoutHeight || latestBlock.Time > packet.TimeOutTime {
-					timeoutTxPacketIds = append(timeoutTxPacketIds, packet.PacketId)
-					timeoutIbcTxMap[packet.ObjectId] = struct{}{}
-				}
+			if latestBlock == nil { // dc chain 最新块未知时, 仍查询 timeout_packet tx
+				timeoutTxPacketIds = append(timeoutTxPacketIds, packet.PacketId)
+			} else if packetExpired(packet.TimeoutHeight, packet.TimeOutTime, latestBlock) {
+				timeoutTxPacketIds = append(timeoutTxPacketIds, packet.PacketId)
+				timeoutIbcTxMap[packet.ObjectId] = struct{}{}
 			}
 		}
 
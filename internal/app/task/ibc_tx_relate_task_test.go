@@ -1,16 +1,25 @@
 package task
 
 import (
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"context"
 	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ibctool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func Test_IbxTxRelateTask(t *testing.T) {
-	new(IbcTxRelateTask).Run()
+	new(IbcTxRelateTask).Run(context.Background())
 }
 
 func Test_IbxTxRelateHistoryTask(t *testing.T) {
-	new(IbcTxRelateHistoryTask).Run()
+	new(IbcTxRelateHistoryTask).Run(context.Background())
 }
 
 func Test_HandlerIbcTxs(t *testing.T) {
@@ -23,6 +32,176 @@ func Test_HandlerIbcTxs(t *testing.T) {
 	ibcTxList, _ := w.handleSourceTx(chain, txList, denomMap)
 
 	rw := newIbcTxRelateWorker("relate", "worker", ibcTxTargetLatest, chainMap)
-	rw.handlerIbcTxs(chain, ibcTxList, denomMap)
+	rw.handlerIbcTxs(context.Background(), chain, ibcTxList, denomMap)
 	t.Log(utils.MustMarshalJsonToStr(ibcTxList))
 }
+
+func Test_LoadForwardTx(t *testing.T) {
+	packetId := "transferchannel-0transferchannel-11"
+	recvTx := &entity.Tx{
+		TxHash: "forward_recv_tx",
+		Status: entity.TxStatusSuccess,
+		DocTxMsgs: []*model.TxMsg{{
+			Type: constant.MsgTypeRecvPacket,
+			Msg: bson.M{
+				"packet_id": packetId,
+				"packet": bson.M{
+					"data": bson.M{
+						"denom":    "uatom",
+						"amount":   "100",
+						"sender":   "cosmos1sender",
+						"receiver": "osmo1middle",
+						"memo":     `{"forward":{"receiver":"juno1receiver","port":"transfer","channel":"channel-42"}}`,
+					},
+				},
+			},
+		}},
+		EventsNew: []entity.EventNew{{
+			MsgIndex: 0,
+			Events: []entity.Event{{
+				Type: "send_packet",
+				Attributes: []entity.KvPair{
+					{Key: "packet_src_port", Value: "transfer"},
+					{Key: "packet_src_channel", Value: "channel-42"},
+					{Key: "packet_dst_port", Value: "transfer"},
+					{Key: "packet_dst_channel", Value: "channel-7"},
+					{Key: "packet_sequence", Value: "9"},
+					{Key: "packet_timeout_timestamp", Value: "1700000000000000000"},
+					{Key: "packet_data", Value: `{"amount":"100","denom":"transfer/channel-1/uatom","receiver":"juno1receiver","sender":"osmo1middle"}`},
+				},
+			}},
+		}},
+	}
+	parent := &entity.ExIbcTx{
+		RecordId: "parent_record",
+		ScChain:  "cosmoshub",
+		DcChain:  "osmosis",
+		ScTxInfo: &entity.TxInfo{Msg: &model.TxMsg{Type: constant.MsgTypeTransfer, Msg: bson.M{"packet_id": packetId}}},
+	}
+
+	rw := newIbcTxRelateWorker("relate", "worker", ibcTxTargetLatest, nil)
+	forwardTx := rw.loadForwardTx(parent, []*entity.Tx{recvTx})
+	if forwardTx == nil {
+		t.Fatal("forward tx not found")
+	}
+	if parent.JourneyId != parent.RecordId || forwardTx.JourneyId != parent.RecordId || forwardTx.ParentRecordId != parent.RecordId {
+		t.Fatalf("journey link error, parent: %s, forward: %s", parent.JourneyId, forwardTx.JourneyId)
+	}
+	if forwardTx.ScChain != "osmosis" || forwardTx.ScTxInfo.Msg.TransferMsg().PacketId != "transferchannel-42transferchannel-79" {
+		t.Fatalf("forward tx error, %s", utils.MustMarshalJsonToStr(forwardTx))
+	}
+	t.Log(utils.MustMarshalJsonToStr(forwardTx))
+}
+
+func Test_UpdateExpiredStatus(t *testing.T) {
+	latestBlock := &dto.HeightTimeDTO{Height: 1000, Time: 1672531200}
+	if !packetExpired(1000, 0, latestBlock) || !packetExpired(0, 1672531100000000000, latestBlock) {
+		t.Fatal("expired packet is not detected")
+	}
+	if packetExpired(0, 0, latestBlock) || packetExpired(1001, 1672531300000000000, latestBlock) {
+		t.Fatal("unexpired packet is detected as expired")
+	}
+
+	ibcTx := &entity.ExIbcTx{Id: primitive.NewObjectID(), Status: entity.IbcTxStatusProcessing}
+	timeoutMap := map[string]struct{}{ibcTx.Id.Hex(): {}}
+	rw := newIbcTxRelateWorker("relate", "worker", ibcTxTargetLatest, nil)
+	rw.updateExpiredStatus(ibcTx, timeoutMap, map[string]struct{}{})
+	if ibcTx.Status != entity.IbcTxStatusExpired {
+		t.Fatalf("status error, %d", ibcTx.Status)
+	}
+
+	// received before timeout, waiting for ack
+	rw.updateExpiredStatus(ibcTx, timeoutMap, timeoutMap)
+	if ibcTx.Status != entity.IbcTxStatusProcessing {
+		t.Fatalf("status error, %d", ibcTx.Status)
+	}
+}
+
+func Test_DeadLetterReason(t *testing.T) {
+	ibcTx := &entity.ExIbcTx{
+		ScChain:     "cosmoshub",
+		DcChain:     "osmosis",
+		ProcessInfo: constant.NoFoundSuccessTimeoutPacket,
+		ScTxInfo:    &entity.TxInfo{Msg: &model.TxMsg{Type: constant.MsgTypeTransfer, Msg: bson.M{"packet_id": "transferchannel-0transferchannel-11"}}},
+	}
+	reason, packetIds := deadLetterReason(ibcTx)
+	if reason != entity.DeadLetterReasonTimeoutPacketNotFound || len(packetIds) != 2 || packetIds[1].Chain != "cosmoshub" {
+		t.Fatalf("dead letter reason error, %s %+v", reason, packetIds)
+	}
+
+	ibcTx.DcChain = ""
+	if reason, _ = deadLetterReason(ibcTx); reason != entity.DeadLetterReasonDcChainNotFound {
+		t.Fatalf("dead letter reason error, %s", reason)
+	}
+
+	ibcTx.RetryTimes = ibcTxDeadLetterRetryTimes - 1
+	ibcTx.Status = entity.IbcTxStatusProcessing
+	if shouldMoveToDeadLetter(ibcTx) {
+		t.Fatal("processing ibc tx with retries left is moved to dead letter")
+	}
+	ibcTx.RetryTimes = ibcTxDeadLetterRetryTimes
+	if !shouldMoveToDeadLetter(ibcTx) {
+		t.Fatal("processing ibc tx exhausts retries but is kept")
+	}
+	defer func(retryTimes int64) { taskConf.IbcTxDeadLetterRetryTimes = retryTimes }(taskConf.IbcTxDeadLetterRetryTimes)
+	taskConf.IbcTxDeadLetterRetryTimes = ibcTxDeadLetterRetryTimes + 100
+	if shouldMoveToDeadLetter(ibcTx) {
+		t.Fatal("the configured retry times is ignored")
+	}
+	ibcTx.RetryTimes = taskConf.IbcTxDeadLetterRetryTimes
+	if !shouldMoveToDeadLetter(ibcTx) {
+		t.Fatal("processing ibc tx exhausts the configured retries but is kept")
+	}
+	ibcTx.Status = entity.IbcTxStatusExpired
+	if shouldMoveToDeadLetter(ibcTx) {
+		t.Fatal("expired ibc tx is moved to dead letter")
+	}
+}
+
+func Test_LoadRecvPacketDenoms(t *testing.T) {
+	var packet model.Packet
+	packet.SourcePort, packet.SourceChannel = "transfer", "channel-1"
+	packet.DestinationPort, packet.DestinationChannel = "transfer", "channel-2"
+	packet.Data.Tokens = []model.PacketToken{
+		{Denom: model.PacketDenom{Base: "uatom"}, Amount: "100"},
+		{Denom: model.PacketDenom{Base: "uosmo", Trace: []model.PacketHop{{PortId: "transfer", ChannelId: "channel-1"}}}, Amount: "200"},
+	}
+	ibcTx := &entity.ExIbcTx{
+		ScChain: "cosmoshub",
+		DcChain: "osmosis",
+		Status:  entity.IbcTxStatusSuccess,
+		Denoms:  &entity.Denoms{ScDenom: "uatom"},
+		Tokens: []*entity.TransferToken{
+			{ScDenom: "uatom", BaseDenom: "uatom", BaseDenomChain: "cosmoshub", Amount: "100"},
+			{ScDenom: "ibc/uosmo", BaseDenom: "uosmo", BaseDenomChain: "osmosis", Amount: "200"},
+		},
+	}
+
+	rw := newIbcTxRelateWorker("relate", "worker", ibcTxTargetLatest, nil)
+	denomList := rw.loadRecvPacketDenoms(ibcTx, packet)
+	// uosmo crosses back to osmosis, only the denom of uatom is new
+	if len(denomList) != 1 || denomList[0].BaseDenom != "uatom" || denomList[0].Denom != ibcTx.Denoms.DcDenom {
+		t.Fatalf("denom list error, %s", utils.MustMarshalJsonToStr(denomList))
+	}
+	if ibcTx.Tokens[0].DcDenom != ibcTx.Denoms.DcDenom || ibcTx.Tokens[1].DcDenom != "uosmo" {
+		t.Fatalf("tokens dc denom error, %s", utils.MustMarshalJsonToStr(ibcTx.Tokens))
+	}
+}
+
+func Test_ClassifyAckError(t *testing.T) {
+	cases := []struct {
+		msgs     []string
+		category entity.IbcTxErrorCategory
+	}{
+		{[]string{`{"error":"ABCI code: 7: error handling packet: see events for details"}`, "decoding bech32 failed: invalid checksum"}, entity.IbcTxErrorInvalidReceiver},
+		{[]string{`{"error":"ABCI code: 5: error handling packet: see events for details"}`, "unable to unescrow tokens: spendable balance 0uatom is smaller than 100uatom: insufficient funds"}, entity.IbcTxErrorInsufficientEscrow},
+		{[]string{`{"error":"rate limit exceeded"}`}, entity.IbcTxErrorRateLimitExceeded},
+		{[]string{"out of gas in location: WriteFlat; gasWanted: 200000, gasUsed: 201000: out of gas"}, entity.IbcTxErrorOutOfGas},
+		{[]string{`{"error":"ABCI code: 1: error handling packet: see events for details"}`}, entity.IbcTxErrorUnknown},
+	}
+	for _, v := range cases {
+		if category := ibctool.ClassifyAckError(v.msgs...); category != v.category {
+			t.Fatalf("classify %v, expect %s, got %s", v.msgs, v.category, category)
+		}
+	}
+}
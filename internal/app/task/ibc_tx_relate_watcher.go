@@ -0,0 +1,282 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	relateWatchName          = "ibc_tx_relate_watcher"
+	relateWatchBatchSize     = 100
+	relateWatchBatchWait     = time.Second
+	relateWatchRetryInterval = time.Minute
+	relateWatchLockExpire    = time.Minute
+	// https://www.mongodb.com/docs/manual/reference/error-codes/ ChangeStreamHistoryLost
+	changeStreamHistoryLostCode = 286
+)
+
+var relateWatchMsgTypes = []string{constant.MsgTypeRecvPacket, constant.MsgTypeAcknowledgement, constant.MsgTypeTimeoutPacket}
+
+// IbcTxRelateWatcher relate ibc txs as soon as recv/ack/timeout txs are inserted into sync_{chain}_tx, by change streams.
+// change streams are only available on replica set. IbcTxRelateTask keeps running as the fallback path, it relates the
+// txs missed while a stream is broken and the transfers synced after their recv txs.
+type IbcTxRelateWatcher struct {
+	mux      sync.Mutex
+	watching map[string]struct{}
+}
+
+// StartIbcTxRelateWatcher watch all chains, chains added later are watched on the next refresh. the watcher stops
+// when ctx is done
+func StartIbcTxRelateWatcher(ctx context.Context) {
+	if !taskConf.SwitchIbcTxRelateWatch {
+		return
+	}
+
+	watcher := &IbcTxRelateWatcher{
+		watching: make(map[string]struct{}),
+	}
+	watchWaitGroup.Add(1)
+	go func() {
+		defer watchWaitGroup.Done()
+		for {
+			watcher.refresh(ctx)
+			select {
+			case <-time.After(ThreeMinute * time.Second):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (t *IbcTxRelateWatcher) refresh(ctx context.Context) {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", relateWatchName, err)
+		return
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	for chain, cf := range chainMap {
+		if cf.Status == entity.ChainStatusClosed {
+			continue
+		}
+		if _, ok := t.watching[chain]; ok {
+			continue
+		}
+		t.watching[chain] = struct{}{}
+		watchWaitGroup.Add(1)
+		go newRelateWatchWorker(chain).run(ctx)
+	}
+}
+
+// =========================================================================
+// =========================================================================
+// worker
+
+func newRelateWatchWorker(chain string) *relateWatchWorker {
+	return &relateWatchWorker{
+		chain:    chain,
+		taskName: fmt.Sprintf(entity.RelateWatchNameFmt, chain),
+		lockKey:  fmt.Sprintf("%s:%s", "task", fmt.Sprintf(entity.RelateWatchNameFmt, chain)),
+	}
+}
+
+type relateWatchWorker struct {
+	chain    string
+	taskName string
+	lockKey  string
+	lock     *chainLease
+}
+
+// run keep the stream alive, a broken stream is reopened after relateWatchRetryInterval. it returns on shutdown
+func (w *relateWatchWorker) run(ctx context.Context) {
+	defer watchWaitGroup.Done()
+	for ctx.Err() == nil {
+		if lock, err := acquireLease(w.lockKey, relateWatchLockExpire); err == nil {
+			w.lock = lock
+			if err = w.watch(ctx); err != nil {
+				logrus.Errorf("task %s chain %s change stream broken, fall back to cron relate task, %v", relateWatchName, w.chain, err)
+			}
+			lock.release()
+			w.lock = nil
+		} // else watched by another instance
+
+		select {
+		case <-time.After(relateWatchRetryInterval):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (w *relateWatchWorker) watch(ctx context.Context) error {
+	taskRecord, err := w.checkTaskRecord()
+	if err != nil {
+		return err
+	}
+	if taskRecord.Status == entity.TaskRecordStatusClose {
+		return nil
+	}
+
+	stream, err := txRepo.WatchInsertTx(ctx, w.chain, relateWatchMsgTypes, taskRecord.ResumeToken)
+	if err != nil {
+		if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.Code == changeStreamHistoryLostCode {
+			// the resume token is out of oplog, txs before now are left to cron relate task
+			logrus.Warningf("task %s chain %s resume token is lost, watch from now", relateWatchName, w.chain)
+			_ = taskRecordRepo.UpdateResumeToken(w.taskName, nil)
+		}
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	logrus.Infof("task %s chain %s change stream opened", relateWatchName, w.chain)
+	var txList []*entity.Tx
+	var batchStart time.Time
+	for {
+		if ctx.Err() != nil {
+			// txs of the unfinished batch are after the resume token, they are watched again on the next start
+			return nil
+		}
+		if w.lock.lost() {
+			return fmt.Errorf("lock %s is lost", w.lockKey)
+		}
+
+		if stream.TryNext(ctx) {
+			var evt struct {
+				FullDocument *entity.Tx `bson:"fullDocument"`
+			}
+			if err = stream.Decode(&evt); err != nil {
+				return err
+			}
+			if len(txList) == 0 {
+				batchStart = time.Now()
+			}
+			txList = append(txList, evt.FullDocument)
+		} else {
+			if err = stream.Err(); err != nil && ctx.Err() == nil {
+				return err
+			}
+			if len(txList) == 0 {
+				time.Sleep(200 * time.Millisecond)
+			}
+		}
+
+		// wait a moment for the batch, it also avoids master-slave delay problem
+		if len(txList) >= relateWatchBatchSize || (len(txList) > 0 && time.Since(batchStart) >= relateWatchBatchWait) {
+			if err = w.relate(ctx, txList); err != nil {
+				return err
+			}
+			txList = txList[:0]
+			if err = taskRecordRepo.UpdateResumeToken(w.taskName, stream.ResumeToken()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// relate find the processing ibc txs of the inserted packet txs, and relate them the same way as cron relate task does
+func (w *relateWatchWorker) relate(ctx context.Context, txList []*entity.Tx) error {
+	var recvPacketIds, ackPacketIds []string
+	for _, tx := range txList {
+		for _, msg := range tx.DocTxMsgs {
+			switch msg.Type {
+			case constant.MsgTypeRecvPacket:
+				recvPacketIds = append(recvPacketIds, msg.CommonMsg().PacketId)
+			case constant.MsgTypeAcknowledgement, constant.MsgTypeTimeoutPacket:
+				ackPacketIds = append(ackPacketIds, msg.CommonMsg().PacketId)
+			}
+		}
+	}
+
+	var ibcTxList []*entity.ExIbcTx
+	if len(recvPacketIds) > 0 {
+		res, err := ibcTxRepo.FindProcessingTxsByPacketIds("", w.chain, recvPacketIds)
+		if err != nil {
+			logrus.Errorf("task %s chain %s find recv packet ibc txs error, %v", relateWatchName, w.chain, err)
+			return err
+		}
+		ibcTxList = append(ibcTxList, res...)
+	}
+	if len(ackPacketIds) > 0 {
+		res, err := ibcTxRepo.FindProcessingTxsByPacketIds(w.chain, "", ackPacketIds)
+		if err != nil {
+			logrus.Errorf("task %s chain %s find ack packet ibc txs error, %v", relateWatchName, w.chain, err)
+			return err
+		}
+		ibcTxList = append(ibcTxList, res...)
+	}
+	if len(ibcTxList) == 0 {
+		return nil
+	}
+
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", relateWatchName, err)
+		return err
+	}
+
+	// relate worker handles ibc txs of one source chain
+	scChainTxsMap := make(map[string][]*entity.ExIbcTx)
+	idMap := make(map[string]struct{}, len(ibcTxList))
+	for _, v := range ibcTxList {
+		if _, ok := idMap[v.Id.Hex()]; ok {
+			continue
+		}
+		idMap[v.Id.Hex()] = struct{}{}
+		scChainTxsMap[v.ScChain] = append(scChainTxsMap[v.ScChain], v)
+	}
+
+	rw := newIbcTxRelateWorker(relateWatchName, w.chain, ibcTxTargetLatest, chainMap)
+	relateTaskName := new(IbcTxRelateTask).Name()
+	for scChain, txs := range scChainTxsMap {
+		// the sc chain is shared with the workers of cron relate task, the txs are left to the holder of the lease
+		lease, err := acquireChainLease(relateTaskName, scChain)
+		if err != nil {
+			logrus.Debugf("task %s chain %s sc chain %s is leased by cron relate task", relateWatchName, w.chain, scChain)
+			continue
+		}
+		denomMap, err := rw.getChainDenomMap(scChain)
+		if err != nil {
+			lease.release()
+			return err
+		}
+		rw.handlerIbcTxs(ctx, scChain, txs, denomMap)
+		lease.release()
+	}
+	logrus.Debugf("task %s chain %s related %d ibc txs", relateWatchName, w.chain, len(idMap))
+	return nil
+}
+
+func (w *relateWatchWorker) checkTaskRecord() (*entity.IbcTaskRecord, error) {
+	taskRecord, err := taskRecordRepo.FindByTaskName(w.taskName)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			logrus.Errorf("task %s checkTaskRecord %s error, %v", relateWatchName, w.chain, err)
+			return nil, err
+		}
+
+		taskRecord = &entity.IbcTaskRecord{
+			TaskName: w.taskName,
+			Status:   entity.TaskRecordStatusOpen,
+			CreateAt: time.Now().Unix(),
+			UpdateAt: time.Now().Unix(),
+		}
+		if err := taskRecordRepo.Insert(taskRecord); err != nil {
+			logrus.Errorf("task %s checkTaskRecord %s error, %v", relateWatchName, w.chain, err)
+			return nil, err
+		}
+	}
+
+	return taskRecord, nil
+}
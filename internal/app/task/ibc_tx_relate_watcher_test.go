@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Test_RelateWatch change streams need a replica set, start a local single-node one by:
+//
+//	mongod --replSet rs0 --dbpath /tmp/rs0 && mongosh --eval "rs.initiate()"
+//	RELATE_WATCH_MONGO_URL="mongodb://127.0.0.1:27017/?replicaSet=rs0" go test -run Test_RelateWatch ./internal/app/task
+func Test_RelateWatch(t *testing.T) {
+	mongoUrl := os.Getenv("RELATE_WATCH_MONGO_URL")
+	if mongoUrl == "" {
+		t.Skip("RELATE_WATCH_MONGO_URL is not set")
+	}
+	repository.InitMgo(conf.Mongo{
+		Url:      mongoUrl,
+		Database: "iobscan-ibc-watch-test",
+	}, context.Background())
+
+	chain := "watch_test"
+	w := newRelateWatchWorker(chain)
+	_ = taskRecordRepo.UpdateResumeToken(w.taskName, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	go func() {
+		_ = w.watch(ctx)
+	}()
+	time.Sleep(2 * time.Second)
+
+	recvTx := &entity.Tx{
+		Height: 1,
+		TxHash: fmt.Sprintf("watch_recv_tx_%d", time.Now().UnixNano()),
+		Status: entity.TxStatusSuccess,
+		Types:  []string{constant.MsgTypeRecvPacket},
+		DocTxMsgs: []*model.TxMsg{{
+			Type: constant.MsgTypeRecvPacket,
+			Msg:  bson.M{"packet_id": "transferchannel-0transferchannel-11"},
+		}},
+	}
+	if _, err := repository.GetDatabase().Collection(entity.Tx{}.CollectionName(chain)).InsertOne(ctx, recvTx); err != nil {
+		t.Fatal(err)
+	}
+
+	for ctx.Err() == nil {
+		taskRecord, err := taskRecordRepo.FindByTaskName(w.taskName)
+		if err == nil && len(taskRecord.ResumeToken) > 0 {
+			t.Log(taskRecord.ResumeToken.String())
+			return
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	t.Fatal("resume token is not persisted")
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/distributiontask"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// runCtx the context of the runs started by GoRun, it is canceled by Shutdown. the scheduled runs have the context
+	// of the scheduler
+	runCtx, cancelRuns = context.WithCancel(context.Background())
+	// watchWaitGroup goroutines of IbcTxRelateWatcher, the scheduled runs are waited by DistributedTask.Stop
+	watchWaitGroup sync.WaitGroup
+	// runWaitGroup admin and one-off runs started by GoRun
+	runWaitGroup sync.WaitGroup
+	runMux       sync.Mutex
+)
+
+// GoRun run fn in a goroutine which Shutdown waits for, the ctx of fn is canceled by Shutdown. fn is not run and
+// false is returned if the instance is shutting down
+func GoRun(fn func(ctx context.Context)) bool {
+	runMux.Lock()
+	defer runMux.Unlock()
+	if runCtx.Err() != nil {
+		return false
+	}
+
+	runWaitGroup.Add(1)
+	go func() {
+		defer runWaitGroup.Done()
+		fn(runCtx)
+	}()
+	return true
+}
+
+// Shutdown cancel the running tasks and wait for them to return, the locks and chain leases are released by the
+// runs as they return, the ones still held when ctx is done are released anyway
+func Shutdown(ctx context.Context, d *distributiontask.DistributedTask) {
+	runMux.Lock()
+	cancelRuns()
+	runMux.Unlock()
+
+	if d != nil {
+		if err := d.Stop(ctx); err != nil {
+			logrus.Errorf("stop distributed tasks error, %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		watchWaitGroup.Wait()
+		runWaitGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logrus.Errorf("wait for %s and admin runs error, %v", relateWatchName, ctx.Err())
+	}
+
+	releaseHeldLeases()
+	logrus.Info("all tasks are stopped")
+}
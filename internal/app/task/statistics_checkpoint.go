@@ -0,0 +1,203 @@
+package task
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	checkpointScopeHistory = "history"
+	checkpointScopeLatest  = "latest"
+)
+
+var errStatisticsInterrupted = fmt.Errorf("statistics is interrupted by shutdown")
+
+// statisticsCheckpoint progress of a full statistics run. the run saves a checkpoint of the scope after each segment
+// and resumes from it after a restart, the segments written to the new collection are swapped by segment, so the
+// segment after the checkpoint is safe to write again.
+type statisticsCheckpoint struct {
+	mux          sync.Mutex
+	taskName     string
+	runStartTime int64
+	scopes       map[string]*entity.IbcStatisticsCheckpoint
+	segments     map[string][]*segment
+	total        int
+	done         int
+}
+
+// statisticsNewColl the new collection written by a full statistics run
+type statisticsNewColl interface {
+	ExistsNew() (bool, error)
+}
+
+func statisticsCheckpointExpiration() int64 {
+	if taskConf.StatisticsCheckpointMaxAge > 0 {
+		return taskConf.StatisticsCheckpointMaxAge
+	}
+	return statisticsCheckpointMaxAge
+}
+
+// loadStatisticsCheckpoint the checkpoints left by the last run of the task, none if the last run is finished
+func loadStatisticsCheckpoint(taskName string) (*statisticsCheckpoint, error) {
+	list, err := statisticsCheckpointRepo.FindByTaskName(taskName)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &statisticsCheckpoint{
+		taskName:     taskName,
+		runStartTime: time.Now().Unix(),
+		scopes:       make(map[string]*entity.IbcStatisticsCheckpoint, len(list)),
+		segments:     make(map[string][]*segment),
+	}
+	for _, v := range list {
+		c.scopes[v.Scope] = v
+		if v.RunStartTime < c.runStartTime {
+			c.runStartTime = v.RunStartTime
+		}
+	}
+	return c, nil
+}
+
+// resume whether the run resumes an interrupted one, a new run should start with empty new collections. the
+// checkpoints are discarded if the interrupted run started more than statistics_checkpoint_max_age ago, one of its new
+// collections is gone, or the segments of a scope are split differently now.
+func (c *statisticsCheckpoint) resume(scopeSegments map[string][]*segment, newColls ...statisticsNewColl) (bool, error) {
+	c.mux.Lock()
+	for scope, segments := range scopeSegments {
+		c.segments[scope] = segments
+	}
+	if len(c.scopes) == 0 {
+		c.mux.Unlock()
+		return false, nil
+	}
+	reason := c.staleReason()
+	c.mux.Unlock()
+
+	if reason == "" {
+		for _, v := range newColls {
+			exists, err := v.ExistsNew()
+			if err != nil {
+				return false, err
+			}
+			if !exists {
+				reason = "the new collection is gone"
+				break
+			}
+		}
+	}
+	if reason == "" {
+		return true, nil
+	}
+
+	logrus.Warningf("task %s discard the checkpoints of the run started at %d, %s", c.taskName, c.runStartTime, reason)
+	if err := c.clear(); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func (c *statisticsCheckpoint) staleReason() string {
+	if time.Now().Unix()-c.runStartTime > statisticsCheckpointExpiration() {
+		return "the run is too old"
+	}
+	for scope, cp := range c.scopes {
+		segments := c.segments[scope]
+		if len(segments) == 0 {
+			continue
+		}
+		if cp.FirstSegmentStartTime != segments[0].StartTime || cp.SegmentStep != segmentStep(segments[0]) {
+			return fmt.Sprintf("the segments of %s are changed", scope)
+		}
+	}
+	return ""
+}
+
+func segmentStep(seg *segment) int64 {
+	return seg.EndTime - seg.StartTime + 1
+}
+
+// pending the segments of the scope after its checkpoint, all the segments are counted into the progress
+func (c *statisticsCheckpoint) pending(scope string, segments []*segment) []*segment {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.total += len(segments)
+	c.segments[scope] = segments
+	addTaskRunCounter(c.taskName, scope, "segments_total", int64(len(segments)))
+
+	cp, ok := c.scopes[scope]
+	if !ok {
+		return segments
+	}
+	if cp.Finished {
+		c.done += len(segments)
+		addTaskRunCounter(c.taskName, scope, "segments_done", int64(len(segments)))
+		return nil
+	}
+
+	res := make([]*segment, 0, len(segments))
+	for _, v := range segments {
+		if v.StartTime <= cp.SegmentStartTime {
+			c.done++
+			addTaskRunCounter(c.taskName, scope, "segments_done", 1)
+			continue
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+// complete save the segment as the checkpoint of the scope
+func (c *statisticsCheckpoint) complete(scope string, seg *segment) error {
+	return c.save(scope, seg, false)
+}
+
+// finish all the segments of the scope are completed
+func (c *statisticsCheckpoint) finish(scope string) error {
+	return c.save(scope, nil, true)
+}
+
+func (c *statisticsCheckpoint) save(scope string, seg *segment, finished bool) error {
+	c.mux.Lock()
+	nowUnix := time.Now().Unix()
+	cp, ok := c.scopes[scope]
+	if !ok {
+		cp = &entity.IbcStatisticsCheckpoint{
+			TaskName:     c.taskName,
+			Scope:        scope,
+			RunStartTime: c.runStartTime,
+			CreateAt:     nowUnix,
+		}
+		c.scopes[scope] = cp
+	}
+	if segments := c.segments[scope]; len(segments) > 0 {
+		cp.FirstSegmentStartTime = segments[0].StartTime
+		cp.SegmentStep = segmentStep(segments[0])
+	}
+	if seg != nil {
+		cp.SegmentStartTime = seg.StartTime
+		cp.SegmentEndTime = seg.EndTime
+		c.done++
+		addTaskRunCounter(c.taskName, scope, "segments_done", 1)
+	}
+	cp.Finished = finished
+	cp.UpdateAt = nowUnix
+	done, total := c.done, c.total
+	c.mux.Unlock()
+
+	setTaskRunProgress(c.taskName, done, total)
+	return statisticsCheckpointRepo.Save(cp)
+}
+
+// clear the run is finished and its new collections are switched in, or the checkpoints are discarded
+func (c *statisticsCheckpoint) clear() error {
+	c.mux.Lock()
+	c.scopes = make(map[string]*entity.IbcStatisticsCheckpoint)
+	c.runStartTime = time.Now().Unix()
+	c.mux.Unlock()
+	return statisticsCheckpointRepo.DeleteByTaskName(c.taskName)
+}
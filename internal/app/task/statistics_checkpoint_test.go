@@ -0,0 +1,59 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+func Test_StatisticsCheckpointPending(t *testing.T) {
+	segments := segmentTool(segmentStepLatest, 1656950400, 1656950400+5*segmentStepLatest-1)
+	c := &statisticsCheckpoint{
+		taskName: "statistics_checkpoint_test",
+		scopes: map[string]*entity.IbcStatisticsCheckpoint{
+			checkpointScopeHistory: {Scope: checkpointScopeHistory, Finished: true},
+			checkpointScopeLatest:  {Scope: checkpointScopeLatest, SegmentStartTime: segments[1].StartTime},
+		},
+		segments: map[string][]*segment{},
+	}
+
+	if res := c.pending(checkpointScopeHistory, segments); len(res) != 0 {
+		t.Fatalf("history pending: %d", len(res))
+	}
+	if res := c.pending(checkpointScopeLatest, segments); len(res) != len(segments)-2 || res[0].StartTime != segments[2].StartTime {
+		t.Fatalf("latest pending: %d", len(res))
+	}
+	if res := c.pending("irishub_qa", segments); len(res) != len(segments) {
+		t.Fatalf("chain pending: %d", len(res))
+	}
+	if c.total != 3*len(segments) || c.done != len(segments)+2 {
+		t.Fatalf("total: %d, done: %d", c.total, c.done)
+	}
+}
+
+func Test_StatisticsCheckpointStale(t *testing.T) {
+	segments := segmentTool(segmentStepLatest, 1656950400, 1656950400+5*segmentStepLatest-1)
+	c := &statisticsCheckpoint{
+		taskName:     "statistics_checkpoint_test",
+		runStartTime: time.Now().Unix() - OneDay,
+		scopes: map[string]*entity.IbcStatisticsCheckpoint{
+			checkpointScopeLatest: {Scope: checkpointScopeLatest, FirstSegmentStartTime: segments[0].StartTime, SegmentStep: segmentStepLatest},
+		},
+		segments: map[string][]*segment{checkpointScopeLatest: segments},
+	}
+	if reason := c.staleReason(); reason != "" {
+		t.Fatalf("checkpoint is stale, %s", reason)
+	}
+
+	c.segments[checkpointScopeLatest] = segments[1:]
+	if c.staleReason() == "" {
+		t.Fatal("segments are changed but the checkpoint is not stale")
+	}
+
+	c.segments[checkpointScopeLatest] = segments
+	c.runStartTime = time.Now().Unix() - statisticsCheckpointExpiration() - 1
+	if c.staleReason() == "" {
+		t.Fatal("run is too old but the checkpoint is not stale")
+	}
+}
@@ -1,27 +1,30 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/distributiontask"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/sirupsen/logrus"
 )
 
 // Task cron task
 type Task interface {
 	Name() string
-	Cron() int // CronExpression
-	Run() int
+	Cron() int // seconds, see distributedTask.Cron
+	Run(ctx context.Context) int
 	//ExpireTime() time.Duration // redis expireTime
 }
 
 var (
-	tasks    []Task
-	taskConf conf.Task
+	tasks     []Task
+	taskConf  conf.Task
+	scheduler *distributiontask.DistributedTask
 )
 
 func RegisterTasks(task ...Task) {
@@ -33,53 +36,137 @@ func GetTasks() []Task {
 	return tasks
 }
 
+// GetTask get the registered task by name
+func GetTask(name string) (Task, bool) {
+	for _, v := range tasks {
+		if v.Name() == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
 func LoadTaskConf(taskCfg conf.Task) {
 	taskConf = taskCfg
 }
 
-func Start() {
-	if len(GetTasks()) == 0 {
-		return
+// Start run all the tasks on the distributed scheduler, the lock of a task is renewed while it is running.
+func Start(d *distributiontask.DistributedTask) {
+	if len(GetTasks()) > 0 {
+		_ibcChainConfigTask.Run(d.Context()) // run chain config task immediately
 	}
-
-	_ibcChainConfigTask.Run() // run chain config task immediately
 	for _, v := range GetTasks() {
-		task := v
-		RunOnce(task)
+		d.RegisterTasks(&distributedTask{task: v})
 	}
 
-	//c := cron.New(cron.WithSeconds())
-	//if taskConf.CronJobRelayerAddr == "" {
-	//	taskConf.CronJobRelayerAddr = ThreeHourCronJobTime
-	//}
-	//_, err := c.AddFunc(taskConf.CronJobRelayerAddr, checkAndUpdateRelayerSrcChainAddr)
-	//if err != nil {
-	//	logrus.Fatal("cron job err", err)
-	//}
-	//c.Start()
-}
-
-func RunOnce(task Task) {
 	redisLockExpireTime := time.Duration(RedisLockExpireTime) * time.Second
 	if taskConf.RedisLockExpireTime > 0 {
 		redisLockExpireTime = time.Duration(taskConf.RedisLockExpireTime) * time.Second
 	}
+	d.SetExpiration(redisLockExpireTime)
+	d.SetRunOnStart(true)
+	d.SetBeforeRun(beforeDistributedRun)
+	for _, v := range d.Tasks() {
+		reportTaskSchedule(v)
+	}
+	scheduler = d
+	d.Start()
+}
 
-	utils.RunTimer(task.Cron(), utils.Sec, func() {
-		//lock redis mux
-		lockKey := fmt.Sprintf("%s:%s", "task", task.Name())
-		if err := cache.GetRedisClient().Lock(lockKey, time.Now().Unix(), redisLockExpireTime); err != nil {
-			logrus.Errorf("redis lock failed, name:%s, err:%v", task.Name(), err.Error())
-			return
-		}
-		startTime := time.Now().Unix()
-		logrus.Infof("task %s start", task.Name())
-		metricValue := task.Run()
-		monitor.SetCronTaskStatusMetricValue(task.Name(), float64(metricValue))
-		//unlock redis mux
-		cache.GetRedisClient().Del(lockKey)
-		logrus.Infof("task %s end, time use %d(s), exec status: %d", task.Name(), time.Now().Unix()-startTime, metricValue)
+// SchedulerStarted whether tasks are started on this instance
+func SchedulerStarted() bool {
+	return scheduler != nil
+}
+
+// RunWithLock run fn once as a run of the task on the distributed scheduler, the run is skipped the same as a scheduled
+// one if the task is paused or locked by others. the task needs not be registered, e.g. the full statistics tasks run
+// by admin only. the run is recorded by the caller
+func RunWithLock(taskName string, fn func(ctx context.Context) int) (int, error) {
+	if scheduler == nil {
+		return -1, fmt.Errorf("tasks are not started on this instance, run task %s on a task instance", taskName)
+	}
+
+	t, scheduled := GetTask(taskName)
+	if !scheduled {
+		t = adminTask(taskName)
+	}
+	once := &onceTask{distributedTask: distributedTask{task: t}, scheduled: scheduled, fn: fn}
+	if !scheduler.RunOnceWithLock(once) {
+		return -1, fmt.Errorf("task %s is paused or running", taskName)
+	}
+	return once.res, nil
+}
+
+// onceTask distributedTask whose run is not recorded, the result of fn is kept in res
+type onceTask struct {
+	distributedTask
+	scheduled bool
+	fn        func(ctx context.Context) int
+	res       int
+}
+
+func (t *onceTask) Run(ctx context.Context) {
+	t.res = t.fn(ctx)
+}
+
+// adminTask a task which is not scheduled, it is run by admin only
+type adminTask string
+
+func (t adminTask) Name() string {
+	return string(t)
+}
+
+func (t adminTask) Cron() int {
+	return 0
+}
+
+func (t adminTask) Run(ctx context.Context) int {
+	return -1
+}
+
+// distributedTask adapter of Task for distributiontask.DistributedTask
+type distributedTask struct {
+	task Task
+}
+
+var _ distributiontask.LockFreeTask = new(distributedTask)
+var _ distributiontask.LockKeyTask = new(distributedTask)
+
+func (t *distributedTask) Name() string {
+	return t.task.Name()
+}
+
+func (t *distributedTask) LockKey() string {
+	return cache.TaskLockKey(t.task.Name())
+}
+
+// Cron the expression in task.cron_expressions, or every Task.Cron() seconds
+func (t *distributedTask) Cron() string {
+	if expr, ok := taskConf.CronExpressions[t.task.Name()]; ok && expr != "" {
+		return expr
+	}
+	return fmt.Sprintf("@every %ds", t.task.Cron())
+}
+
+func (t *distributedTask) BeforeHook() error {
+	if hook, ok := t.task.(interface{ BeforeHook() error }); ok {
+		return hook.BeforeHook()
+	}
+	return nil
+}
+
+func (t *distributedTask) Run(ctx context.Context) {
+	metricValue := RunWithRecord(t.task.Name(), entity.TaskRunTriggerSchedule, func() int {
+		return t.task.Run(ctx)
 	})
+	monitor.SetCronTaskStatusMetricValue(t.task.Name(), float64(metricValue))
+	logrus.Infof("task %s exec status: %d", t.task.Name(), metricValue)
+}
+
+// LockFree the chains of a chainLeasedTask are locked one by one
+func (t *distributedTask) LockFree() bool {
+	_, ok := t.task.(chainLeasedTask)
+	return ok
 }
 
 // ============================================================================
@@ -89,7 +176,7 @@ func RunOnce(task Task) {
 type OneOffTask interface {
 	Name() string
 	Switch() bool
-	Run() int
+	Run(ctx context.Context) int
 }
 
 var oneOffTasks []OneOffTask
@@ -103,14 +190,18 @@ func StartOneOffTask() {
 		return
 	}
 
-	_ibcChainConfigTask.Run() // run chain config task immediately
-	for _, v := range oneOffTasks {
-		task := v
-		go OneOffTaskRun(task)
-	}
+	GoRun(func(ctx context.Context) {
+		_ibcChainConfigTask.Run(ctx) // run chain config task immediately
+		for _, v := range oneOffTasks {
+			task := v
+			GoRun(func(ctx context.Context) {
+				OneOffTaskRun(ctx, task)
+			})
+		}
+	})
 }
 
-func OneOffTaskRun(task OneOffTask) {
+func OneOffTaskRun(ctx context.Context, task OneOffTask) {
 	if !task.Switch() {
 		logrus.Infof("one-off task %s closed", task.Name())
 		return
@@ -122,7 +213,9 @@ func OneOffTaskRun(task OneOffTask) {
 	}
 	logrus.Infof("one-off task %s start", task.Name())
 	startTime := time.Now().Unix()
-	res := task.Run()
+	res := RunWithRecord(task.Name(), entity.TaskRunTriggerOneOff, func() int {
+		return task.Run(ctx)
+	})
 
 	if res != 1 { // 为避免错误操作、重启、扩容等因素带来的风险，one-ff task 执行成功时不释放锁
 		_, _ = cache.GetRedisClient().Del(lockKey)
@@ -0,0 +1,49 @@
+package task
+
+import (
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/distributiontask"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// taskCronParser same as the parser of cron.WithSeconds
+var taskCronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// beforeDistributedRun report the schedule of the task, the run is skipped if the task is paused
+func beforeDistributedRun(t distributiontask.CronTask) bool {
+	if once, ok := t.(*onceTask); !ok || once.scheduled {
+		reportTaskSchedule(t)
+	}
+	paused, err := taskAdminCache.IsPaused(t.Name())
+	if err != nil {
+		logrus.Errorf("task %s check pause flag error, %v", t.Name(), err)
+		return true
+	}
+	if paused {
+		logrus.Infof("task %s is paused", t.Name())
+		return false
+	}
+	return true
+}
+
+func reportTaskSchedule(t distributiontask.CronTask) {
+	schedule := &dto.TaskScheduleDTO{
+		TaskName:   t.Name(),
+		Cron:       t.Cron(),
+		LockKey:    distributiontask.LockKey(t),
+		InstanceId: instanceId,
+		UpdateAt:   time.Now().Unix(),
+	}
+	if lf, ok := t.(distributiontask.LockFreeTask); ok {
+		schedule.LockFree = lf.LockFree()
+	}
+	if s, err := taskCronParser.Parse(t.Cron()); err == nil {
+		schedule.NextRunTime = s.Next(time.Now()).Unix()
+	}
+	if err := taskAdminCache.SetSchedule(schedule); err != nil {
+		logrus.Errorf("task %s report schedule error, %v", t.Name(), err)
+	}
+}
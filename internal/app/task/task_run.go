@@ -0,0 +1,169 @@
+package task
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const taskRunMaxErrors = 10
+
+// instanceId identifies the process in task runs and chain leases
+var instanceId = genInstanceId()
+
+func genInstanceId() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// runningTaskRuns task name -> recorder, workers add errors and counters to the running record of their task
+var (
+	runningTaskRuns    = make(map[string]*taskRunRecorder)
+	runningTaskRunsMux sync.RWMutex
+)
+
+type taskRunRecorder struct {
+	mux    sync.Mutex
+	run    *entity.IbcTaskRun
+	errors []string
+}
+
+// RunWithRecord run the task and record the run in ibc_task_run. a failure of recording is only logged, it never
+// stops the task.
+func RunWithRecord(taskName string, trigger entity.TaskRunTrigger, run func() int) int {
+	recorder := &taskRunRecorder{
+		run: &entity.IbcTaskRun{
+			Id:            primitive.NewObjectID(),
+			TaskName:      taskName,
+			Trigger:       trigger,
+			InstanceId:    instanceId,
+			StartTime:     time.Now().Unix(),
+			Status:        entity.TaskRunStatusRunning,
+			ChainCounters: map[string]map[string]int64{},
+		},
+	}
+	if err := taskRunRepo.Insert(recorder.run); err != nil {
+		logrus.Errorf("task %s insert task run error, %v", taskName, err)
+	}
+	runningTaskRunsMux.Lock()
+	runningTaskRuns[taskName] = recorder
+	runningTaskRunsMux.Unlock()
+
+	startTime := time.Now()
+	defer func() {
+		if p := recover(); p != nil {
+			recorder.addError(fmt.Sprintf("panic: %v", p))
+			recorder.finish(startTime, -1)
+			panic(p)
+		}
+	}()
+	exitStatus := run()
+	recorder.finish(startTime, exitStatus)
+	return exitStatus
+}
+
+func (r *taskRunRecorder) addError(msg string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if len(r.errors) < taskRunMaxErrors {
+		r.errors = append(r.errors, msg)
+	}
+}
+
+func (r *taskRunRecorder) addCounter(chain, counter string, delta int64) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.run.ChainCounters[chain] == nil {
+		r.run.ChainCounters[chain] = make(map[string]int64)
+	}
+	r.run.ChainCounters[chain][counter] += delta
+}
+
+// setProgress the progress is saved when its integer part changes, so a run is updated 100 times at most
+func (r *taskRunRecorder) setProgress(progress float64) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	changed := int(progress) != int(r.run.Progress)
+	r.run.Progress = progress
+	if changed {
+		if err := taskRunRepo.UpdateProgress(r.run.Id, progress); err != nil {
+			logrus.Errorf("task %s update task run progress error, %v", r.run.TaskName, err)
+		}
+	}
+}
+
+func (r *taskRunRecorder) setReport(report *entity.DryRunReport) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.run.DryRunReport = report
+}
+
+func (r *taskRunRecorder) finish(startTime time.Time, exitStatus int) {
+	runningTaskRunsMux.Lock()
+	if runningTaskRuns[r.run.TaskName] == r {
+		delete(runningTaskRuns, r.run.TaskName)
+	}
+	runningTaskRunsMux.Unlock()
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.run.EndTime = time.Now().Unix()
+	r.run.Duration = time.Since(startTime).Milliseconds()
+	r.run.ExitStatus = exitStatus
+	r.run.Status = entity.TaskRunStatusSuccess
+	if exitStatus < 0 {
+		r.run.Status = entity.TaskRunStatusFailed
+		if len(r.errors) == 0 {
+			r.errors = append(r.errors, fmt.Sprintf("exec status: %d", exitStatus))
+		}
+	}
+	r.run.ErrorMsg = strings.Join(r.errors, "; ")
+	if err := taskRunRepo.Finish(r.run); err != nil {
+		logrus.Errorf("task %s finish task run error, %v", r.run.TaskName, err)
+	}
+}
+
+func getTaskRunRecorder(taskName string) (*taskRunRecorder, bool) {
+	runningTaskRunsMux.RLock()
+	defer runningTaskRunsMux.RUnlock()
+	recorder, ok := runningTaskRuns[taskName]
+	return recorder, ok
+}
+
+// addTaskRunError the error is added to the error summary of the running record of the task
+func addTaskRunError(taskName, chain string, err error) {
+	if recorder, ok := getTaskRunRecorder(taskName); ok {
+		recorder.addError(fmt.Sprintf("%s: %v", chain, err))
+	}
+}
+
+// addTaskRunCounter the counter of the chain is added to the running record of the task
+func addTaskRunCounter(taskName, chain, counter string, delta int64) {
+	if recorder, ok := getTaskRunRecorder(taskName); ok {
+		recorder.addCounter(chain, counter, delta)
+	}
+}
+
+// setTaskRunProgress the percent complete of the running record of the task, done of total
+func setTaskRunProgress(taskName string, done, total int) {
+	if total <= 0 {
+		return
+	}
+	if recorder, ok := getTaskRunRecorder(taskName); ok {
+		recorder.setProgress(math.Round(float64(done)*10000/float64(total)) / 100)
+	}
+}
+
+// setTaskRunReport the dry run report is saved with the running record of the task when it finishes
+func setTaskRunReport(taskName string, report *entity.DryRunReport) {
+	if recorder, ok := getTaskRunRecorder(taskName); ok {
+		recorder.setReport(report)
+	}
+}
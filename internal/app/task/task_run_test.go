@@ -0,0 +1,29 @@
+package task
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+func Test_RunWithRecord(t *testing.T) {
+	taskName := "task_run_test"
+	res := RunWithRecord(taskName, entity.TaskRunTriggerAdmin, func() int {
+		addTaskRunCounter(taskName, "irishub_qa", "txs_parsed", 100)
+		addTaskRunError(taskName, "irishub_qa", fmt.Errorf("lcd timeout"))
+		return -1
+	})
+	if res != -1 {
+		t.Fatalf("unexpected exit status %d", res)
+	}
+
+	runs, err := taskRunRepo.FindRuns(taskName, entity.TaskRunStatusFailed, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) == 0 || runs[0].ChainCounters["irishub_qa"]["txs_parsed"] != 100 {
+		t.Fatalf("unexpected task runs %+v", runs)
+	}
+	t.Log(runs[0].ErrorMsg)
+}
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
@@ -15,6 +16,7 @@ const (
 	OneDay               = 86400
 	OneWeek              = 86400 * 7
 	RedisLockExpireTime  = 300
+	ChainLeaseExpireTime = 60
 	OneOffTaskLockTime   = 86400 * 30
 	statisticsCheckTimes = 5
 )
@@ -36,6 +38,8 @@ const (
 	ibcTxRelateTaskWorkerNum    = 5
 	relayerStatisticsWorkerNum  = 4
 	defaultMaxHandlerTx         = 2000
+	ibcTxDeadLetterRetryTimes   = 300 // the retry interval grows by 2s each retry, 300 retries take about 25 hours
+	statisticsCheckpointMaxAge  = 3 * OneDay
 	ibcTxTargetLatest           = "latest"
 	ibcTxTargetHistory          = "history"
 
@@ -43,6 +47,12 @@ const (
 	segmentStepHistory = 24 * 3600
 
 	relayerAddressGatherRangeTime = 7 * 86400
+
+	channelsPageLimit      = 200
+	channelsPageRetryTimes = 3
+
+	clientStatesPageLimit = 100
+	clientUpdateTxWindow  = 30 * OneDay
 )
 
 var (
@@ -54,6 +64,7 @@ var (
 	chainCache                 cache.ChainCacheRepo
 	authDenomCache             cache.AuthDenomCacheRepo
 	chainFlowCacheRepo         cache.ChainFlowCacheRepo
+	taskAdminCache             cache.TaskAdminCacheRepo
 	lcdTxDataCacheRepo         cache.LcdTxDataCacheRepo
 	tokenRepo                  repository.ITokenRepo                  = new(repository.TokenRepo)
 	tokenTraceRepo             repository.ITokenTraceRepo             = new(repository.TokenTraceRepo)
@@ -63,6 +74,13 @@ var (
 	denomRepo                  repository.IDenomRepo                  = new(repository.DenomRepo)
 	chainConfigRepo            repository.IChainConfigRepo            = new(repository.ChainConfigRepo)
 	ibcTxRepo                  repository.IExIbcTxRepo                = new(repository.ExIbcTxRepo)
+	ibcNftTxRepo               repository.IExIbcNftTxRepo             = new(repository.ExIbcNftTxRepo)
+	ibcIcaTxRepo               repository.IExIbcIcaTxRepo             = new(repository.ExIbcIcaTxRepo)
+	icaAccountRepo             repository.IIcaAccountRepo             = new(repository.IcaAccountRepo)
+	packetFeeRepo              repository.IPacketFeeRepo              = new(repository.PacketFeeRepo)
+	feePayeeRepo               repository.IFeePayeeRepo               = new(repository.FeePayeeRepo)
+	feeDistributionRepo        repository.IFeeDistributionRepo        = new(repository.FeeDistributionRepo)
+	ibcTxDeadLetterRepo        repository.IIbcTxDeadLetterRepo        = new(repository.IbcTxDeadLetterRepo)
 	chainRepo                  repository.IChainRepo                  = new(repository.IbcChainRepo)
 	relayerRepo                repository.IRelayerRepo                = new(repository.IbcRelayerRepo)
 	txRepo                     repository.ITxRepo                     = new(repository.TxRepo)
@@ -75,6 +93,7 @@ var (
 	relayerAddressRepo         repository.IRelayerAddressRepo         = new(repository.RelayerAddressRepo)
 	statisticsRepo             repository.IStatisticRepo              = new(repository.IbcStatisticRepo)
 	taskRecordRepo             repository.ITaskRecordRepo             = new(repository.TaskRecordRepo)
+	taskRunRepo                repository.ITaskRunRepo                = new(repository.TaskRunRepo)
 	syncTaskRepo               repository.ISyncTaskRepo               = new(repository.SyncTaskRepo)
 	syncBlockRepo              repository.ISyncBlockRepo              = new(repository.SyncBlockRepo)
 	txNewRepo                  repository.ITxNewRepo                  = new(repository.TxNewRepo)
@@ -82,6 +101,9 @@ var (
 	denomHeatmap               repository.IDenomHeatmap               = new(repository.DenomHeatmap)
 	chainInflowStatisticsRepo  repository.IChainInflowStatisticsRepo  = new(repository.ChainInflowStatisticsRepo)
 	chainOutflowStatisticsRepo repository.IChainOutflowStatisticsRepo = new(repository.ChainOutflowStatisticsRepo)
+	packetLatencyRepo          repository.IPacketLatencyRepo          = new(repository.PacketLatencyRepo)
+	statisticsCheckpointRepo   repository.IStatisticsCheckpointRepo   = new(repository.StatisticsCheckpointRepo)
+	ibcClientRepo              repository.IIBCClientRepo              = new(repository.IBCClientRepo)
 	relayerStatisticsTask      RelayerStatisticsTask
 )
 
@@ -89,10 +111,13 @@ type stringQueueCoordinator struct {
 	stringQueue *utils.QueueString
 }
 
-func (coordinator *stringQueueCoordinator) getOne() (string, error) {
+func (coordinator *stringQueueCoordinator) getOne(ctx context.Context) (string, error) {
 	if coordinator.stringQueue == nil {
 		return "", fmt.Errorf("coordinator or string queue is nil")
 	}
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("task is stopping")
+	}
 
 	return coordinator.stringQueue.Pop()
 }
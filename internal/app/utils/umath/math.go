@@ -2,6 +2,8 @@ package umath
 
 import (
 	"fmt"
+	"math"
+
 	"github.com/shopspring/decimal"
 )
 
@@ -33,3 +35,43 @@ func CalculateRate(dividend, divisor, precision int64) float64 {
 		Float64()
 	return value
 }
+
+// Percentile nearest-rank percentile of the sorted values, p is in (0, 100]
+func Percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// HistogramPercentile percentile estimated from the counts of buckets, the upper bound of the bucket is taken.
+// counts has one more item than bounds for the values greater than the last bound, which takes the last bound too.
+func HistogramPercentile(bounds, counts []int64, p float64) int64 {
+	var total int64
+	for _, v := range counts {
+		total += v
+	}
+	if total == 0 || len(bounds) == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(total)))
+	var cumulative int64
+	for i, v := range counts {
+		cumulative += v
+		if cumulative >= rank {
+			if i >= len(bounds) {
+				break
+			}
+			return bounds[i]
+		}
+	}
+	return bounds[len(bounds)-1]
+}
@@ -21,3 +21,25 @@ func TestPercent(t *testing.T) {
 	fmt.Println(CalculateRate(321, 0, 2))
 
 }
+
+func TestPercentile(t *testing.T) {
+	sorted := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if v := Percentile(sorted, 50); v != 5 {
+		t.Fatalf("p50 expect 5, got %d", v)
+	}
+	if v := Percentile(sorted, 99); v != 10 {
+		t.Fatalf("p99 expect 10, got %d", v)
+	}
+
+	bounds := []int64{10, 30, 60}
+	counts := []int64{5, 3, 1, 1}
+	if v := HistogramPercentile(bounds, counts, 50); v != 10 {
+		t.Fatalf("histogram p50 expect 10, got %d", v)
+	}
+	if v := HistogramPercentile(bounds, counts, 90); v != 60 {
+		t.Fatalf("histogram p90 expect 60, got %d", v)
+	}
+	if v := HistogramPercentile(bounds, counts, 99); v != 60 {
+		t.Fatalf("histogram p99 expect 60, got %d", v)
+	}
+}
@@ -192,6 +192,34 @@ db.getCollection("ex_ibc_tx").createIndex({
     background: true
 });
 
+db.getCollection("ex_ibc_tx").createIndex({
+    "sc_tx_info.msg.msg.packet_id": 1,
+    "sc_chain": 1
+}, {
+    background: true
+});
+
+db.getCollection("ex_ibc_tx").createIndex({
+    "record_id": 1
+}, {
+    background: true
+});
+
+db.getCollection("ex_ibc_tx").createIndex({
+    "journey_id": 1
+}, {
+    background: true,
+    partialFilterExpression: {"journey_id": {$gt: ""}}
+});
+
+db.getCollection("ex_ibc_tx").createIndex({
+    "error_category": 1,
+    "tx_time": -1
+}, {
+    background: true,
+    partialFilterExpression: {"error_category": {$gt: ""}}
+});
+
 // ex_ibc_tx_latest表
 
 db.getCollection("ex_ibc_tx_latest").createIndex({
@@ -290,6 +318,34 @@ db.getCollection("ex_ibc_tx_latest").createIndex({
     background: true
 });
 
+db.getCollection("ex_ibc_tx_latest").createIndex({
+    "sc_tx_info.msg.msg.packet_id": 1,
+    "sc_chain": 1
+}, {
+    background: true
+});
+
+db.getCollection("ex_ibc_tx_latest").createIndex({
+    "record_id": 1
+}, {
+    background: true
+});
+
+db.getCollection("ex_ibc_tx_latest").createIndex({
+    "journey_id": 1
+}, {
+    background: true,
+    partialFilterExpression: {"journey_id": {$gt: ""}}
+});
+
+db.getCollection("ex_ibc_tx_latest").createIndex({
+    "error_category": 1,
+    "tx_time": -1
+}, {
+    background: true,
+    partialFilterExpression: {"error_category": {$gt: ""}}
+});
+
 // sync_{chain}_tx表
 db.sync_xxxx_tx.createIndex({"tx_hash": -1, "height": -1}, {unique: true, background: true});
 db.sync_xxxx_tx.createIndex({"height": -1}, {background: true});
@@ -297,6 +353,11 @@ db.sync_xxxx_tx.createIndex({"types": -1, "height": -1}, {background: true});
 db.sync_xxxx_tx.createIndex({"msgs.msg.packet_id": -1}, {background: true});
 db.sync_xxxx_tx.createIndex({"msgs.msg.signer": 1, "msgs.type": 1, "time": 1}, {background: true});
 db.sync_xxxx_tx.createIndex({"time": -1, "msgs.type": -1}, {background: true});
+db.sync_xxxx_tx.createIndex({"msgs.msg.client_id": 1, "msgs.type": 1, "time": 1}, {background: true});
+
+// ibc_client
+db.ibc_client.createIndex({"chain": 1, "client_id": 1}, {unique: true, background: true});
+db.ibc_client.createIndex({"expire_time": 1}, {background: true});
 
 // uba_search_record
 db.uba_search_record.createIndex({
@@ -358,4 +419,245 @@ db.denom_heatmap.createIndex({
     "statistics_time": 1
 }, {
     "background": true
-})
\ No newline at end of file
+})
+// ex_ibc_nft_tx表
+db.ex_ibc_nft_tx.createIndex({
+    "sc_tx_info.hash": 1,
+    "sc_tx_info.height": 1,
+    "sc_chain": 1,
+    "sc_tx_info.msg.msg.packet_id": 1
+}, {
+    name: "sc_tx_unique",
+    background: true,
+    unique: true
+});
+
+db.ex_ibc_nft_tx.createIndex({
+    "dc_tx_info.hash": -1,
+}, {
+    background: true
+});
+
+db.ex_ibc_nft_tx.createIndex({
+    "ack_timeout_tx_info.hash": -1,
+}, {
+    background: true
+});
+
+db.ex_ibc_nft_tx.createIndex({
+    "sc_chain": 1,
+    "status": 1,
+    "next_try_time": 1
+}, {
+    background: true
+});
+
+db.ex_ibc_nft_tx.createIndex({
+    "tx_time": -1,
+    "status": 1
+}, {
+    background: true
+});
+
+db.ex_ibc_nft_tx.createIndex({
+    "class.base_class_id": 1,
+    "class.base_class_chain": 1
+}, {
+    background: true
+});
+
+// ex_ibc_ica_tx表
+db.ex_ibc_ica_tx.createIndex({
+    "sc_tx_info.hash": 1,
+    "sc_tx_info.height": 1,
+    "sc_chain": 1,
+    "packet_id": 1
+}, {
+    name: "sc_tx_unique",
+    background: true,
+    unique: true
+});
+
+db.ex_ibc_ica_tx.createIndex({
+    "dc_tx_info.hash": -1,
+}, {
+    background: true
+});
+
+db.ex_ibc_ica_tx.createIndex({
+    "ack_timeout_tx_info.hash": -1,
+}, {
+    background: true
+});
+
+db.ex_ibc_ica_tx.createIndex({
+    "sc_chain": 1,
+    "status": 1,
+    "next_try_time": 1
+}, {
+    background: true
+});
+
+db.ex_ibc_ica_tx.createIndex({
+    "sc_addr": 1,
+    "tx_time": -1
+}, {
+    background: true
+});
+
+db.ex_ibc_ica_tx.createIndex({
+    "dc_chain": 1,
+    "tx_time": -1
+}, {
+    background: true
+});
+
+// ibc_ica_account表
+db.ibc_ica_account.createIndex({
+    "controller_chain": 1,
+    "controller_port": 1,
+    "controller_connection_id": 1
+}, {
+    background: true,
+    unique: true
+});
+
+db.ibc_ica_account.createIndex({
+    "controller_address": 1
+}, {
+    background: true
+});
+
+db.ibc_ica_account.createIndex({
+    "host_chain": 1
+}, {
+    background: true
+});
+
+// ibc_packet_fee表
+db.ibc_packet_fee.createIndex({
+    "chain": 1,
+    "tx_hash": 1,
+    "msg_index": 1
+}, {
+    background: true,
+    unique: true
+});
+
+db.ibc_packet_fee.createIndex({
+    "chain": 1,
+    "port_id": 1,
+    "channel_id": 1,
+    "sequence": 1
+}, {
+    background: true
+});
+
+// ibc_fee_payee表
+db.ibc_fee_payee.createIndex({
+    "chain": 1,
+    "port_id": 1,
+    "channel_id": 1,
+    "relayer": 1,
+    "payee_type": 1
+}, {
+    background: true,
+    unique: true
+});
+
+db.ibc_fee_payee.createIndex({
+    "relayer": 1
+}, {
+    background: true
+});
+
+// ibc_fee_distribution表
+db.ibc_fee_distribution.createIndex({
+    "chain": 1,
+    "tx_hash": 1,
+    "msg_index": 1,
+    "distribute_index": 1,
+    "fee_denom": 1
+}, {
+    background: true,
+    unique: true
+});
+
+db.ibc_fee_distribution.createIndex({
+    "chain_address_comb": 1,
+    "is_refund": 1
+}, {
+    background: true
+});
+
+// ibc_tx_dead_letter表
+db.ibc_tx_dead_letter.createIndex({
+    "record_id": 1
+}, {
+    background: true,
+    unique: true
+});
+
+db.ibc_tx_dead_letter.createIndex({
+    "reason_code": 1,
+    "create_at": -1
+}, {
+    background: true
+});
+
+// ibc_packet_latency_statistics表
+db.ibc_packet_latency_statistics.createIndex({
+    "sc_chain": 1,
+    "sc_channel": 1,
+    "period": 1,
+    "segment_start_time": 1
+}, {
+    background: true
+});
+
+db.ibc_packet_latency_statistics.createIndex({
+    "dc_chain": 1,
+    "dc_channel": 1,
+    "period": 1,
+    "segment_start_time": 1
+}, {
+    background: true
+});
+
+db.ibc_packet_latency_statistics.createIndex({
+    "relayer": 1,
+    "period": 1,
+    "segment_start_time": 1
+}, {
+    background: true
+});
+
+db.ibc_packet_latency_statistics.createIndex({
+    "segment_start_time": 1,
+    "segment_end_time": 1
+}, {
+    background: true
+});
+
+// ibc_task_run表
+db.ibc_task_run.createIndex({
+    "task_name": 1,
+    "start_time": -1
+}, {
+    background: true
+});
+
+db.ibc_task_run.createIndex({
+    "start_time": -1
+}, {
+    background: true
+});
+
+// ibc_statistics_checkpoint表
+db.ibc_statistics_checkpoint.createIndex({
+    "task_name": 1,
+    "scope": 1
+}, {
+    background: true,
+    unique: true
+});